@@ -14,8 +14,12 @@ import (
 const defaultServiceName = "graphql"
 
 type config struct {
-	serviceName   string
-	analyticsRate float64
+	serviceName          string
+	analyticsRate        float64
+	skipTrivialResolvers bool
+
+	// variablesAllowlist holds the names of the variables tagged on the operation spans.
+	variablesAllowlist []string
 }
 
 // An Option configures the gqlgen integration.
@@ -47,3 +51,21 @@ func WithServiceName(name string) Option {
 		t.serviceName = name
 	}
 }
+
+// WithoutTraceTrivialResolvers disables the spans of the fields resolved without a resolver
+// nor a method, which only read a field of their parent object. Their resolution is usually
+// too fast to be of interest, and may produce many spans for large lists.
+func WithoutTraceTrivialResolvers() Option {
+	return func(t *config) {
+		t.skipTrivialResolvers = true
+	}
+}
+
+// WithVariablesAllowlist tags the operation spans with the values of the variables of the
+// given names, as "graphql.variables.<name>". Variables aren't tagged by default, since they
+// are where the queries are expected to hold their sensitive data.
+func WithVariablesAllowlist(names ...string) Option {
+	return func(t *config) {
+		t.variablesAllowlist = append(t.variablesAllowlist, names...)
+	}
+}
@@ -7,10 +7,10 @@
 // to construct and configure the tracer. The tracer can be passed to the gqlgen
 // handler (see package github.com/99designs/gqlgen/handler)
 //
-// Warning: Data obfuscation hasn't been implemented for graphql queries yet,
-// any sensitive data in the query will be sent to Datadog as the resource name
-// of the span. To ensure no sensitive data is included in your spans, always
-// use parameterized graphql queries with sensitive data in variables.
+// The literal values of the queries are obfuscated before they are used as the
+// resource names of the spans, and the variables are left out, unless allowed
+// with WithVariablesAllowlist. Each error of a response is recorded as an event
+// of its span, along with its path and locations.
 //
 // Usage example:
 //
@@ -44,12 +44,15 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/graphqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -65,15 +68,28 @@ func init() {
 const (
 	defaultGraphqlOperation = "graphql.request"
 
-	readOp       = "graphql.read"
-	parsingOp    = "graphql.parse"
-	validationOp = "graphql.validate"
+	readOp              = "graphql.read"
+	parsingOp           = "graphql.parse"
+	validationOp        = "graphql.validate"
+	fieldOp             = "graphql.field"
+	subscriptionEventOp = "graphql.subscription.event"
+
+	tagFieldName       = "graphql.field.name"
+	tagFieldPath       = "graphql.field.path"
+	tagFieldParentType = "graphql.field.parent_type"
+	tagVariables       = "graphql.variables."
+
+	// errorEvent is the name of the span events recording the errors of a response.
+	errorEvent = "dd.graphql.query.error"
 )
 
 type gqlTracer struct {
 	cfg *config
 }
 
+// subscriptionKey is the context key of the span context of the start of a subscription.
+type subscriptionKey struct{}
+
 // NewTracer creates a graphql.HandlerExtension instance that can be used with
 // a graphql.handler.Server.
 // Options can be passed in for further configuration.
@@ -94,42 +110,40 @@ func (t *gqlTracer) Validate(_ graphql.ExecutableSchema) error {
 	return nil // unimplemented
 }
 
-func (t *gqlTracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
-	opts := []ddtrace.StartSpanOption{
-		tracer.SpanType(ext.SpanTypeGraphQL),
-		tracer.ServiceName(t.cfg.serviceName),
-		tracer.Tag(ext.Component, componentName),
+// InterceptOperation traces the start of the subscriptions, which resolves their root field.
+// Their events are traced by InterceptResponse. The other operations are traced by
+// InterceptResponse only.
+func (t *gqlTracer) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
 	}
-	if !math.IsNaN(t.cfg.analyticsRate) {
-		opts = append(opts, tracer.Tag(ext.EventSampleRate, t.cfg.analyticsRate))
+	octx := graphql.GetOperationContext(ctx)
+	if octx.Operation == nil || octx.Operation.Operation != ast.Subscription {
+		return next(ctx)
+	}
+	span, ctx := t.startOperationSpan(ctx, octx)
+	ctx = context.WithValue(ctx, subscriptionKey{}, span.Context())
+	defer span.Finish()
+	return next(ctx)
+}
+
+func (t *gqlTracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if sctx, ok := ctx.Value(subscriptionKey{}).(ddtrace.SpanContext); ok {
+		return t.interceptSubscriptionEvent(ctx, sctx, next)
 	}
 	var (
+		span ddtrace.Span
 		octx *graphql.OperationContext
 	)
-	name := defaultGraphqlOperation
 	if graphql.HasOperationContext(ctx) {
-		// Variables in the operation will be left out of the tags
-		// until obfuscation is implemented in the agent.
 		octx = graphql.GetOperationContext(ctx)
-		if octx.Operation != nil {
-			if octx.Operation.Operation == ast.Subscription {
-				// These are long running queries for a subscription,
-				// remaining open indefinitely until a subscription ends.
-				// Return early and do not create these spans.
-				return next(ctx)
-			}
-			name = fmt.Sprintf("%s.%s", ext.SpanTypeGraphQL, octx.Operation.Operation)
-		}
-		if octx.RawQuery != "" {
-			opts = append(opts, tracer.ResourceName(octx.RawQuery))
-		}
-		opts = append(opts, tracer.StartTime(octx.Stats.OperationStart))
 	}
-	var span ddtrace.Span
-	span, ctx = tracer.StartSpanFromContext(ctx, name, opts...)
+	span, ctx = t.startOperationSpan(ctx, octx)
 	defer func() {
+		gqlErrs := graphql.GetErrors(ctx)
+		addErrorEvents(span, gqlErrs)
 		var errs []string
-		for _, err := range graphql.GetErrors(ctx) {
+		for _, err := range gqlErrs {
 			errs = append(errs, err.Message)
 		}
 		var err error
@@ -138,7 +152,36 @@ func (t *gqlTracer) InterceptResponse(ctx context.Context, next graphql.Response
 		}
 		span.Finish(tracer.WithError(err))
 	}()
+	return next(ctx)
+}
 
+// startOperationSpan starts the span of the operation of octx, which may be nil, along with
+// the child spans of its read, parsing and validation.
+func (t *gqlTracer) startOperationSpan(ctx context.Context, octx *graphql.OperationContext) (ddtrace.Span, context.Context) {
+	opts := []ddtrace.StartSpanOption{
+		tracer.SpanType(ext.SpanTypeGraphQL),
+		tracer.ServiceName(t.cfg.serviceName),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if !math.IsNaN(t.cfg.analyticsRate) {
+		opts = append(opts, tracer.Tag(ext.EventSampleRate, t.cfg.analyticsRate))
+	}
+	name := defaultGraphqlOperation
+	if octx != nil {
+		if octx.Operation != nil {
+			name = fmt.Sprintf("%s.%s", ext.SpanTypeGraphQL, octx.Operation.Operation)
+		}
+		if octx.RawQuery != "" {
+			opts = append(opts, tracer.ResourceName(graphqlobfuscate.Query(octx.RawQuery)))
+		}
+		for _, k := range t.cfg.variablesAllowlist {
+			if v, ok := octx.Variables[k]; ok {
+				opts = append(opts, tracer.Tag(tagVariables+k, v))
+			}
+		}
+		opts = append(opts, tracer.StartTime(octx.Stats.OperationStart))
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, name, opts...)
 	if octx != nil {
 		// Create child spans based on the stats in the operation context.
 		createChildSpan := func(name string, start, finish time.Time) {
@@ -154,11 +197,95 @@ func (t *gqlTracer) InterceptResponse(ctx context.Context, next graphql.Response
 		createChildSpan(parsingOp, octx.Stats.Parsing.Start, octx.Stats.Parsing.End)
 		createChildSpan(validationOp, octx.Stats.Validation.Start, octx.Stats.Validation.End)
 	}
-	return next(ctx)
+	return span, ctx
+}
+
+// interceptSubscriptionEvent traces an event of the subscription started with the span
+// context sctx. Each event is the root of its own trace, linked to the subscription start,
+// as subscriptions may remain open indefinitely.
+func (t *gqlTracer) interceptSubscriptionEvent(ctx context.Context, sctx ddtrace.SpanContext, next graphql.ResponseHandler) *graphql.Response {
+	opts := []ddtrace.StartSpanOption{
+		tracer.SpanType(ext.SpanTypeGraphQL),
+		tracer.ServiceName(t.cfg.serviceName),
+		tracer.Tag(ext.Component, componentName),
+		tracer.WithSpanLinks([]ddtrace.SpanLink{{TraceID: sctx.TraceID(), SpanID: sctx.SpanID()}}),
+	}
+	if !math.IsNaN(t.cfg.analyticsRate) {
+		opts = append(opts, tracer.Tag(ext.EventSampleRate, t.cfg.analyticsRate))
+	}
+	if octx := graphql.GetOperationContext(ctx); octx.RawQuery != "" {
+		opts = append(opts, tracer.ResourceName(graphqlobfuscate.Query(octx.RawQuery)))
+	}
+	span := tracer.StartSpan(subscriptionEventOp, opts...)
+	resp := next(tracer.ContextWithSpan(ctx, span))
+	if resp == nil {
+		// the subscription ended: there was no event.
+		span.SetTag(ext.ManualDrop, true)
+		span.Finish()
+		return nil
+	}
+	addErrorEvents(span, resp.Errors)
+	var err error
+	if len(resp.Errors) > 0 {
+		err = resp.Errors
+	}
+	span.Finish(tracer.WithError(err))
+	return resp
+}
+
+// addErrorEvents adds an event to span for each error of the response, when the span
+// supports events.
+func addErrorEvents(span ddtrace.Span, errs gqlerror.List) {
+	s, ok := span.(ddtrace.SpanWithEvents)
+	if !ok {
+		return
+	}
+	for _, err := range errs {
+		attrs := map[string]interface{}{"message": err.Message}
+		if len(err.Path) > 0 {
+			path := make([]string, len(err.Path))
+			for i, p := range err.Path {
+				path[i] = fmt.Sprint(p)
+			}
+			attrs["path"] = path
+		}
+		if len(err.Locations) > 0 {
+			locs := make([]string, len(err.Locations))
+			for i, l := range err.Locations {
+				locs[i] = strconv.Itoa(l.Line) + ":" + strconv.Itoa(l.Column)
+			}
+			attrs["locations"] = locs
+		}
+		s.AddEvent(errorEvent, tracer.WithSpanEventAttributes(attrs))
+	}
+}
+
+// InterceptField traces the resolution of each field, unless the tracer was configured to
+// skip the trivial resolvers.
+func (t *gqlTracer) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || (t.cfg.skipTrivialResolvers && !fc.IsMethod && !fc.IsResolver) {
+		return next(ctx)
+	}
+	opts := []ddtrace.StartSpanOption{
+		tracer.SpanType(ext.SpanTypeGraphQL),
+		tracer.ServiceName(t.cfg.serviceName),
+		tracer.ResourceName(fc.Object + "." + fc.Field.Name),
+		tracer.Tag(ext.Component, componentName),
+		tracer.Tag(tagFieldName, fc.Field.Name),
+		tracer.Tag(tagFieldPath, fc.Path().String()),
+		tracer.Tag(tagFieldParentType, fc.Object),
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, fieldOp, opts...)
+	res, err := next(ctx)
+	span.Finish(tracer.WithError(err))
+	return res, err
 }
 
 // Ensure all of these interfaces are implemented.
 var _ interface {
 	graphql.HandlerExtension
+	graphql.OperationInterceptor
 	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
 } = &gqlTracer{}
@@ -6,6 +6,9 @@
 package gqlgen
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/99designs/gqlgen/client"
@@ -13,9 +16,13 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler/testserver"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 )
 
 func TestOptions(t *testing.T) {
@@ -120,7 +127,80 @@ func TestObfuscation(t *testing.T) {
 	// No spans should contain the sensitive ID.
 	for _, span := range mt.FinishedSpans() {
 		assert.NotContains(span.Tag(ext.ResourceName), "12345")
+		assert.Nil(span.Tag("graphql.variables.id"))
 	}
+
+	// Nor when it is a literal of the query.
+	mt.Reset()
+	err = c.Post(`{ name, find(id: 12345) }`, &resp)
+	assert.Nil(err)
+	root := mt.FinishedSpans()[len(mt.FinishedSpans())-1]
+	assert.Equal("graphql.query", root.OperationName())
+	assert.Equal(`{ name, find(id: ?) }`, root.Tag(ext.ResourceName))
+}
+
+func TestVariablesAllowlist(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	c := newTestClient(t, testserver.New(), NewTracer(WithVariablesAllowlist("id", "missing")))
+	var resp struct {
+		Name string
+	}
+	err := c.Post(`query($id: Int!) { find(id: $id) }`, &resp, client.Var("id", 12345))
+	assert.Nil(err)
+	root := mt.FinishedSpans()[len(mt.FinishedSpans())-1]
+	assert.Equal("graphql.query", root.OperationName())
+	assert.Equal("12345", fmt.Sprint(root.Tag("graphql.variables.id")))
+	assert.Nil(root.Tag("graphql.variables.missing"))
+}
+
+func TestErrorEvents(t *testing.T) {
+	t.Run("validation", func(t *testing.T) {
+		assert := assert.New(t)
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		c := newTestClient(t, testserver.New(), NewTracer())
+		var resp struct {
+			Name string
+		}
+		err := c.Post("{\n  name\n  unknown\n  other\n}", &resp)
+		assert.NotNil(err)
+		var root mocktracer.Span
+		for _, span := range mt.FinishedSpans() {
+			if span.ParentID() == 0 {
+				root = span
+			}
+		}
+		assert.NotNil(root.Tag(ext.Error))
+		events := root.Events()
+		assert.Len(events, 2)
+		for i, e := range events {
+			assert.Equal("dd.graphql.query.error", e.Name)
+			assert.Contains(e.Attributes["message"], "Cannot query field")
+			assert.Equal([]string{fmt.Sprintf("%d:3", i+3)}, e.Attributes["locations"])
+		}
+	})
+
+	t.Run("path", func(t *testing.T) {
+		assert := assert.New(t)
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		span := tracer.StartSpan("graphql.query")
+		addErrorEvents(span, gqlerror.List{{
+			Message:   "resolver error",
+			Path:      ast.Path{ast.PathName("users"), ast.PathIndex(1), ast.PathName("name")},
+			Locations: []gqlerror.Location{{Line: 1, Column: 9}},
+		}})
+		span.Finish()
+		events := mt.FinishedSpans()[0].Events()
+		assert.Len(events, 1)
+		assert.Equal(map[string]interface{}{
+			"message":   "resolver error",
+			"path":      []string{"users", "1", "name"},
+			"locations": []string{"1:9"},
+		}, events[0].Attributes)
+	})
 }
 
 func TestChildSpans(t *testing.T) {
@@ -146,12 +226,109 @@ func TestChildSpans(t *testing.T) {
 		opNames = append(opNames, span.OperationName())
 		assert.Equal("99designs/gqlgen", span.Tag(ext.Component))
 	}
-	assert.ElementsMatch(resNames, []string{readOp, validationOp, parsingOp, query})
-	assert.ElementsMatch(opNames, []string{readOp, validationOp, parsingOp, "graphql.query"})
+	assert.ElementsMatch(resNames, []string{readOp, validationOp, parsingOp, "Query.name", query})
+	assert.ElementsMatch(opNames, []string{readOp, validationOp, parsingOp, fieldOp, "graphql.query"})
 	assert.NotNil(root)
 	assert.Nil(root.Tag(ext.Error))
 }
 
+func TestFieldSpans(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		assert := assert.New(t)
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		c := newTestClient(t, testserver.New(), NewTracer(WithServiceName("TestServer")))
+		var resp struct {
+			Name string
+		}
+		c.MustPost(`{ name }`, &resp)
+
+		var root, field mocktracer.Span
+		for _, span := range mt.FinishedSpans() {
+			switch span.OperationName() {
+			case "graphql.query":
+				root = span
+			case fieldOp:
+				field = span
+			}
+		}
+		assert.NotNil(root)
+		assert.NotNil(field)
+		assert.Equal(root.SpanID(), field.ParentID())
+		assert.Equal("Query.name", field.Tag(ext.ResourceName))
+		assert.Equal("TestServer", field.Tag(ext.ServiceName))
+		assert.Equal(ext.SpanTypeGraphQL, field.Tag(ext.SpanType))
+		assert.Equal("name", field.Tag(tagFieldName))
+		assert.Equal("name", field.Tag(tagFieldPath))
+		assert.Equal("Query", field.Tag(tagFieldParentType))
+	})
+
+	t.Run("trivial", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		gt := NewTracer(WithoutTraceTrivialResolvers()).(*gqlTracer)
+		resolve := func(ctx context.Context) (interface{}, error) { return "test", nil }
+		fieldContext := func(fc *graphql.FieldContext) context.Context {
+			fc.Object = "Query"
+			fc.Field = graphql.CollectedField{Field: &ast.Field{Name: "name", Alias: "name"}}
+			return graphql.WithFieldContext(context.Background(), fc)
+		}
+
+		gt.InterceptField(fieldContext(&graphql.FieldContext{}), resolve)
+		assert.Empty(t, mt.FinishedSpans())
+
+		gt.InterceptField(fieldContext(&graphql.FieldContext{IsResolver: true}), resolve)
+		gt.InterceptField(fieldContext(&graphql.FieldContext{IsMethod: true}), func(ctx context.Context) (interface{}, error) {
+			return nil, errors.New("resolver error")
+		})
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 2)
+		assert.Nil(t, spans[0].Tag(ext.Error))
+		assert.NotNil(t, spans[1].Tag(ext.Error))
+	})
+}
+
+func TestSubscription(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	srv := testserver.New()
+	srv.AddTransport(transport.Websocket{})
+	c := newTestClient(t, srv, NewTracer())
+
+	query := `subscription { name }`
+	sub := c.Websocket(query)
+	var resp struct {
+		Name string
+	}
+	for i := 0; i < 2; i++ {
+		srv.SendNextSubscriptionMessage()
+		assert.NoError(sub.Next(&resp))
+		assert.Equal("test", resp.Name)
+	}
+	assert.NoError(sub.Close())
+
+	var start mocktracer.Span
+	var events []mocktracer.Span
+	for _, span := range mt.FinishedSpans() {
+		switch span.OperationName() {
+		case "graphql.subscription":
+			start = span
+		case subscriptionEventOp:
+			events = append(events, span)
+		}
+	}
+	assert.NotNil(start)
+	assert.Equal(query, start.Tag(ext.ResourceName))
+	assert.Len(events, 2)
+	for _, event := range events {
+		assert.Equal(query, event.Tag(ext.ResourceName))
+		assert.Equal(uint64(0), event.ParentID())
+		assert.NotEqual(start.TraceID(), event.TraceID())
+		assert.Equal([]ddtrace.SpanLink{{TraceID: start.TraceID(), SpanID: start.SpanID()}}, event.Links())
+	}
+}
+
 func newTestClient(t *testing.T, h *testserver.TestServer, tracer graphql.HandlerExtension) *client.Client {
 	t.Helper()
 	h.AddTransport(transport.POST{})
@@ -18,6 +18,8 @@ type config struct {
 	consumerOperationName string
 	producerOperationName string
 	analyticsRate         float64
+	dataStreamsEnabled    bool
+	groupID               string
 }
 
 func defaults(cfg *config) {
@@ -31,6 +33,8 @@ func defaults(cfg *config) {
 	cfg.consumerOperationName = namingschema.NewKafkaInboundOp().GetName()
 	cfg.producerOperationName = namingschema.NewKafkaOutboundOp().GetName()
 
+	cfg.dataStreamsEnabled = internal.BoolEnv("DD_DATA_STREAMS_ENABLED", false)
+
 	// cfg.analyticsRate = globalconfig.AnalyticsRate()
 	if internal.BoolEnv("DD_TRACE_SARAMA_ANALYTICS_ENABLED", false) {
 		cfg.analyticsRate = 1.0
@@ -72,3 +76,22 @@ func WithAnalyticsRate(rate float64) Option {
 		}
 	}
 }
+
+// WithDataStreams enables Data Streams Monitoring: checkpoints are set on produced and consumed
+// messages, the pathway context is propagated in the message headers, and the produced and consumed
+// offsets are tracked. It requires Data Streams Monitoring to be enabled in the tracer.
+// This can also be enabled with the DD_DATA_STREAMS_ENABLED environment variable.
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreamsEnabled = true
+	}
+}
+
+// WithGroupID sets the consumer group of the wrapped partition consumers and consumer group
+// handlers, which identifies them in Data Streams Monitoring. The offsets committed by the
+// group are tracked from the offsets marked by the sessions of WrapConsumerGroupHandler.
+func WithGroupID(groupID string) Option {
+	return func(cfg *config) {
+		cfg.groupID = groupID
+	}
+}
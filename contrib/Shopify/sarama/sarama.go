@@ -7,8 +7,10 @@
 package sarama // import "github.com/lannguyen-c0x12c/dd-trace-go/contrib/Shopify/sarama"
 
 import (
+	"context"
 	"math"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/datastreams"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -24,6 +26,10 @@ func init() {
 	telemetry.LoadIntegration("Shopify/sarama")
 }
 
+// trackCommitOffset reports the offsets committed by a consumer group to Data Streams
+// Monitoring; replaced in tests.
+var trackCommitOffset = tracer.TrackKafkaCommitOffset
+
 type partitionConsumer struct {
 	sarama.PartitionConsumer
 	messages chan *sarama.ConsumerMessage
@@ -75,6 +81,15 @@ func WrapPartitionConsumer(pc sarama.PartitionConsumer, opts ...Option) sarama.P
 			next := tracer.StartSpan(cfg.consumerOperationName, opts...)
 			// reinject the span context so consumers can pick it up
 			tracer.Inject(next.Context(), carrier)
+			if cfg.dataStreamsEnabled {
+				setConsumeCheckpoint(cfg.groupID, msg)
+				// the high water mark is the offset of the next message produced to the
+				// partition. Consumed offsets aren't committed: the offsets committed by
+				// consumer groups are tracked by WrapConsumerGroupHandler.
+				if hwm := pc.HighWaterMarkOffset(); hwm > 0 {
+					tracer.TrackKafkaProduceOffset(msg.Topic, msg.Partition, hwm-1)
+				}
+			}
 
 			wrapped.messages <- msg
 
@@ -117,6 +132,65 @@ func WrapConsumer(c sarama.Consumer, opts ...Option) sarama.Consumer {
 	}
 }
 
+type consumerGroupHandler struct {
+	sarama.ConsumerGroupHandler
+	cfg *config
+}
+
+// Setup calls the Setup method of the wrapped handler with a traced session.
+func (h *consumerGroupHandler) Setup(s sarama.ConsumerGroupSession) error {
+	return h.ConsumerGroupHandler.Setup(h.wrapSession(s))
+}
+
+// Cleanup calls the Cleanup method of the wrapped handler with a traced session.
+func (h *consumerGroupHandler) Cleanup(s sarama.ConsumerGroupSession) error {
+	return h.ConsumerGroupHandler.Cleanup(h.wrapSession(s))
+}
+
+// ConsumeClaim calls the ConsumeClaim method of the wrapped handler with a traced session.
+func (h *consumerGroupHandler) ConsumeClaim(s sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	return h.ConsumerGroupHandler.ConsumeClaim(h.wrapSession(s), claim)
+}
+
+func (h *consumerGroupHandler) wrapSession(s sarama.ConsumerGroupSession) sarama.ConsumerGroupSession {
+	if !h.cfg.dataStreamsEnabled || h.cfg.groupID == "" {
+		return s
+	}
+	return &consumerGroupSession{ConsumerGroupSession: s, groupID: h.cfg.groupID}
+}
+
+// WrapConsumerGroupHandler wraps a sarama.ConsumerGroupHandler so that, when Data Streams
+// Monitoring is enabled, the offsets marked by its sessions are tracked as the offsets
+// committed by the consumer group set with WithGroupID.
+func WrapConsumerGroupHandler(h sarama.ConsumerGroupHandler, opts ...Option) sarama.ConsumerGroupHandler {
+	cfg := new(config)
+	defaults(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	log.Debug("contrib/Shopify/sarama: Wrapping Consumer Group Handler: %#v", cfg)
+	return &consumerGroupHandler{ConsumerGroupHandler: h, cfg: cfg}
+}
+
+type consumerGroupSession struct {
+	sarama.ConsumerGroupSession
+	groupID string
+}
+
+// MarkOffset calls sarama.ConsumerGroupSession.MarkOffset and tracks the marked offset,
+// which is the next offset to be consumed, as committed by the consumer group.
+func (s *consumerGroupSession) MarkOffset(topic string, partition int32, offset int64, metadata string) {
+	s.ConsumerGroupSession.MarkOffset(topic, partition, offset, metadata)
+	trackCommitOffset(s.groupID, topic, partition, offset)
+}
+
+// MarkMessage calls sarama.ConsumerGroupSession.MarkMessage and tracks the offset following
+// the message as committed by the consumer group.
+func (s *consumerGroupSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.ConsumerGroupSession.MarkMessage(msg, metadata)
+	trackCommitOffset(s.groupID, msg.Topic, msg.Partition, msg.Offset+1)
+}
+
 type syncProducer struct {
 	sarama.SyncProducer
 	version sarama.KafkaVersion
@@ -128,6 +202,9 @@ func (p *syncProducer) SendMessage(msg *sarama.ProducerMessage) (partition int32
 	span := startProducerSpan(p.cfg, p.version, msg)
 	partition, offset, err = p.SyncProducer.SendMessage(msg)
 	finishProducerSpan(span, partition, offset, err)
+	if err == nil && p.cfg.dataStreamsEnabled {
+		tracer.TrackKafkaProduceOffset(msg.Topic, partition, offset)
+	}
 	return partition, offset, err
 }
 
@@ -143,6 +220,11 @@ func (p *syncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
 	for i, span := range spans {
 		finishProducerSpan(span, msgs[i].Partition, msgs[i].Offset, err)
 	}
+	if err == nil && p.cfg.dataStreamsEnabled {
+		for _, msg := range msgs {
+			tracer.TrackKafkaProduceOffset(msg.Topic, msg.Partition, msg.Offset)
+		}
+	}
 	return err
 }
 
@@ -242,6 +324,9 @@ func WrapAsyncProducer(saramaConfig *sarama.Config, p sarama.AsyncProducer, opts
 						finishProducerSpan(span, msg.Partition, msg.Offset, nil)
 					}
 				}
+				if cfg.dataStreamsEnabled {
+					tracer.TrackKafkaProduceOffset(msg.Topic, msg.Partition, msg.Offset)
+				}
 				wrapped.successes <- msg
 			case err, ok := <-p.Errors():
 				if !ok {
@@ -283,10 +368,40 @@ func startProducerSpan(cfg *config, version sarama.KafkaVersion, msg *sarama.Pro
 	if version.IsAtLeast(sarama.V0_11_0_0) {
 		// re-inject the span context so consumers can pick it up
 		tracer.Inject(span.Context(), carrier)
+		if cfg.dataStreamsEnabled {
+			setProduceCheckpoint(msg)
+		}
 	}
 	return span
 }
 
+// setConsumeCheckpoint sets a Data Streams checkpoint on the consumed message, continuing the
+// pathway found in its headers, and reinjects the resulting pathway for downstream producers.
+func setConsumeCheckpoint(groupID string, msg *sarama.ConsumerMessage) {
+	edges := []string{"direction:in", "topic:" + msg.Topic, "type:kafka"}
+	if groupID != "" {
+		edges = append(edges, "group:"+groupID)
+	}
+	carrier := NewConsumerMessageCarrier(msg)
+	ctx, ok := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(context.Background(), carrier), edges...)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+// setProduceCheckpoint sets a Data Streams checkpoint on the produced message, continuing the
+// pathway found in its headers if any, and injects the resulting pathway in the message headers.
+func setProduceCheckpoint(msg *sarama.ProducerMessage) {
+	edges := []string{"direction:out", "topic:" + msg.Topic, "type:kafka"}
+	carrier := NewProducerMessageCarrier(msg)
+	ctx, ok := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(context.Background(), carrier), edges...)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
 func finishProducerSpan(span ddtrace.Span, partition int32, offset int64, err error) {
 	span.SetTag(ext.MessagingKafkaPartition, partition)
 	span.SetTag("offset", offset)
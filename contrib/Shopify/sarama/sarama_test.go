@@ -11,11 +11,14 @@ import (
 	"time"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/namingschematest"
+	dsm "github.com/lannguyen-c0x12c/dd-trace-go/datastreams"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
 
 	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -217,6 +220,111 @@ func TestSyncProducer(t *testing.T) {
 	}
 }
 
+func TestDataStreams(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V0_11_0_0
+	cfg.Producer.Return.Successes = true
+
+	mockProducer := mocks.NewSyncProducer(t, cfg)
+	mockProducer.ExpectSendMessageAndSucceed()
+	producer := WrapSyncProducer(cfg, mockProducer, WithDataStreams())
+	msg := &sarama.ProducerMessage{
+		Topic: "my_topic",
+		Value: sarama.StringEncoder("test 1"),
+	}
+	_, _, err := producer.SendMessage(msg)
+	require.NoError(t, err)
+	require.NoError(t, producer.Close())
+
+	produced, ok := datastreams.PathwayFromContext(dsm.ExtractFromBase64Carrier(context.Background(), NewProducerMessageCarrier(msg)))
+	require.True(t, ok, "the pathway should be injected into the producer message headers")
+
+	// the consumer continues the pathway found in the message headers
+	mockConsumer := mocks.NewConsumer(t, cfg)
+	consumed := &sarama.ConsumerMessage{Topic: "my_topic"}
+	for _, h := range msg.Headers {
+		h := h
+		consumed.Headers = append(consumed.Headers, &h)
+	}
+	mockConsumer.ExpectConsumePartition("my_topic", 0, 0).YieldMessage(consumed)
+	consumer := WrapConsumer(mockConsumer, WithDataStreams(), WithGroupID("group"))
+	pc, err := consumer.ConsumePartition("my_topic", 0, 0)
+	require.NoError(t, err)
+	got := <-pc.Messages()
+	require.NoError(t, pc.Close())
+	require.NoError(t, consumer.Close())
+
+	consumedPathway, ok := datastreams.PathwayFromContext(dsm.ExtractFromBase64Carrier(context.Background(), NewConsumerMessageCarrier(got)))
+	require.True(t, ok)
+	assert.NotEqual(t, produced.GetHash(), consumedPathway.GetHash())
+	assert.Equal(t, produced.PathwayStart(), consumedPathway.PathwayStart())
+}
+
+// testConsumerGroupSession is a sarama.ConsumerGroupSession recording the marked offsets.
+type testConsumerGroupSession struct {
+	sarama.ConsumerGroupSession
+	marked []int64
+}
+
+func (s *testConsumerGroupSession) MarkOffset(_ string, _ int32, offset int64, _ string) {
+	s.marked = append(s.marked, offset)
+}
+
+func (s *testConsumerGroupSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset+1)
+}
+
+// testConsumerGroupHandler is a sarama.ConsumerGroupHandler marking the offsets of msgs.
+type testConsumerGroupHandler struct {
+	msgs []*sarama.ConsumerMessage
+}
+
+func (h *testConsumerGroupHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *testConsumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *testConsumerGroupHandler) ConsumeClaim(s sarama.ConsumerGroupSession, _ sarama.ConsumerGroupClaim) error {
+	for _, msg := range h.msgs {
+		s.MarkMessage(msg, "")
+	}
+	s.MarkOffset("my_topic", 1, 10, "")
+	return nil
+}
+
+func TestConsumerGroupHandler(t *testing.T) {
+	type commit struct {
+		group, topic string
+		partition    int32
+		offset       int64
+	}
+	var commits []commit
+	defer func(track func(string, string, int32, int64)) { trackCommitOffset = track }(trackCommitOffset)
+	trackCommitOffset = func(group, topic string, partition int32, offset int64) {
+		commits = append(commits, commit{group, topic, partition, offset})
+	}
+	h := &testConsumerGroupHandler{msgs: []*sarama.ConsumerMessage{{Topic: "my_topic", Partition: 0, Offset: 4}}}
+
+	t.Run("data-streams", func(t *testing.T) {
+		commits = nil
+		session := &testConsumerGroupSession{}
+		err := WrapConsumerGroupHandler(h, WithDataStreams(), WithGroupID("group")).ConsumeClaim(session, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{5, 10}, session.marked)
+		assert.Equal(t, []commit{{"group", "my_topic", 0, 5}, {"group", "my_topic", 1, 10}}, commits)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		commits = nil
+		session := &testConsumerGroupSession{}
+		err := WrapConsumerGroupHandler(h, WithGroupID("group")).ConsumeClaim(session, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{5, 10}, session.marked)
+		assert.Empty(t, commits)
+	})
+}
+
 func TestSyncProducerSendMessages(t *testing.T) {
 	mt := mocktracer.Start()
 	defer mt.Stop()
@@ -11,6 +11,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/aws/internal/awsresource"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -91,7 +92,17 @@ func (mw *traceMiddleware) startTraceMiddleware(stack *middleware.Stack) error {
 		if !math.IsNaN(mw.cfg.analyticsRate) {
 			opts = append(opts, tracer.Tag(ext.EventSampleRate, mw.cfg.analyticsRate))
 		}
+		res := awsresource.Extract(serviceID, in.Parameters, mw.cfg.objectKeyTag)
+		for k, v := range res.Tags {
+			opts = append(opts, tracer.Tag(k, v))
+		}
+		if mw.cfg.peerServiceDefaults && res.PeerService != "" {
+			opts = append(opts, tracer.Tag(ext.PeerService, res.PeerService))
+		}
 		span, spanctx := tracer.StartSpanFromContext(ctx, fmt.Sprintf("%s.request", serviceID), opts...)
+		if mw.cfg.propagation {
+			in.Parameters = injectTraceContext(span.Context(), in.Parameters)
+		}
 
 		// Handle initialize and continue through the middleware chain.
 		out, metadata, err = next.HandleInitialize(spanctx, in)
@@ -258,3 +258,44 @@ func TestHTTPCredentials(t *testing.T) {
 	// receives the auth request.
 	assert.Equal(t, auth, "myuser:mypassword")
 }
+
+func TestResourceTags(t *testing.T) {
+	server := mockAWS(200)
+	defer server.Close()
+
+	newClient := func(opts ...Option) *sqs.Client {
+		awsCfg := aws.Config{
+			Region:      "eu-west-1",
+			Credentials: aws.AnonymousCredentials{},
+			EndpointResolver: aws.EndpointResolverFunc(func(service, region string) (aws.Endpoint, error) {
+				return aws.Endpoint{PartitionID: "aws", URL: server.URL, SigningRegion: "eu-west-1"}, nil
+			}),
+		}
+		AppendMiddleware(&awsCfg, opts...)
+		return sqs.NewFromConfig(awsCfg)
+	}
+	in := &sqs.GetQueueAttributesInput{QueueUrl: aws.String(server.URL + "/000000000000/my-queue")}
+
+	t.Run("defaults", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		newClient().GetQueueAttributes(context.Background(), in)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "my-queue", spans[0].Tag("queuename"))
+		assert.Equal(t, *in.QueueUrl, spans[0].Tag("queueurl"))
+		assert.Equal(t, "my-queue", spans[0].Tag(ext.PeerService))
+	})
+
+	t.Run("no-peer-service", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		newClient(WithPeerServiceDefaults(false)).GetQueueAttributes(context.Background(), in)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "my-queue", spans[0].Tag("queuename"))
+		assert.Nil(t, spans[0].Tag(ext.PeerService))
+	})
+}
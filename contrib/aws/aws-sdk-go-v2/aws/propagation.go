@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package aws
+
+import (
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/aws/internal/awsprop"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	kinesistypes "github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// injectTraceContext returns the parameters of an operation publishing messages with the
+// trace context of sctx added to the messages. The parameters of the other operations are
+// returned as is. The given parameters are never modified, so that the messages can be
+// reused by the caller.
+func injectTraceContext(sctx ddtrace.SpanContext, params interface{}) interface{} {
+	switch p := params.(type) {
+	case *sqs.ReceiveMessageInput:
+		// the trace context is only received if it is requested.
+		for _, name := range p.MessageAttributeNames {
+			if name == "All" || name == ".*" || name == awsprop.DatadogKey {
+				return params
+			}
+		}
+		in := *p
+		in.MessageAttributeNames = append(append([]string(nil), p.MessageAttributeNames...), awsprop.DatadogKey)
+		return &in
+	case *sqs.SendMessageInput, *sqs.SendMessageBatchInput, *sns.PublishInput,
+		*kinesis.PutRecordInput, *kinesis.PutRecordsInput, *eventbridge.PutEventsInput:
+	default:
+		return params
+	}
+	carrier, err := awsprop.Inject(sctx)
+	if err != nil {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: failed to inject the trace context: %v", err)
+		return params
+	}
+	switch p := params.(type) {
+	case *sqs.SendMessageInput:
+		in := *p
+		in.MessageAttributes = injectSQSAttributes(p.MessageAttributes, carrier)
+		return &in
+	case *sqs.SendMessageBatchInput:
+		in := *p
+		in.Entries = make([]sqstypes.SendMessageBatchRequestEntry, len(p.Entries))
+		for i, e := range p.Entries {
+			e.MessageAttributes = injectSQSAttributes(e.MessageAttributes, carrier)
+			in.Entries[i] = e
+		}
+		return &in
+	case *sns.PublishInput:
+		in := *p
+		in.MessageAttributes = injectSNSAttributes(p.MessageAttributes, carrier)
+		return &in
+	case *kinesis.PutRecordInput:
+		in := *p
+		if data, ok := awsprop.InjectJSON(p.Data, carrier, awsprop.MaxKinesisRecordSize); ok {
+			in.Data = data
+		}
+		return &in
+	case *kinesis.PutRecordsInput:
+		in := *p
+		in.Records = make([]kinesistypes.PutRecordsRequestEntry, len(p.Records))
+		for i, r := range p.Records {
+			if data, ok := awsprop.InjectJSON(r.Data, carrier, awsprop.MaxKinesisRecordSize); ok {
+				r.Data = data
+			}
+			in.Records[i] = r
+		}
+		return &in
+	case *eventbridge.PutEventsInput:
+		in := *p
+		in.Entries = append(in.Entries[:0:0], p.Entries...)
+		for i, e := range in.Entries {
+			detail := "{}"
+			if e.Detail != nil {
+				detail = *e.Detail
+			}
+			if data, ok := awsprop.InjectJSON([]byte(detail), carrier, awsprop.MaxEventBridgeDetailSize); ok {
+				in.Entries[i].Detail = aws.String(string(data))
+			}
+		}
+		return &in
+	}
+	return params
+}
+
+// injectSQSAttributes returns a copy of attrs with the trace context carrier, unless attrs
+// already has the maximum number of attributes.
+func injectSQSAttributes(attrs map[string]sqstypes.MessageAttributeValue, carrier []byte) map[string]sqstypes.MessageAttributeValue {
+	if _, ok := attrs[awsprop.DatadogKey]; !ok && len(attrs) >= awsprop.MaxMessageAttributes {
+		return attrs
+	}
+	out := make(map[string]sqstypes.MessageAttributeValue, len(attrs)+1)
+	for k, v := range attrs {
+		out[k] = v
+	}
+	out[awsprop.DatadogKey] = sqstypes.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(string(carrier)),
+	}
+	return out
+}
+
+// injectSNSAttributes returns a copy of attrs with the trace context carrier, unless attrs
+// already has the maximum number of attributes. The carrier is a binary attribute, which SNS
+// subscription filter policies ignore.
+func injectSNSAttributes(attrs map[string]snstypes.MessageAttributeValue, carrier []byte) map[string]snstypes.MessageAttributeValue {
+	if _, ok := attrs[awsprop.DatadogKey]; !ok && len(attrs) >= awsprop.MaxMessageAttributes {
+		return attrs
+	}
+	out := make(map[string]snstypes.MessageAttributeValue, len(attrs)+1)
+	for k, v := range attrs {
+		out[k] = v
+	}
+	out[awsprop.DatadogKey] = snstypes.MessageAttributeValue{
+		DataType:    aws.String("Binary"),
+		BinaryValue: carrier,
+	}
+	return out
+}
+
+// ExtractSQSMessage returns the trace context propagated with the given SQS message. It is
+// found in the message attributes of the messages sent by a traced SQS producer, or of the
+// messages delivered by an SNS subscription with raw message delivery, and in the body of
+// the other SNS notifications and of the events delivered by EventBridge. It returns
+// tracer.ErrSpanContextNotFound if the message carries no trace context.
+//
+// The message attributes are only received when they are requested: the traced clients
+// request the trace context attribute along with the ones set in the ReceiveMessage input.
+func ExtractSQSMessage(msg sqstypes.Message) (ddtrace.SpanContext, error) {
+	if attr, ok := msg.MessageAttributes[awsprop.DatadogKey]; ok {
+		if attr.StringValue != nil {
+			return awsprop.Extract([]byte(*attr.StringValue))
+		}
+		return awsprop.Extract(attr.BinaryValue)
+	}
+	if msg.Body == nil {
+		return nil, tracer.ErrSpanContextNotFound
+	}
+	return awsprop.ExtractBody(*msg.Body)
+}
+
+// ExtractKinesisRecord returns the trace context propagated with the given Kinesis record.
+// It is only injected into the records whose data is a JSON object. It returns
+// tracer.ErrSpanContextNotFound if the record carries no trace context.
+func ExtractKinesisRecord(rec kinesistypes.Record) (ddtrace.SpanContext, error) {
+	return awsprop.ExtractJSON(rec.Data)
+}
+
+// ExtractEventBridgeDetail returns the trace context propagated with the detail of an
+// EventBridge event, such as the one received by an AWS Lambda function. It returns
+// tracer.ErrSpanContextNotFound if the detail carries no trace context.
+func ExtractEventBridgeDetail(detail []byte) (ddtrace.SpanContext, error) {
+	return awsprop.ExtractJSON(detail)
+}
@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgetypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	kinesistypes "github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropagation(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	awsCfg := aws.Config{
+		Region:      "eu-west-1",
+		Credentials: aws.AnonymousCredentials{},
+		EndpointResolver: aws.EndpointResolverFunc(func(service, region string) (aws.Endpoint, error) {
+			return aws.Endpoint{PartitionID: "aws", URL: server.URL, SigningRegion: "eu-west-1"}, nil
+		}),
+	}
+	AppendMiddleware(&awsCfg)
+	client := sqs.NewFromConfig(awsCfg)
+
+	in := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(server.URL),
+		MessageBody: aws.String("hello"),
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"key": {DataType: aws.String("String"), StringValue: aws.String("value")},
+		},
+	}
+	client.SendMessage(context.Background(), in)
+	assert.Len(t, in.MessageAttributes, 1, "the input must not be modified")
+
+	// the consumer receives the attribute sent by the producer.
+	msg := sqstypes.Message{MessageAttributes: map[string]sqstypes.MessageAttributeValue{}}
+	for i := 1; form.Get("MessageAttribute."+strconv.Itoa(i)+".Name") != ""; i++ {
+		prefix := "MessageAttribute." + strconv.Itoa(i)
+		msg.MessageAttributes[form.Get(prefix+".Name")] = sqstypes.MessageAttributeValue{
+			DataType:    aws.String(form.Get(prefix + ".Value.DataType")),
+			StringValue: aws.String(form.Get(prefix + ".Value.StringValue")),
+		}
+	}
+	require.Len(t, msg.MessageAttributes, 2)
+	sctx, err := ExtractSQSMessage(msg)
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, spans[0].TraceID(), sctx.TraceID())
+	assert.Equal(t, spans[0].SpanID(), sctx.SpanID())
+
+	t.Run("receive", func(t *testing.T) {
+		client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(server.URL),
+			MessageAttributeNames: []string{"key"},
+		})
+		assert.Equal(t, "key", form.Get("MessageAttributeName.1"))
+		assert.Equal(t, "_datadog", form.Get("MessageAttributeName.2"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		awsCfg := awsCfg
+		awsCfg.APIOptions = nil
+		AppendMiddleware(&awsCfg, WithPropagation(false))
+		sqs.NewFromConfig(awsCfg).SendMessage(context.Background(), in)
+		assert.Equal(t, "key", form.Get("MessageAttribute.1.Name"))
+		assert.Empty(t, form.Get("MessageAttribute.2.Name"))
+	})
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	span := tracer.StartSpan("producer")
+	defer span.Finish()
+	assertContext := func(t *testing.T) func(ddtrace.SpanContext, error) {
+		return func(sctx ddtrace.SpanContext, err error) {
+			t.Helper()
+			require.NoError(t, err)
+			assert.Equal(t, span.Context().SpanID(), sctx.SpanID())
+		}
+	}
+
+	t.Run("sqs-batch", func(t *testing.T) {
+		full := make(map[string]sqstypes.MessageAttributeValue)
+		for i := 0; i < 10; i++ {
+			full[strconv.Itoa(i)] = sqstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String("v")}
+		}
+		in := &sqs.SendMessageBatchInput{Entries: []sqstypes.SendMessageBatchRequestEntry{
+			{Id: aws.String("1"), MessageBody: aws.String("hello")},
+			{Id: aws.String("2"), MessageBody: aws.String("hello"), MessageAttributes: full},
+		}}
+		out := injectTraceContext(span.Context(), in).(*sqs.SendMessageBatchInput)
+		assert.Nil(t, in.Entries[0].MessageAttributes)
+		assertContext(t)(ExtractSQSMessage(sqstypes.Message{MessageAttributes: out.Entries[0].MessageAttributes}))
+		// the attribute limit is respected.
+		assert.Len(t, out.Entries[1].MessageAttributes, 10)
+		assert.NotContains(t, out.Entries[1].MessageAttributes, "_datadog")
+	})
+
+	t.Run("sns", func(t *testing.T) {
+		in := &sns.PublishInput{Message: aws.String("hello")}
+		out := injectTraceContext(span.Context(), in).(*sns.PublishInput)
+		assert.Nil(t, in.MessageAttributes)
+		attr := out.MessageAttributes["_datadog"]
+		assert.Equal(t, "Binary", *attr.DataType)
+		// with raw message delivery, SQS consumers receive the binary attribute.
+		assertContext(t)(ExtractSQSMessage(sqstypes.Message{MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"_datadog": {DataType: attr.DataType, BinaryValue: attr.BinaryValue},
+		}}))
+	})
+
+	t.Run("sns-limit", func(t *testing.T) {
+		attrs := make(map[string]snstypes.MessageAttributeValue)
+		for i := 0; i < 10; i++ {
+			attrs[strconv.Itoa(i)] = snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String("v")}
+		}
+		out := injectTraceContext(span.Context(), &sns.PublishInput{MessageAttributes: attrs}).(*sns.PublishInput)
+		assert.NotContains(t, out.MessageAttributes, "_datadog")
+	})
+
+	t.Run("kinesis", func(t *testing.T) {
+		in := &kinesis.PutRecordsInput{Records: []kinesistypes.PutRecordsRequestEntry{
+			{Data: []byte(`{"id":1}`)},
+			{Data: []byte("not json")},
+		}}
+		out := injectTraceContext(span.Context(), in).(*kinesis.PutRecordsInput)
+		assert.Equal(t, `{"id":1}`, string(in.Records[0].Data))
+		assertContext(t)(ExtractKinesisRecord(kinesistypes.Record{Data: out.Records[0].Data}))
+		assert.Equal(t, "not json", string(out.Records[1].Data))
+
+		put := injectTraceContext(span.Context(), &kinesis.PutRecordInput{Data: []byte(`{}`)}).(*kinesis.PutRecordInput)
+		assertContext(t)(ExtractKinesisRecord(kinesistypes.Record{Data: put.Data}))
+	})
+
+	t.Run("eventbridge", func(t *testing.T) {
+		in := &eventbridge.PutEventsInput{Entries: []eventbridgetypes.PutEventsRequestEntry{
+			{Detail: aws.String(`{"id":1}`)},
+		}}
+		out := injectTraceContext(span.Context(), in).(*eventbridge.PutEventsInput)
+		assert.Equal(t, `{"id":1}`, *in.Entries[0].Detail)
+		assertContext(t)(ExtractEventBridgeDetail([]byte(*out.Entries[0].Detail)))
+	})
+
+	t.Run("other", func(t *testing.T) {
+		in := &sqs.ListQueuesInput{}
+		assert.Same(t, in, injectTraceContext(span.Context(), in))
+		in2 := &sqs.ReceiveMessageInput{MessageAttributeNames: []string{"All"}}
+		assert.Same(t, in2, injectTraceContext(span.Context(), in2))
+	})
+}
@@ -7,9 +7,11 @@
 package aws // import "github.com/lannguyen-c0x12c/dd-trace-go/contrib/aws/aws-sdk-go/aws"
 
 import (
+	"context"
 	"math"
 	"strconv"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/aws/internal/awsresource"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -18,6 +20,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
 )
 
 const componentName = "aws/aws-sdk-go/aws"
@@ -32,6 +35,8 @@ const (
 	tagAWSRegion     = "aws.region"
 	tagAWSRetryCount = "aws.retry_count"
 	tagAWSRequestID  = "aws.request_id"
+	// BuildHandlerName is the name of the Datadog NamedHandler for the Build phase of an awsv1 request
+	BuildHandlerName = "github.com/lannguyen-c0x12c/dd-trace-go/contrib/aws/aws-sdk-go/aws/handlers.Build"
 	// SendHandlerName is the name of the Datadog NamedHandler for the Send phase of an awsv1 request
 	SendHandlerName = "github.com/lannguyen-c0x12c/dd-trace-go/contrib/aws/aws-sdk-go/aws/handlers.Send"
 	// CompleteHandlerName is the name of the Datadog NamedHandler for the Complete phase of an awsv1 request
@@ -42,6 +47,10 @@ type handlers struct {
 	cfg *config
 }
 
+// spanStartedKey is the context key marking the requests whose span was started in the
+// Build phase, to propagate its context with the request parameters.
+type spanStartedKey struct{}
+
 // WrapSession wraps a session.Session, causing requests and responses to be traced.
 func WrapSession(s *session.Session, opts ...Option) *session.Session {
 	cfg := new(config)
@@ -52,6 +61,10 @@ func WrapSession(s *session.Session, opts ...Option) *session.Session {
 	log.Debug("contrib/aws/aws-sdk-go/aws: Wrapping Session: %#v", cfg)
 	h := &handlers{cfg: cfg}
 	s = s.Copy()
+	s.Handlers.Build.PushFrontNamed(request.NamedHandler{
+		Name: BuildHandlerName,
+		Fn:   h.Build,
+	})
 	s.Handlers.Send.PushFrontNamed(request.NamedHandler{
 		Name: SendHandlerName,
 		Fn:   h.Send,
@@ -63,10 +76,39 @@ func WrapSession(s *session.Session, opts ...Option) *session.Session {
 	return s
 }
 
+// Build propagates the trace context with the messages published by the request, before
+// the parameters are serialized. The span of such requests is started here rather than in Send.
+func (h *handlers) Build(req *request.Request) {
+	if !h.cfg.propagation {
+		return
+	}
+	if p, ok := req.Params.(*sqs.ReceiveMessageInput); ok {
+		req.Params = requestTraceContext(p)
+		return
+	}
+	if !propagatesTraceContext(req.Params) {
+		return
+	}
+	h.startSpan(req)
+	span, _ := tracer.SpanFromContext(req.Context())
+	req.Params = injectTraceContext(span.Context(), req.Params)
+	req.SetContext(context.WithValue(req.Context(), spanStartedKey{}, true))
+}
+
 func (h *handlers) Send(req *request.Request) {
 	if req.RetryCount != 0 {
 		return
 	}
+	if req.Context().Value(spanStartedKey{}) != nil {
+		// the User-Agent header is set in the Build phase, after the span was started.
+		span, _ := tracer.SpanFromContext(req.Context())
+		span.SetTag(tagAWSAgent, h.awsAgent(req))
+		return
+	}
+	h.startSpan(req)
+}
+
+func (h *handlers) startSpan(req *request.Request) {
 	// Make a copy of the URL so we don't modify the outgoing request
 	url := *req.HTTPRequest.URL
 	url.User = nil // Do not include userinfo in the HTTPURL tag.
@@ -85,6 +127,13 @@ func (h *handlers) Send(req *request.Request) {
 	if !math.IsNaN(h.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, h.cfg.analyticsRate))
 	}
+	res := awsresource.Extract(h.awsService(req), req.Params, h.cfg.objectKeyTag)
+	for k, v := range res.Tags {
+		opts = append(opts, tracer.Tag(k, v))
+	}
+	if h.cfg.peerServiceDefaults && res.PeerService != "" {
+		opts = append(opts, tracer.Tag(ext.PeerService, res.PeerService))
+	}
 	_, ctx := tracer.StartSpanFromContext(req.Context(), h.operationName(req), opts...)
 	req.SetContext(ctx)
 }
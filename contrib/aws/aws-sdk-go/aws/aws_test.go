@@ -251,3 +251,42 @@ func TestHTTPCredentials(t *testing.T) {
 	// receives the auth request.
 	assert.Equal(t, auth, "myuser:mypassword")
 }
+
+func TestResourceTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	cfg := aws.NewConfig().
+		WithRegion("us-west-2").
+		WithEndpoint(server.URL).
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.AnonymousCredentials)
+	sess := session.Must(session.NewSession(cfg))
+	in := &s3.GetObjectInput{Bucket: aws.String("my-bucket"), Key: aws.String("my-key")}
+
+	t.Run("defaults", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		s3.New(WrapSession(sess)).GetObject(in)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "my-bucket", spans[0].Tag("bucketname"))
+		assert.Equal(t, "my-bucket", spans[0].Tag(ext.PeerService))
+		assert.Nil(t, spans[0].Tag("objectkey"))
+	})
+
+	t.Run("options", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		s3.New(WrapSession(sess, WithObjectKeyTag(true), WithPeerServiceDefaults(false))).GetObject(in)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "my-bucket", spans[0].Tag("bucketname"))
+		assert.Equal(t, "my-key", spans[0].Tag("objectkey"))
+		assert.Nil(t, spans[0].Tag(ext.PeerService))
+	})
+}
@@ -12,14 +12,19 @@ import (
 )
 
 type config struct {
-	serviceName   string
-	analyticsRate float64
+	serviceName         string
+	analyticsRate       float64
+	propagation         bool
+	objectKeyTag        bool
+	peerServiceDefaults bool
 }
 
 // Option represents an option that can be passed to Dial.
 type Option func(*config)
 
 func defaults(cfg *config) {
+	cfg.propagation = true
+	cfg.peerServiceDefaults = true
 	// cfg.analyticsRate = globalconfig.AnalyticsRate()
 	if internal.BoolEnv("DD_TRACE_AWS_ANALYTICS_ENABLED", false) {
 		cfg.analyticsRate = 1.0
@@ -59,3 +64,30 @@ func WithAnalyticsRate(rate float64) Option {
 		}
 	}
 }
+
+// WithPropagation sets whether the trace context is propagated with the messages published
+// to SQS, SNS, Kinesis and EventBridge, so that the traces of their consumers can be
+// connected to the ones of the producers. It is enabled by default.
+func WithPropagation(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.propagation = enabled
+	}
+}
+
+// WithObjectKeyTag sets whether the key of the S3 objects targeted by the requests is tagged,
+// along with their bucket. It is disabled by default, as the keys may hold personal data.
+func WithObjectKeyTag(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.objectKeyTag = enabled
+	}
+}
+
+// WithPeerServiceDefaults sets whether the peer.service of the requests defaults to the name
+// of the resource they target, such as the S3 bucket, the DynamoDB table, the SQS queue, the
+// SNS topic, the Kinesis stream, the Lambda function or the Step Functions state machine. It
+// is enabled by default.
+func WithPeerServiceDefaults(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.peerServiceDefaults = enabled
+	}
+}
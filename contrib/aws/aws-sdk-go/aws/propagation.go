@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package aws
+
+import (
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/aws/internal/awsprop"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+// propagatesTraceContext reports whether params are the parameters of an operation
+// publishing messages, which the trace context is propagated with.
+func propagatesTraceContext(params interface{}) bool {
+	switch params.(type) {
+	case *sqs.SendMessageInput, *sqs.SendMessageBatchInput, *sns.PublishInput,
+		*kinesis.PutRecordInput, *kinesis.PutRecordsInput, *eventbridge.PutEventsInput:
+		return true
+	}
+	return false
+}
+
+// requestTraceContext returns the parameters of a ReceiveMessage request which also request
+// the trace context message attribute, which is only received if it is requested.
+func requestTraceContext(p *sqs.ReceiveMessageInput) *sqs.ReceiveMessageInput {
+	for _, name := range p.MessageAttributeNames {
+		if n := aws.StringValue(name); n == "All" || n == ".*" || n == awsprop.DatadogKey {
+			return p
+		}
+	}
+	in := *p
+	in.MessageAttributeNames = append(append([]*string(nil), p.MessageAttributeNames...), aws.String(awsprop.DatadogKey))
+	return &in
+}
+
+// injectTraceContext returns the parameters of an operation publishing messages with the
+// trace context of sctx added to the messages. The given parameters are never modified, so
+// that the messages can be reused by the caller.
+func injectTraceContext(sctx ddtrace.SpanContext, params interface{}) interface{} {
+	carrier, err := awsprop.Inject(sctx)
+	if err != nil {
+		log.Debug("contrib/aws/aws-sdk-go/aws: failed to inject the trace context: %v", err)
+		return params
+	}
+	switch p := params.(type) {
+	case *sqs.SendMessageInput:
+		in := *p
+		in.MessageAttributes = injectSQSAttributes(p.MessageAttributes, carrier)
+		return &in
+	case *sqs.SendMessageBatchInput:
+		in := *p
+		in.Entries = make([]*sqs.SendMessageBatchRequestEntry, len(p.Entries))
+		for i, e := range p.Entries {
+			if e == nil {
+				continue
+			}
+			entry := *e
+			entry.MessageAttributes = injectSQSAttributes(e.MessageAttributes, carrier)
+			in.Entries[i] = &entry
+		}
+		return &in
+	case *sns.PublishInput:
+		in := *p
+		in.MessageAttributes = injectSNSAttributes(p.MessageAttributes, carrier)
+		return &in
+	case *kinesis.PutRecordInput:
+		in := *p
+		if data, ok := awsprop.InjectJSON(p.Data, carrier, awsprop.MaxKinesisRecordSize); ok {
+			in.Data = data
+		}
+		return &in
+	case *kinesis.PutRecordsInput:
+		in := *p
+		in.Records = make([]*kinesis.PutRecordsRequestEntry, len(p.Records))
+		for i, r := range p.Records {
+			if r == nil {
+				continue
+			}
+			rec := *r
+			if data, ok := awsprop.InjectJSON(r.Data, carrier, awsprop.MaxKinesisRecordSize); ok {
+				rec.Data = data
+			}
+			in.Records[i] = &rec
+		}
+		return &in
+	case *eventbridge.PutEventsInput:
+		in := *p
+		in.Entries = make([]*eventbridge.PutEventsRequestEntry, len(p.Entries))
+		for i, e := range p.Entries {
+			if e == nil {
+				continue
+			}
+			entry := *e
+			detail := "{}"
+			if e.Detail != nil {
+				detail = *e.Detail
+			}
+			if data, ok := awsprop.InjectJSON([]byte(detail), carrier, awsprop.MaxEventBridgeDetailSize); ok {
+				entry.Detail = aws.String(string(data))
+			}
+			in.Entries[i] = &entry
+		}
+		return &in
+	}
+	return params
+}
+
+// injectSQSAttributes returns a copy of attrs with the trace context carrier, unless attrs
+// already has the maximum number of attributes.
+func injectSQSAttributes(attrs map[string]*sqs.MessageAttributeValue, carrier []byte) map[string]*sqs.MessageAttributeValue {
+	if _, ok := attrs[awsprop.DatadogKey]; !ok && len(attrs) >= awsprop.MaxMessageAttributes {
+		return attrs
+	}
+	out := make(map[string]*sqs.MessageAttributeValue, len(attrs)+1)
+	for k, v := range attrs {
+		out[k] = v
+	}
+	out[awsprop.DatadogKey] = &sqs.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(string(carrier)),
+	}
+	return out
+}
+
+// injectSNSAttributes returns a copy of attrs with the trace context carrier, unless attrs
+// already has the maximum number of attributes. The carrier is a binary attribute, which SNS
+// subscription filter policies ignore.
+func injectSNSAttributes(attrs map[string]*sns.MessageAttributeValue, carrier []byte) map[string]*sns.MessageAttributeValue {
+	if _, ok := attrs[awsprop.DatadogKey]; !ok && len(attrs) >= awsprop.MaxMessageAttributes {
+		return attrs
+	}
+	out := make(map[string]*sns.MessageAttributeValue, len(attrs)+1)
+	for k, v := range attrs {
+		out[k] = v
+	}
+	out[awsprop.DatadogKey] = &sns.MessageAttributeValue{
+		DataType:    aws.String("Binary"),
+		BinaryValue: carrier,
+	}
+	return out
+}
+
+// ExtractSQSMessage returns the trace context propagated with the given SQS message. It is
+// found in the message attributes of the messages sent by a traced SQS producer, or of the
+// messages delivered by an SNS subscription with raw message delivery, and in the body of
+// the other SNS notifications and of the events delivered by EventBridge. It returns
+// tracer.ErrSpanContextNotFound if the message carries no trace context.
+//
+// The message attributes are only received when they are requested: the traced sessions
+// request the trace context attribute along with the ones set in the ReceiveMessage input.
+func ExtractSQSMessage(msg *sqs.Message) (ddtrace.SpanContext, error) {
+	if msg == nil {
+		return nil, tracer.ErrSpanContextNotFound
+	}
+	if attr, ok := msg.MessageAttributes[awsprop.DatadogKey]; ok && attr != nil {
+		if attr.StringValue != nil {
+			return awsprop.Extract([]byte(*attr.StringValue))
+		}
+		return awsprop.Extract(attr.BinaryValue)
+	}
+	if msg.Body == nil {
+		return nil, tracer.ErrSpanContextNotFound
+	}
+	return awsprop.ExtractBody(*msg.Body)
+}
+
+// ExtractKinesisRecord returns the trace context propagated with the given Kinesis record.
+// It is only injected into the records whose data is a JSON object. It returns
+// tracer.ErrSpanContextNotFound if the record carries no trace context.
+func ExtractKinesisRecord(rec *kinesis.Record) (ddtrace.SpanContext, error) {
+	if rec == nil {
+		return nil, tracer.ErrSpanContextNotFound
+	}
+	return awsprop.ExtractJSON(rec.Data)
+}
+
+// ExtractEventBridgeDetail returns the trace context propagated with the detail of an
+// EventBridge event, such as the one received by an AWS Lambda function. It returns
+// tracer.ErrSpanContextNotFound if the detail carries no trace context.
+func ExtractEventBridgeDetail(detail []byte) (ddtrace.SpanContext, error) {
+	return awsprop.ExtractJSON(detail)
+}
@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropagation(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	cfg := aws.NewConfig().
+		WithRegion("us-west-2").
+		WithEndpoint(server.URL).
+		WithCredentials(credentials.AnonymousCredentials)
+	sess := session.Must(session.NewSession(cfg))
+	client := sqs.New(WrapSession(sess))
+
+	in := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(server.URL),
+		MessageBody: aws.String("hello"),
+		MessageAttributes: map[string]*sqs.MessageAttributeValue{
+			"key": {DataType: aws.String("String"), StringValue: aws.String("value")},
+		},
+	}
+	client.SendMessage(in)
+	assert.Len(t, in.MessageAttributes, 1, "the input must not be modified")
+
+	// the consumer receives the attribute sent by the producer.
+	msg := sentMessage(form)
+	require.Len(t, msg.MessageAttributes, 2)
+	sctx, err := ExtractSQSMessage(msg)
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, spans[0].TraceID(), sctx.TraceID())
+	assert.Equal(t, spans[0].SpanID(), sctx.SpanID())
+	assert.Equal(t, "sqs.SendMessage", spans[0].Tag("resource.name"))
+	assert.Contains(t, spans[0].Tag(tagAWSAgent), "aws-sdk-go/")
+
+	t.Run("receive", func(t *testing.T) {
+		client.ReceiveMessage(&sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(server.URL),
+			MessageAttributeNames: aws.StringSlice([]string{"key"}),
+		})
+		assert.Equal(t, "key", form.Get("MessageAttributeName.1"))
+		assert.Equal(t, "_datadog", form.Get("MessageAttributeName.2"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		sqs.New(WrapSession(sess, WithPropagation(false))).SendMessage(in)
+		assert.Equal(t, "key", form.Get("MessageAttribute.1.Name"))
+		assert.Empty(t, form.Get("MessageAttribute.2.Name"))
+	})
+
+	t.Run("context", func(t *testing.T) {
+		mt.Reset()
+		root, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+		client.SendMessageWithContext(ctx, in)
+		root.Finish()
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, spans[1].SpanID(), spans[0].ParentID())
+		sctx, err := ExtractSQSMessage(sentMessage(form))
+		require.NoError(t, err)
+		assert.Equal(t, spans[1].TraceID(), sctx.TraceID())
+		assert.Equal(t, spans[0].SpanID(), sctx.SpanID())
+	})
+}
+
+// sentMessage returns the message of the SendMessage request with the given form.
+func sentMessage(form url.Values) *sqs.Message {
+	msg := &sqs.Message{MessageAttributes: map[string]*sqs.MessageAttributeValue{}}
+	for i := 1; form.Get("MessageAttribute."+strconv.Itoa(i)+".Name") != ""; i++ {
+		prefix := "MessageAttribute." + strconv.Itoa(i)
+		msg.MessageAttributes[form.Get(prefix+".Name")] = &sqs.MessageAttributeValue{
+			DataType:    aws.String(form.Get(prefix + ".Value.DataType")),
+			StringValue: aws.String(form.Get(prefix + ".Value.StringValue")),
+		}
+	}
+	return msg
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	span := tracer.StartSpan("producer")
+	defer span.Finish()
+	assertContext := func(t *testing.T) func(ddtrace.SpanContext, error) {
+		return func(sctx ddtrace.SpanContext, err error) {
+			t.Helper()
+			require.NoError(t, err)
+			assert.Equal(t, span.Context().SpanID(), sctx.SpanID())
+		}
+	}
+
+	t.Run("sqs-batch", func(t *testing.T) {
+		full := make(map[string]*sqs.MessageAttributeValue)
+		for i := 0; i < 10; i++ {
+			full[strconv.Itoa(i)] = &sqs.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String("v")}
+		}
+		in := &sqs.SendMessageBatchInput{Entries: []*sqs.SendMessageBatchRequestEntry{
+			{Id: aws.String("1"), MessageBody: aws.String("hello")},
+			{Id: aws.String("2"), MessageBody: aws.String("hello"), MessageAttributes: full},
+		}}
+		out := injectTraceContext(span.Context(), in).(*sqs.SendMessageBatchInput)
+		assert.Nil(t, in.Entries[0].MessageAttributes)
+		assertContext(t)(ExtractSQSMessage(&sqs.Message{MessageAttributes: out.Entries[0].MessageAttributes}))
+		// the attribute limit is respected.
+		assert.Len(t, out.Entries[1].MessageAttributes, 10)
+		assert.NotContains(t, out.Entries[1].MessageAttributes, "_datadog")
+	})
+
+	t.Run("sns", func(t *testing.T) {
+		in := &sns.PublishInput{Message: aws.String("hello")}
+		out := injectTraceContext(span.Context(), in).(*sns.PublishInput)
+		assert.Nil(t, in.MessageAttributes)
+		attr := out.MessageAttributes["_datadog"]
+		assert.Equal(t, "Binary", *attr.DataType)
+		// with raw message delivery, SQS consumers receive the binary attribute.
+		assertContext(t)(ExtractSQSMessage(&sqs.Message{MessageAttributes: map[string]*sqs.MessageAttributeValue{
+			"_datadog": {DataType: attr.DataType, BinaryValue: attr.BinaryValue},
+		}}))
+	})
+
+	t.Run("kinesis", func(t *testing.T) {
+		in := &kinesis.PutRecordsInput{Records: []*kinesis.PutRecordsRequestEntry{
+			{Data: []byte(`{"id":1}`)},
+			{Data: []byte("not json")},
+		}}
+		out := injectTraceContext(span.Context(), in).(*kinesis.PutRecordsInput)
+		assert.Equal(t, `{"id":1}`, string(in.Records[0].Data))
+		assertContext(t)(ExtractKinesisRecord(&kinesis.Record{Data: out.Records[0].Data}))
+		assert.Equal(t, "not json", string(out.Records[1].Data))
+	})
+
+	t.Run("eventbridge", func(t *testing.T) {
+		in := &eventbridge.PutEventsInput{Entries: []*eventbridge.PutEventsRequestEntry{
+			{Detail: aws.String(`{"id":1}`)},
+		}}
+		out := injectTraceContext(span.Context(), in).(*eventbridge.PutEventsInput)
+		assert.Equal(t, `{"id":1}`, *in.Entries[0].Detail)
+		assertContext(t)(ExtractEventBridgeDetail([]byte(*out.Entries[0].Detail)))
+	})
+}
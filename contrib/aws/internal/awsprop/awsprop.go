@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package awsprop holds the trace context propagation logic shared by the AWS SDK integrations.
+// The trace context is carried as a JSON object of the propagation headers, stored under the
+// "_datadog" key: in the message attributes of SQS and SNS messages, and in the JSON data of
+// Kinesis records and the JSON detail of EventBridge events.
+package awsprop
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+)
+
+const (
+	// DatadogKey is the message attribute or JSON key holding the trace context.
+	DatadogKey = "_datadog"
+
+	// MaxMessageAttributes is the maximum number of message attributes of SQS messages. The
+	// trace context isn't injected into messages which already have as many attributes. The
+	// same limit applies to SNS messages, which may be delivered to SQS queues.
+	MaxMessageAttributes = 10
+
+	// MaxKinesisRecordSize is the maximum size of the data of a Kinesis record.
+	MaxKinesisRecordSize = 1 << 20
+
+	// MaxEventBridgeDetailSize is the maximum size of an EventBridge event. Only the detail
+	// of the events is checked against it.
+	MaxEventBridgeDetailSize = 256 * 1024
+)
+
+// Inject returns the JSON object of the propagation headers of sctx.
+func Inject(sctx ddtrace.SpanContext) ([]byte, error) {
+	carrier := tracer.TextMapCarrier{}
+	if err := tracer.Inject(sctx, carrier); err != nil {
+		return nil, err
+	}
+	return json.Marshal(carrier)
+}
+
+// Extract returns the span context of the JSON object of propagation headers data.
+func Extract(data []byte) (ddtrace.SpanContext, error) {
+	var carrier tracer.TextMapCarrier
+	if err := json.Unmarshal(data, &carrier); err != nil {
+		return nil, err
+	}
+	return tracer.Extract(carrier)
+}
+
+// InjectJSON returns the JSON object obj with the JSON object of propagation headers carrier
+// stored under DatadogKey. It returns false if obj isn't a JSON object or if the result would
+// be larger than maxSize bytes. The other fields of obj are kept as is.
+func InjectJSON(obj, carrier []byte, maxSize int) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(obj)
+	if len(trimmed) < 2 || trimmed[0] != '{' {
+		return nil, false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return nil, false
+	}
+	var out []byte
+	if _, ok := fields[DatadogKey]; ok {
+		// the object was already propagated, for example when it is forwarded: the stale
+		// trace context is replaced, at the cost of reordering the fields.
+		fields[DatadogKey] = carrier
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return nil, false
+		}
+		out = b
+	} else {
+		out = make([]byte, 0, len(trimmed)+len(DatadogKey)+len(carrier)+4)
+		out = append(out, `{"`+DatadogKey+`":`...)
+		out = append(out, carrier...)
+		if len(fields) > 0 {
+			out = append(out, ',')
+		}
+		out = append(out, trimmed[1:]...)
+	}
+	if len(out) > maxSize {
+		return nil, false
+	}
+	return out, true
+}
+
+// ExtractJSON returns the span context stored under DatadogKey in the JSON object obj.
+func ExtractJSON(obj []byte) (ddtrace.SpanContext, error) {
+	var fields struct {
+		Datadog json.RawMessage `json:"_datadog"`
+	}
+	if err := json.Unmarshal(obj, &fields); err != nil || len(fields.Datadog) == 0 {
+		return nil, tracer.ErrSpanContextNotFound
+	}
+	return Extract(fields.Datadog)
+}
+
+// ExtractBody returns the span context carried by the body of an SQS message which wasn't sent
+// by an SQS producer: the JSON envelope of a notification delivered by an SNS subscription
+// without raw message delivery, or the JSON of an event delivered by an EventBridge rule.
+func ExtractBody(body string) (ddtrace.SpanContext, error) {
+	var envelope struct {
+		Type              string `json:"Type"`
+		MessageAttributes map[string]struct {
+			Type  string `json:"Type"`
+			Value string `json:"Value"`
+		} `json:"MessageAttributes"`
+		Detail json.RawMessage `json:"detail"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		return nil, tracer.ErrSpanContextNotFound
+	}
+	if envelope.Type == "Notification" {
+		attr, ok := envelope.MessageAttributes[DatadogKey]
+		if !ok {
+			return nil, tracer.ErrSpanContextNotFound
+		}
+		if attr.Type == "Binary" {
+			data, err := base64.StdEncoding.DecodeString(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+			return Extract(data)
+		}
+		return Extract([]byte(attr.Value))
+	}
+	if len(envelope.Detail) > 0 {
+		return ExtractJSON(envelope.Detail)
+	}
+	return nil, tracer.ErrSpanContextNotFound
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package awsprop
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectJSON(t *testing.T) {
+	carrier := []byte(`{"k":"v"}`)
+	for _, tt := range []struct {
+		name, in, out string
+	}{
+		{name: "empty", in: `{}`, out: `{"_datadog":{"k":"v"}}`},
+		{name: "fields", in: ` {"a": 1, "b": [2]} `, out: `{"_datadog":{"k":"v"},"a": 1, "b": [2]}`},
+		{name: "replace", in: `{"b":1,"_datadog":{"old":"x"}}`, out: `{"_datadog":{"k":"v"},"b":1}`},
+		{name: "array", in: `[1]`},
+		{name: "string", in: `"{}"`},
+		{name: "invalid", in: `{"a":`},
+		{name: "binary", in: "\x00\x01"},
+		{name: "too-large", in: `{"a":"` + strings.Repeat("a", 100) + `"}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out, ok := InjectJSON([]byte(tt.in), carrier, 100)
+			if tt.out == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.out, string(out))
+		})
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	span := tracer.StartSpan("producer")
+	defer span.Finish()
+
+	carrier, err := Inject(span.Context())
+	require.NoError(t, err)
+	assertContext := func(t *testing.T, body string) {
+		sctx, err := ExtractBody(body)
+		require.NoError(t, err)
+		assert.Equal(t, span.Context().TraceID(), sctx.TraceID())
+		assert.Equal(t, span.Context().SpanID(), sctx.SpanID())
+	}
+
+	t.Run("json", func(t *testing.T) {
+		obj, ok := InjectJSON([]byte(`{"a":1}`), carrier, MaxKinesisRecordSize)
+		require.True(t, ok)
+		sctx, err := ExtractJSON(obj)
+		require.NoError(t, err)
+		assert.Equal(t, span.Context().SpanID(), sctx.SpanID())
+
+		_, err = ExtractJSON([]byte(`{"a":1}`))
+		assert.Equal(t, tracer.ErrSpanContextNotFound, err)
+		_, err = ExtractJSON([]byte(`not json`))
+		assert.Equal(t, tracer.ErrSpanContextNotFound, err)
+	})
+
+	t.Run("sns", func(t *testing.T) {
+		assertContext(t, `{
+			"Type": "Notification",
+			"Message": "hello",
+			"MessageAttributes": {
+				"_datadog": {"Type": "Binary", "Value": "`+base64.StdEncoding.EncodeToString(carrier)+`"}
+			}
+		}`)
+		assertContext(t, `{"Type":"Notification","MessageAttributes":{"_datadog":{"Type":"String","Value":`+strconv.Quote(string(carrier))+`}}}`)
+	})
+
+	t.Run("eventbridge", func(t *testing.T) {
+		assertContext(t, `{"detail-type":"created","source":"app","detail":{"id":1,"_datadog":`+string(carrier)+`}}`)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		for _, body := range []string{
+			`hello`,
+			`{"Type":"Notification","MessageAttributes":{}}`,
+			`{"detail":{"id":1}}`,
+			`{"a":1}`,
+		} {
+			_, err := ExtractBody(body)
+			assert.Equal(t, tracer.ErrSpanContextNotFound, err, body)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package awsresource extracts the resource targeted by an AWS request, such as the S3 bucket
+// or the DynamoDB table, from the input of the request. It is shared by the AWS SDK integrations,
+// whose inputs have the same field names.
+package awsresource
+
+import (
+	"reflect"
+	"strings"
+)
+
+// The tags holding the resource targeted by a request.
+const (
+	TagBucketName       = "bucketname"
+	TagObjectKey        = "objectkey"
+	TagTableName        = "tablename"
+	TagQueueName        = "queuename"
+	TagTopicName        = "topicname"
+	TagStreamName       = "streamname"
+	TagFunctionName     = "functionname"
+	TagStateMachineName = "statemachinename"
+	TagQueueURL         = "queueurl"
+	TagTopicARN         = "topicarn"
+	TagStateMachineARN  = "statemachinearn"
+)
+
+// Resource is the resource targeted by a request.
+type Resource struct {
+	// Tags holds the tags naming the resource, along with its full URL or ARN when the
+	// request identifies it this way.
+	Tags map[string]string
+	// PeerService is the default peer.service of the request: the name of the resource.
+	PeerService string
+}
+
+// extractor returns the resource targeted by the request with the given input.
+type extractor func(in reflect.Value, objectKey bool) Resource
+
+// extractors holds the extractors by lowercase service name. Both the service names of the
+// aws-sdk-go clients and the service IDs of the aws-sdk-go-v2 clients are registered.
+var extractors = map[string]extractor{
+	"s3":       s3Resource,
+	"dynamodb": dynamodbResource,
+	"sqs":      sqsResource,
+	"sns":      snsResource,
+	"kinesis":  kinesisResource,
+	"lambda":   lambdaResource,
+	"sfn":      sfnResource,
+	"states":   sfnResource,
+}
+
+// Extract returns the resource targeted by a request to the given service with the input
+// params. The S3 object keys are only tagged if objectKey is set, as they may hold personal
+// data. The returned resource is empty if the service or the request isn't supported.
+func Extract(service string, params interface{}, objectKey bool) Resource {
+	ex, ok := extractors[strings.ToLower(service)]
+	if !ok {
+		return Resource{}
+	}
+	in := reflect.ValueOf(params)
+	for in.Kind() == reflect.Ptr || in.Kind() == reflect.Interface {
+		if in.IsNil() {
+			return Resource{}
+		}
+		in = in.Elem()
+	}
+	if in.Kind() != reflect.Struct {
+		return Resource{}
+	}
+	return ex(in, objectKey)
+}
+
+// newResource returns the resource with the tag name and value, also used as its peer.service.
+// It returns an empty resource if value is empty.
+func newResource(name, value string) Resource {
+	if value == "" {
+		return Resource{}
+	}
+	return Resource{Tags: map[string]string{name: value}, PeerService: value}
+}
+
+// withTag adds the tag name with value to r, unless either of them is empty.
+func (r Resource) withTag(name, value string) Resource {
+	if r.Tags != nil && value != "" {
+		r.Tags[name] = value
+	}
+	return r
+}
+
+func s3Resource(in reflect.Value, objectKey bool) Resource {
+	r := newResource(TagBucketName, stringField(in, "Bucket"))
+	if key := stringField(in, "Key"); objectKey && key != "" && r.Tags != nil {
+		r.Tags[TagObjectKey] = key
+	}
+	return r
+}
+
+func dynamodbResource(in reflect.Value, _ bool) Resource {
+	if table := stringField(in, "TableName"); table != "" {
+		return newResource(TagTableName, table)
+	}
+	// the batch operations are keyed by table, they are tagged if they target a single one.
+	items := in.FieldByName("RequestItems")
+	if items.Kind() == reflect.Map && items.Len() == 1 && items.Type().Key().Kind() == reflect.String {
+		return newResource(TagTableName, items.MapKeys()[0].String())
+	}
+	return Resource{}
+}
+
+func sqsResource(in reflect.Value, _ bool) Resource {
+	if name := stringField(in, "QueueName"); name != "" {
+		return newResource(TagQueueName, name)
+	}
+	// https://sqs.<region>.amazonaws.com/<account>/<name>
+	url := stringField(in, "QueueUrl")
+	return newResource(TagQueueName, url[strings.LastIndexByte(url, '/')+1:]).withTag(TagQueueURL, url)
+}
+
+func snsResource(in reflect.Value, _ bool) Resource {
+	arn := stringField(in, "TopicArn")
+	if arn == "" {
+		arn = stringField(in, "TargetArn")
+	}
+	// arn:aws:sns:<region>:<account>:<name>
+	return newResource(TagTopicName, arn[strings.LastIndexByte(arn, ':')+1:]).withTag(TagTopicARN, arn)
+}
+
+func kinesisResource(in reflect.Value, _ bool) Resource {
+	if name := stringField(in, "StreamName"); name != "" {
+		return newResource(TagStreamName, name)
+	}
+	// arn:aws:kinesis:<region>:<account>:stream/<name>
+	arn := stringField(in, "StreamARN")
+	return newResource(TagStreamName, arn[strings.LastIndexByte(arn, '/')+1:])
+}
+
+func lambdaResource(in reflect.Value, _ bool) Resource {
+	// The function name may be a name, a partial ARN (<account>:function:<name>) or an ARN
+	// (arn:aws:lambda:<region>:<account>:function:<name>), all optionally qualified with a
+	// version or alias.
+	name := stringField(in, "FunctionName")
+	if _, after, ok := strings.Cut(name, ":function:"); ok {
+		name = after
+	}
+	name, _, _ = strings.Cut(name, ":")
+	return newResource(TagFunctionName, name)
+}
+
+func sfnResource(in reflect.Value, _ bool) Resource {
+	// arn:aws:states:<region>:<account>:stateMachine:<name>
+	arn := stringField(in, "StateMachineArn")
+	return newResource(TagStateMachineName, arn[strings.LastIndexByte(arn, ':')+1:]).withTag(TagStateMachineARN, arn)
+}
+
+// stringField returns the value of the string or *string field of in with the given name, or
+// an empty string if in has no such field.
+func stringField(in reflect.Value, name string) string {
+	f := in.FieldByName(name)
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return ""
+		}
+		f = f.Elem()
+	}
+	if f.Kind() != reflect.String {
+		return ""
+	}
+	return f.String()
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package awsresource
+
+import (
+	"testing"
+
+	sqsv2 "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtract(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		service string
+		params  interface{}
+		tag     string
+		value   string
+		full    map[string]string // tags holding the full URL or ARN of the resource
+	}{
+		{name: "s3", service: "s3", params: &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")}, tag: TagBucketName, value: "bucket"},
+		{name: "s3-v2", service: "S3", params: &s3.ListObjectsInput{Bucket: aws.String("bucket")}, tag: TagBucketName, value: "bucket"},
+		{name: "dynamodb", service: "dynamodb", params: &dynamodb.GetItemInput{TableName: aws.String("table")}, tag: TagTableName, value: "table"},
+		{name: "dynamodb-batch", service: "DynamoDB", params: &dynamodb.BatchGetItemInput{RequestItems: map[string]*dynamodb.KeysAndAttributes{"table": {}}}, tag: TagTableName, value: "table"},
+		{name: "sqs-url", service: "sqs", params: &sqs.SendMessageInput{QueueUrl: aws.String("https://sqs.us-east-1.amazonaws.com/123456789012/queue")}, tag: TagQueueName, value: "queue", full: map[string]string{TagQueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/queue"}},
+		{name: "sqs-name", service: "sqs", params: &sqs.GetQueueUrlInput{QueueName: aws.String("queue")}, tag: TagQueueName, value: "queue"},
+		{name: "sqs-v2", service: "SQS", params: &sqsv2.ReceiveMessageInput{QueueUrl: aws.String("http://localhost/000000000000/queue")}, tag: TagQueueName, value: "queue", full: map[string]string{TagQueueURL: "http://localhost/000000000000/queue"}},
+		{name: "sns", service: "sns", params: &sns.PublishInput{TopicArn: aws.String("arn:aws:sns:us-east-1:123456789012:topic")}, tag: TagTopicName, value: "topic", full: map[string]string{TagTopicARN: "arn:aws:sns:us-east-1:123456789012:topic"}},
+		{name: "sns-target", service: "SNS", params: &sns.PublishInput{TargetArn: aws.String("arn:aws:sns:us-east-1:123456789012:topic")}, tag: TagTopicName, value: "topic", full: map[string]string{TagTopicARN: "arn:aws:sns:us-east-1:123456789012:topic"}},
+		{name: "kinesis", service: "kinesis", params: &kinesis.PutRecordInput{StreamName: aws.String("stream")}, tag: TagStreamName, value: "stream"},
+		{name: "lambda", service: "lambda", params: &lambda.InvokeInput{FunctionName: aws.String("function")}, tag: TagFunctionName, value: "function"},
+		{name: "lambda-arn", service: "Lambda", params: &lambda.InvokeInput{FunctionName: aws.String("arn:aws:lambda:us-east-1:123456789012:function:function:prod")}, tag: TagFunctionName, value: "function"},
+		{name: "lambda-partial-arn", service: "lambda", params: &lambda.InvokeInput{FunctionName: aws.String("123456789012:function:function")}, tag: TagFunctionName, value: "function"},
+		{name: "sfn", service: "states", params: &sfn.StartExecutionInput{StateMachineArn: aws.String("arn:aws:states:us-east-1:123456789012:stateMachine:machine")}, tag: TagStateMachineName, value: "machine", full: map[string]string{TagStateMachineARN: "arn:aws:states:us-east-1:123456789012:stateMachine:machine"}},
+		{name: "sfn-v2", service: "SFN", params: &sfn.DescribeStateMachineInput{StateMachineArn: aws.String("arn:aws:states:us-east-1:123456789012:stateMachine:machine")}, tag: TagStateMachineName, value: "machine", full: map[string]string{TagStateMachineARN: "arn:aws:states:us-east-1:123456789012:stateMachine:machine"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Extract(tt.service, tt.params, false)
+			tags := map[string]string{tt.tag: tt.value}
+			for k, v := range tt.full {
+				tags[k] = v
+			}
+			assert.Equal(t, tags, r.Tags)
+			assert.Equal(t, tt.value, r.PeerService)
+		})
+	}
+
+	t.Run("object-key", func(t *testing.T) {
+		r := Extract("s3", &s3.PutObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")}, true)
+		assert.Equal(t, map[string]string{TagBucketName: "bucket", TagObjectKey: "key"}, r.Tags)
+		assert.Equal(t, "bucket", r.PeerService)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		for _, tt := range []struct {
+			service string
+			params  interface{}
+		}{
+			{"ec2", &s3.GetObjectInput{Bucket: aws.String("bucket")}},
+			{"s3", &s3.ListBucketsInput{}},
+			{"s3", (*s3.GetObjectInput)(nil)},
+			{"s3", nil},
+			{"s3", "bucket"},
+			{"dynamodb", &dynamodb.BatchGetItemInput{RequestItems: map[string]*dynamodb.KeysAndAttributes{"a": {}, "b": {}}}},
+			{"sqs", &sqs.ListQueuesInput{}},
+		} {
+			assert.Equal(t, Resource{}, Extract(tt.service, tt.params, true))
+		}
+	})
+}
@@ -6,8 +6,9 @@
 package pubsub
 
 type config struct {
-	serviceName string
-	measured    bool
+	serviceName        string
+	measured           bool
+	dataStreamsEnabled bool
 }
 
 // A Option is used to customize spans started by WrapReceiveHandler or Publish.
@@ -29,3 +30,13 @@ func WithMeasured() Option {
 		cfg.measured = true
 	}
 }
+
+// WithDataStreams enables Data Streams Monitoring: checkpoints are set on published and received
+// messages, and the pathway context is propagated in the message attributes. The pathway of a received
+// message is continued in the context passed to the receive handler, so that messages published with
+// that context are part of the same pathway. It requires Data Streams Monitoring to be enabled in the tracer.
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreamsEnabled = true
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/datastreams"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -62,6 +63,12 @@ func Publish(ctx context.Context, t *pubsub.Topic, msg *pubsub.Message, opts ...
 	if err := tracer.Inject(span.Context(), tracer.TextMapCarrier(msg.Attributes)); err != nil {
 		log.Debug("contrib/cloud.google.com/go/pubsub.v1/: failed injecting tracing attributes: %v", err)
 	}
+	if cfg.dataStreamsEnabled {
+		edges := []string{"direction:out", "topic:" + t.ID(), "type:google-pubsub"}
+		if dsmCtx, ok := tracer.SetDataStreamsCheckpoint(ctx, edges...); ok {
+			datastreams.InjectToBase64Carrier(dsmCtx, tracer.TextMapCarrier(msg.Attributes))
+		}
+	}
 	span.SetTag("num_attributes", len(msg.Attributes))
 	return &PublishResult{
 		PublishResult: t.Publish(ctx, msg),
@@ -118,6 +125,11 @@ func WrapReceiveHandler(s *pubsub.Subscription, f func(context.Context, *pubsub.
 			opts = append(opts, tracer.Measured())
 		}
 		span, ctx := tracer.StartSpanFromContext(ctx, "pubsub.receive", opts...)
+		if cfg.dataStreamsEnabled {
+			// subscriptions are the equivalent of consumer groups
+			edges := []string{"direction:in", "group:" + s.ID(), "type:google-pubsub"}
+			ctx, _ = tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(ctx, tracer.TextMapCarrier(msg.Attributes)), edges...)
+		}
 		if msg.DeliveryAttempt != nil {
 			span.SetTag("delivery_attempt", *msg.DeliveryAttempt)
 		}
@@ -10,9 +10,11 @@ import (
 	"testing"
 	"time"
 
+	dsm "github.com/lannguyen-c0x12c/dd-trace-go/datastreams"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
 
 	"cloud.google.com/go/pubsub"
 	"cloud.google.com/go/pubsub/pstest"
@@ -114,6 +116,32 @@ func TestPropagationWithServiceName(t *testing.T) {
 	assert.Equal("example.service", spans[2].Tag(ext.ServiceName))
 }
 
+func TestDataStreams(t *testing.T) {
+	assert := assert.New(t)
+	ctx, topic, sub, _, cleanup := setup(t)
+	defer cleanup()
+
+	// Publisher
+	_, err := Publish(ctx, topic, &pubsub.Message{Data: []byte("hello")}, WithDataStreams()).Get(ctx)
+	assert.NoError(err)
+
+	// Subscriber
+	var called bool
+	err = sub.Receive(ctx, WrapReceiveHandler(sub, func(ctx context.Context, msg *pubsub.Message) {
+		assert.Contains(msg.Attributes, datastreams.PropagationKeyBase64)
+		produced, ok := datastreams.PathwayFromContext(dsm.ExtractFromBase64Carrier(context.Background(), tracer.TextMapCarrier(msg.Attributes)))
+		assert.True(ok, "the pathway should be propagated in the message attributes")
+		consumed, ok := datastreams.PathwayFromContext(ctx)
+		assert.True(ok, "the pathway should be continued in the handler context")
+		assert.NotEqual(produced.GetHash(), consumed.GetHash())
+		assert.Equal(produced.PathwayStart(), consumed.PathwayStart())
+		msg.Ack()
+		called = true
+	}, WithDataStreams()))
+	assert.NoError(err)
+	assert.True(called, "callback not called")
+}
+
 func TestPropagationNoParentSpan(t *testing.T) {
 	assert := assert.New(t)
 	ctx, topic, sub, mt, cleanup := setup(t)
@@ -7,9 +7,11 @@
 package kafka // import "github.com/lannguyen-c0x12c/dd-trace-go/contrib/confluentinc/confluent-kafka-go/kafka"
 
 import (
+	"context"
 	"math"
 	"time"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/datastreams"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -31,6 +33,7 @@ func NewConsumer(conf *kafka.ConfigMap, opts ...Option) (*Consumer, error) {
 	if err != nil {
 		return nil, err
 	}
+	opts = append([]Option{WithConfig(conf)}, opts...)
 	return WrapConsumer(c, opts...), nil
 }
 
@@ -124,9 +127,66 @@ func (c *Consumer) startSpan(msg *kafka.Message) ddtrace.Span {
 	span, _ := tracer.StartSpanFromContext(c.cfg.ctx, c.cfg.consumerOperationName, opts...)
 	// reinject the span context so consumers can pick it up
 	tracer.Inject(span.Context(), carrier)
+	if c.cfg.dataStreamsEnabled {
+		setConsumeCheckpoint(c.cfg.groupID, msg)
+	}
 	return span
 }
 
+// setConsumeCheckpoint sets a Data Streams checkpoint on the consumed message, continuing the
+// pathway found in its headers, and reinjects the resulting pathway for downstream producers.
+func setConsumeCheckpoint(groupID string, msg *kafka.Message) {
+	if msg == nil || msg.TopicPartition.Topic == nil {
+		return
+	}
+	edges := []string{"direction:in", "topic:" + *msg.TopicPartition.Topic, "type:kafka"}
+	if groupID != "" {
+		edges = append(edges, "group:"+groupID)
+	}
+	carrier := NewMessageCarrier(msg)
+	ctx, ok := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(context.Background(), carrier), edges...)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+// setProduceCheckpoint sets a Data Streams checkpoint on the produced message, continuing the
+// pathway found in its headers if any, and injects the resulting pathway in the message headers.
+func setProduceCheckpoint(msg *kafka.Message) {
+	if msg == nil || msg.TopicPartition.Topic == nil {
+		return
+	}
+	edges := []string{"direction:out", "topic:" + *msg.TopicPartition.Topic, "type:kafka"}
+	carrier := NewMessageCarrier(msg)
+	ctx, ok := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(context.Background(), carrier), edges...)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+// commitOffsets tracks the committed offsets in Data Streams Monitoring.
+func commitOffsets(dataStreamsEnabled bool, groupID string, tps []kafka.TopicPartition, err error) {
+	if err != nil || !dataStreamsEnabled {
+		return
+	}
+	for _, tp := range tps {
+		if tp.Topic == nil {
+			continue
+		}
+		tracer.TrackKafkaCommitOffset(groupID, *tp.Topic, tp.Partition, int64(tp.Offset))
+	}
+}
+
+// trackProduceOffsets tracks the offset of a delivered message in Data Streams Monitoring.
+func trackProduceOffsets(dataStreamsEnabled bool, msg *kafka.Message) {
+	if !dataStreamsEnabled || msg.TopicPartition.Error != nil || msg.TopicPartition.Topic == nil {
+		return
+	}
+	tracer.TrackKafkaProduceOffset(*msg.TopicPartition.Topic, msg.TopicPartition.Partition, int64(msg.TopicPartition.Offset))
+}
+
 // Close calls the underlying Consumer.Close and if polling is enabled, finishes
 // any remaining span.
 func (c *Consumer) Close() error {
@@ -175,11 +235,33 @@ func (c *Consumer) ReadMessage(timeout time.Duration) (*kafka.Message, error) {
 	return msg, nil
 }
 
+// Commit commits current offsets and tracks the commit offsets if Data Streams Monitoring is enabled.
+func (c *Consumer) Commit() ([]kafka.TopicPartition, error) {
+	tps, err := c.Consumer.Commit()
+	commitOffsets(c.cfg.dataStreamsEnabled, c.cfg.groupID, tps, err)
+	return tps, err
+}
+
+// CommitMessage commits a message and tracks the commit offsets if Data Streams Monitoring is enabled.
+func (c *Consumer) CommitMessage(msg *kafka.Message) ([]kafka.TopicPartition, error) {
+	tps, err := c.Consumer.CommitMessage(msg)
+	commitOffsets(c.cfg.dataStreamsEnabled, c.cfg.groupID, tps, err)
+	return tps, err
+}
+
+// CommitOffsets commits provided offsets and tracks the commit offsets if Data Streams Monitoring is enabled.
+func (c *Consumer) CommitOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	tps, err := c.Consumer.CommitOffsets(offsets)
+	commitOffsets(c.cfg.dataStreamsEnabled, c.cfg.groupID, tps, err)
+	return tps, err
+}
+
 // A Producer wraps a kafka.Producer.
 type Producer struct {
 	*kafka.Producer
 	cfg            *config
 	produceChannel chan *kafka.Message
+	events         chan kafka.Event
 }
 
 // WrapProducer wraps a kafka.Producer so requests are traced.
@@ -190,9 +272,38 @@ func WrapProducer(p *kafka.Producer, opts ...Option) *Producer {
 	}
 	log.Debug("contrib/confluentinc/confluent-kafka-go/kafka: Wrapping Producer: %#v", wrapped.cfg)
 	wrapped.produceChannel = wrapped.traceProduceChannel(p.ProduceChannel())
+	if wrapped.cfg.dataStreamsEnabled {
+		wrapped.events = wrapped.traceEventsChannel(p.Events())
+	}
 	return wrapped
 }
 
+// Events returns the kafka Events channel. When Data Streams Monitoring is enabled, the offsets
+// of the delivered messages are tracked.
+func (p *Producer) Events() chan kafka.Event {
+	if p.events == nil {
+		return p.Producer.Events()
+	}
+	return p.events
+}
+
+func (p *Producer) traceEventsChannel(in chan kafka.Event) chan kafka.Event {
+	if in == nil {
+		return nil
+	}
+	out := make(chan kafka.Event, 1)
+	go func() {
+		defer close(out)
+		for evt := range in {
+			if msg, ok := evt.(*kafka.Message); ok {
+				trackProduceOffsets(p.cfg.dataStreamsEnabled, msg)
+			}
+			out <- evt
+		}
+	}()
+	return out
+}
+
 func (p *Producer) traceProduceChannel(out chan *kafka.Message) chan *kafka.Message {
 	if out == nil {
 		return out
@@ -232,6 +343,9 @@ func (p *Producer) startSpan(msg *kafka.Message) ddtrace.Span {
 	span, _ := tracer.StartSpanFromContext(p.cfg.ctx, p.cfg.producerOperationName, opts...)
 	// inject the span context so consumers can pick it up
 	tracer.Inject(span.Context(), carrier)
+	if p.cfg.dataStreamsEnabled {
+		setProduceCheckpoint(msg)
+	}
 	return span
 }
 
@@ -257,6 +371,7 @@ func (p *Producer) Produce(msg *kafka.Message, deliveryChan chan kafka.Event) er
 			if msg, ok := evt.(*kafka.Message); ok {
 				// delivery errors are returned via TopicPartition.Error
 				err = msg.TopicPartition.Error
+				trackProduceOffsets(p.cfg.dataStreamsEnabled, msg)
 			}
 			span.Finish(tracer.WithError(err))
 			oldDeliveryChan <- evt
@@ -13,9 +13,11 @@ import (
 	"time"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/namingschematest"
+	dsm "github.com/lannguyen-c0x12c/dd-trace-go/datastreams"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/stretchr/testify/assert"
@@ -151,6 +153,56 @@ func TestConsumerChannel(t *testing.T) {
 	}
 }
 
+func TestDataStreams(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	c, err := NewConsumer(&kafka.ConfigMap{
+		"go.events.channel.enable": true, // required for the events channel to be turned on
+		"group.id":                 testGroupID,
+		"socket.timeout.ms":        10,
+		"session.timeout.ms":       10,
+		"enable.auto.offset.store": false,
+	}, WithDataStreams())
+	require.NoError(t, err)
+	assert.Equal(t, testGroupID, c.cfg.groupID)
+
+	// the producer checkpoint injects the pathway in the headers
+	msg := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &testTopic,
+			Partition: 1,
+			Offset:    1,
+		},
+		Key:   []byte("key1"),
+		Value: []byte("value1"),
+	}
+	setProduceCheckpoint(msg)
+	produced, ok := datastreams.PathwayFromContext(dsm.ExtractFromBase64Carrier(context.Background(), NewMessageCarrier(msg)))
+	require.True(t, ok)
+
+	go func() {
+		c.Consumer.Events() <- msg
+	}()
+	got := (<-c.Events()).(*kafka.Message)
+	c.Close()
+	// wait for the events channel to be closed
+	<-c.Events()
+
+	// the consumer checkpoint continues the pathway
+	consumed, ok := datastreams.PathwayFromContext(dsm.ExtractFromBase64Carrier(context.Background(), NewMessageCarrier(got)))
+	require.True(t, ok)
+	assert.NotEqual(t, produced.GetHash(), consumed.GetHash())
+	assert.Equal(t, produced.PathwayStart(), consumed.PathwayStart())
+	var n int
+	for _, h := range got.Headers {
+		if h.Key == datastreams.PropagationKeyBase64 {
+			n++
+		}
+	}
+	assert.Equal(t, 1, n)
+}
+
 /*
 to run the integration test locally:
 
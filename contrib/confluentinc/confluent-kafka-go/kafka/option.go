@@ -23,6 +23,8 @@ type config struct {
 	producerOperationName string
 	analyticsRate         float64
 	tagFns                map[string]func(msg *kafka.Message) interface{}
+	dataStreamsEnabled    bool
+	groupID               string
 }
 
 // An Option customizes the config.
@@ -37,6 +39,7 @@ func newConfig(opts ...Option) *config {
 	if internal.BoolEnv("DD_TRACE_KAFKA_ANALYTICS_ENABLED", false) {
 		cfg.analyticsRate = 1.0
 	}
+	cfg.dataStreamsEnabled = internal.BoolEnv("DD_DATA_STREAMS_ENABLED", false)
 
 	cfg.consumerServiceName = namingschema.NewServiceNameSchema("", "kafka").GetName()
 	cfg.producerServiceName = namingschema.NewServiceNameSchema(
@@ -104,3 +107,23 @@ func WithCustomTag(tag string, tagFn func(msg *kafka.Message) interface{}) Optio
 		cfg.tagFns[tag] = tagFn
 	}
 }
+
+// WithConfig extracts the consumer group from the given kafka configuration, in order to
+// identify the consumer in Data Streams Monitoring. It is applied automatically by NewConsumer.
+func WithConfig(cg *kafka.ConfigMap) Option {
+	return func(cfg *config) {
+		if groupID, err := cg.Get("group.id", ""); err == nil {
+			cfg.groupID, _ = groupID.(string)
+		}
+	}
+}
+
+// WithDataStreams enables Data Streams Monitoring: checkpoints are set on produced and consumed
+// messages, the pathway context is propagated in the message headers, and the produced and committed
+// offsets are tracked. It requires Data Streams Monitoring to be enabled in the tracer.
+// This can also be enabled with the DD_DATA_STREAMS_ENABLED environment variable.
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreamsEnabled = true
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/sqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -287,6 +288,9 @@ func (tp *traceParams) tryTrace(ctx context.Context, qtype QueryType, query stri
 	resource := string(qtype)
 	if query != "" {
 		resource = query
+		if tp.cfg.queryObfuscation {
+			resource = sqlobfuscate.Query(query, dbSystem)
+		}
 	}
 	span.SetTag("sql.query_type", string(qtype))
 	span.SetTag(ext.ResourceName, resource)
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/database/sql/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
 
@@ -381,3 +382,43 @@ func TestWithCustomTag(t *testing.T) {
 		})
 	}
 }
+
+func TestWithQueryObfuscation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		registerOpts []Option
+		openOpts     []Option
+		want         string
+	}{
+		"disabled":           {want: "SELECT * FROM t WHERE a = 'x' /* secret */"},
+		"enabled":            {registerOpts: []Option{WithQueryObfuscation(true)}, want: "SELECT * FROM t WHERE a = ?"},
+		"enabled-on-open":    {openOpts: []Option{WithQueryObfuscation(true)}, want: "SELECT * FROM t WHERE a = ?"},
+		"disabled-on-open":   {registerOpts: []Option{WithQueryObfuscation(true)}, openOpts: []Option{WithQueryObfuscation(false)}, want: "SELECT * FROM t WHERE a = 'x' /* secret */"},
+		"registered-on-open": {registerOpts: []Option{WithQueryObfuscation(true)}, openOpts: []Option{WithServiceName("db")}, want: "SELECT * FROM t WHERE a = ?"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			d := &internal.MockDriver{}
+			Register("test", d, tc.registerOpts...)
+			defer unregister("test")
+
+			db, err := Open("test", "dn", tc.openOpts...)
+			require.NoError(t, err)
+			_, err = db.ExecContext(context.Background(), "SELECT * FROM t WHERE a = 'x' /* secret */")
+			require.NoError(t, err)
+
+			// the query sent to the database is left untouched
+			require.Len(t, d.Executed, 1)
+			assert.Equal(t, "SELECT * FROM t WHERE a = 'x' /* secret */", d.Executed[0])
+			var exec bool
+			for _, s := range mt.FinishedSpans() {
+				if s.Tag("sql.query_type") == string(QueryTypeExec) {
+					exec = true
+					assert.Equal(t, tc.want, s.Tag(ext.ResourceName))
+				}
+			}
+			assert.True(t, exec)
+		})
+	}
+}
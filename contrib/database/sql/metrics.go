@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package sql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
+)
+
+const metricsPrefix = "datadog.tracer.sql.db."
+
+// Names of the connection pool metrics reported by WithDBStats. The gauges report the state
+// of the pool when polled, the counts report the increase since the previous poll.
+const (
+	metricMaxOpenConnections = metricsPrefix + "connections.max_open"              // gauge
+	metricOpenConnections    = metricsPrefix + "connections.open"                  // gauge
+	metricInUse              = metricsPrefix + "connections.in_use"                // gauge
+	metricIdle               = metricsPrefix + "connections.idle"                  // gauge
+	metricWaitCount          = metricsPrefix + "connections.waited"                // count
+	metricWaitDuration       = metricsPrefix + "connections.wait_duration"         // count, in milliseconds
+	metricMaxIdleClosed      = metricsPrefix + "connections.closed.max_idle_conns" // count
+	metricMaxIdleTimeClosed  = metricsPrefix + "connections.closed.max_idle_time"  // count
+	metricMaxLifetimeClosed  = metricsPrefix + "connections.closed.max_lifetime"   // count
+)
+
+// keyConnWaitTime is the tag of Connect spans holding the time in milliseconds the caller
+// waited for the connection, when WithDBStats is enabled.
+const keyConnWaitTime = "db.connection.wait_time_ms"
+
+// dbStatsInterval is the interval at which the connection pool stats are reported.
+var dbStatsInterval = 10 * time.Second
+
+// pollDBStats reports the connection pool stats of db through the tracer's statsd client
+// every dbStatsInterval, until stop is closed. Nothing is reported while the tracer isn't
+// running.
+func pollDBStats(db *sql.DB, cfg *config, meta map[string]string, stop <-chan struct{}) {
+	tags := dbStatsTags(cfg, meta)
+	tick := time.NewTicker(dbStatsInterval)
+	defer tick.Stop()
+	var prev sql.DBStats
+	for {
+		select {
+		case <-tick.C:
+			stats := db.Stats()
+			if statsd := globalconfig.Statsd(); statsd != nil {
+				reportDBStats(statsd, stats, prev, tags)
+			}
+			prev = stats
+		case <-stop:
+			return
+		}
+	}
+}
+
+// dbStatsTags returns the tags of the connection pool metrics.
+func dbStatsTags(cfg *config, meta map[string]string) []string {
+	tags := []string{"service:" + cfg.serviceName}
+	if name, ok := meta[ext.DBName]; ok {
+		tags = append(tags, "db.name:"+name)
+	}
+	return tags
+}
+
+// reportDBStats reports stats, given prev, the stats reported at the previous poll.
+func reportDBStats(statsd globalconfig.StatsdClient, stats, prev sql.DBStats, tags []string) {
+	statsd.Gauge(metricMaxOpenConnections, float64(stats.MaxOpenConnections), tags, 1)
+	statsd.Gauge(metricOpenConnections, float64(stats.OpenConnections), tags, 1)
+	statsd.Gauge(metricInUse, float64(stats.InUse), tags, 1)
+	statsd.Gauge(metricIdle, float64(stats.Idle), tags, 1)
+	statsd.Count(metricWaitCount, stats.WaitCount-prev.WaitCount, tags, 1)
+	statsd.Count(metricWaitDuration, (stats.WaitDuration - prev.WaitDuration).Milliseconds(), tags, 1)
+	statsd.Count(metricMaxIdleClosed, stats.MaxIdleClosed-prev.MaxIdleClosed, tags, 1)
+	statsd.Count(metricMaxIdleTimeClosed, stats.MaxIdleTimeClosed-prev.MaxIdleTimeClosed, tags, 1)
+	statsd.Count(metricMaxLifetimeClosed, stats.MaxLifetimeClosed-prev.MaxLifetimeClosed, tags, 1)
+}
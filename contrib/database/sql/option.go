@@ -10,6 +10,7 @@ import (
 	"math"
 	"os"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/sqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/namingschema"
@@ -25,6 +26,13 @@ type config struct {
 	errCheck           func(err error) bool
 	tags               map[string]interface{}
 	dbmPropagationMode tracer.DBMPropagationMode
+	dbStats            bool
+	queryObfuscation   bool
+
+	// dbStatsSet and queryObfuscationSet report whether dbStats and queryObfuscation were
+	// set explicitly, in which case OpenDB doesn't replace them with the registered ones.
+	dbStatsSet          bool
+	queryObfuscationSet bool
 }
 
 // Option represents an option that can be passed to Register, Open or OpenDB.
@@ -48,6 +56,7 @@ func defaults(cfg *config, driverName string, rc *registerConfig) {
 		mode = os.Getenv("DD_TRACE_SQL_COMMENT_INJECTION_MODE")
 	}
 	cfg.dbmPropagationMode = tracer.DBMPropagationMode(mode)
+	cfg.queryObfuscation = sqlobfuscate.Enabled()
 	cfg.serviceName = getServiceName(driverName, rc)
 	cfg.spanName = getSpanName(driverName)
 }
@@ -178,3 +187,27 @@ func WithDBMPropagation(mode tracer.DBMPropagationMode) Option {
 		cfg.dbmPropagationMode = mode
 	}
 }
+
+// WithDBStats specifies whether the connection pool stats of the database (open, in-use
+// and idle connections, connection waits and closed connections) are periodically reported
+// through the tracer's statsd client, tagged with the service and database names. It also
+// tags Connect spans with the time the caller waited for the connection. It is disabled by
+// default.
+func WithDBStats(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.dbStats = enabled
+		cfg.dbStatsSet = true
+	}
+}
+
+// WithQueryObfuscation specifies whether the queries are obfuscated before being reported
+// as the resource of the spans, replacing literals with "?", collapsing lists of values and
+// removing comments, so that no sensitive value leaves the process. The default is set by
+// the DD_TRACE_SQL_OBFUSCATION_ENABLED environment variable, and is disabled otherwise,
+// in which case the queries are obfuscated by the agent.
+func WithQueryObfuscation(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.queryObfuscation = enabled
+		cfg.queryObfuscationSet = true
+	}
+}
@@ -20,12 +20,14 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"errors"
+	"io"
 	"math"
 	"reflect"
 	"sync"
 	"time"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/database/sql/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
@@ -146,31 +148,61 @@ type tracedConnector struct {
 	connector  driver.Connector
 	driverName string
 	cfg        *config
+
+	// stopStats stops the reporting of the connection pool stats when closed.
+	// It is nil unless WithDBStats is enabled.
+	stopStats chan struct{}
+	closeOnce sync.Once
 }
 
 func (t *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
 	tp := &traceParams{
 		driverName: t.driverName,
 		cfg:        t.cfg,
-	}
-	if dc, ok := t.connector.(*dsnConnector); ok {
-		tp.meta, _ = internal.ParseDSN(t.driverName, dc.dsn)
-	} else if t.cfg.dsn != "" {
-		tp.meta, _ = internal.ParseDSN(t.driverName, t.cfg.dsn)
+		meta:       t.meta(),
 	}
 	start := time.Now()
 	conn, err := t.connector.Connect(ctx)
-	tp.tryTrace(ctx, QueryTypeConnect, "", start, err)
+	var opts []ddtrace.StartSpanOption
+	if t.cfg.dbStats {
+		opts = append(opts, tracer.Tag(keyConnWaitTime, float64(time.Since(start))/float64(time.Millisecond)))
+	}
+	tp.tryTrace(ctx, QueryTypeConnect, "", start, err, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &TracedConn{conn, tp}, err
 }
 
+// meta returns the span tags derived from the DSN of the database.
+func (t *tracedConnector) meta() map[string]string {
+	var meta map[string]string
+	if dc, ok := t.connector.(*dsnConnector); ok {
+		meta, _ = internal.ParseDSN(t.driverName, dc.dsn)
+	} else if t.cfg.dsn != "" {
+		meta, _ = internal.ParseDSN(t.driverName, t.cfg.dsn)
+	}
+	return meta
+}
+
 func (t *tracedConnector) Driver() driver.Driver {
 	return t.connector.Driver()
 }
 
+// Close implements io.Closer, which is called by sql.DB.Close. It stops the reporting
+// of the connection pool stats and closes the wrapped connector if it implements io.Closer.
+func (t *tracedConnector) Close() error {
+	t.closeOnce.Do(func() {
+		if t.stopStats != nil {
+			close(t.stopStats)
+		}
+	})
+	if c, ok := t.connector.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 // from Go stdlib implementation of sql.Open
 type dsnConnector struct {
 	dsn    string
@@ -210,12 +242,24 @@ func OpenDB(c driver.Connector, opts ...Option) *sql.DB {
 	}
 	cfg.ignoreQueryTypes = rc.ignoreQueryTypes
 	cfg.childSpansOnly = rc.childSpansOnly
+	if !cfg.dbStatsSet {
+		cfg.dbStats = rc.dbStats
+	}
+	if !cfg.queryObfuscationSet {
+		cfg.queryObfuscation = rc.queryObfuscation
+	}
 	tc := &tracedConnector{
 		connector:  c,
 		driverName: driverName,
 		cfg:        cfg,
 	}
-	return sql.OpenDB(tc)
+	if !cfg.dbStats {
+		return sql.OpenDB(tc)
+	}
+	tc.stopStats = make(chan struct{})
+	db := sql.OpenDB(tc)
+	go pollDBStats(db, cfg, tc.meta(), tc.stopStats)
+	return db
 }
 
 // Open returns connection to a DB using the traced version of the given driver. In order for Open
@@ -43,6 +43,7 @@ func FilterFunc(configOpts ...Option) restful.FilterFunction {
 		}
 		span, ctx := httptrace.StartRequestSpan(req.Request, spanOpts...)
 		defer func() {
+			httptrace.SetResponseHeaderTags(span, resp.Header(), nil)
 			httptrace.FinishRequestSpan(span, resp.StatusCode(), tracer.WithError(resp.Error()))
 		}()
 
@@ -56,6 +57,7 @@ func FilterFunc(configOpts ...Option) restful.FilterFunction {
 func Filter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
 	span, ctx := httptrace.StartRequestSpan(req.Request, tracer.ResourceName(req.SelectedRoutePath()))
 	defer func() {
+		httptrace.SetResponseHeaderTags(span, resp.Header(), nil)
 		httptrace.FinishRequestSpan(span, resp.StatusCode(), tracer.WithError(resp.Error()))
 	}()
 
@@ -51,6 +51,7 @@ func Middleware(service string, opts ...Option) gin.HandlerFunc {
 
 		span, ctx := httptrace.StartRequestSpan(c.Request, opts...)
 		defer func() {
+			httptrace.SetResponseHeaderTags(span, c.Writer.Header(), nil)
 			httptrace.FinishRequestSpan(span, c.Writer.Status())
 		}()
 
@@ -57,6 +57,7 @@ func Middleware(opts ...Option) func(next http.Handler) http.Handler {
 				if cfg.isStatusError(status) {
 					opts = []tracer.FinishOption{tracer.WithError(fmt.Errorf("%d: %s", status, http.StatusText(status)))}
 				}
+				httptrace.SetResponseHeaderTags(span, ww.Header(), nil)
 				httptrace.FinishRequestSpan(span, status, opts...)
 			}()
 
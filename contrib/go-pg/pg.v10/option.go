@@ -7,14 +7,19 @@ package pg
 
 import (
 	"math"
+	"os"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/sqlobfuscate"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 )
 
 type config struct {
-	serviceName   string
-	analyticsRate float64
+	serviceName        string
+	analyticsRate      float64
+	queryObfuscation   bool
+	dbmPropagationMode tracer.DBMPropagationMode
 }
 
 // Option represents an option that can be used to create or wrap a client.
@@ -32,6 +37,8 @@ func defaults(cfg *config) {
 	} else {
 		cfg.analyticsRate = math.NaN()
 	}
+	cfg.queryObfuscation = sqlobfuscate.Enabled()
+	cfg.dbmPropagationMode = tracer.DBMPropagationMode(os.Getenv("DD_DBM_PROPAGATION_MODE"))
 }
 
 // WithServiceName sets the given service name for the client.
@@ -63,3 +70,24 @@ func WithAnalyticsRate(rate float64) Option {
 		}
 	}
 }
+
+// WithQueryObfuscation sets whether the queries are obfuscated in the tracer before being
+// used as span resources. Defaults to the value of DD_TRACE_SQL_OBFUSCATION_ENABLED, or false.
+func WithQueryObfuscation(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.queryObfuscation = enabled
+	}
+}
+
+// WithDBMPropagation sets the mode of the trace context propagated to the database by the
+// comments added with CommentQuery. The default is set by the DD_DBM_PROPAGATION_MODE
+// environment variable.
+//
+// Note that enabling sql comment propagation results in potentially confidential data (service names)
+// being stored in the databases which can then be accessed by other 3rd parties that have been granted
+// access to the database.
+func WithDBMPropagation(mode tracer.DBMPropagationMode) Option {
+	return func(cfg *config) {
+		cfg.dbmPropagationMode = mode
+	}
+}
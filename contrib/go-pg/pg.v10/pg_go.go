@@ -6,9 +6,11 @@
 package pg
 
 import (
+	"bytes"
 	"context"
 	"math"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/sqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -20,6 +22,9 @@ import (
 
 const componentName = "go-pg/pg.v10"
 
+// keyDBMTraceInjected is the span tag set when the trace context is propagated to the database.
+const keyDBMTraceInjected = "_dd.dbm_trace_injected"
+
 func init() {
 	telemetry.LoadIntegration(componentName)
 }
@@ -41,9 +46,15 @@ type queryHook struct {
 
 // BeforeQuery implements pg.QueryHook.
 func (h *queryHook) BeforeQuery(ctx context.Context, qe *pg.QueryEvent) (context.Context, error) {
+	var dbmOpts []ddtrace.StartSpanOption
 	query, err := qe.UnformattedQuery()
 	if err != nil {
 		query = []byte("unknown")
+	} else {
+		query, dbmOpts = extractDBMComment(query)
+		if h.cfg.queryObfuscation {
+			query = []byte(sqlobfuscate.Query(string(query), ext.DBSystemPostgreSQL))
+		}
 	}
 
 	opts := []ddtrace.StartSpanOption{
@@ -56,6 +67,7 @@ func (h *queryHook) BeforeQuery(ctx context.Context, qe *pg.QueryEvent) (context
 	if !math.IsNaN(h.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, h.cfg.analyticsRate))
 	}
+	opts = append(opts, dbmOpts...)
 	_, ctx = tracer.StartSpanFromContext(ctx, "go-pg", opts...)
 	return ctx, qe.Err
 }
@@ -68,3 +80,51 @@ func (h *queryHook) AfterQuery(ctx context.Context, qe *pg.QueryEvent) error {
 
 	return qe.Err
 }
+
+// CommentQuery returns query prefixed with a comment propagating the trace context of ctx to
+// the database, in the mode set with WithDBMPropagation. Since go-pg formats the queries before
+// calling the query hooks, the comment must be added by the caller. In DBMPropagationModeFull,
+// the comment holds the span ID of the span traced for the query, and the query must only be
+// executed once. The options should be the ones given to Wrap, so that the comment holds the
+// same service name.
+func CommentQuery(ctx context.Context, query string, opts ...Option) string {
+	cfg := new(config)
+	defaults(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	var spanCtx ddtrace.SpanContext
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		spanCtx = span.Context()
+	}
+	carrier := tracer.SQLCommentCarrier{Query: query, Mode: cfg.dbmPropagationMode, DBServiceName: cfg.serviceName}
+	if err := carrier.Inject(spanCtx); err != nil {
+		// this should never happen
+		log.Warn("contrib/go-pg/pg.v10: failed to inject query comments: %v", err)
+		return query
+	}
+	return carrier.Query
+}
+
+// extractDBMComment removes the comment added by CommentQuery from query, returning the
+// options of the span of the query which link it to the propagated trace context.
+func extractDBMComment(query []byte) ([]byte, []ddtrace.StartSpanOption) {
+	if !bytes.HasPrefix(query, []byte("/*")) {
+		return query, nil
+	}
+	end := bytes.Index(query, []byte("*/"))
+	if end < 0 {
+		return query, nil
+	}
+	if !bytes.Contains(query[:end], []byte("dddbs=")) {
+		// not a dbm comment
+		return query, nil
+	}
+	rest := bytes.TrimPrefix(query[end+2:], []byte(" "))
+	carrier := tracer.DBMCommentCarrier{Comment: string(query[2:end])}
+	sctx, err := carrier.Extract()
+	if err != nil {
+		return rest, nil
+	}
+	return rest, []ddtrace.StartSpanOption{tracer.WithSpanID(sctx.SpanID()), tracer.Tag(keyDBMTraceInjected, true)}
+}
@@ -264,3 +264,53 @@ func TestAnalyticsSettings(t *testing.T) {
 		assertRate(t, mt, 0.23, WithAnalyticsRate(0.23))
 	})
 }
+
+func TestQueryObfuscation(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	conn := pg.Connect(&pg.Options{
+		User:     "postgres",
+		Password: "postgres",
+		Database: "postgres",
+	})
+	Wrap(conn, WithQueryObfuscation(true))
+
+	var n int
+	_, err := conn.QueryOne(pg.Scan(&n), "SELECT 1 /* secret */ WHERE 'jane' IN ('jane', 'john')")
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "SELECT ? WHERE ? IN ( ? )", spans[0].Tag(ext.ResourceName))
+}
+
+func TestDBMPropagation(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	conn := pg.Connect(&pg.Options{
+		User:     "postgres",
+		Password: "postgres",
+		Database: "postgres",
+	})
+	opts := []Option{WithServiceName("pg-db"), WithDBMPropagation(tracer.DBMPropagationModeFull)}
+	Wrap(conn, opts...)
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	query := CommentQuery(ctx, "SELECT 1", opts...)
+	assert.Regexp(t, `^/\*dddbs='pg-db',traceparent='00-[\da-f]{32}-[\da-f]{16}-00'\*/ SELECT 1$`, query)
+
+	var n int
+	_, err := conn.QueryOneContext(ctx, pg.Scan(&n), query)
+	require.NoError(t, err)
+	parent.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	assert.Equal(t, "SELECT 1", s.Tag(ext.ResourceName))
+	assert.Equal(t, true, s.Tag(keyDBMTraceInjected))
+	assert.Contains(t, query, fmt.Sprintf("-%016x-", s.SpanID()))
+	assert.Equal(t, parent.Context().SpanID(), s.ParentID())
+}
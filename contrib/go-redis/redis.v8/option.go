@@ -8,12 +8,14 @@ package redis
 import (
 	"math"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 )
 
 type clientConfig struct {
 	serviceName   string
 	analyticsRate float64
+	dbmMode       tracer.DBMPropagationMode
 	skipRaw       bool
 }
 
@@ -68,3 +70,22 @@ func WithAnalyticsRate(rate float64) ClientOption {
 		}
 	}
 }
+
+// WithDBMPropagation enables the propagation of the service tags (name, env and version) to
+// the Redis server. Only DBMPropagationModeService is supported: since Redis commands can't
+// carry comments, the tags are set per connection rather than per command, and the trace
+// context is never propagated, so DBMPropagationModeFull behaves as DBMPropagationModeService.
+// The tags are set as the client name of each connection with CLIENT SETNAME, formatted as a
+// sqlcommenter comment, which replaces the client name of the application; a name set in
+// the OnConnect callback of the client options takes precedence and disables the
+// propagation. The propagation only applies to clients created with NewClient: WrapClient
+// can't hook into the connections of an existing client.
+//
+// Note that enabling dbm propagation results in potentially confidential data (service names)
+// being sent to the database which can then be accessed by other 3rd parties that have been
+// granted access to the database.
+func WithDBMPropagation(mode tracer.DBMPropagationMode) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.dbmMode = mode
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
 
 	"github.com/go-redis/redis/v8"
@@ -42,11 +43,56 @@ type params struct {
 // NewClient returns a new Client that is traced with the default tracer under
 // the service name "redis".
 func NewClient(opt *redis.Options, opts ...ClientOption) redis.UniversalClient {
+	cfg := new(clientConfig)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	if cfg.dbmMode != tracer.DBMPropagationModeUndefined && cfg.dbmMode != tracer.DBMPropagationModeDisabled {
+		o := *opt
+		o.OnConnect = dbmOnConnect(cfg, opt.OnConnect)
+		opt = &o
+	}
 	client := redis.NewClient(opt)
 	WrapClient(client, opts...)
 	return client
 }
 
+// dbmOnConnect returns an OnConnect callback setting the client name propagating the
+// service tags, before calling next.
+func dbmOnConnect(cfg *clientConfig, next func(context.Context, *redis.Conn) error) func(context.Context, *redis.Conn) error {
+	return func(ctx context.Context, cn *redis.Conn) error {
+		var spanCtx ddtrace.SpanContext
+		if span, ok := tracer.SpanFromContext(ctx); ok {
+			spanCtx = span.Context()
+		}
+		if name := dbmClientName(spanCtx, cfg); name != "" {
+			if err := cn.ClientSetName(ctx, name).Err(); err != nil {
+				log.Warn("%s: failed to set the dbm client name: %v", componentName, err)
+			}
+		}
+		if next != nil {
+			return next(ctx, cn)
+		}
+		return nil
+	}
+}
+
+// dbmClientName returns the client name propagating the service tags to the Redis server,
+// or an empty string if the propagation is disabled.
+func dbmClientName(spanCtx ddtrace.SpanContext, cfg *clientConfig) string {
+	if cfg.dbmMode == tracer.DBMPropagationModeUndefined || cfg.dbmMode == tracer.DBMPropagationModeDisabled {
+		return ""
+	}
+	carrier := tracer.DBMCommentCarrier{Mode: tracer.DBMPropagationModeService, DBServiceName: cfg.serviceName}
+	if err := carrier.Inject(spanCtx); err != nil {
+		// this should never happen
+		log.Warn("%s: failed to inject the dbm client name: %v", componentName, err)
+		return ""
+	}
+	return carrier.Comment
+}
+
 // WrapClient adds a hook to the given client that traces with the default tracer under
 // the service name "redis".
 func WrapClient(client redis.UniversalClient, opts ...ClientOption) {
@@ -544,3 +544,30 @@ func TestWithContext(t *testing.T) {
 	assert.Equal(span1.SpanID(), setSpan.ParentID())
 	assert.Equal(span2.SpanID(), getSpan.ParentID())
 }
+
+func TestDBMPropagation(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	globalconfig.SetServiceName("test-service")
+	defer globalconfig.SetServiceName("")
+
+	var connected bool
+	opts := &redis.Options{
+		Addr: "127.0.0.1:6379",
+		DB:   15,
+		OnConnect: func(context.Context, *redis.Conn) error {
+			connected = true
+			return nil
+		},
+	}
+	client := NewClient(opts, WithServiceName("my-redis"), WithDBMPropagation(tracer.DBMPropagationModeFull))
+	defer client.Close()
+
+	name, err := client.Do(ctx, "CLIENT", "GETNAME").Text()
+	assert.NoError(err)
+	assert.Equal("dddbs='my-redis',ddps='test-service'", name)
+	// the callback of the user is still called
+	assert.True(connected)
+}
@@ -8,12 +8,14 @@ package redis // import "github.com/lannguyen-c0x12c/dd-trace-go/contrib/go-redi
 import (
 	"math"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 )
 
 type clientConfig struct {
 	serviceName   string
 	analyticsRate float64
+	dbmMode       tracer.DBMPropagationMode
 }
 
 // ClientOption represents an option that can be used to create or wrap a client.
@@ -58,3 +60,19 @@ func WithAnalyticsRate(rate float64) ClientOption {
 		}
 	}
 }
+
+// WithDBMPropagation enables the propagation of the service tags (name, env and version) to
+// the Redis server for clients created with NewClient. Since Redis commands can't carry
+// comments, the tags are set as the client name of each connection with CLIENT SETNAME,
+// formatted as a sqlcommenter comment, and the trace context isn't propagated:
+// DBMPropagationModeFull is the same as DBMPropagationModeService. Client names set in
+// the OnConnect callback of the client options take precedence.
+//
+// Note that enabling dbm propagation results in potentially confidential data (service names)
+// being sent to the database which can then be accessed by other 3rd parties that have been
+// granted access to the database.
+func WithDBMPropagation(mode tracer.DBMPropagationMode) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.dbmMode = mode
+	}
+}
@@ -62,9 +62,50 @@ type params struct {
 // NewClient returns a new Client that is traced with the default tracer under
 // the service name "redis".
 func NewClient(opt *redis.Options, opts ...ClientOption) *Client {
+	cfg := new(clientConfig)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	if cfg.dbmMode != tracer.DBMPropagationModeUndefined && cfg.dbmMode != tracer.DBMPropagationModeDisabled {
+		o := *opt
+		o.OnConnect = dbmOnConnect(cfg, opt.OnConnect)
+		opt = &o
+	}
 	return WrapClient(redis.NewClient(opt), opts...)
 }
 
+// dbmOnConnect returns an OnConnect callback setting the client name propagating the
+// service tags, before calling next.
+func dbmOnConnect(cfg *clientConfig, next func(*redis.Conn) error) func(*redis.Conn) error {
+	return func(cn *redis.Conn) error {
+		if name := dbmClientName(nil, cfg); name != "" {
+			if err := cn.ClientSetName(name).Err(); err != nil {
+				log.Warn("%s: failed to set the dbm client name: %v", componentName, err)
+			}
+		}
+		if next != nil {
+			return next(cn)
+		}
+		return nil
+	}
+}
+
+// dbmClientName returns the client name propagating the service tags to the Redis server,
+// or an empty string if the propagation is disabled.
+func dbmClientName(spanCtx ddtrace.SpanContext, cfg *clientConfig) string {
+	if cfg.dbmMode == tracer.DBMPropagationModeUndefined || cfg.dbmMode == tracer.DBMPropagationModeDisabled {
+		return ""
+	}
+	carrier := tracer.DBMCommentCarrier{Mode: tracer.DBMPropagationModeService, DBServiceName: cfg.serviceName}
+	if err := carrier.Inject(spanCtx); err != nil {
+		// this should never happen
+		log.Warn("%s: failed to inject the dbm client name: %v", componentName, err)
+		return ""
+	}
+	return carrier.Comment
+}
+
 // WrapClient wraps a given redis.Client with a tracer under the given service name.
 func WrapClient(c *redis.Client, opts ...ClientOption) *Client {
 	cfg := new(clientConfig)
@@ -28,6 +28,9 @@ import (
 
 const componentName = "go.mongodb.org/mongo-driver/mongo"
 
+// keyDBMTraceInjected is the span tag set when the trace context is propagated to the database.
+const keyDBMTraceInjected = "_dd.dbm_trace_injected"
+
 func init() {
 	telemetry.LoadIntegration(componentName)
 }
@@ -62,6 +65,13 @@ func (m *monitor) Started(ctx context.Context, evt *event.CommandStartedEvent) {
 	if !math.IsNaN(m.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, m.cfg.analyticsRate))
 	}
+	if comment, ok := evt.Command.Lookup("comment").StringValueOK(); ok {
+		// the span of a command commented with DBMComment gets the span ID propagated to the database
+		carrier := tracer.DBMCommentCarrier{Comment: comment}
+		if sctx, err := carrier.Extract(); err == nil {
+			opts = append(opts, tracer.WithSpanID(sctx.SpanID()), tracer.Tag(keyDBMTraceInjected, true))
+		}
+	}
 	span, _ := tracer.StartSpanFromContext(ctx, m.cfg.spanName, opts...)
 	key := spanKey{
 		ConnectionID: evt.ConnectionID,
@@ -116,6 +126,35 @@ func NewMonitor(opts ...Option) *event.CommandMonitor {
 	}
 }
 
+// DBMComment returns a comment propagating the trace context of ctx to the database, in the
+// mode set with WithDBMPropagation, or an empty string if the propagation is disabled. Since
+// the commands can't be modified by a CommandMonitor, the comment must be set on the
+// operations by the caller, such as with options.Find().SetComment. In
+// DBMPropagationModeFull, the comment holds the span ID of the span traced by the monitor,
+// and must only be used for a single operation. The options should be the ones given to
+// NewMonitor, so that the comment holds the same service name.
+func DBMComment(ctx context.Context, opts ...Option) string {
+	cfg := new(config)
+	defaults(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.dbmMode == tracer.DBMPropagationModeUndefined || cfg.dbmMode == tracer.DBMPropagationModeDisabled {
+		return ""
+	}
+	var spanCtx ddtrace.SpanContext
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		spanCtx = span.Context()
+	}
+	carrier := tracer.DBMCommentCarrier{Mode: cfg.dbmMode, DBServiceName: cfg.serviceName}
+	if err := carrier.Inject(spanCtx); err != nil {
+		// this should never happen
+		log.Warn("contrib/go.mongodb.org/mongo-driver/mongo: failed to inject the dbm comment: %v", err)
+		return ""
+	}
+	return carrier.Comment
+}
+
 func peerInfo(evt *event.CommandStartedEvent) (hostname, port string) {
 	hostname = evt.ConnectionID
 	port = "27017"
@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -164,3 +165,38 @@ func TestNamingSchema(t *testing.T) {
 	})
 	namingschematest.NewMongoDBTest(genSpans, "mongo")(t)
 }
+
+func TestDBMPropagation(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mongodb-test")
+	opts := []Option{WithServiceName("mongo-db"), WithDBMPropagation(tracer.DBMPropagationModeFull)}
+	comment := DBMComment(ctx, opts...)
+	assert.Regexp(t, `^dddbs='mongo-db',traceparent='00-[\da-f]{32}-[\da-f]{16}-00'$`, comment)
+
+	cmd, err := bson.Marshal(bson.D{{Key: "find", Value: "test-collection"}, {Key: "comment", Value: comment}})
+	require.NoError(t, err)
+	monitor := NewMonitor(opts...)
+	monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      cmd,
+		DatabaseName: "test-database",
+		CommandName:  "find",
+		RequestID:    1,
+		ConnectionID: "localhost:27017[-1]",
+	})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1, ConnectionID: "localhost:27017[-1]"},
+	})
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	assert.Equal(t, "mongo.find", s.Tag(ext.ResourceName))
+	assert.Equal(t, true, s.Tag(keyDBMTraceInjected))
+	assert.Contains(t, comment, fmt.Sprintf("-%016x-", s.SpanID()))
+	assert.Equal(t, span.Context().SpanID(), s.ParentID())
+
+	assert.Empty(t, DBMComment(ctx, WithServiceName("mongo-db")))
+}
@@ -8,6 +8,7 @@ package mongo
 import (
 	"math"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/namingschema"
 )
@@ -18,6 +19,7 @@ type config struct {
 	serviceName   string
 	spanName      string
 	analyticsRate float64
+	dbmMode       tracer.DBMPropagationMode
 }
 
 // Option represents an option that can be passed to Dial.
@@ -69,3 +71,15 @@ func WithAnalyticsRate(rate float64) Option {
 		}
 	}
 }
+
+// WithDBMPropagation sets the mode of the trace context propagated to the database by the
+// comments returned by DBMComment. It has no effect on NewMonitor.
+//
+// Note that enabling dbm propagation results in potentially confidential data (service names)
+// being stored in the databases which can then be accessed by other 3rd parties that have been
+// granted access to the database.
+func WithDBMPropagation(mode tracer.DBMPropagationMode) Option {
+	return func(cfg *config) {
+		cfg.dbmMode = mode
+	}
+}
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package zap_test
+
+import (
+	"context"
+
+	zaptrace "github.com/lannguyen-c0x12c/dd-trace-go/contrib/go.uber.org/zap"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+
+	"go.uber.org/zap"
+)
+
+func Example() {
+	tracer.Start()
+	defer tracer.Stop()
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Correlate the log entries with the current span.
+	zaptrace.WithContext(ctx, logger).Info("Completed some work!")
+	// Or add the fields to a single entry.
+	logger.Info("Completed some more work!", zaptrace.TraceFields(ctx)...)
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package zap provides log/span correlation fields for the go.uber.org/zap package (https://github.com/uber-go/zap).
+package zap
+
+import (
+	"context"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/logtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
+
+	"go.uber.org/zap"
+)
+
+const componentName = "go.uber.org/zap"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+}
+
+// TraceFields returns the fields correlating the log entries to the span found in ctx, or
+// nil if there is none. They are meant to be passed to the logging calls or to
+// (*zap.Logger).With.
+func TraceFields(ctx context.Context) []zap.Field {
+	c, ok := logtrace.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	fields := make([]zap.Field, 2, 5)
+	fields[0] = zap.String(logtrace.KeyTraceID, c.TraceID)
+	fields[1] = zap.String(logtrace.KeySpanID, c.SpanID)
+	if c.Service != "" {
+		fields = append(fields, zap.String(logtrace.KeyService, c.Service))
+	}
+	if c.Env != "" {
+		fields = append(fields, zap.String(logtrace.KeyEnv, c.Env))
+	}
+	if c.Version != "" {
+		fields = append(fields, zap.String(logtrace.KeyVersion, c.Version))
+	}
+	return fields
+}
+
+// WithContext returns a child of logger whose entries are correlated to the span found in
+// ctx. It returns logger itself if there is none.
+func WithContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := TraceFields(ctx)
+	if fields == nil {
+		return logger
+	}
+	return logger.With(fields...)
+}
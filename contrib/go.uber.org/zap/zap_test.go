@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package zap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithContext(t *testing.T) {
+	tracer.Start(tracer.WithService("web"), tracer.WithEnv("prod"), tracer.WithLogStartup(false))
+	defer tracer.Stop()
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "test", tracer.WithSpanID(1234))
+	defer span.Finish()
+
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+
+	WithContext(ctx, logger).Info("correlated")
+	WithContext(context.Background(), logger).Info("uncorrelated")
+	logger.Info("fields", TraceFields(ctx)...)
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 3)
+	expected := map[string]interface{}{
+		"dd.trace_id": "1234",
+		"dd.span_id":  "1234",
+		"dd.service":  "web",
+		"dd.env":      "prod",
+	}
+	assert.Equal(t, expected, entries[0].ContextMap())
+	assert.Empty(t, entries[1].ContextMap())
+	assert.Equal(t, expected, entries[2].ContextMap())
+}
@@ -24,6 +24,9 @@ import (
 
 const componentName = "gocql/gocql"
 
+// keyDBMTraceInjected is the span tag set when the trace context is propagated to the database.
+const keyDBMTraceInjected = "_dd.dbm_trace_injected"
+
 func init() {
 	telemetry.LoadIntegration(componentName)
 }
@@ -59,6 +62,38 @@ type params struct {
 	config    *queryConfig
 	keyspace  string
 	paginated bool
+	// customPayload holds the custom payload set on the traced Query, to which the
+	// database monitoring payload is added.
+	customPayload map[string][]byte
+}
+
+// injectDBM returns the custom payload propagating the trace context of ctx to the database,
+// along with the options of the span of the database call, or a nil payload if the
+// propagation is disabled.
+func (p *params) injectDBM(ctx context.Context) (map[string][]byte, []ddtrace.StartSpanOption) {
+	mode := p.config.dbmPropagationMode
+	if mode == tracer.DBMPropagationModeUndefined || mode == tracer.DBMPropagationModeDisabled {
+		return nil, nil
+	}
+	var spanCtx ddtrace.SpanContext
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		spanCtx = span.Context()
+	}
+	carrier := tracer.DBMCommentCarrier{Mode: mode, DBServiceName: p.config.serviceName}
+	if err := carrier.Inject(spanCtx); err != nil {
+		// this should never happen
+		log.Warn("contrib/gocql/gocql: failed to inject the dbm payload: %v", err)
+		return nil, nil
+	}
+	payload := make(map[string][]byte, len(carrier.Tags))
+	for k, v := range carrier.Tags {
+		payload[k] = []byte(v)
+	}
+	opts := []ddtrace.StartSpanOption{tracer.WithSpanID(carrier.SpanID)}
+	if mode == tracer.DBMPropagationModeFull {
+		opts = append(opts, tracer.Tag(keyDBMTraceInjected, true))
+	}
+	return payload, opts
 }
 
 // WrapQuery wraps a gocql.Query into a traced Query under the given service name.
@@ -68,8 +103,10 @@ type params struct {
 // the tracing context could be lost.
 //
 // To be more specific: it is ok (and recommended) to use and chain the return value
-// of `WithContext` and `PageState` but not that of `Consistency`, `Trace`,
-// `Observer`, etc.
+// of `WithContext`, `PageState` and `CustomPayload` but not that of `Consistency`,
+// `Trace`, `Observer`, etc. The custom payload of the query must be set on the
+// traced Query, so that it is preserved when propagating the trace context to the
+// database.
 func WrapQuery(q *gocql.Query, opts ...WrapOption) *Query {
 	cfg := new(queryConfig)
 	defaults(cfg)
@@ -100,6 +137,14 @@ func (tq *Query) PageState(state []byte) *Query {
 	return tq
 }
 
+// CustomPayload sets the custom payload of the query. When the trace context is
+// propagated to the database, its payload is added to customPayload.
+func (tq *Query) CustomPayload(customPayload map[string][]byte) *Query {
+	tq.params.customPayload = customPayload
+	tq.Query = tq.Query.CustomPayload(customPayload)
+	return tq
+}
+
 // NewChildSpan creates a new span from the params and the context.
 func (tq *Query) newChildSpan(ctx context.Context) ddtrace.Span {
 	p := tq.params
@@ -116,6 +161,16 @@ func (tq *Query) newChildSpan(ctx context.Context) ddtrace.Span {
 	if !math.IsNaN(p.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, p.config.analyticsRate))
 	}
+	if payload, dbmOpts := p.injectDBM(ctx); payload != nil {
+		if p.customPayload == nil {
+			p.customPayload = make(map[string][]byte, len(payload))
+		}
+		for k, v := range payload {
+			p.customPayload[k] = v
+		}
+		tq.Query = tq.Query.CustomPayload(p.customPayload)
+		opts = append(opts, dbmOpts...)
+	}
 	span, _ := tracer.StartSpanFromContext(ctx, ext.CassandraQuery, opts...)
 	return span
 }
@@ -270,6 +325,15 @@ func (tb *Batch) newChildSpan(ctx context.Context) ddtrace.Span {
 	if !math.IsNaN(p.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, p.config.analyticsRate))
 	}
+	if payload, dbmOpts := p.injectDBM(ctx); payload != nil {
+		if tb.CustomPayload == nil {
+			tb.CustomPayload = make(map[string][]byte, len(payload))
+		}
+		for k, v := range payload {
+			tb.CustomPayload[k] = v
+		}
+		opts = append(opts, dbmOpts...)
+	}
 	span, _ := tracer.StartSpanFromContext(ctx, ext.CassandraBatch, opts...)
 	return span
 }
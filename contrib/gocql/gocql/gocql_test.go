@@ -361,3 +361,65 @@ func TestBatch(t *testing.T) {
 	assert.Equal(childSpan.Tag(ext.SpanKind), ext.SpanKindClient)
 	assert.Equal(childSpan.Tag(ext.DBSystem), "cassandra")
 }
+
+func TestDBMPropagation(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parentSpan, ctx := tracer.StartSpanFromContext(context.Background(), "parentSpan")
+	cluster := newCassandraCluster()
+	cluster.Keyspace = "trace"
+	session, err := cluster.CreateSession()
+	assert.NoError(err)
+
+	b := session.NewBatch(gocql.UnloggedBatch)
+	b.CustomPayload = map[string][]byte{"user": []byte("value")}
+	tb := WrapBatch(b, WithServiceName("cassandra-db"), WithDBMPropagation(tracer.DBMPropagationModeFull))
+	tb.Query("INSERT INTO trace.person (name, age, description) VALUES (?, ?, ?)", "Kate", 80, "Cassandra's sister")
+	err = tb.WithContext(ctx).ExecuteBatch(session)
+	assert.NoError(err)
+	parentSpan.Finish()
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	batchSpan := spans[0]
+	assert.Equal(ext.CassandraBatch, batchSpan.OperationName())
+	assert.Equal(true, batchSpan.Tag("_dd.dbm_trace_injected"))
+
+	// the payload set by the user is preserved
+	assert.Equal("value", string(tb.CustomPayload["user"]))
+	assert.Equal("cassandra-db", string(tb.CustomPayload["dddbs"]))
+	assert.Equal(fmt.Sprintf("00-%032x-%016x-01", parentSpan.Context().TraceID(), batchSpan.SpanID()), string(tb.CustomPayload["traceparent"]))
+}
+
+func TestDBMPropagationQuery(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parentSpan, ctx := tracer.StartSpanFromContext(context.Background(), "parentSpan")
+	cluster := newCassandraCluster()
+	session, err := cluster.CreateSession()
+	assert.NoError(err)
+
+	payload := map[string][]byte{"user": []byte("value")}
+	q := session.Query("SELECT * from trace.person")
+	tq := WrapQuery(q, WithServiceName("cassandra-db"), WithDBMPropagation(tracer.DBMPropagationModeFull)).
+		CustomPayload(payload).
+		WithContext(ctx)
+	err = tq.Exec()
+	assert.NoError(err)
+	parentSpan.Finish()
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	querySpan := spans[0]
+	assert.Equal(ext.CassandraQuery, querySpan.OperationName())
+	assert.Equal(true, querySpan.Tag("_dd.dbm_trace_injected"))
+
+	// the payload set by the user is preserved
+	assert.Equal("value", string(payload["user"]))
+	assert.Equal("cassandra-db", string(payload["dddbs"]))
+	assert.Equal(fmt.Sprintf("00-%032x-%016x-01", parentSpan.Context().TraceID(), querySpan.SpanID()), string(payload["traceparent"]))
+}
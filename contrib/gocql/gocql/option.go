@@ -8,6 +8,7 @@ package gocql
 import (
 	"math"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 )
 
@@ -16,6 +17,7 @@ type queryConfig struct {
 	noDebugStack              bool
 	analyticsRate             float64
 	errCheck                  func(err error) bool
+	dbmPropagationMode        tracer.DBMPropagationMode
 }
 
 // WrapOption represents an option that can be passed to WrapQuery.
@@ -104,3 +106,18 @@ func WithErrorCheck(fn func(err error) bool) WrapOption {
 		cfg.errCheck = fn
 	}
 }
+
+// WithDBMPropagation enables the propagation of the trace context to the database in the
+// custom payload of the traced queries and batches, one entry per tag, using the same tags
+// as the SQL comments of the database/sql integration. It requires native protocol v4 or
+// above. Since gocql doesn't allow reading the custom payload of a query, the payload
+// set on a query before wrapping it is replaced. Batch payloads are merged.
+//
+// Note that enabling dbm propagation results in potentially confidential data (service names)
+// being sent to the database which can then be accessed by other 3rd parties that have been
+// granted access to the database.
+func WithDBMPropagation(mode tracer.DBMPropagationMode) WrapOption {
+	return func(cfg *queryConfig) {
+		cfg.dbmPropagationMode = mode
+	}
+}
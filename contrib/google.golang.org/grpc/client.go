@@ -26,6 +26,7 @@ type clientStream struct {
 	ctx    context.Context
 	cfg    *config
 	method string
+	stats  *payloadStats
 }
 
 func (cs *clientStream) Context() context.Context {
@@ -33,8 +34,9 @@ func (cs *clientStream) Context() context.Context {
 }
 
 func (cs *clientStream) RecvMsg(m interface{}) (err error) {
+	var span ddtrace.Span
 	if _, ok := cs.cfg.untracedMethods[cs.method]; cs.cfg.traceStreamMessages && !ok {
-		span, _ := startSpanFromContext(
+		span, _ = startSpanFromContext(
 			cs.Context(),
 			cs.method,
 			"grpc.message",
@@ -48,12 +50,16 @@ func (cs *clientStream) RecvMsg(m interface{}) (err error) {
 		defer func() { finishWithError(span, err, cs.cfg) }()
 	}
 	err = cs.ClientStream.RecvMsg(m)
+	if err == nil {
+		recordMessage(span, m, cs.stats.addResponse)
+	}
 	return err
 }
 
 func (cs *clientStream) SendMsg(m interface{}) (err error) {
+	var span ddtrace.Span
 	if _, ok := cs.cfg.untracedMethods[cs.method]; cs.cfg.traceStreamMessages && !ok {
-		span, _ := startSpanFromContext(
+		span, _ = startSpanFromContext(
 			cs.Context(),
 			cs.method,
 			"grpc.message",
@@ -67,6 +73,9 @@ func (cs *clientStream) SendMsg(m interface{}) (err error) {
 		defer func() { finishWithError(span, err, cs.cfg) }()
 	}
 	err = cs.ClientStream.SendMsg(m)
+	if err == nil {
+		recordMessage(span, m, cs.stats.addRequest)
+	}
 	return err
 }
 
@@ -91,7 +100,10 @@ func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
 				methodKind = methodKindClientStream
 			}
 		}
-		var stream grpc.ClientStream
+		var (
+			stream grpc.ClientStream
+			ps     = new(payloadStats)
+		)
 		if _, ok := cfg.untracedMethods[method]; cfg.traceStreamCalls && !ok {
 			var (
 				span tracer.Span
@@ -116,6 +128,8 @@ func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
 
 			go func() {
 				<-stream.Context().Done()
+				ps.setTags(span, true)
+				setDeadlineExceeded(stream.Context(), span, stream.Context().Err())
 				finishWithError(span, stream.Context().Err(), cfg)
 			}()
 		} else {
@@ -137,6 +151,7 @@ func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
 			cfg:          cfg,
 			method:       method,
 			ctx:          ctx,
+			stats:        ps,
 		}, nil
 	}
 }
@@ -158,6 +173,13 @@ func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
 			func(ctx context.Context, opts []grpc.CallOption) error {
 				return invoker(ctx, method, req, reply, cc, opts...)
 			})
+		if size, ok := messageSize(req); ok {
+			span.SetTag(tagRequestSize, size)
+		}
+		if size, ok := messageSize(reply); ok && err == nil {
+			span.SetTag(tagResponseSize, size)
+		}
+		setDeadlineExceeded(ctx, span, err)
 		finishWithError(span, err, cfg)
 		return err
 	}
@@ -182,15 +204,27 @@ func doClientRequest(
 	if methodKind != "" {
 		span.SetTag(tagMethodKind, methodKind)
 	}
+	setDeadlineRemaining(ctx, span)
+	if c := compressorFromCallOptions(opts); c != "" {
+		span.SetTag(tagCompressor, c)
+	}
+	md, _ := metadata.FromOutgoingContext(ctx) // nil is ok
+	setHeaderTags(span, cfg, ext.HTTPRequestHeaders, md)
 
 	// fill in the peer so we can add it to the tags
 	var p peer.Peer
 	opts = append(opts, grpc.Peer(&p))
+	// the Header call option is only safe to read from once unary calls return
+	var header metadata.MD
+	if methodKind == methodKindUnary {
+		opts = append(opts, grpc.Header(&header))
+	}
 
 	handlerCtx := injectSpanIntoContext(ctx)
 	err := handler(handlerCtx, opts)
 
 	setSpanTargetFromPeer(span, p)
+	setHeaderTags(span, cfg, ext.HTTPResponseHeaders, header)
 
 	return span, ctx, err
 }
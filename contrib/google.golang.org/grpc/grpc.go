@@ -11,11 +11,14 @@ package grpc // import "github.com/lannguyen-c0x12c/dd-trace-go/contrib/google.g
 import (
 	"errors"
 	"io"
+	"strings"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/google.golang.org/internal/grpcutil"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/normalizer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
 
 	context "golang.org/x/net/context"
@@ -69,6 +72,21 @@ func startSpanFromContext(
 	return tracer.StartSpanFromContext(ctx, operation, opts...)
 }
 
+// setHeaderTags reports the metadata found in the header tags of cfg as span tags, prefix being the
+// default tag prefix (ext.HTTPRequestHeaders or ext.HTTPResponseHeaders). It falls back to the header
+// tags configured at the tracer level when none were given to the integration.
+func setHeaderTags(span ddtrace.Span, cfg *config, prefix string, md metadata.MD) {
+	headerTags := cfg.headerTags
+	if headerTags == nil {
+		headerTags = globalconfig.HeaderTagMap()
+	}
+	for key, tag := range headerTags {
+		if vs := md.Get(key); len(vs) > 0 {
+			span.SetTag(normalizer.HeaderTagName(prefix, key, tag), strings.Join(vs, ","))
+		}
+	}
+}
+
 // finishWithError applies finish option and a tag with gRPC status code, disregarding OK, EOF and Canceled errors.
 func finishWithError(span ddtrace.Span, err error, cfg *config) {
 	if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
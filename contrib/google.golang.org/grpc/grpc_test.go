@@ -21,11 +21,13 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	context "golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
@@ -525,6 +527,111 @@ func TestStreamSendsErrorCode(t *testing.T) {
 	assert.Equal(t, gotLastSpanCode, wantCode, "last span should contain error code")
 }
 
+func TestPayloadTags(t *testing.T) {
+	req := &FixtureRequest{Name: "pass"}
+	reqSize := proto.Size(req)
+	replySize := proto.Size(&FixtureReply{Message: "passed"})
+
+	t.Run("unary", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		rig, err := newRig(true)
+		require.NoError(t, err, "error setting up rig")
+		defer rig.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		_, err = rig.client.Ping(ctx, req, grpc.UseCompressor("gzip"))
+		require.NoError(t, err)
+
+		waitForSpans(mt, 2)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		for _, s := range spans {
+			assert.Equal(t, reqSize, s.Tag(tagRequestSize))
+			assert.Equal(t, replySize, s.Tag(tagResponseSize))
+			assert.Nil(t, s.Tag(tagRequestWireSize))
+			assert.Nil(t, s.Tag(tagRequestMessages))
+			assert.InDelta(t, time.Minute.Milliseconds(), s.Tag(tagDeadlineRemaining), 5000)
+			assert.Equal(t, false, s.Tag(tagDeadlineExceeded))
+		}
+		client := spans[0]
+		if client.OperationName() != "grpc.client" {
+			client = spans[1]
+		}
+		assert.Equal(t, "gzip", client.Tag(tagCompressor))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		rig, err := newRig(true)
+		require.NoError(t, err, "error setting up rig")
+		defer rig.Close()
+
+		stream, err := rig.client.StreamPing(context.Background())
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, stream.Send(req))
+			_, err := stream.Recv()
+			require.NoError(t, err)
+		}
+		require.NoError(t, stream.CloseSend())
+		// to flush the spans
+		stream.Recv()
+
+		// both sides trace 3 sends, 4 receives, the last one ending the stream, and the call.
+		waitForSpans(mt, 16)
+		var calls, sized int
+		for _, s := range mt.FinishedSpans() {
+			switch s.OperationName() {
+			case "grpc.client", "grpc.server":
+				calls++
+				assert.Equal(t, 3, s.Tag(tagRequestMessages))
+				assert.Equal(t, 3*reqSize, s.Tag(tagRequestSize))
+				assert.Equal(t, 3, s.Tag(tagResponseMessages))
+				assert.Equal(t, 3*replySize, s.Tag(tagResponseSize))
+				assert.Nil(t, s.Tag(tagDeadlineRemaining))
+				assert.Nil(t, s.Tag(tagDeadlineExceeded))
+			case "grpc.message":
+				if size := s.Tag(tagMessageSize); size != nil {
+					sized++
+					assert.Contains(t, []interface{}{reqSize, replySize}, size)
+				}
+			}
+		}
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 12, sized)
+	})
+
+	t.Run("deadline-exceeded", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		rig, err := newRig(true)
+		require.NoError(t, err, "error setting up rig")
+		defer rig.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = rig.client.Ping(ctx, &FixtureRequest{Name: "slow"})
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		waitForSpans(mt, 2)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		for _, s := range spans {
+			assert.LessOrEqual(t, s.Tag(tagDeadlineRemaining), 50.0)
+			assert.Nil(t, s.Tag(tagResponseSize))
+			if s.OperationName() == "grpc.client" {
+				assert.Equal(t, true, s.Tag(tagDeadlineExceeded))
+			} else {
+				// the server may see the call canceled by the client before its own deadline.
+				assert.NotNil(t, s.Tag(tagDeadlineExceeded))
+			}
+		}
+	})
+}
+
 // fixtureServer a dummy implementation of our grpc fixtureServer.
 type fixtureServer struct {
 	lastRequestMetadata atomic.Value
@@ -569,6 +676,12 @@ func (s *fixtureServer) Ping(ctx context.Context, in *FixtureRequest) (*FixtureR
 		return &FixtureReply{Message: "disabled"}, nil
 	case in.Name == "invalid":
 		return nil, status.Error(codes.InvalidArgument, "invalid")
+	case in.Name == "slow":
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case in.Name == "header":
+		grpc.SetHeader(ctx, metadata.Pairs("test-response-key", "test-response-value"))
+		return &FixtureReply{Message: "passed"}, nil
 	}
 	return &FixtureReply{Message: "passed"}, nil
 }
@@ -928,6 +1041,64 @@ func TestIgnoredMetadata(t *testing.T) {
 	}
 }
 
+func TestHeaderTags(t *testing.T) {
+	spansByKind := func(mt mocktracer.Tracer) (client, server mocktracer.Span) {
+		for _, s := range mt.FinishedSpans() {
+			switch s.Tag(ext.SpanKind) {
+			case ext.SpanKindClient:
+				client = s
+			case ext.SpanKindServer:
+				server = s
+			}
+		}
+		return client, server
+	}
+	ping := func(t *testing.T, opts ...Option) (client, server mocktracer.Span) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		rig, err := newRig(true, opts...)
+		require.NoError(t, err)
+		defer rig.Close()
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "test-key", "test-value", "Test-Key2", "test-value2")
+		_, err = rig.client.Ping(ctx, &FixtureRequest{Name: "header"})
+		require.NoError(t, err)
+		return spansByKind(mt)
+	}
+
+	t.Run("global", func(t *testing.T) {
+		globalconfig.SetHeaderTags(map[string]string{"test-key": "", "test-key2": "custom.tag", "test-response-key": ""})
+		defer globalconfig.SetHeaderTags(nil)
+
+		client, server := ping(t)
+		for _, s := range []mocktracer.Span{client, server} {
+			assert.Equal(t, "test-value", s.Tag("http.request.headers.test-key"))
+			assert.Equal(t, "test-value2", s.Tag("custom.tag"))
+		}
+		assert.Equal(t, "test-response-value", client.Tag("http.response.headers.test-response-key"))
+	})
+
+	t.Run("override", func(t *testing.T) {
+		globalconfig.SetHeaderTags(map[string]string{"test-key": ""})
+		defer globalconfig.SetHeaderTags(nil)
+
+		client, server := ping(t, WithHeaderTags([]string{"test-key2"}))
+		for _, s := range []mocktracer.Span{client, server} {
+			assert.Nil(t, s.Tag("http.request.headers.test-key"))
+			assert.Equal(t, "test-value2", s.Tag("http.request.headers.test-key2"))
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		client, server := ping(t)
+		for _, s := range []mocktracer.Span{client, server} {
+			for k := range s.Tags() {
+				assert.NotContains(t, k, "http.request.headers.")
+			}
+		}
+	})
+}
+
 func TestSpanOpts(t *testing.T) {
 	t.Run("unary", func(t *testing.T) {
 		mt := mocktracer.Start()
@@ -10,6 +10,7 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/namingschema"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/normalizer"
 
 	"google.golang.org/grpc/codes"
 )
@@ -37,6 +38,7 @@ type config struct {
 	withRequestTags     bool
 	spanOpts            []ddtrace.StartSpanOption
 	tags                map[string]interface{}
+	headerTags          map[string]string
 }
 
 // InterceptorOption represents an option that can be passed to the grpc unary
@@ -183,6 +185,18 @@ func WithIgnoredMetadata(ms ...string) Option {
 	}
 }
 
+// WithHeaderTags specifies the request and response metadata to be reported as span tags, overriding
+// the headers configured through tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS. Each entry is of the form
+// "key[:tag]", the metadata being reported as http.request.headers.<key> or http.response.headers.<key>
+// when the tag is left out. An empty list disables metadata tagging. Response metadata is only reported
+// for unary client calls.
+func WithHeaderTags(headers []string) Option {
+	headerTags := normalizer.HeaderTags(headers)
+	return func(cfg *config) {
+		cfg.headerTags = headerTags
+	}
+}
+
 // WithRequestTags specifies whether gRPC requests should be added to spans as tags.
 func WithRequestTags() Option {
 	return func(cfg *config) {
@@ -0,0 +1,157 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package grpc
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+
+	"github.com/golang/protobuf/proto"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// payloadStats aggregates the messages exchanged by an RPC, in both directions, so that
+// they can be reported on its span once it ends. It is safe for concurrent use, as the
+// messages of a stream may be sent and received from different goroutines.
+type payloadStats struct {
+	mu         sync.Mutex
+	request    messageStats
+	response   messageStats
+	compressor string
+}
+
+// messageStats aggregates the messages exchanged in one direction. The wire size is
+// only known by the stats handlers, and is left to zero by the interceptors.
+type messageStats struct {
+	count    int
+	size     int
+	wireSize int
+}
+
+// payloadStatsKey is the context key of the payloadStats of the stats handlers.
+type payloadStatsKey struct{}
+
+// payloadStatsFromContext returns the payloadStats stored in ctx by the stats handlers. A new
+// one is returned if there is none, so that the callers don't have to check.
+func payloadStatsFromContext(ctx context.Context) *payloadStats {
+	if ps, ok := ctx.Value(payloadStatsKey{}).(*payloadStats); ok {
+		return ps
+	}
+	return new(payloadStats)
+}
+
+// addRequest records a request message of the given uncompressed and wire sizes.
+func (ps *payloadStats) addRequest(size, wireSize int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.request.add(size, wireSize)
+}
+
+// addResponse records a response message of the given uncompressed and wire sizes.
+func (ps *payloadStats) addResponse(size, wireSize int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.response.add(size, wireSize)
+}
+
+// setCompressor records the compressor of the messages, unless one is already known.
+func (ps *payloadStats) setCompressor(name string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.compressor == "" {
+		ps.compressor = name
+	}
+}
+
+func (m *messageStats) add(size, wireSize int) {
+	m.count++
+	m.size += size
+	m.wireSize += wireSize
+}
+
+// setTags reports the sizes of the messages on span. The message counts are only reported
+// when withCounts is true, as they are only relevant to streams or when the kind of the
+// RPC is unknown, such as in the stats handlers.
+func (ps *payloadStats) setTags(span ddtrace.Span, withCounts bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	setMessageTags(span, ps.request, tagRequestSize, tagRequestWireSize, tagRequestMessages, withCounts)
+	setMessageTags(span, ps.response, tagResponseSize, tagResponseWireSize, tagResponseMessages, withCounts)
+	if ps.compressor != "" {
+		span.SetTag(tagCompressor, ps.compressor)
+	}
+}
+
+func setMessageTags(span ddtrace.Span, m messageStats, sizeTag, wireSizeTag, countTag string, withCounts bool) {
+	if withCounts {
+		span.SetTag(countTag, m.count)
+	}
+	if m.count == 0 {
+		return
+	}
+	span.SetTag(sizeTag, m.size)
+	if m.wireSize > 0 {
+		span.SetTag(wireSizeTag, m.wireSize)
+	}
+}
+
+// messageSize returns the uncompressed size of the protobuf message m, and false when m
+// isn't a protobuf message.
+func messageSize(m interface{}) (int, bool) {
+	if p, ok := m.(proto.Message); ok {
+		return proto.Size(p), true
+	}
+	return 0, false
+}
+
+// setDeadlineRemaining reports the time remaining before the deadline of ctx on span, in
+// milliseconds. Nothing is reported if ctx has no deadline.
+func setDeadlineRemaining(ctx context.Context, span ddtrace.Span) {
+	if d, ok := ctx.Deadline(); ok {
+		span.SetTag(tagDeadlineRemaining, float64(time.Until(d))/float64(time.Millisecond))
+	}
+}
+
+// setDeadlineExceeded reports whether the RPC with the context ctx, which ended with err,
+// exceeded its deadline. Nothing is reported if ctx has no deadline.
+func setDeadlineExceeded(ctx context.Context, span ddtrace.Span, err error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return
+	}
+	exceeded := status.Code(err) == codes.DeadlineExceeded ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		ctx.Err() == context.DeadlineExceeded
+	span.SetTag(tagDeadlineExceeded, exceeded)
+}
+
+// compressorFromCallOptions returns the compressor set in opts with grpc.UseCompressor,
+// if any.
+func compressorFromCallOptions(opts []grpc.CallOption) string {
+	var name string
+	for _, o := range opts {
+		if c, ok := o.(grpc.CompressorCallOption); ok {
+			name = c.CompressorType
+		}
+	}
+	return name
+}
+
+// recordMessage reports the size of the stream message m on its span, when the messages
+// are traced, and adds it to the messages of the stream with add. The messages which aren't
+// protobuf messages are counted without their size.
+func recordMessage(span ddtrace.Span, m interface{}, add func(size, wireSize int)) {
+	size, ok := messageSize(m)
+	if ok && span != nil {
+		span.SetTag(tagMessageSize, size)
+	}
+	add(size, 0)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/appsec"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/dyninst"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 
 	"github.com/golang/protobuf/jsonpb"
@@ -24,6 +25,7 @@ type serverStream struct {
 	cfg    *config
 	method string
 	ctx    context.Context
+	stats  *payloadStats
 }
 
 // Context returns the ServerStream Context.
@@ -41,8 +43,9 @@ func (ss *serverStream) Context() context.Context {
 func (ss *serverStream) RecvMsg(m interface{}) (err error) {
 	_, im := ss.cfg.ignoredMethods[ss.method]
 	_, um := ss.cfg.untracedMethods[ss.method]
+	var span ddtrace.Span
 	if ss.cfg.traceStreamMessages && !im && !um {
-		span, _ := startSpanFromContext(
+		span, _ = startSpanFromContext(
 			ss.ctx,
 			ss.method,
 			"grpc.message",
@@ -57,14 +60,18 @@ func (ss *serverStream) RecvMsg(m interface{}) (err error) {
 		}()
 	}
 	err = ss.ServerStream.RecvMsg(m)
+	if err == nil {
+		recordMessage(span, m, ss.stats.addRequest)
+	}
 	return err
 }
 
 func (ss *serverStream) SendMsg(m interface{}) (err error) {
 	_, im := ss.cfg.ignoredMethods[ss.method]
 	_, um := ss.cfg.untracedMethods[ss.method]
+	var span ddtrace.Span
 	if ss.cfg.traceStreamMessages && !im && !um {
-		span, _ := startSpanFromContext(
+		span, _ = startSpanFromContext(
 			ss.ctx,
 			ss.method,
 			"grpc.message",
@@ -75,6 +82,9 @@ func (ss *serverStream) SendMsg(m interface{}) (err error) {
 		defer func() { finishWithError(span, err, ss.cfg) }()
 	}
 	err = ss.ServerStream.SendMsg(m)
+	if err == nil {
+		recordMessage(span, m, ss.stats.addResponse)
+	}
 	return err
 }
 
@@ -88,6 +98,7 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 	log.Debug("contrib/google.golang.org/grpc: Configuring StreamServerInterceptor: %#v", cfg)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
 		ctx := ss.Context()
+		ps := new(payloadStats)
 		// if we've enabled call tracing, create a span
 		_, im := cfg.ignoredMethods[info.FullMethod]
 		_, um := cfg.untracedMethods[info.FullMethod]
@@ -110,7 +121,17 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 			case info.IsClientStream:
 				span.SetTag(tagMethodKind, methodKindClientStream)
 			}
-			defer func() { finishWithError(span, err, cfg) }()
+			md, _ := metadata.FromIncomingContext(ctx) // nil is ok
+			setHeaderTags(span, cfg, ext.HTTPRequestHeaders, md)
+			setDeadlineRemaining(ctx, span)
+			if dyninst.Enabled(dyninst.GRPCServer) {
+				dyninst.Run(dyninst.GRPCServer, span, map[string]interface{}{"method": info.FullMethod})
+			}
+			defer func() {
+				ps.setTags(span, true)
+				setDeadlineExceeded(ctx, span, err)
+				finishWithError(span, err, cfg)
+			}()
 			if appsec.Enabled() {
 				handler = appsecStreamHandlerMiddleware(span, handler)
 			}
@@ -123,6 +144,7 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 			cfg:          cfg,
 			method:       info.FullMethod,
 			ctx:          ctx,
+			stats:        ps,
 		})
 	}
 }
@@ -151,12 +173,28 @@ func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 				tracer.Tag(ext.SpanKind, ext.SpanKindServer))...,
 		)
 		span.SetTag(tagMethodKind, methodKindUnary)
+		md, _ := metadata.FromIncomingContext(ctx) // nil is ok
+		setHeaderTags(span, cfg, ext.HTTPRequestHeaders, md)
 		withMetadataTags(ctx, cfg, span)
 		withRequestTags(cfg, req, span)
+		setDeadlineRemaining(ctx, span)
+		if size, ok := messageSize(req); ok {
+			span.SetTag(tagRequestSize, size)
+		}
+		if dyninst.Enabled(dyninst.GRPCServer) {
+			dyninst.Run(dyninst.GRPCServer, span, map[string]interface{}{
+				"method":  info.FullMethod,
+				"request": req,
+			})
+		}
 		if appsec.Enabled() {
 			handler = appsecUnaryHandlerMiddleware(span, handler)
 		}
 		resp, err := handler(ctx, req)
+		if size, ok := messageSize(resp); ok && err == nil {
+			span.SetTag(tagResponseSize, size)
+		}
+		setDeadlineExceeded(ctx, span, err)
 		finishWithError(span, err, cfg)
 		return resp, err
 	}
@@ -32,23 +32,27 @@ type clientStatsHandler struct{ cfg *config }
 // TagRPC starts a new span for the initiated RPC request.
 func (h *clientStatsHandler) TagRPC(ctx context.Context, rti *stats.RPCTagInfo) context.Context {
 	spanOpts := append([]tracer.StartSpanOption{tracer.Tag(ext.SpanKind, ext.SpanKindClient)}, h.cfg.spanOpts...)
-	_, ctx = startSpanFromContext(
+	span, ctx := startSpanFromContext(
 		ctx,
 		rti.FullMethodName,
 		h.cfg.spanName,
 		h.cfg.serviceName,
 		spanOpts...,
 	)
+	setDeadlineRemaining(ctx, span)
+	ctx = context.WithValue(ctx, payloadStatsKey{}, new(payloadStats))
 	ctx = injectSpanIntoContext(ctx)
 	return ctx
 }
 
-// HandleRPC processes the RPC ending event by finishing the span from the context.
+// HandleRPC records the sizes and compression of the messages of the RPC, and processes its
+// ending event by finishing the span from the context.
 func (h *clientStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 	span, ok := tracer.SpanFromContext(ctx)
 	if !ok {
 		return
 	}
+	ps := payloadStatsFromContext(ctx)
 	switch rs := rs.(type) {
 	case *stats.OutHeader:
 		host, port, err := net.SplitHostPort(rs.RemoteAddr.String())
@@ -58,7 +62,16 @@ func (h *clientStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 			}
 			span.SetTag(ext.TargetPort, port)
 		}
+		ps.setCompressor(rs.Compression)
+	case *stats.InHeader:
+		ps.setCompressor(rs.Compression)
+	case *stats.OutPayload:
+		ps.addRequest(rs.Length, rs.WireLength)
+	case *stats.InPayload:
+		ps.addResponse(rs.Length, rs.WireLength)
 	case *stats.End:
+		ps.setTags(span, true)
+		setDeadlineExceeded(ctx, span, rs.Error)
 		finishWithError(span, rs.Error, h.cfg)
 	}
 }
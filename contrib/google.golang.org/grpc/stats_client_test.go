@@ -6,12 +6,15 @@
 package grpc
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	context "golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -60,6 +63,39 @@ func TestClientStatsHandler(t *testing.T) {
 	assert.Equal(ext.SpanKindClient, tags[ext.SpanKind])
 }
 
+func TestClientStatsHandlerPayloadTags(t *testing.T) {
+	assert := assert.New(t)
+
+	server, err := newClientStatsHandlerTestServer(NewClientStatsHandler())
+	if err != nil {
+		t.Fatalf("failed to start test server: %s", err)
+	}
+	defer server.Close()
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	req := &FixtureRequest{Name: strings.Repeat("name", 100)}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err = server.client.Ping(ctx, req, grpc.UseCompressor("gzip"))
+	assert.NoError(err)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	tags := spans[0].Tags()
+	assert.Equal(1, tags[tagRequestMessages])
+	assert.Equal(proto.Size(req), tags[tagRequestSize])
+	// the repeated name compresses well.
+	assert.Less(tags[tagRequestWireSize], tags[tagRequestSize])
+	assert.Equal(1, tags[tagResponseMessages])
+	assert.Equal(proto.Size(&FixtureReply{Message: "passed"}), tags[tagResponseSize])
+	assert.NotNil(tags[tagResponseWireSize])
+	assert.Equal("gzip", tags[tagCompressor])
+	assert.InDelta(time.Minute.Milliseconds(), tags[tagDeadlineRemaining], 5000)
+	assert.Equal(false, tags[tagDeadlineExceeded])
+}
+
 func newClientStatsHandlerTestServer(statsHandler stats.Handler) (*rig, error) {
 	return newRigWithInterceptors(
 		nil,
@@ -36,24 +36,39 @@ func (h *serverStatsHandler) TagRPC(ctx context.Context, rti *stats.RPCTagInfo)
 		tracer.Tag(ext.SpanKind, ext.SpanKindServer)},
 		h.cfg.spanOpts...,
 	)
-	_, ctx = startSpanFromContext(
+	span, ctx := startSpanFromContext(
 		ctx,
 		rti.FullMethodName,
 		h.cfg.spanName,
 		h.cfg.serviceName,
 		spanOpts...,
 	)
-	return ctx
+	// the deadline of the context is the one sent by the client, as received.
+	setDeadlineRemaining(ctx, span)
+	return context.WithValue(ctx, payloadStatsKey{}, new(payloadStats))
 }
 
-// HandleRPC processes the RPC ending event by finishing the span from the context.
+// HandleRPC records the messages received and sent for the RPC, and processes its ending
+// event by finishing the span from the context.
 func (h *serverStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 	span, ok := tracer.SpanFromContext(ctx)
 	if !ok {
 		return
 	}
-	if v, ok := rs.(*stats.End); ok {
-		finishWithError(span, v.Error, h.cfg)
+	ps := payloadStatsFromContext(ctx)
+	switch rs := rs.(type) {
+	case *stats.InHeader:
+		ps.setCompressor(rs.Compression)
+	case *stats.OutHeader:
+		ps.setCompressor(rs.Compression)
+	case *stats.InPayload:
+		ps.addRequest(rs.Length, rs.WireLength)
+	case *stats.OutPayload:
+		ps.addResponse(rs.Length, rs.WireLength)
+	case *stats.End:
+		ps.setTags(span, true)
+		setDeadlineExceeded(ctx, span, rs.Error)
+		finishWithError(span, rs.Error, h.cfg)
 	}
 }
 
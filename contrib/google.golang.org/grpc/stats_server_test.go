@@ -7,16 +7,19 @@ package grpc
 
 import (
 	"testing"
+	"time"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
 )
 
 func TestServerStatsHandler(t *testing.T) {
@@ -57,6 +60,38 @@ func TestServerStatsHandler(t *testing.T) {
 	assert.Equal(ext.SpanKindServer, tags[ext.SpanKind])
 }
 
+func TestServerStatsHandlerPayloadTags(t *testing.T) {
+	assert := assert.New(t)
+
+	server, err := newServerStatsHandlerTestServer(NewServerStatsHandler())
+	if err != nil {
+		t.Fatalf("failed to start test server: %s", err)
+	}
+	defer server.Close()
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := &FixtureRequest{Name: "slow"}
+	_, err = server.client.Ping(ctx, req, grpc.UseCompressor("gzip"))
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+
+	waitForSpans(mt, 1)
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	tags := spans[0].Tags()
+	assert.Equal(1, tags[tagRequestMessages])
+	assert.Equal(proto.Size(req), tags[tagRequestSize])
+	assert.NotNil(tags[tagRequestWireSize])
+	assert.Equal(0, tags[tagResponseMessages])
+	assert.Nil(tags[tagResponseSize])
+	assert.Equal("gzip", tags[tagCompressor])
+	assert.LessOrEqual(tags[tagDeadlineRemaining], 50.0)
+	assert.NotNil(tags[tagDeadlineExceeded])
+}
+
 func newServerStatsHandlerTestServer(statsHandler stats.Handler) (*rig, error) {
 	return newRigWithInterceptors(
 		[]grpc.ServerOption{
@@ -12,6 +12,23 @@ const (
 	tagCode           = "grpc.code"
 	tagMetadataPrefix = "grpc.metadata."
 	tagRequest        = "grpc.request"
+
+	// Tags reporting the messages of the RPCs. The sizes are in bytes, the wire sizes
+	// being those of the compressed messages, as sent over the network, and are only
+	// known by the stats handlers. The counts are reported on the stream spans.
+	tagRequestSize      = "grpc.request.size"
+	tagRequestWireSize  = "grpc.request.wire_size"
+	tagRequestMessages  = "grpc.request.messages"
+	tagResponseSize     = "grpc.response.size"
+	tagResponseWireSize = "grpc.response.wire_size"
+	tagResponseMessages = "grpc.response.messages"
+	tagMessageSize      = "grpc.message.size"
+	tagCompressor       = "grpc.compressor"
+
+	// Tags reporting the deadline of the RPCs: the time remaining before it, in
+	// milliseconds, when the RPC starts, and whether it was exceeded.
+	tagDeadlineRemaining = "grpc.deadline.remaining_ms"
+	tagDeadlineExceeded  = "grpc.deadline.exceeded"
 )
 
 const (
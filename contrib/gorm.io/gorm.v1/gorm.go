@@ -11,6 +11,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/sqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -125,11 +126,15 @@ func after(db *gorm.DB, operationName string, cfg *config) {
 		return
 	}
 
+	resource := db.Statement.SQL.String()
+	if cfg.queryObfuscation {
+		resource = sqlobfuscate.Query(resource, db.Dialector.Name())
+	}
 	opts := []ddtrace.StartSpanOption{
 		tracer.StartTime(t),
 		tracer.ServiceName(cfg.serviceName),
 		tracer.SpanType(ext.SpanTypeSQL),
-		tracer.ResourceName(db.Statement.SQL.String()),
+		tracer.ResourceName(resource),
 		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(cfg.analyticsRate) {
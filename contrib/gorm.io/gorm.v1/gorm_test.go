@@ -496,3 +496,33 @@ func TestCustomTags(t *testing.T) {
 
 	assert.Equal("bar", s.Tag("foo"))
 }
+
+func TestQueryObfuscation(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	sqltrace.Register("pgx", &stdlib.Driver{})
+	sqlDb, err := sqltrace.Open("pgx", pgConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := Open(postgres.New(postgres.Config{Conn: sqlDb}), &gorm.Config{}, WithQueryObfuscation(true))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Product{}); err != nil {
+		log.Fatal(err)
+	}
+
+	db = db.WithContext(context.Background())
+	db.Where("code = 'L1212' AND price IN (1000, 2000)").First(&Product{})
+
+	spans := mt.FinishedSpans()
+	assert.True(len(spans) > 0)
+	span := spans[len(spans)-1]
+	assert.Equal("gorm.query", span.OperationName())
+	assert.Contains(span.Tag(ext.ResourceName), "code = ? AND price IN ( ? )")
+	assert.NotContains(span.Tag(ext.ResourceName), "L1212")
+}
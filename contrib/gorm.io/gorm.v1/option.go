@@ -8,6 +8,7 @@ package gorm
 import (
 	"math"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/sqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 
 	"gorm.io/gorm"
@@ -19,6 +20,8 @@ type config struct {
 	dsn           string
 	errCheck      func(err error) bool
 	tagFns        map[string]func(db *gorm.DB) interface{}
+
+	queryObfuscation bool
 }
 
 // Option represents an option that can be passed to Register, Open or OpenDB.
@@ -34,6 +37,7 @@ func defaults(cfg *config) {
 	}
 	cfg.errCheck = func(error) bool { return true }
 	cfg.tagFns = make(map[string]func(db *gorm.DB) interface{})
+	cfg.queryObfuscation = sqlobfuscate.Enabled()
 }
 
 // WithServiceName sets the given service name when registering a driver,
@@ -87,3 +91,12 @@ func WithCustomTag(tag string, tagFn func(db *gorm.DB) interface{}) Option {
 		}
 	}
 }
+
+// WithQueryObfuscation sets whether the SQL statements are obfuscated before being set as
+// the resource of the gorm spans. Enable it on the underlying database/sql integration too
+// to obfuscate its spans. Defaults to the value of DD_TRACE_SQL_OBFUSCATION_ENABLED, or false.
+func WithQueryObfuscation(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.queryObfuscation = enabled
+	}
+}
@@ -9,13 +9,19 @@
 // https://godoc.org/github.com/graph-gophers/graphql-go/trace subpackage.
 // Create a new Tracer with `NewTracer` and pass it as an additional option to
 // `MustParseSchema`.
+//
+// The "graphql.query" tag holds the query without its string and number literals.
+// Variables are only tagged when listed with WithVariablesAllowlist. Query errors are
+// reported one by one as span events holding their path and locations.
 package graphql // import "github.com/lannguyen-c0x12c/dd-trace-go/contrib/graph-gophers/graphql-go"
 
 import (
 	"context"
 	"fmt"
 	"math"
+	"strconv"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/graphqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -38,6 +44,10 @@ const (
 	tagGraphqlQuery         = "graphql.query"
 	tagGraphqlType          = "graphql.type"
 	tagGraphqlOperationName = "graphql.operation.name"
+	tagGraphqlVariables     = "graphql.variables."
+
+	// errorEvent is the name of the span events recording the errors of a query.
+	errorEvent = "dd.graphql.query.error"
 )
 
 // A Tracer implements the graphql-go/trace.Tracer interface by sending traces
@@ -49,10 +59,10 @@ type Tracer struct {
 var _ trace.Tracer = (*Tracer)(nil)
 
 // TraceQuery traces a GraphQL query.
-func (t *Tracer) TraceQuery(ctx context.Context, queryString string, operationName string, _ map[string]interface{}, _ map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+func (t *Tracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, _ map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
 	opts := []ddtrace.StartSpanOption{
 		tracer.ServiceName(t.cfg.serviceName),
-		tracer.Tag(tagGraphqlQuery, queryString),
+		tracer.Tag(tagGraphqlQuery, graphqlobfuscate.Query(queryString)),
 		tracer.Tag(tagGraphqlOperationName, operationName),
 		tracer.Tag(ext.Component, componentName),
 		tracer.Measured(),
@@ -60,9 +70,15 @@ func (t *Tracer) TraceQuery(ctx context.Context, queryString string, operationNa
 	if !math.IsNaN(t.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, t.cfg.analyticsRate))
 	}
+	for _, name := range t.cfg.variablesAllowlist {
+		if v, ok := variables[name]; ok {
+			opts = append(opts, tracer.Tag(tagGraphqlVariables+name, v))
+		}
+	}
 	span, ctx := tracer.StartSpanFromContext(ctx, "graphql.request", opts...)
 
 	return ctx, func(errs []*errors.QueryError) {
+		addErrorEvents(span, errs)
 		var err error
 		switch n := len(errs); n {
 		case 0:
@@ -76,6 +92,32 @@ func (t *Tracer) TraceQuery(ctx context.Context, queryString string, operationNa
 	}
 }
 
+// addErrorEvents records each of errs as an event of span, if it supports events.
+func addErrorEvents(span ddtrace.Span, errs []*errors.QueryError) {
+	s, ok := span.(ddtrace.SpanWithEvents)
+	if !ok {
+		return
+	}
+	for _, err := range errs {
+		attrs := map[string]interface{}{"message": err.Message}
+		if len(err.Path) > 0 {
+			path := make([]string, len(err.Path))
+			for i, p := range err.Path {
+				path[i] = fmt.Sprint(p)
+			}
+			attrs["path"] = path
+		}
+		if len(err.Locations) > 0 {
+			locs := make([]string, len(err.Locations))
+			for i, l := range err.Locations {
+				locs[i] = strconv.Itoa(l.Line) + ":" + strconv.Itoa(l.Column)
+			}
+			attrs["locations"] = locs
+		}
+		s.AddEvent(errorEvent, tracer.WithSpanEventAttributes(attrs))
+	}
+}
+
 // TraceField traces a GraphQL field access.
 func (t *Tracer) TraceField(ctx context.Context, _ string, typeName string, fieldName string, trivial bool, _ map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
 	if t.cfg.omitTrivial && trivial {
@@ -6,17 +6,21 @@
 package graphql
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 )
 
@@ -25,6 +29,14 @@ type testResolver struct{}
 func (*testResolver) Hello() string                    { return "Hello, world!" }
 func (*testResolver) HelloNonTrivial() (string, error) { return "Hello, world!", nil }
 
+type testGreetResolver struct{}
+
+func (*testGreetResolver) Greet(args struct{ Name, Greeting string }) string {
+	return args.Greeting + ", " + args.Name
+}
+
+func (*testGreetResolver) Fail() (string, error) { return "", errors.New("resolver failed") }
+
 func Test(t *testing.T) {
 	s := `
 		schema {
@@ -210,3 +222,76 @@ func TestAnalyticsSettings(t *testing.T) {
 		assertRate(t, mt, 0.23, WithAnalyticsRate(0.23))
 	})
 }
+
+func TestQueryObfuscationAndErrors(t *testing.T) {
+	s := `
+		schema {
+			query: Query
+		}
+		type Query {
+			greet(name: String!, greeting: String!): String!
+			fail: String!
+		}
+	`
+	exec := func(query string, variables map[string]interface{}, opts ...Option) mocktracer.Span {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		schema := graphql.MustParseSchema(s, new(testGreetResolver),
+			graphql.Tracer(NewTracer(opts...)))
+		schema.Exec(context.Background(), query, "", variables)
+		for _, span := range mt.FinishedSpans() {
+			if span.OperationName() == "graphql.request" {
+				return span
+			}
+		}
+		t.Fatal("no graphql.request span")
+		return nil
+	}
+
+	t.Run("obfuscation", func(t *testing.T) {
+		span := exec(`query($name: String!) { greet(name: $name, greeting: "Hello") }`,
+			map[string]interface{}{"name": "Jane"})
+		assert.Equal(t, `query($name: String!) { greet(name: $name, greeting: ?) }`, span.Tag(tagGraphqlQuery))
+		assert.Nil(t, span.Tag("graphql.variables.name"))
+	})
+
+	t.Run("WithVariablesAllowlist", func(t *testing.T) {
+		span := exec(`query($name: String!, $greeting: String!) { greet(name: $name, greeting: $greeting) }`,
+			map[string]interface{}{"name": "Jane", "greeting": "Hello"}, WithVariablesAllowlist("greeting", "missing"))
+		assert.Equal(t, "Hello", span.Tag("graphql.variables.greeting"))
+		assert.Nil(t, span.Tag("graphql.variables.name"))
+		assert.Nil(t, span.Tag("graphql.variables.missing"))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		span := exec("{\n  fail\n  a: fail\n}", nil)
+		assert.NotNil(t, span.Tag(ext.Error))
+		events := span.Events()
+		assert.Len(t, events, 2)
+		for _, e := range events {
+			assert.Equal(t, "dd.graphql.query.error", e.Name)
+			assert.Contains(t, e.Attributes["message"], "resolver failed")
+		}
+		assert.ElementsMatch(t, []interface{}{[]string{"fail"}, []string{"a"}},
+			[]interface{}{events[0].Attributes["path"], events[1].Attributes["path"]})
+	})
+
+	t.Run("locations", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		span := tracer.StartSpan("graphql.request")
+		addErrorEvents(span, []*gqlerrors.QueryError{{
+			Message:   "invalid",
+			Locations: []gqlerrors.Location{{Line: 2, Column: 3}, {Line: 4, Column: 1}},
+			Path:      []interface{}{"users", 1, "name"},
+		}})
+		span.Finish()
+		events := mt.FinishedSpans()[0].Events()
+		assert.Len(t, events, 1)
+		assert.Equal(t, map[string]interface{}{
+			"message":   "invalid",
+			"locations": []string{"2:3", "4:1"},
+			"path":      []string{"users", "1", "name"},
+		}, events[0].Attributes)
+	})
+}
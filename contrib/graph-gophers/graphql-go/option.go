@@ -16,6 +16,9 @@ type config struct {
 	serviceName   string
 	analyticsRate float64
 	omitTrivial   bool
+
+	// variablesAllowlist holds the names of the variables tagged on the query spans.
+	variablesAllowlist []string
 }
 
 // Option represents an option that can be used customize the Tracer.
@@ -70,3 +73,12 @@ func WithOmitTrivial() Option {
 		cfg.omitTrivial = true
 	}
 }
+
+// WithVariablesAllowlist tags the query spans with the values of the variables of the given
+// names, as "graphql.variables.<name>". No variable is tagged by default, as they may hold
+// sensitive data.
+func WithVariablesAllowlist(names ...string) Option {
+	return func(cfg *config) {
+		cfg.variablesAllowlist = append(cfg.variablesAllowlist, names...)
+	}
+}
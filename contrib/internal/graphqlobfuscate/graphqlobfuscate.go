@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package graphqlobfuscate obfuscates the GraphQL documents traced by the GraphQL integrations,
+// so that the literal values they hold never leave the process.
+package graphqlobfuscate
+
+import (
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/lexer"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+)
+
+// TextNonParsable is reported in place of the queries which can't be obfuscated.
+const TextNonParsable = "Non-parsable GraphQL query"
+
+// Query returns the obfuscated version of query, in which the string and number literals,
+// including the block strings, are replaced by "?" and the comments are removed. The rest
+// of the document, such as the variables, enum values and its layout, is kept as is.
+// TextNonParsable is returned if query can't be tokenized.
+func Query(query string) string {
+	var (
+		src  = []rune(query)
+		lex  = lexer.New(&ast.Source{Input: query})
+		last int // end of the previous token, in runes
+		b    strings.Builder
+	)
+	b.Grow(len(query))
+	for {
+		tok, err := lex.ReadToken()
+		if err != nil {
+			log.Debug("contrib/internal/graphqlobfuscate: unable to obfuscate query: %v", err)
+			return TextNonParsable
+		}
+		// the lexer skips the comments, which are only found between the tokens,
+		// along with the white space and the commas.
+		writeIgnored(&b, string(src[last:tok.Pos.Start]))
+		last = tok.Pos.End
+		switch tok.Kind {
+		case lexer.EOF:
+			return b.String()
+		case lexer.Int, lexer.Float, lexer.String, lexer.BlockString:
+			b.WriteByte('?')
+		default:
+			b.WriteString(string(src[tok.Pos.Start:tok.Pos.End]))
+		}
+	}
+}
+
+// writeIgnored writes the ignored tokens found between two lexical tokens to b, without
+// the comments, which run from a "#" to the end of the line.
+func writeIgnored(b *strings.Builder, s string) {
+	for {
+		i := strings.IndexByte(s, '#')
+		if i < 0 {
+			b.WriteString(s)
+			return
+		}
+		b.WriteString(strings.TrimRight(s[:i], " \t"))
+		s = s[i:]
+		if j := strings.IndexAny(s, "\r\n"); j >= 0 {
+			s = s[j:]
+		} else {
+			s = ""
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package graphqlobfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	for name, tc := range map[string]struct {
+		query, want string
+	}{
+		"no-literals": {
+			query: "query($id: ID!) { user(id: $id) { name } }",
+			want:  "query($id: ID!) { user(id: $id) { name } }",
+		},
+		"literals": {
+			query: `{ user(email: "jane@example.com", age: 42, score: -1.5e3) { name } }`,
+			want:  `{ user(email: ?, age: ?, score: ?) { name } }`,
+		},
+		"lists-and-objects": {
+			query: `mutation { add(input: {tags: ["a", "b"], ids: [1, 2]}) { id } }`,
+			want:  `mutation { add(input: {tags: [?, ?], ids: [?, ?]}) { id } }`,
+		},
+		"enums-and-booleans": {
+			query: `{ users(role: ADMIN, active: true, team: null) { name } }`,
+			want:  `{ users(role: ADMIN, active: true, team: null) { name } }`,
+		},
+		"default-values": {
+			query: `query($limit: Int = 10) { users(limit: $limit) { name } }`,
+			want:  `query($limit: Int = ?) { users(limit: $limit) { name } }`,
+		},
+		"block-string": {
+			query: "{ search(text: \"\"\"\nsecret\n\"\"\") { id } }",
+			want:  "{ search(text: ?) { id } }",
+		},
+		"comments": {
+			query: "# user: jane\n{ name # trailing\n}",
+			want:  "\n{ name\n}",
+		},
+		"unicode": {
+			query: `{ search(text: "héllo wörld") { title } }`,
+			want:  `{ search(text: ?) { title } }`,
+		},
+		"non-parsable": {
+			query: `{ user(email: "unterminated) { name } }`,
+			want:  TextNonParsable,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Query(tc.query))
+		})
+	}
+}
@@ -18,7 +18,10 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/appsec/dyngo/instrumentation/httpsec"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/dyninst"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/namingschema"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/normalizer"
 )
 
 var (
@@ -38,7 +41,8 @@ func InitServerSpanName() {
 }
 
 // StartRequestSpan starts an HTTP request span with the standard list of HTTP request span tags (http.method, http.url,
-// http.useragent). Any further span start option can be added with opts.
+// http.useragent), along with the request headers configured through tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS.
+// Any further span start option can be added with opts, such as HeaderTagsFromRequest to override the header tags.
 func StartRequestSpan(r *http.Request, opts ...ddtrace.StartSpanOption) (tracer.Span, context.Context) {
 	// Append our span options before the given ones so that the caller can "overwrite" them.
 	// TODO(): rework span start option handling (https://github.com/DataDog/dd-trace-go/issues/1352)
@@ -48,6 +52,7 @@ func StartRequestSpan(r *http.Request, opts ...ddtrace.StartSpanOption) (tracer.
 		tracer.Tag(ext.HTTPURL, urlFromRequest(r)),
 		tracer.Tag(ext.HTTPUserAgent, r.UserAgent()),
 		tracer.Measured(),
+		headerTagsFromRequest(r, globalconfig.HeaderTagMap()),
 	}, opts...)
 	if r.Host != "" {
 		opts = append([]ddtrace.StartSpanOption{
@@ -63,7 +68,17 @@ func StartRequestSpan(r *http.Request, opts ...ddtrace.StartSpanOption) (tracer.
 			opts = append(opts, tracer.Tag(k, v))
 		}
 	}
-	return tracer.StartSpanFromContext(r.Context(), serverSpanName, opts...)
+	span, ctx := tracer.StartSpanFromContext(r.Context(), serverSpanName, opts...)
+	if dyninst.Enabled(dyninst.HTTPServer) {
+		// The headers and the query string are left out, as they may hold sensitive data.
+		dyninst.Run(dyninst.HTTPServer, span, map[string]interface{}{
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"host":       r.Host,
+			"user_agent": r.UserAgent(),
+		})
+	}
+	return span, ctx
 }
 
 // FinishRequestSpan finishes the given HTTP request span and sets the expected response-related tags such as the status
@@ -82,6 +97,64 @@ func FinishRequestSpan(s tracer.Span, status int, opts ...tracer.FinishOption) {
 	s.Finish(opts...)
 }
 
+// HeaderTagsFromRequest returns a span start option reporting the request headers found in headerTags as
+// span tags. headerTags maps lowercased header names to span tags, an empty tag meaning that the header is
+// reported as http.request.headers.<header>. It lets integrations override the tracer-level header tags
+// applied by StartRequestSpan, whose tags it removes: it is meant to be passed to StartRequestSpan.
+// A nil headerTags keeps the tracer-level header tags.
+func HeaderTagsFromRequest(r *http.Request, headerTags map[string]string) ddtrace.StartSpanOption {
+	if headerTags == nil {
+		return func(_ *ddtrace.StartSpanConfig) {}
+	}
+	fromRequest := headerTagsFromRequest(r, headerTags)
+	return func(cfg *ddtrace.StartSpanConfig) {
+		for header, tag := range globalconfig.HeaderTagMap() {
+			delete(cfg.Tags, normalizer.HeaderTagName(ext.HTTPRequestHeaders, header, tag))
+		}
+		fromRequest(cfg)
+	}
+}
+
+// headerTagsFromRequest returns a span start option reporting the request headers found in headerTags as span tags.
+func headerTagsFromRequest(r *http.Request, headerTags map[string]string) ddtrace.StartSpanOption {
+	return func(cfg *ddtrace.StartSpanConfig) {
+		for header, tag := range headerTags {
+			if vs := r.Header.Values(header); len(vs) > 0 {
+				if cfg.Tags == nil {
+					cfg.Tags = make(map[string]interface{})
+				}
+				cfg.Tags[normalizer.HeaderTagName(ext.HTTPRequestHeaders, header, tag)] = strings.Join(vs, ",")
+			}
+		}
+	}
+}
+
+// SetRequestHeaderTags reports the request headers found in headerTags as span tags, an empty tag meaning
+// that the header is reported as http.request.headers.<header>. A nil headerTags falls back to the header
+// tags configured through tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS. It is meant for client integrations,
+// server integrations relying on StartRequestSpan instead.
+func SetRequestHeaderTags(s ddtrace.Span, h http.Header, headerTags map[string]string) {
+	setHeaderTags(s, ext.HTTPRequestHeaders, h, headerTags)
+}
+
+// SetResponseHeaderTags reports the response headers found in headerTags as span tags, an empty tag meaning
+// that the header is reported as http.response.headers.<header>. A nil headerTags falls back to the header
+// tags configured through tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS.
+func SetResponseHeaderTags(s ddtrace.Span, h http.Header, headerTags map[string]string) {
+	setHeaderTags(s, ext.HTTPResponseHeaders, h, headerTags)
+}
+
+func setHeaderTags(s ddtrace.Span, prefix string, h http.Header, headerTags map[string]string) {
+	if headerTags == nil {
+		headerTags = globalconfig.HeaderTagMap()
+	}
+	for header, tag := range headerTags {
+		if vs := h.Values(header); len(vs) > 0 {
+			s.SetTag(normalizer.HeaderTagName(prefix, header, tag), strings.Join(vs, ","))
+		}
+	}
+}
+
 // urlFromRequest returns the full URL from the HTTP request. If query params are collected, they are obfuscated granted
 // obfuscation is not disabled by the user (through DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP)
 // See https://docs.datadoghq.com/tracing/configure_data_security#redacting-the-query-in-the-url for more information.
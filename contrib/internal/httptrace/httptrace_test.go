@@ -18,6 +18,8 @@ import (
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 )
 
@@ -32,6 +34,61 @@ func TestStartRequestSpan(t *testing.T) {
 	assert.Equal(t, "example.com", spans[0].Tag("http.host"))
 }
 
+func TestHeaderTags(t *testing.T) {
+	globalconfig.SetHeaderTags(map[string]string{"x-global": "", "x-custom": "custom.tag"})
+	defer globalconfig.SetHeaderTags(nil)
+
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/somePath", nil)
+		r.Header.Set("X-Global", "global")
+		r.Header.Add("X-Custom", "a")
+		r.Header.Add("X-Custom", "b")
+		r.Header.Set("X-Local", "local")
+		return r
+	}
+
+	t.Run("global", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		s, _ := StartRequestSpan(newRequest())
+		w := httptest.NewRecorder()
+		w.Header().Set("X-Global", "response")
+		SetResponseHeaderTags(s, w.Header(), nil)
+		s.Finish()
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "global", spans[0].Tag("http.request.headers.x-global"))
+		assert.Equal(t, "a,b", spans[0].Tag("custom.tag"))
+		assert.Equal(t, "response", spans[0].Tag("http.response.headers.x-global"))
+		assert.Nil(t, spans[0].Tag("http.request.headers.x-local"))
+	})
+
+	t.Run("override", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		r := newRequest()
+		s, _ := StartRequestSpan(r, HeaderTagsFromRequest(r, map[string]string{"x-local": ""}))
+		s.Finish()
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "local", spans[0].Tag("http.request.headers.x-local"))
+		assert.Nil(t, spans[0].Tag("http.request.headers.x-global"))
+		assert.Nil(t, spans[0].Tag("custom.tag"))
+	})
+
+	t.Run("client", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		s := tracer.StartSpan("http.request")
+		SetRequestHeaderTags(s, newRequest().Header, map[string]string{"x-local": "local.tag"})
+		s.Finish()
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "local", spans[0].Tag("local.tag"))
+		assert.Nil(t, spans[0].Tag("http.request.headers.x-global"))
+	})
+}
+
 // TestClientIP tests behavior of StartRequestSpan based on
 // the DD_TRACE_CLIENT_IP_ENABLED environment variable
 func TestTraceClientIPFlag(t *testing.T) {
@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package logtrace provides the trace and span details which the log correlation
+// integrations add to log records.
+package logtrace
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
+)
+
+// The keys of the log correlation fields.
+const (
+	KeyTraceID = "dd.trace_id"
+	KeySpanID  = "dd.span_id"
+	KeyService = "dd.service"
+	KeyEnv     = "dd.env"
+	KeyVersion = "dd.version"
+)
+
+// log128BitTraceIDs specifies whether 128-bit trace IDs are logged as such. Value from
+// DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED, read at startup.
+var log128BitTraceIDs = internal.BoolEnv("DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED", false)
+
+// Correlation holds the log correlation fields of a span. Service, Env and Version are
+// empty when they aren't configured.
+type Correlation struct {
+	TraceID string
+	SpanID  string
+	Service string
+	Env     string
+	Version string
+}
+
+// FromContext returns the log correlation fields of the span found in ctx, if any, unless
+// logs injection is disabled through DD_LOGS_INJECTION or remote configuration. The
+// trace ID is logged as a 32-character hex string when the trace has a 128-bit ID and
+// DD_TRACE_128_BIT_TRACEID_LOGGING_ENABLED is set, and as a decimal 64-bit ID otherwise.
+func FromContext(ctx context.Context) (Correlation, bool) {
+	if ctx == nil || !globalconfig.LogsInjection() {
+		return Correlation{}, false
+	}
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return Correlation{}, false
+	}
+	sctx := span.Context()
+	c := Correlation{
+		TraceID: traceID(sctx),
+		SpanID:  strconv.FormatUint(sctx.SpanID(), 10),
+		Service: globalconfig.ServiceName(),
+		Env:     globalconfig.Env(),
+		Version: globalconfig.Version(),
+	}
+	if c.Env == "" {
+		c.Env = os.Getenv("DD_ENV")
+	}
+	if c.Version == "" {
+		c.Version = os.Getenv("DD_VERSION")
+	}
+	return c, true
+}
+
+func traceID(sctx ddtrace.SpanContext) string {
+	if w3c, ok := sctx.(ddtrace.SpanContextW3C); ok && log128BitTraceIDs {
+		id := w3c.TraceID128Bytes()
+		for _, b := range id[:8] {
+			if b != 0 {
+				return w3c.TraceID128()
+			}
+		}
+	}
+	return strconv.FormatUint(sctx.TraceID(), 10)
+}
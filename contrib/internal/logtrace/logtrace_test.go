@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package logtrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Run("no-span", func(t *testing.T) {
+		_, ok := FromContext(context.Background())
+		assert.False(t, ok)
+		_, ok = FromContext(nil) //nolint:staticcheck
+		assert.False(t, ok)
+	})
+
+	t.Run("tracer", func(t *testing.T) {
+		tracer.Start(tracer.WithService("web"), tracer.WithEnv("prod"), tracer.WithServiceVersion("1.2"), tracer.WithLogStartup(false))
+		defer tracer.Stop()
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test", tracer.WithSpanID(1234))
+		defer span.Finish()
+
+		c, ok := FromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, Correlation{TraceID: "1234", SpanID: "1234", Service: "web", Env: "prod", Version: "1.2"}, c)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		defer globalconfig.SetLogsInjection(true)
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+		defer span.Finish()
+
+		globalconfig.SetLogsInjection(false)
+		_, ok := FromContext(ctx)
+		assert.False(t, ok)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_ENV", "staging")
+		t.Setenv("DD_VERSION", "2.0")
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+		defer span.Finish()
+
+		c, ok := FromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, "staging", c.Env)
+		assert.Equal(t, "2.0", c.Version)
+	})
+
+	t.Run("128-bit", func(t *testing.T) {
+		t.Setenv("DD_TRACE_128_BIT_TRACEID_GENERATION_ENABLED", "true")
+		tracer.Start(tracer.WithLogStartup(false))
+		defer tracer.Stop()
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+		defer span.Finish()
+		id128 := span.Context().(ddtrace.SpanContextW3C).TraceID128()
+
+		// the 128-bit trace IDs are only logged if enabled.
+		c, _ := FromContext(ctx)
+		assert.NotEqual(t, id128, c.TraceID)
+
+		defer func(enabled bool) { log128BitTraceIDs = enabled }(log128BitTraceIDs)
+		log128BitTraceIDs = true
+		c, _ = FromContext(ctx)
+		assert.Equal(t, id128, c.TraceID)
+		assert.Len(t, c.TraceID, 32)
+	})
+}
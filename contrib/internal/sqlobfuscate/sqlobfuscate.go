@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package sqlobfuscate obfuscates the SQL queries traced by the database integrations,
+// so that the literal values they hold never leave the process.
+package sqlobfuscate
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/obfuscate"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+)
+
+// TextNonParsable is reported in place of the queries which can't be obfuscated.
+const TextNonParsable = "Non-parsable SQL query"
+
+// obfuscator is shared by all the integrations; it is safe for concurrent use.
+var obfuscator = obfuscate.NewObfuscator(obfuscate.Config{})
+
+// Enabled reports whether the integrations obfuscate queries when not configured otherwise,
+// according to DD_TRACE_SQL_OBFUSCATION_ENABLED. It is disabled by default, leaving the
+// obfuscation to the agent.
+func Enabled() bool {
+	return internal.BoolEnv("DD_TRACE_SQL_OBFUSCATION_ENABLED", false)
+}
+
+// Query returns the obfuscated version of query, in which the literals are replaced by "?",
+// the lists of values, such as in IN clauses, are collapsed and the comments are removed.
+// dbms is the database system, or the name of its driver or dialect, which allows parsing
+// the system-specific syntax. TextNonParsable is returned if query can't be parsed.
+func Query(query, dbms string) string {
+	switch dbms {
+	case ext.DBSystemPostgreSQL, "postgres", "pgx":
+		dbms = obfuscate.DBMSPostgres
+	case ext.DBSystemMicrosoftSQLServer, "sqlserver":
+		dbms = obfuscate.DBMSSQLServer
+	}
+	oq, err := obfuscator.ObfuscateSQLStringWithOptions(query, &obfuscate.SQLConfig{DBMS: dbms})
+	if err != nil {
+		log.Debug("contrib/internal/sqlobfuscate: unable to obfuscate query: %v", err)
+		return TextNonParsable
+	}
+	return oq.Query
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package sqlobfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	for name, tc := range map[string]struct {
+		query, dbms, want string
+	}{
+		"literals": {
+			query: "SELECT * FROM users WHERE email = 'jane@example.com' AND age > 42",
+			want:  "SELECT * FROM users WHERE email = ? AND age > ?",
+		},
+		"in-list": {
+			query: "SELECT id FROM orders WHERE status IN ('new', 'paid', 'sent')",
+			want:  "SELECT id FROM orders WHERE status IN ( ? )",
+		},
+		"comments": {
+			query: "/* user: jane */ SELECT 1 -- trailing\nFROM dual",
+			want:  "SELECT ? FROM dual",
+		},
+		"postgres": {
+			query: "SELECT * FROM t WHERE data @> '{\"plan\":\"pro\"}'",
+			dbms:  "postgres",
+			want:  "SELECT * FROM t WHERE data @> ?",
+		},
+		"non-parsable": {
+			query: "SELECT 'unterminated",
+			want:  TextNonParsable,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Query(tc.query, tc.dbms))
+		})
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, Enabled())
+	t.Setenv("DD_TRACE_SQL_OBFUSCATION_ENABLED", "true")
+	assert.True(t, Enabled())
+}
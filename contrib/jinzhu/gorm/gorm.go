@@ -12,6 +12,7 @@ import (
 	"time"
 
 	sqltraced "github.com/lannguyen-c0x12c/dd-trace-go/contrib/database/sql"
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/sqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -127,11 +128,15 @@ func after(scope *gorm.Scope, operationName string) {
 		return
 	}
 
+	resource := scope.SQL
+	if cfg.queryObfuscation {
+		resource = sqlobfuscate.Query(resource, scope.Dialect().GetName())
+	}
 	opts := []ddtrace.StartSpanOption{
 		tracer.StartTime(t),
 		tracer.ServiceName(cfg.serviceName),
 		tracer.SpanType(ext.SpanTypeSQL),
-		tracer.ResourceName(scope.SQL),
+		tracer.ResourceName(resource),
 		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(cfg.analyticsRate) {
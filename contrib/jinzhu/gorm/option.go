@@ -8,6 +8,7 @@ package gorm
 import (
 	"math"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/sqlobfuscate"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 
 	"github.com/jinzhu/gorm"
@@ -19,6 +20,8 @@ type config struct {
 	dsn           string
 	tagFns        map[string]func(scope *gorm.Scope) interface{}
 	errCheck      func(err error) bool
+
+	queryObfuscation bool
 }
 
 // Option represents an option that can be passed to Register, Open or OpenDB.
@@ -33,6 +36,7 @@ func defaults(cfg *config) {
 		cfg.analyticsRate = math.NaN()
 	}
 	cfg.errCheck = func(error) bool { return true }
+	cfg.queryObfuscation = sqlobfuscate.Enabled()
 }
 
 // WithServiceName sets the given service name when registering a driver,
@@ -85,3 +89,12 @@ func WithErrorCheck(fn func(err error) bool) Option {
 		cfg.errCheck = fn
 	}
 }
+
+// WithQueryObfuscation sets whether the SQL statements are obfuscated before being set as
+// the resource of the gorm spans. The spans of the database/sql integration used by Open are
+// configured separately. Defaults to the value of DD_TRACE_SQL_OBFUSCATION_ENABLED, or false.
+func WithQueryObfuscation(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.queryObfuscation = enabled
+	}
+}
@@ -70,6 +70,7 @@ func Middleware(opts ...Option) echo.MiddlewareFunc {
 
 			span, ctx := httptrace.StartRequestSpan(request, opts...)
 			defer func() {
+				httptrace.SetResponseHeaderTags(span, c.Response().Header(), nil)
 				span.Finish(finishOpts...)
 			}()
 
@@ -60,6 +60,7 @@ func Middleware(opts ...Option) echo.MiddlewareFunc {
 			span, ctx := httptrace.StartRequestSpan(request, opts...)
 			defer func() {
 				//httptrace.FinishRequestSpan(span, c.Response().Status, finishOpts...)
+				httptrace.SetResponseHeaderTags(span, c.Response().Header(), nil)
 				span.Finish(finishOpts...)
 			}()
 
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+//go:build go1.21
+
+package slog_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	slogtrace "github.com/lannguyen-c0x12c/dd-trace-go/contrib/log/slog"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+)
+
+func Example() {
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Setup the logger once at the beginning of your program.
+	logger := slog.New(slogtrace.WrapHandler(slog.NewJSONHandler(os.Stdout, nil)))
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Log with the context of the span to correlate the record with it.
+	logger.InfoContext(ctx, "Completed some work!")
+}
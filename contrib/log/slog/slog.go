@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+//go:build go1.21
+
+// Package slog provides a log/span correlation handler for the standard library log/slog package (https://pkg.go.dev/log/slog).
+package slog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/logtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
+)
+
+const componentName = "log/slog"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+}
+
+// WrapHandler returns a handler which adds the trace and span details of the span found in
+// the context of the records to the records before passing them to h. The records must be
+// logged with a context, for example with slog.InfoContext or (*slog.Logger).InfoContext.
+// Like any other record attributes, the details are nested in the groups opened with
+// (slog.Handler).WithGroup.
+func WrapHandler(h slog.Handler) slog.Handler {
+	return &handler{h}
+}
+
+type handler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler.
+func (h *handler) Handle(ctx context.Context, rec slog.Record) error {
+	if c, ok := logtrace.FromContext(ctx); ok {
+		rec.AddAttrs(
+			slog.String(logtrace.KeyTraceID, c.TraceID),
+			slog.String(logtrace.KeySpanID, c.SpanID),
+		)
+		if c.Service != "" {
+			rec.AddAttrs(slog.String(logtrace.KeyService, c.Service))
+		}
+		if c.Env != "" {
+			rec.AddAttrs(slog.String(logtrace.KeyEnv, c.Env))
+		}
+		if c.Version != "" {
+			rec.AddAttrs(slog.String(logtrace.KeyVersion, c.Version))
+		}
+	}
+	return h.Handler.Handle(ctx, rec)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+//go:build go1.21
+
+package slog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	tracer.Start(tracer.WithService("web"), tracer.WithEnv("prod"), tracer.WithServiceVersion("1.2"), tracer.WithLogStartup(false))
+	defer tracer.Stop()
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "test", tracer.WithSpanID(1234))
+	defer span.Finish()
+
+	var buf bytes.Buffer
+	logger := slog.New(WrapHandler(slog.NewJSONHandler(&buf, nil))).With("key", "value")
+
+	t.Run("correlated", func(t *testing.T) {
+		buf.Reset()
+		logger.InfoContext(ctx, "message")
+		var rec map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+		assert.Equal(t, "message", rec["msg"])
+		assert.Equal(t, "value", rec["key"])
+		assert.Equal(t, "1234", rec["dd.trace_id"])
+		assert.Equal(t, "1234", rec["dd.span_id"])
+		assert.Equal(t, "web", rec["dd.service"])
+		assert.Equal(t, "prod", rec["dd.env"])
+		assert.Equal(t, "1.2", rec["dd.version"])
+	})
+
+	t.Run("no-span", func(t *testing.T) {
+		buf.Reset()
+		logger.InfoContext(context.Background(), "message")
+		logger.Info("message")
+		assert.NotContains(t, buf.String(), "dd.")
+	})
+
+}
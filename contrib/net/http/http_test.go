@@ -17,6 +17,7 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHttpTracer200(t *testing.T) {
@@ -48,6 +49,47 @@ func TestHttpTracer200(t *testing.T) {
 	assert.Equal("net/http", s.Tag(ext.Component))
 }
 
+func TestHeaderTags(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Response", "response")
+		w.Write([]byte("OK\n"))
+	})
+	serve := func(opts ...Option) mocktracer.Span {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		r := httptest.NewRequest("GET", "/200", nil)
+		r.Header.Set("X-Global", "global")
+		r.Header.Set("X-Local", "local")
+		WrapHandler(handler, "my-service", "my-resource", opts...).ServeHTTP(httptest.NewRecorder(), r)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		return spans[0]
+	}
+	globalconfig.SetHeaderTags(map[string]string{"x-global": "", "x-response": ""})
+	defer globalconfig.SetHeaderTags(nil)
+
+	t.Run("global", func(t *testing.T) {
+		s := serve()
+		assert.Equal(t, "global", s.Tag("http.request.headers.x-global"))
+		assert.Equal(t, "response", s.Tag("http.response.headers.x-response"))
+		assert.Nil(t, s.Tag("http.request.headers.x-local"))
+	})
+
+	t.Run("override", func(t *testing.T) {
+		s := serve(WithHeaderTags([]string{"X-Local:local.tag", "X-Response:response.tag"}))
+		assert.Equal(t, "local", s.Tag("local.tag"))
+		assert.Equal(t, "response", s.Tag("response.tag"))
+		assert.Nil(t, s.Tag("http.request.headers.x-global"))
+		assert.Nil(t, s.Tag("http.response.headers.x-response"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := serve(WithHeaderTags(nil))
+		assert.Nil(t, s.Tag("http.request.headers.x-global"))
+		assert.Nil(t, s.Tag("http.response.headers.x-response"))
+	})
+}
+
 func TestHttpTracer500(t *testing.T) {
 	mt := mocktracer.Start()
 	defer mt.Stop()
@@ -15,6 +15,7 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/namingschema"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/normalizer"
 )
 
 const defaultServiceName = "http.router"
@@ -26,6 +27,7 @@ type config struct {
 	finishOpts    []ddtrace.FinishOption
 	ignoreRequest func(*http.Request) bool
 	resourceNamer func(*http.Request) string
+	headerTags    map[string]string
 }
 
 // MuxOption has been deprecated in favor of Option.
@@ -104,6 +106,17 @@ func WithResourceNamer(namer func(req *http.Request) string) Option {
 	}
 }
 
+// WithHeaderTags specifies the request and response headers to be reported as span tags, overriding
+// the ones configured through tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS. Each entry is of the form
+// "header[:tag]", the header being reported as http.request.headers.<header> or http.response.headers.<header>
+// when the tag is left out. An empty list disables header tagging.
+func WithHeaderTags(headers []string) Option {
+	headerTags := normalizer.HeaderTags(headers)
+	return func(cfg *config) {
+		cfg.headerTags = headerTags
+	}
+}
+
 // NoDebugStack prevents stack traces from being attached to spans finishing
 // with an error. This is useful in situations where errors are frequent and
 // performance is critical.
@@ -131,6 +144,7 @@ type roundTripperConfig struct {
 	ignoreRequest func(*http.Request) bool
 	spanOpts      []ddtrace.StartSpanOption
 	errCheck      func(err error) bool
+	headerTags    map[string]string
 }
 
 func newRoundTripperConfig() *roundTripperConfig {
@@ -244,3 +258,14 @@ func RTWithErrorCheck(fn func(err error) bool) RoundTripperOption {
 		cfg.errCheck = fn
 	}
 }
+
+// RTWithHeaderTags specifies the request and response headers to be reported as span tags, overriding
+// the ones configured through tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS. Each entry is of the form
+// "header[:tag]", the header being reported as http.request.headers.<header> or http.response.headers.<header>
+// when the tag is left out. An empty list disables header tagging.
+func RTWithHeaderTags(headers []string) RoundTripperOption {
+	headerTags := normalizer.HeaderTags(headers)
+	return func(cfg *roundTripperConfig) {
+		cfg.headerTags = headerTags
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/httptrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -49,6 +50,7 @@ func (rt *roundTripper) RoundTrip(req *http.Request) (res *http.Response, err er
 		opts = append(opts, rt.cfg.spanOpts...)
 	}
 	span, ctx := tracer.StartSpanFromContext(req.Context(), spanName, opts...)
+	httptrace.SetRequestHeaderTags(span, req.Header, rt.cfg.headerTags)
 	defer func() {
 		if rt.cfg.after != nil {
 			rt.cfg.after(res, span)
@@ -77,6 +79,7 @@ func (rt *roundTripper) RoundTrip(req *http.Request) (res *http.Response, err er
 		}
 	} else {
 		span.SetTag(ext.HTTPCode, strconv.Itoa(res.StatusCode))
+		httptrace.SetResponseHeaderTags(span, res.Header, rt.cfg.headerTags)
 		// treat 5XX as errors
 		if res.StatusCode/100 == 5 {
 			span.SetTag("http.errors", res.Status)
@@ -88,6 +88,41 @@ func TestRoundTripper(t *testing.T) {
 	assert.Equal(t, "net/http", s1.Tag(ext.Component))
 }
 
+func TestRoundTripperHeaderTags(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Response", "response")
+		w.Write([]byte("Hello World"))
+	}))
+	defer s.Close()
+	roundTrip := func(opts ...RoundTripperOption) mocktracer.Span {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		req, err := http.NewRequest("GET", s.URL+"/hello/world", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Request", "request")
+		resp, err := WrapClient(&http.Client{}, opts...).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		return spans[0]
+	}
+	globalconfig.SetHeaderTags(map[string]string{"x-request": "", "x-response": ""})
+	defer globalconfig.SetHeaderTags(nil)
+
+	t.Run("global", func(t *testing.T) {
+		span := roundTrip()
+		assert.Equal(t, "request", span.Tag("http.request.headers.x-request"))
+		assert.Equal(t, "response", span.Tag("http.response.headers.x-response"))
+	})
+
+	t.Run("override", func(t *testing.T) {
+		span := roundTrip(RTWithHeaderTags([]string{"x-response:response.tag"}))
+		assert.Nil(t, span.Tag("http.request.headers.x-request"))
+		assert.Equal(t, "response", span.Tag("response.tag"))
+	})
+}
+
 func TestRoundTripperServerError(t *testing.T) {
 	mt := mocktracer.Start()
 	defer mt.Stop()
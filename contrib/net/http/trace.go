@@ -44,6 +44,11 @@ type ServeConfig struct {
 	FinishOpts []ddtrace.FinishOption
 	// SpanOpts specifies any options to be applied to the request starting span.
 	SpanOpts []ddtrace.StartSpanOption
+	// HeaderTags optionally overrides the request and response headers reported as span tags,
+	// which default to the ones configured through tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS.
+	// It maps header names to span tags, an empty tag meaning that the header is reported as
+	// http.request.headers.<header> or http.response.headers.<header>.
+	HeaderTags map[string]string
 }
 
 // TraceAndServe serves the handler h using the given ResponseWriter and Request, applying tracing
@@ -54,9 +59,13 @@ func TraceAndServe(h http.Handler, w http.ResponseWriter, r *http.Request, cfg *
 	}
 	opts := append(cfg.SpanOpts, tracer.ServiceName(cfg.Service), tracer.ResourceName(cfg.Resource))
 	opts = append(opts, tracer.Tag(ext.HTTPRoute, cfg.Route))
+	if cfg.HeaderTags != nil {
+		opts = append(opts, httptrace.HeaderTagsFromRequest(r, cfg.HeaderTags))
+	}
 	span, ctx := httptrace.StartRequestSpan(r, opts...)
 	rw, ddrw := wrapResponseWriter(w)
 	defer func() {
+		httptrace.SetResponseHeaderTags(span, rw.Header(), cfg.HeaderTags)
 		httptrace.FinishRequestSpan(span, ddrw.status, cfg.FinishOpts...)
 	}()
 
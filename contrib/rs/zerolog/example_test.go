@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package zerolog_test
+
+import (
+	"context"
+	"os"
+
+	zerologtrace "github.com/lannguyen-c0x12c/dd-trace-go/contrib/rs/zerolog"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+
+	"github.com/rs/zerolog"
+)
+
+func ExampleDDContextLogHook() {
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Setup the logger once at the beginning of your program.
+	logger := zerolog.New(os.Stdout).Hook(zerologtrace.DDContextLogHook{})
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Pass the current span context to the event.
+	logger.Info().Ctx(ctx).Msg("Completed some work!")
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package zerolog provides a log/span correlation hook for the rs/zerolog package (https://github.com/rs/zerolog).
+package zerolog
+
+import (
+	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/logtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
+
+	"github.com/rs/zerolog"
+)
+
+const componentName = "rs/zerolog"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+}
+
+// DDContextLogHook ensures that any span in the event context is correlated to log output.
+// The context is set with (*zerolog.Event).Ctx or (zerolog.Context).Ctx.
+type DDContextLogHook struct{}
+
+// Run implements zerolog.Hook, it adds the trace and span details found in the event context.
+func (DDContextLogHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	c, ok := logtrace.FromContext(e.GetCtx())
+	if !ok {
+		return
+	}
+	e.Str(logtrace.KeyTraceID, c.TraceID).Str(logtrace.KeySpanID, c.SpanID)
+	if c.Service != "" {
+		e.Str(logtrace.KeyService, c.Service)
+	}
+	if c.Env != "" {
+		e.Str(logtrace.KeyEnv, c.Env)
+	}
+	if c.Version != "" {
+		e.Str(logtrace.KeyVersion, c.Version)
+	}
+}
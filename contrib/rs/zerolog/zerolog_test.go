@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	tracer.Start(tracer.WithService("web"), tracer.WithServiceVersion("1.2"), tracer.WithLogStartup(false))
+	defer tracer.Stop()
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "test", tracer.WithSpanID(1234))
+	defer span.Finish()
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(DDContextLogHook{})
+
+	logger.Info().Ctx(ctx).Msg("correlated")
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Equal(t, map[string]interface{}{
+		"level":       "info",
+		"message":     "correlated",
+		"dd.trace_id": "1234",
+		"dd.span_id":  "1234",
+		"dd.service":  "web",
+		"dd.version":  "1.2",
+	}, rec)
+
+	buf.Reset()
+	logger.Info().Msg("uncorrelated")
+	assert.NotContains(t, buf.String(), "dd.")
+
+	buf.Reset()
+	ctxLogger := logger.With().Ctx(ctx).Logger()
+	ctxLogger.Info().Msg("logger context")
+	assert.Contains(t, buf.String(), `"dd.span_id":"1234"`)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/segmentio/kafka-go"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/datastreams"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
@@ -76,9 +77,40 @@ func (r *Reader) startSpan(ctx context.Context, msg *kafka.Message) ddtrace.Span
 	if err := tracer.Inject(span.Context(), carrier); err != nil {
 		log.Debug("contrib/segmentio/kafka.go.v0: Failed to inject span context into carrier, %v", err)
 	}
+	if r.cfg.dataStreamsEnabled {
+		setConsumeCheckpoint(ctx, r.Reader.Config().GroupID, msg)
+	}
 	return span
 }
 
+// setConsumeCheckpoint sets a Data Streams checkpoint on the read message, continuing the
+// pathway found in its headers, and reinjects the resulting pathway for downstream writers.
+func setConsumeCheckpoint(ctx context.Context, groupID string, msg *kafka.Message) {
+	edges := []string{"direction:in", "topic:" + msg.Topic, "type:kafka"}
+	if groupID != "" {
+		edges = append(edges, "group:"+groupID)
+	}
+	carrier := messageCarrier{msg}
+	ctx, ok := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(ctx, carrier), edges...)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+// setProduceCheckpoint sets a Data Streams checkpoint on the written message, continuing the
+// pathway found in its headers, or else in ctx, and injects the resulting pathway in the message
+// headers.
+func setProduceCheckpoint(ctx context.Context, topic string, msg *kafka.Message) {
+	edges := []string{"direction:out", "topic:" + topic, "type:kafka"}
+	carrier := messageCarrier{msg}
+	ctx, ok := tracer.SetDataStreamsCheckpoint(datastreams.ExtractFromBase64Carrier(ctx, carrier), edges...)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
 // Close calls the underlying Reader.Close and if polling is enabled, finishes
 // any remaining span.
 func (r *Reader) Close() error {
@@ -118,6 +150,20 @@ func (r *Reader) FetchMessage(ctx context.Context) (kafka.Message, error) {
 	return msg, nil
 }
 
+// CommitMessages commits the offsets of the given messages and tracks them if Data Streams
+// Monitoring is enabled.
+func (r *Reader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	err := r.Reader.CommitMessages(ctx, msgs...)
+	if err != nil || !r.cfg.dataStreamsEnabled {
+		return err
+	}
+	groupID := r.Reader.Config().GroupID
+	for _, msg := range msgs {
+		tracer.TrackKafkaCommitOffset(groupID, msg.Topic, int32(msg.Partition), msg.Offset)
+	}
+	return nil
+}
+
 // WrapWriter wraps a kafka.Writer so requests are traced.
 func WrapWriter(w *kafka.Writer, opts ...Option) *Writer {
 	writer := &Writer{
@@ -142,11 +188,11 @@ func (w *Writer) startSpan(ctx context.Context, msg *kafka.Message) ddtrace.Span
 		tracer.Tag(ext.SpanKind, ext.SpanKindProducer),
 		tracer.Tag(ext.MessagingSystem, "kafka"),
 	}
+	topic := msg.Topic
 	if w.Writer.Topic != "" {
-		opts = append(opts, tracer.ResourceName("Produce Topic "+w.Writer.Topic))
-	} else {
-		opts = append(opts, tracer.ResourceName("Produce Topic "+msg.Topic))
+		topic = w.Writer.Topic
 	}
+	opts = append(opts, tracer.ResourceName("Produce Topic "+topic))
 	if !math.IsNaN(w.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, w.cfg.analyticsRate))
 	}
@@ -154,6 +200,9 @@ func (w *Writer) startSpan(ctx context.Context, msg *kafka.Message) ddtrace.Span
 	span, _ := tracer.StartSpanFromContext(ctx, w.cfg.producerOperationName, opts...)
 	err := tracer.Inject(span.Context(), carrier)
 	log.Debug("contrib/segmentio/kafka.go.v0: Failed to inject span context into carrier, %v", err)
+	if w.cfg.dataStreamsEnabled {
+		setProduceCheckpoint(ctx, topic, msg)
+	}
 	return span
 }
 
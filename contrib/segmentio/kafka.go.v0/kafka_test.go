@@ -12,8 +12,10 @@ import (
 	"time"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/contrib/internal/namingschematest"
+	dsm "github.com/lannguyen-c0x12c/dd-trace-go/datastreams"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/mocktracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
 
 	kafka "github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
@@ -172,6 +174,38 @@ func TestFetchMessageFunctional(t *testing.T) {
 	assert.Equal(t, "kafka", s1.Tag(ext.MessagingSystem))
 }
 
+func TestDataStreams(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	w := WrapWriter(&kafka.Writer{Topic: testTopic}, WithDataStreams())
+	msg := kafka.Message{Key: []byte("key1"), Value: []byte("value1")}
+	w.startSpan(context.Background(), &msg).Finish()
+	produced, ok := datastreams.PathwayFromContext(dsm.ExtractFromBase64Carrier(context.Background(), messageCarrier{&msg}))
+	require.True(t, ok, "the pathway should be injected into the message headers")
+
+	r := NewReader(kafka.ReaderConfig{
+		Brokers: []string{"localhost:9092"},
+		GroupID: testGroupID,
+		Topic:   testTopic,
+	}, WithDataStreams())
+	defer r.Close()
+	msg.Topic = testTopic
+	r.startSpan(context.Background(), &msg).Finish()
+	consumed, ok := datastreams.PathwayFromContext(dsm.ExtractFromBase64Carrier(context.Background(), messageCarrier{&msg}))
+	require.True(t, ok)
+	assert.NotEqual(t, produced.GetHash(), consumed.GetHash())
+	assert.Equal(t, produced.PathwayStart(), consumed.PathwayStart())
+
+	var n int
+	for _, h := range msg.Headers {
+		if h.Key == datastreams.PropagationKeyBase64 {
+			n++
+		}
+	}
+	assert.Equal(t, 1, n, "the pathway header should be replaced")
+}
+
 func TestNamingSchema(t *testing.T) {
 	genSpans := func(t *testing.T, serviceOverride string) []mocktracer.Span {
 		var opts []Option
@@ -18,6 +18,7 @@ type config struct {
 	consumerOperationName string
 	producerOperationName string
 	analyticsRate         float64
+	dataStreamsEnabled    bool
 }
 
 // An Option customizes the config.
@@ -31,6 +32,7 @@ func newConfig(opts ...Option) *config {
 	if internal.BoolEnv("DD_TRACE_KAFKA_ANALYTICS_ENABLED", false) {
 		cfg.analyticsRate = 1.0
 	}
+	cfg.dataStreamsEnabled = internal.BoolEnv("DD_DATA_STREAMS_ENABLED", false)
 
 	cfg.consumerServiceName = namingschema.NewServiceNameSchema("", "kafka").GetName()
 	cfg.producerServiceName = namingschema.NewServiceNameSchema(
@@ -78,3 +80,13 @@ func WithAnalyticsRate(rate float64) Option {
 		}
 	}
 }
+
+// WithDataStreams enables Data Streams Monitoring: checkpoints are set on written and read
+// messages, the pathway context is propagated in the message headers, and the committed
+// offsets are tracked. It requires Data Streams Monitoring to be enabled in the tracer.
+// This can also be enabled with the DD_DATA_STREAMS_ENABLED environment variable.
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreamsEnabled = true
+	}
+}
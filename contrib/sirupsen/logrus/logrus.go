@@ -8,6 +8,7 @@ package logrus
 
 import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
 
 	"github.com/sirupsen/logrus"
@@ -27,8 +28,12 @@ func (d *DDContextLogHook) Levels() []logrus.Level {
 	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel, logrus.InfoLevel, logrus.DebugLevel, logrus.TraceLevel}
 }
 
-// Fire implements logrus.Hook interface, attaches trace and span details found in entry context
+// Fire implements logrus.Hook interface, attaches trace and span details found in entry context,
+// unless logs injection is disabled through DD_LOGS_INJECTION or remote configuration.
 func (d *DDContextLogHook) Fire(e *logrus.Entry) error {
+	if !globalconfig.LogsInjection() {
+		return nil
+	}
 	span, found := tracer.SpanFromContext(e.Context)
 	if !found {
 		return nil
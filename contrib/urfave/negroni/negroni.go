@@ -51,6 +51,7 @@ func (m *DatadogMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request, ne
 				opts = []tracer.FinishOption{tracer.WithError(fmt.Errorf("%d: %s", status, http.StatusText(status)))}
 			}
 		}
+		httptrace.SetResponseHeaderTags(span, w.Header(), nil)
 		httptrace.FinishRequestSpan(span, status, opts...)
 	}()
 
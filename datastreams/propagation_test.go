@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package datastreams
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type carrier map[string]string
+
+func (c carrier) Set(key, val string) {
+	c[key] = val
+}
+
+func (c carrier) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range c {
+		if err := handler(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestBase64Propagation(t *testing.T) {
+	c := make(carrier)
+	processor := datastreams.NewProcessor(nil, "env", "service", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	p, ctx := processor.SetCheckpoint(context.Background(), "direction:out", "type:kafka")
+	InjectToBase64Carrier(ctx, c)
+	assert.Contains(t, c, datastreams.PropagationKeyBase64)
+
+	got, ok := datastreams.PathwayFromContext(ExtractFromBase64Carrier(context.Background(), c))
+	assert.True(t, ok)
+	assert.Equal(t, p.GetHash(), got.GetHash())
+	assert.Equal(t, p.PathwayStart().UnixMilli(), got.PathwayStart().UnixMilli())
+	assert.Equal(t, p.EdgeStart().UnixMilli(), got.EdgeStart().UnixMilli())
+}
+
+func TestBase64PropagationNoPathway(t *testing.T) {
+	c := make(carrier)
+	InjectToBase64Carrier(context.Background(), c)
+	assert.Empty(t, c)
+
+	ctx := context.Background()
+	assert.Equal(t, ctx, ExtractFromBase64Carrier(ctx, c))
+	c[datastreams.PropagationKeyBase64] = "invalid"
+	assert.Equal(t, ctx, ExtractFromBase64Carrier(ctx, c))
+}
+
+func TestMergeContexts(t *testing.T) {
+	assert.NotNil(t, MergeContexts())
+
+	ctx := context.Background()
+	assert.Equal(t, ctx, MergeContexts(ctx, context.Background()))
+
+	processor := datastreams.NewProcessor(nil, "env", "service", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	p, withPathway := processor.SetCheckpoint(context.Background(), "direction:in", "type:kafka")
+	got, ok := datastreams.PathwayFromContext(MergeContexts(ctx, withPathway))
+	assert.True(t, ok)
+	assert.Equal(t, p, got)
+}
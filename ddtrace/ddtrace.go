@@ -77,6 +77,24 @@ type Span interface {
 	Context() SpanContext
 }
 
+// SpanWithLinks represents a Span which can be linked to other spans, possibly
+// belonging to different traces.
+type SpanWithLinks interface {
+	Span
+
+	// AddLink adds the given link to the span.
+	AddLink(link SpanLink)
+}
+
+// SpanWithEvents represents a Span which can record events, i.e. time-stamped
+// annotations happening during the span's lifetime.
+type SpanWithEvents interface {
+	Span
+
+	// AddEvent records an event with the given name on the span.
+	AddEvent(name string, opts ...SpanEventOption)
+}
+
 // SpanContext represents a span state that can propagate to descendant spans
 // and across process boundaries. It contains all the information needed to
 // spawn a direct descendant of the span that it belongs to. It can be used
@@ -144,6 +162,25 @@ type StartSpanConfig struct {
 
 	// Context is the parent context where the span should be stored.
 	Context context.Context
+
+	// SpanLinks represents a list of links to spans outside of the span's own
+	// parent-child relationship, which will be attached to the new span.
+	SpanLinks []SpanLink
+}
+
+// SpanEventOption is a configuration option that can be used with a SpanWithEvents' AddEvent method.
+type SpanEventOption func(cfg *SpanEventConfig)
+
+// SpanEventConfig holds the configuration for recording a span event. It is usually passed around
+// by reference to one or more SpanEventOption functions which shape it into its final form.
+type SpanEventConfig struct {
+	// Time holds the time at which the event happened. Implementations should use
+	// the current time when Time.IsZero().
+	Time time.Time
+
+	// Attributes holds a set of key/value pairs describing the event. Values are
+	// expected to be strings, booleans, numbers or slices of those.
+	Attributes map[string]interface{}
 }
 
 // Logger implementations are able to log given messages that the tracer or profiler might output.
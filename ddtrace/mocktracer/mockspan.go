@@ -16,6 +16,8 @@ import (
 )
 
 var _ ddtrace.Span = (*mockspan)(nil)
+var _ ddtrace.SpanWithLinks = (*mockspan)(nil)
+var _ ddtrace.SpanWithEvents = (*mockspan)(nil)
 var _ Span = (*mockspan)(nil)
 
 // Span is an interface that allows querying a span returned by the mock tracer.
@@ -47,10 +49,28 @@ type Span interface {
 	// Context returns the span's SpanContext.
 	Context() ddtrace.SpanContext
 
+	// Links returns a copy of all the span links in this span.
+	Links() []ddtrace.SpanLink
+
+	// Events returns a copy of all the events recorded on this span.
+	Events() []Event
+
 	// Stringer allows pretty-printing the span's fields for debugging.
 	fmt.Stringer
 }
 
+// Event is an event recorded on a span returned by the mock tracer.
+type Event struct {
+	// Name is the name of the event.
+	Name string
+
+	// Time is the time at which the event happened.
+	Time time.Time
+
+	// Attributes holds the attributes describing the event.
+	Attributes map[string]interface{}
+}
+
 func newSpan(t *mocktracer, operationName string, cfg *ddtrace.StartSpanConfig) *mockspan {
 	if cfg.Tags == nil {
 		cfg.Tags = make(map[string]interface{})
@@ -93,6 +113,9 @@ func newSpan(t *mocktracer, operationName string, cfg *ddtrace.StartSpanConfig)
 	for k, v := range cfg.Tags {
 		s.SetTag(k, v)
 	}
+	for _, l := range cfg.SpanLinks {
+		s.AddLink(l)
+	}
 	return s
 }
 
@@ -100,6 +123,8 @@ type mockspan struct {
 	sync.RWMutex // guards below fields
 	name         string
 	tags         map[string]interface{}
+	links        []ddtrace.SpanLink
+	events       []Event
 	finishTime   time.Time
 	finished     bool
 
@@ -130,6 +155,49 @@ func (s *mockspan) SetTag(key string, value interface{}) {
 	s.tags[key] = value
 }
 
+// AddLink adds a span link to the span.
+func (s *mockspan) AddLink(link ddtrace.SpanLink) {
+	s.Lock()
+	defer s.Unlock()
+	if s.finished {
+		return
+	}
+	s.links = append(s.links, link)
+}
+
+func (s *mockspan) Links() []ddtrace.SpanLink {
+	s.RLock()
+	defer s.RUnlock()
+	links := make([]ddtrace.SpanLink, len(s.links))
+	copy(links, s.links)
+	return links
+}
+
+// AddEvent records an event on the span.
+func (s *mockspan) AddEvent(name string, opts ...ddtrace.SpanEventOption) {
+	var cfg ddtrace.SpanEventConfig
+	for _, fn := range opts {
+		fn(&cfg)
+	}
+	if cfg.Time.IsZero() {
+		cfg.Time = time.Now()
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.finished {
+		return
+	}
+	s.events = append(s.events, Event{Name: name, Time: cfg.Time, Attributes: cfg.Attributes})
+}
+
+func (s *mockspan) Events() []Event {
+	s.RLock()
+	defer s.RUnlock()
+	events := make([]Event, len(s.events))
+	copy(events, s.events)
+	return events
+}
+
 func (s *mockspan) FinishTime() time.Time {
 	s.RLock()
 	defer s.RUnlock()
@@ -67,6 +67,33 @@ func TestNewSpan(t *testing.T) {
 	})
 }
 
+func TestSpanLinks(t *testing.T) {
+	link := ddtrace.SpanLink{TraceID: 1, SpanID: 2}
+	s := newSpan(&mocktracer{}, "http.request", &ddtrace.StartSpanConfig{SpanLinks: []ddtrace.SpanLink{link}})
+	link2 := ddtrace.SpanLink{TraceID: 3, SpanID: 4, Attributes: map[string]string{"k": "v"}}
+	s.AddLink(link2)
+	s.Finish()
+	s.AddLink(ddtrace.SpanLink{TraceID: 5, SpanID: 6})
+
+	assert.Equal(t, []ddtrace.SpanLink{link, link2}, s.Links())
+}
+
+func TestSpanEvents(t *testing.T) {
+	s := basicSpan("http.request")
+	now := time.Now()
+	s.AddEvent("first", tracer.WithSpanEventTime(now), tracer.WithSpanEventAttributes(map[string]interface{}{"k": "v"}))
+	s.AddEvent("second")
+	s.Finish()
+	s.AddEvent("late")
+
+	events := s.Events()
+	assert.Len(t, events, 2)
+	assert.Equal(t, Event{Name: "first", Time: now, Attributes: map[string]interface{}{"k": "v"}}, events[0])
+	assert.Equal(t, "second", events[1].Name)
+	assert.False(t, events[1].Time.IsZero())
+	assert.Nil(t, events[1].Attributes)
+}
+
 func TestSpanSetTag(t *testing.T) {
 	s := basicSpan("http.request")
 	s.SetTag("a", "b")
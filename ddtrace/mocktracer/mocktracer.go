@@ -13,6 +13,7 @@
 package mocktracer
 
 import (
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,6 +21,7 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
 )
 
 var _ ddtrace.Tracer = (*mocktracer)(nil)
@@ -58,14 +60,24 @@ type mocktracer struct {
 	sync.RWMutex  // guards below spans
 	finishedSpans []Span
 	openSpans     map[uint64]Span
+	dsmProcessor  *datastreams.Processor
 }
 
 func newMockTracer() *mocktracer {
 	var t mocktracer
 	t.openSpans = make(map[uint64]Span)
+	// the processor is never started, so that no data streams stats are sent
+	// while checkpoints still propagate pathways.
+	t.dsmProcessor = datastreams.NewProcessor(nil, "", "", &url.URL{Scheme: "http", Host: "localhost"}, nil)
 	return &t
 }
 
+// GetDataStreamsProcessor returns the Data Streams Monitoring processor of the mock tracer,
+// allowing the messaging integrations to set checkpoints in tests.
+func (t *mocktracer) GetDataStreamsProcessor() *datastreams.Processor {
+	return t.dsmProcessor
+}
+
 // Stop deactivates the mock tracer and sets the active tracer to a no-op.
 func (*mocktracer) Stop() {
 	internal.SetGlobalTracer(&internal.NoopTracer{})
@@ -7,6 +7,8 @@ package opentelemetry
 
 import (
 	"encoding/binary"
+	"reflect"
+	"runtime/debug"
 	"strconv"
 	"strings"
 
@@ -30,9 +32,59 @@ type span struct {
 	*oteltracer
 }
 
-func (s *span) TracerProvider() oteltrace.TracerProvider        { return s.oteltracer.provider }
-func (s *span) AddEvent(_ string, _ ...oteltrace.EventOption)   { /*	no-op */ }
-func (s *span) RecordError(_ error, _ ...oteltrace.EventOption) { /*	no-op */ }
+func (s *span) TracerProvider() oteltrace.TracerProvider { return s.oteltracer.provider }
+
+// AddEvent records an event with the given name and options on the underlying
+// Datadog span. Events are ignored once the span has ended.
+func (s *span) AddEvent(name string, options ...oteltrace.EventOption) {
+	if !s.IsRecording() {
+		return
+	}
+	s.addEvent(name, oteltrace.NewEventConfig(options...), nil)
+}
+
+// RecordError marks the span as errored and sets the error.message, error.type
+// and, if oteltrace.WithStackTrace is used, error.stack tags accordingly. Unless
+// disabled with DD_TRACE_OTEL_EXCEPTION_EVENTS_ENABLED, err is also recorded as
+// an "exception" event on the span.
+func (s *span) RecordError(err error, options ...oteltrace.EventOption) {
+	if !s.IsRecording() || err == nil {
+		return
+	}
+	cfg := oteltrace.NewEventConfig(options...)
+	errType := reflect.TypeOf(err).String()
+	s.SetTag(ext.Error, true)
+	s.SetTag(ext.ErrorMsg, err.Error())
+	s.SetTag(ext.ErrorType, errType)
+	attrs := map[string]interface{}{
+		"exception.type":    errType,
+		"exception.message": err.Error(),
+	}
+	if cfg.StackTrace() {
+		stack := string(debug.Stack())
+		s.SetTag(ext.ErrorStack, stack)
+		attrs["exception.stacktrace"] = stack
+	}
+	if s.provider.exceptionEvents {
+		s.addEvent("exception", cfg, attrs)
+	}
+}
+
+// addEvent records an event on the underlying Datadog span, if it supports
+// events. The attributes in cfg take precedence over the given attrs.
+func (s *span) addEvent(name string, cfg oteltrace.EventConfig, attrs map[string]interface{}) {
+	ds, ok := s.Span.(ddtrace.SpanWithEvents)
+	if !ok {
+		return
+	}
+	if attrs == nil {
+		attrs = make(map[string]interface{}, len(cfg.Attributes()))
+	}
+	for _, attr := range cfg.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	ds.AddEvent(name, tracer.WithSpanEventTime(cfg.Timestamp()), tracer.WithSpanEventAttributes(attrs))
+}
 
 func (s *span) SetName(name string) { s.SetOperationName(name) }
 
@@ -43,7 +95,8 @@ func (s *span) End(options ...oteltrace.SpanEndOption) {
 	s.finished = true
 	var finishCfg = oteltrace.NewSpanEndConfig(options...)
 	var opts []tracer.FinishOption
-	if s.statusInfo.code == otelcodes.Error {
+	if s.statusInfo.code == otelcodes.Error && s.statusInfo.description != "" {
+		// an empty description doesn't override the message of a recorded error
 		s.SetTag(ext.ErrorMsg, s.statusInfo.description)
 	}
 	if t := finishCfg.Timestamp(); !t.IsZero() {
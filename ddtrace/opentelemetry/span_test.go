@@ -390,3 +390,119 @@ func TestTracerStartOptions(t *testing.T) {
 	assert.Contains(payload, "\"service\":\"test_serv\"")
 	assert.Contains(payload, "\"env\":\"test_env\"")
 }
+
+func TestSpanLinks(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, payloads, cleanup := mockTracerProvider(t)
+	tr := otel.Tracer("")
+	defer cleanup()
+
+	traceState, err := oteltrace.ParseTraceState("dd=s:1")
+	assert.NoError(err)
+	producer := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    oteltrace.TraceID{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2},
+		SpanID:     oteltrace.SpanID{0, 0, 0, 0, 0, 0, 0, 3},
+		TraceFlags: oteltrace.FlagsSampled,
+		TraceState: traceState,
+	})
+	_, sp := tr.Start(context.Background(), "consumer", oteltrace.WithLinks(
+		oteltrace.Link{SpanContext: producer, Attributes: []attribute.KeyValue{attribute.String("link.kind", "producer")}},
+		oteltrace.Link{SpanContext: oteltrace.SpanContext{}}, // invalid, ignored
+	))
+	sp.End()
+
+	tracer.Flush()
+	p, err := waitForPayload(ctx, payloads)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Contains(p, `"span_links":[{"trace_id":2,"trace_id_high":1,"span_id":3,"attributes":{"link.kind":"producer"},"tracestate":"dd=s:1","flags":2147483649}]`)
+}
+
+func TestSpanAddEvent(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, payloads, cleanup := mockTracerProvider(t)
+	tr := otel.Tracer("")
+	defer cleanup()
+
+	_, sp := tr.Start(context.Background(), "span_event")
+	sp.AddEvent("cache.miss", oteltrace.WithTimestamp(time.Unix(0, 1234)), oteltrace.WithAttributes(
+		attribute.String("cache.key", "users"),
+		attribute.Bool("cache.cold", true),
+		attribute.Int64("cache.size", 42),
+		attribute.Float64("cache.ratio", 0.5),
+		attribute.StringSlice("cache.tags", []string{"a", "b"}),
+	))
+	sp.End()
+	sp.AddEvent("ignored") // the span has ended
+
+	tracer.Flush()
+	p, err := waitForPayload(ctx, payloads)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Contains(p, `"span_events":[{"name":"cache.miss","time_unix_nano":1234,"attributes":{`)
+	assert.Contains(p, `"cache.key":{"type":0,"string_value":"users"}`)
+	assert.Contains(p, `"cache.cold":{"type":1,"bool_value":true}`)
+	assert.Contains(p, `"cache.size":{"type":2,"int_value":42}`)
+	assert.Contains(p, `"cache.ratio":{"type":3,"double_value":0.5}`)
+	assert.Contains(p, `"cache.tags":{"type":4,"array_value":{"values":[{"type":0,"string_value":"a"},{"type":0,"string_value":"b"}]}}`)
+	assert.NotContains(p, "ignored")
+}
+
+func TestSpanRecordError(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, payloads, cleanup := mockTracerProvider(t)
+	tr := otel.Tracer("")
+	defer cleanup()
+
+	_, sp := tr.Start(context.Background(), "record_error")
+	sp.RecordError(nil) // no-op
+	sp.RecordError(errors.New("boom"), oteltrace.WithStackTrace(true), oteltrace.WithAttributes(attribute.String("retry", "no")))
+	sp.SetStatus(codes.Error, "")
+	sp.End()
+
+	tracer.Flush()
+	p, err := waitForPayload(ctx, payloads)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Contains(p, `"error":1`)
+	assert.Contains(p, `"error.message":"boom"`)
+	assert.Contains(p, `"error.type":"*errors.errorString"`)
+	assert.Contains(p, `"error.stack":"goroutine`)
+	assert.Contains(p, `"name":"exception"`)
+	assert.Contains(p, `"exception.message":{"type":0,"string_value":"boom"}`)
+	assert.Contains(p, `"exception.type":{"type":0,"string_value":"*errors.errorString"}`)
+	assert.Contains(p, `"exception.stacktrace":{"type":0,"string_value":"goroutine`)
+	assert.Contains(p, `"retry":{"type":0,"string_value":"no"}`)
+}
+
+func TestSpanRecordErrorNoEvent(t *testing.T) {
+	t.Setenv("DD_TRACE_OTEL_EXCEPTION_EVENTS_ENABLED", "false")
+	assert := assert.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, payloads, cleanup := mockTracerProvider(t)
+	tr := otel.Tracer("")
+	defer cleanup()
+
+	_, sp := tr.Start(context.Background(), "record_error")
+	sp.RecordError(errors.New("boom"))
+	sp.End()
+
+	tracer.Flush()
+	p, err := waitForPayload(ctx, payloads)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Contains(p, `"error":1`)
+	assert.Contains(p, `"error.message":"boom"`)
+	assert.NotContains(p, `"exception"`)
+}
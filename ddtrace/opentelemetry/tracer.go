@@ -47,6 +47,9 @@ func (t *oteltracer) Start(ctx context.Context, spanName string, opts ...oteltra
 	if k := ssConfig.SpanKind(); k != 0 {
 		ddopts = append(ddopts, tracer.SpanType(k.String()))
 	}
+	if links := ssConfig.Links(); len(links) > 0 {
+		ddopts = append(ddopts, tracer.WithSpanLinks(otelLinksToDDLinks(links)))
+	}
 	if opts, ok := spanOptionsFromContext(ctx); ok {
 		ddopts = append(ddopts, opts...)
 	}
@@ -59,6 +62,35 @@ func (t *oteltracer) Start(ctx context.Context, spanName string, opts ...oteltra
 	return oteltrace.ContextWithSpan(tracer.ContextWithSpan(ctx, s), os), os
 }
 
+// otelLinksToDDLinks converts the given OpenTelemetry links into Datadog span links,
+// skipping the ones holding an invalid span context.
+func otelLinksToDDLinks(links []oteltrace.Link) []ddtrace.SpanLink {
+	ddlinks := make([]ddtrace.SpanLink, 0, len(links))
+	for _, l := range links {
+		sc := l.SpanContext
+		if !sc.IsValid() {
+			continue
+		}
+		traceID, spanID := sc.TraceID(), sc.SpanID()
+		link := ddtrace.SpanLink{
+			TraceID:     binary.BigEndian.Uint64(traceID[8:]),
+			TraceIDHigh: binary.BigEndian.Uint64(traceID[:8]),
+			SpanID:      binary.BigEndian.Uint64(spanID[:]),
+			Tracestate:  sc.TraceState().String(),
+			// the high bit signals that the flags are set, as opposed to an unset zero value.
+			Flags: uint32(sc.TraceFlags()) | 1<<31,
+		}
+		if len(l.Attributes) > 0 {
+			link.Attributes = make(map[string]string, len(l.Attributes))
+			for _, attr := range l.Attributes {
+				link.Attributes[string(attr.Key)] = attr.Value.Emit()
+			}
+		}
+		ddlinks = append(ddlinks, link)
+	}
+	return ddlinks
+}
+
 type otelCtxToDDCtx struct {
 	oc oteltrace.SpanContext
 }
@@ -23,7 +23,9 @@
 // This package seeks to implement a minimal set of functions within
 // the OpenTelemetry Tracing API (https://opentelemetry.io/docs/reference/specification/trace/api)
 // to allow users to send traces to Datadog using existing OpenTelemetry code with minimal changes to the application.
-// Span events (https://opentelemetry.io/docs/concepts/signals/traces/#span-events) are not supported at this time.
+// Span events (https://opentelemetry.io/docs/concepts/signals/traces/#span-events) are recorded on the
+// Datadog spans. The "exception" events of Span.RecordError can be disabled by setting the
+// DD_TRACE_OTEL_EXCEPTION_EVENTS_ENABLED environment variable to false.
 package opentelemetry
 
 import (
@@ -35,6 +37,7 @@ import (
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/tracer"
+	sharedinternal "github.com/lannguyen-c0x12c/dd-trace-go/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 )
 
@@ -48,13 +51,19 @@ type TracerProvider struct {
 	tracer  *oteltracer
 	ddopts  []tracer.StartOption
 	stopped uint32 // stopped indicates whether the tracerProvider has been shutdown.
+	// exceptionEvents specifies whether RecordError adds an "exception" event to the span.
+	// Value from DD_TRACE_OTEL_EXCEPTION_EVENTS_ENABLED, default true.
+	exceptionEvents bool
 	sync.Once
 }
 
 // NewTracerProvider returns an instance of OpenTelemetry TracerProvider with Datadog Tracer start options.
 // This allows propagation of the parameters to tracer.Start.
 func NewTracerProvider(opts ...tracer.StartOption) *TracerProvider {
-	return &TracerProvider{ddopts: opts}
+	return &TracerProvider{
+		ddopts:          opts,
+		exceptionEvents: sharedinternal.BoolEnv("DD_TRACE_OTEL_EXCEPTION_EVENTS_ENABLED", true),
+	}
 }
 
 // Tracer returns an instance of OpenTelemetry Tracer and initializes Datadog Tracer.
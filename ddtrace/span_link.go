@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+//go:generate msgp -unexported -marshal=false -o=span_link_msgp.go -tests=false
+
+package ddtrace
+
+// SpanLink represents a reference to a span that exists outside of the span's
+// own trace, or in a causal relationship other than parent-child, e.g. a batch
+// consumer span referencing each of the producer spans of the messages it processes.
+type SpanLink struct {
+	// TraceID represents the lower 64 bits of the linked span's trace ID. This field is required.
+	TraceID uint64 `msg:"trace_id" json:"trace_id"`
+	// TraceIDHigh represents the upper 64 bits of the linked span's trace ID. It is only set
+	// when the linked span has a 128-bit trace ID.
+	TraceIDHigh uint64 `msg:"trace_id_high,omitempty" json:"trace_id_high"`
+	// SpanID represents the linked span's ID. This field is required.
+	SpanID uint64 `msg:"span_id" json:"span_id"`
+	// Attributes is a set of key/value pairs providing additional context about the link.
+	Attributes map[string]string `msg:"attributes,omitempty" json:"attributes"`
+	// Tracestate is the W3C tracestate of the linked span. This field is optional.
+	Tracestate string `msg:"tracestate,omitempty" json:"tracestate"`
+	// Flags represents the W3C trace flags of the linked span. This field is optional.
+	// When set, the high bit (1<<31) must also be set in order to distinguish an explicit
+	// zero value from an unset one.
+	Flags uint32 `msg:"flags,omitempty" json:"flags"`
+}
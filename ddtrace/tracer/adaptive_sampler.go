@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
+)
+
+const (
+	// adaptiveSamplingInterval is the interval at which the bucket rates are adjusted.
+	adaptiveSamplingInterval = time.Second
+
+	// adaptiveSamplingAlpha is the weight of the last interval in the moving averages of
+	// the bucket throughputs. The averages settle after about 1/alpha intervals.
+	adaptiveSamplingAlpha = 0.2
+
+	// adaptiveSamplingMinInterval is the maximum time between two traces kept in a bucket,
+	// regardless of its rate, so that rare endpoints are always represented.
+	adaptiveSamplingMinInterval = time.Minute
+
+	// adaptiveSamplingMaxBuckets limits the number of buckets tracked by the sampler. The
+	// traces of any further buckets share a single bucket.
+	adaptiveSamplingMaxBuckets = 1000
+
+	// adaptiveSamplingIdleThroughput is the average throughput, in traces per second, below
+	// which a bucket which saw no traces in the last interval is forgotten.
+	adaptiveSamplingIdleThroughput = 0.001
+)
+
+// adaptiveBucket holds the state of the traces sharing a service, env and resource.
+type adaptiveBucket struct {
+	seen     float64   // number of traces seen in the current interval
+	avg      float64   // exponential moving average of the throughput, in traces per second
+	rate     float64   // sampling rate applied to the bucket
+	lastKept time.Time // time at which the last trace of the bucket was kept
+}
+
+// adaptiveSampler samples traces so that the process keeps about tps traces per second, by
+// adjusting a sampling rate per service, env and resource bucket. The budget is split evenly
+// between the buckets based on exponential moving averages of their throughput: the buckets
+// below their share are fully kept and the rest of the budget goes to the busier ones. Each
+// bucket keeps a trace at least every adaptiveSamplingMinInterval.
+type adaptiveSampler struct {
+	tps float64
+
+	mu          sync.Mutex // guards below fields
+	buckets     map[string]*adaptiveBucket
+	intervalEnd time.Time // end of the current interval
+}
+
+func newAdaptiveSampler(tps float64) *adaptiveSampler {
+	return &adaptiveSampler{
+		tps:     tps,
+		buckets: make(map[string]*adaptiveBucket),
+	}
+}
+
+// adaptiveBucketKey returns the key of the bucket of spn. Callers must guard the span.
+func adaptiveBucketKey(spn *span) string {
+	return "service:" + spn.Service + ",env:" + spn.Meta[ext.Environment] + ",resource:" + spn.Resource
+}
+
+// apply counts the trace of the given local root span in its bucket and applies the
+// bucket's sampling priority to it. Caller must ensure it is safe to modify the span.
+func (as *adaptiveSampler) apply(spn *span) {
+	as.sample(spn, true)
+}
+
+// reapply applies the sampling priority of the bucket of the given local root span, which
+// was already counted by apply but whose bucket may have changed since.
+func (as *adaptiveSampler) reapply(spn *span) {
+	as.sample(spn, false)
+}
+
+func (as *adaptiveSampler) sample(spn *span, count bool) {
+	spn.RLock()
+	key := adaptiveBucketKey(spn)
+	spn.RUnlock()
+	now := nowTime()
+
+	as.mu.Lock()
+	if now.After(as.intervalEnd) {
+		as.adjust(now)
+	}
+	b := as.bucket(key)
+	if count {
+		b.seen++
+	}
+	rate := b.rate
+	keep := sampledByRate(spn.TraceID, rate)
+	if !keep && now.Sub(b.lastKept) >= adaptiveSamplingMinInterval {
+		// the bucket is kept from going unrepresented.
+		keep = true
+	}
+	if keep {
+		b.lastKept = now
+	}
+	as.mu.Unlock()
+
+	if keep {
+		spn.setSamplingPriority(ext.PriorityAutoKeep, samplernames.AdaptiveRate)
+	} else {
+		spn.setSamplingPriority(ext.PriorityAutoReject, samplernames.AdaptiveRate)
+	}
+	spn.SetTag(keyAdaptiveSamplerRate, rate)
+}
+
+// bucket returns the bucket with the given key, creating it if needed. New buckets are
+// fully sampled until the next adjustment. as.mu must be held.
+func (as *adaptiveSampler) bucket(key string) *adaptiveBucket {
+	if b, ok := as.buckets[key]; ok {
+		return b
+	}
+	if len(as.buckets) >= adaptiveSamplingMaxBuckets {
+		key = ""
+		if b, ok := as.buckets[key]; ok {
+			return b
+		}
+	}
+	b := &adaptiveBucket{rate: 1}
+	as.buckets[key] = b
+	return b
+}
+
+// adjust updates the moving averages of the bucket throughputs with the traces seen in the
+// interval ending at now, and splits the budget between the buckets. as.mu must be held.
+func (as *adaptiveSampler) adjust(now time.Time) {
+	elapsed := adaptiveSamplingInterval
+	if !as.intervalEnd.IsZero() {
+		elapsed += now.Sub(as.intervalEnd)
+	}
+	as.intervalEnd = now.Add(adaptiveSamplingInterval)
+
+	buckets := make([]*adaptiveBucket, 0, len(as.buckets))
+	for k, b := range as.buckets {
+		tps := b.seen / elapsed.Seconds()
+		if b.avg == 0 {
+			// first interval of the bucket: start the average from the observed value.
+			b.avg = tps
+		} else {
+			b.avg = adaptiveSamplingAlpha*tps + (1-adaptiveSamplingAlpha)*b.avg
+		}
+		if b.seen == 0 && b.avg < adaptiveSamplingIdleThroughput {
+			delete(as.buckets, k)
+			continue
+		}
+		b.seen = 0
+		buckets = append(buckets, b)
+	}
+
+	// Fill the buckets from the quietest to the busiest, each getting an even share of
+	// the remaining budget.
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].avg < buckets[j].avg })
+	budget := as.tps
+	for i, b := range buckets {
+		share := budget / float64(len(buckets)-i)
+		if b.avg <= share {
+			b.rate = 1
+			budget -= b.avg
+			continue
+		}
+		b.rate = share / b.avg
+		budget -= share
+	}
+}
@@ -0,0 +1,165 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveSampler(t *testing.T) {
+	current := time.Now()
+	nowTime = func() time.Time { return current }
+	defer func() { nowTime = func() time.Time { return time.Now() } }()
+
+	makeSpan := func(resource string) *span {
+		s := newSpan("http.request", "web", resource, random.Uint64(), random.Uint64(), 0)
+		s.context.trace.root = s
+		return s
+	}
+	// run sends the given number of traces per second to each resource for the given
+	// number of seconds, and returns the number of traces kept by resource.
+	run := func(as *adaptiveSampler, tps map[string]int, seconds int) map[string]int {
+		kept := make(map[string]int)
+		for i := 0; i < seconds; i++ {
+			for resource, n := range tps {
+				for j := 0; j < n; j++ {
+					s := makeSpan(resource)
+					as.apply(s)
+					if p, _ := s.context.samplingPriority(); p > 0 {
+						kept[resource]++
+					}
+				}
+			}
+			current = current.Add(time.Second + time.Millisecond)
+		}
+		return kept
+	}
+
+	t.Run("budget", func(t *testing.T) {
+		as := newAdaptiveSampler(50)
+		tps := map[string]int{"GET /busy": 5000, "GET /other": 500, "GET /rare": 2}
+		run(as, tps, 20)
+		kept := run(as, tps, 10)
+
+		// the rare endpoint is fully kept and the rest of the budget is split evenly.
+		assert.Equal(t, 20, kept["GET /rare"])
+		assert.InDelta(t, 240, kept["GET /busy"], 60)
+		assert.InDelta(t, 240, kept["GET /other"], 60)
+		total := kept["GET /busy"] + kept["GET /other"] + kept["GET /rare"]
+		assert.InDelta(t, 500, total, 100)
+
+		as.mu.Lock()
+		defer as.mu.Unlock()
+		assert.Equal(t, 1.0, as.buckets["service:web,env:,resource:GET /rare"].rate)
+		assert.InDelta(t, 24.0/5000, as.buckets["service:web,env:,resource:GET /busy"].rate, 0.001)
+	})
+
+	t.Run("adapts", func(t *testing.T) {
+		as := newAdaptiveSampler(10)
+		kept := run(as, map[string]int{"GET /": 10}, 10)
+		assert.Equal(t, 100, kept["GET /"])
+
+		// the traffic is multiplied by 100: the kept traces converge back to the budget.
+		run(as, map[string]int{"GET /": 1000}, 30)
+		kept = run(as, map[string]int{"GET /": 1000}, 10)
+		assert.InDelta(t, 100, kept["GET /"], 40)
+	})
+
+	t.Run("minimum", func(t *testing.T) {
+		as := newAdaptiveSampler(0.001)
+		run(as, map[string]int{"GET /": 1000}, 5)
+		as.mu.Lock()
+		rate := as.buckets["service:web,env:,resource:GET /"].rate
+		as.mu.Unlock()
+		require.Less(t, rate, 0.0001)
+
+		// a trace is kept at least every minute.
+		current = current.Add(adaptiveSamplingMinInterval)
+		kept := run(as, map[string]int{"GET /": 1000}, 1)
+		assert.Equal(t, 1, kept["GET /"])
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		as := newAdaptiveSampler(10)
+		run(as, map[string]int{"GET /": 1}, 1)
+		run(as, map[string]int{"GET /other": 1}, 60)
+		as.mu.Lock()
+		defer as.mu.Unlock()
+		assert.Len(t, as.buckets, 1)
+	})
+
+	t.Run("max-buckets", func(t *testing.T) {
+		as := newAdaptiveSampler(10)
+		tps := make(map[string]int)
+		for i := 0; i < adaptiveSamplingMaxBuckets+10; i++ {
+			tps[time.Duration(i).String()] = 1
+		}
+		run(as, tps, 1)
+		as.mu.Lock()
+		defer as.mu.Unlock()
+		assert.Len(t, as.buckets, adaptiveSamplingMaxBuckets+1)
+		assert.Contains(t, as.buckets, "")
+	})
+
+	t.Run("decision-maker", func(t *testing.T) {
+		as := newAdaptiveSampler(10)
+		s := makeSpan("GET /")
+		as.apply(s)
+		p, _ := s.context.samplingPriority()
+		assert.Equal(t, ext.PriorityAutoKeep, p)
+		assert.Equal(t, "-10", s.context.trace.propagatingTags[keyDecisionMaker])
+		assert.Equal(t, 1.0, s.Metrics[keyAdaptiveSamplerRate])
+	})
+}
+
+func TestAdaptiveSamplingConfig(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		tr, _, _, stop := startTestTracer(t)
+		defer stop()
+		assert.Nil(t, tr.adaptiveSampling)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_TRACE_SAMPLING_TARGET_TPS", "25")
+		tr, _, _, stop := startTestTracer(t)
+		defer stop()
+		require.NotNil(t, tr.adaptiveSampling)
+		assert.Equal(t, 25.0, tr.adaptiveSampling.tps)
+	})
+
+	t.Run("invalid-env", func(t *testing.T) {
+		t.Setenv("DD_TRACE_SAMPLING_TARGET_TPS", "lots")
+		tr, _, _, stop := startTestTracer(t)
+		defer stop()
+		assert.Nil(t, tr.adaptiveSampling)
+	})
+
+	t.Run("option", func(t *testing.T) {
+		tr, _, _, stop := startTestTracer(t, WithAdaptiveSampling(5))
+		defer stop()
+		require.NotNil(t, tr.adaptiveSampling)
+
+		root := tr.StartSpan("http.request", ResourceName("GET /")).(*span)
+		p, _ := root.context.samplingPriority()
+		assert.Equal(t, ext.PriorityAutoKeep, p)
+		assert.Equal(t, "-10", root.context.trace.propagatingTags[keyDecisionMaker])
+		assert.NotContains(t, root.Metrics, keySamplingPriorityRate)
+	})
+
+	t.Run("rules-first", func(t *testing.T) {
+		tr, _, _, stop := startTestTracer(t, WithAdaptiveSampling(5), WithSamplingRules([]SamplingRule{RateRule(1)}))
+		defer stop()
+
+		root := tr.StartSpan("http.request").(*span)
+		assert.Equal(t, "-3", root.context.trace.propagatingTags[keyDecisionMaker])
+	})
+}
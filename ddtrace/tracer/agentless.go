@@ -0,0 +1,311 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+const (
+	// defaultSite is the Datadog site receiving traces in agentless mode, unless DD_SITE is set.
+	defaultSite = "datadoghq.com"
+
+	// agentlessSendRetries is the number of times a payload is re-sent to the intake after a
+	// retriable failure, unless WithSendRetries specifies otherwise.
+	agentlessSendRetries = 3
+
+	// agentlessHTTPTimeout is the timeout of the requests to the intake. It is larger than
+	// defaultHTTPTimeout, as the intake is reached over the internet rather than locally.
+	agentlessHTTPTimeout = 10 * time.Second
+)
+
+var (
+	// agentlessRetryInterval is the time waited before the first retry of a failed intake
+	// request. It doubles with every attempt, up to agentlessMaxRetryInterval. Replaced in tests.
+	agentlessRetryInterval = 100 * time.Millisecond
+
+	// agentlessMaxRetryInterval caps the time waited between two retries of an intake request.
+	agentlessMaxRetryInterval = 5 * time.Second
+)
+
+// agentlessClient is the HTTP client used to reach the intake when the user provides none.
+var agentlessClient = &http.Client{
+	Transport: defaultClient.Transport,
+	Timeout:   agentlessHTTPTimeout,
+}
+
+// intakeURLForSite returns the URL of the trace intake for the given Datadog site.
+func intakeURLForSite(site string) string {
+	return "https://trace.agent." + site
+}
+
+// agentlessTransport is a transport sending gzipped traces and stats straight to the
+// Datadog intake, authenticating with an API key. Both are encoded in the protobuf format
+// of the intake. Failed requests are retried with an exponential backoff when the failure
+// is transient.
+type agentlessTransport struct {
+	traceURL string            // the delivery URL for traces
+	statsURL string            // the delivery URL for stats
+	retries  int               // the number of retries of a failed request
+	client   *http.Client      // the HTTP client used in the POST
+	headers  map[string]string // the Transport headers
+	meta     intakeMetadata    // the description of the application in the trace payloads
+}
+
+// newAgentlessTransport returns a transport sending traces and stats to the intake found
+// at the given url, using the given API key and *http.Client. The trace payloads are
+// described by meta.
+func newAgentlessTransport(url, apiKey string, client *http.Client, retries int, meta intakeMetadata) *agentlessTransport {
+	headers := defaultHeaders()
+	headers["Content-Type"] = "application/x-protobuf"
+	headers["Content-Encoding"] = "gzip"
+	headers["DD-API-KEY"] = apiKey
+	return &agentlessTransport{
+		traceURL: fmt.Sprintf("%s/api/v0.2/traces", url),
+		statsURL: fmt.Sprintf("%s/api/v0.2/stats", url),
+		retries:  retries,
+		client:   client,
+		headers:  headers,
+		meta:     meta,
+	}
+}
+
+// agentlessTransportForConfig returns the agentless transport configured by c.
+func agentlessTransportForConfig(c *config) *agentlessTransport {
+	if c.intakeURL == "" {
+		c.intakeURL = intakeURLForSite(c.site)
+	}
+	retries := c.sendRetries
+	if retries == 0 {
+		retries = agentlessSendRetries
+	}
+	return newAgentlessTransport(c.intakeURL, c.apiKey, c.httpClient, retries, intakeMetadata{
+		hostname: c.hostname,
+		env:      c.env,
+		version:  c.version,
+	})
+}
+
+// send implements transport. As the intake doesn't accept msgpack, the traces of p are
+// decoded and sent using sendTraces, which the agentlessTraceWriter calls directly.
+func (t *agentlessTransport) send(p *payload) (body io.ReadCloser, err error) {
+	var traces spanLists
+	if err := msgp.Decode(p, &traces); err != nil {
+		return nil, fmt.Errorf("cannot decode payload: %v", err)
+	}
+	var ip intakePayload
+	for _, trace := range traces {
+		ip.push(trace)
+	}
+	return t.sendTraces(&ip)
+}
+
+// sendTraces sends the traces of p to the intake, as an AgentPayload.
+func (t *agentlessTransport) sendTraces(p *intakePayload) (io.ReadCloser, error) {
+	buf, err := compress(bytes.NewReader(p.encode(t.meta)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot compress payload: %v", err)
+	}
+	return t.post(t.traceURL, buf, map[string]string{
+		traceCountHeader:                strconv.Itoa(p.itemCount()),
+		headerComputedTopLevel:          "yes",
+		"Datadog-Client-Computed-Stats": "yes",
+	})
+}
+
+func (t *agentlessTransport) sendStats(s *statsPayload) error {
+	buf, err := compress(bytes.NewReader(encodeIntakeStats(s)))
+	if err != nil {
+		return fmt.Errorf("cannot compress stats payload: %v", err)
+	}
+	rc, err := t.post(t.statsURL, buf, nil)
+	if err != nil {
+		return err
+	}
+	return rc.Close()
+}
+
+func (t *agentlessTransport) endpoint() string {
+	return t.traceURL
+}
+
+// post sends body to url with the transport headers and the given extra headers. It retries
+// transient failures up to t.retries times, doubling the wait between two attempts.
+func (t *agentlessTransport) post(url string, body []byte, headers map[string]string) (io.ReadCloser, error) {
+	wait := agentlessRetryInterval
+	for attempt := 0; ; attempt++ {
+		rc, err := t.do(url, body, headers)
+		if err == nil {
+			return rc, nil
+		}
+		if attempt >= t.retries || !isRetriable(err) {
+			return nil, err
+		}
+		log.Debug("failure sending to the intake (attempt %d), retrying in %s: %v", attempt+1, wait, err)
+		time.Sleep(wait)
+		if wait *= 2; wait > agentlessMaxRetryInterval {
+			wait = agentlessMaxRetryInterval
+		}
+	}
+}
+
+func (t *agentlessTransport) do(url string, body []byte, headers map[string]string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create http request: %v", err)
+	}
+	for header, value := range t.headers {
+		req.Header.Set(header, value)
+	}
+	for header, value := range headers {
+		req.Header.Set(header, value)
+	}
+	response, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if code := response.StatusCode; code >= 400 {
+		// error, check the body for context information and
+		// return a nice error.
+		msg := make([]byte, 1000)
+		n, _ := response.Body.Read(msg)
+		response.Body.Close()
+		return nil, &intakeError{status: code, msg: string(msg[:n])}
+	}
+	return response.Body, nil
+}
+
+// intakeError is returned when the intake responds to a request with an error status.
+type intakeError struct {
+	status int    // the HTTP status code
+	msg    string // the beginning of the response body, if any
+}
+
+func (e *intakeError) Error() string {
+	txt := http.StatusText(e.status)
+	if e.msg != "" {
+		return fmt.Sprintf("%s (Status: %s)", e.msg, txt)
+	}
+	return txt
+}
+
+// isRetriable reports whether a request which failed with err may succeed if re-sent.
+// Network errors, timeouts, throttling and server errors are transient; other errors,
+// such as an invalid API key, are not.
+func isRetriable(err error) bool {
+	var ierr *intakeError
+	if !errors.As(err, &ierr) {
+		return true
+	}
+	return ierr.status == http.StatusRequestTimeout || ierr.status == http.StatusTooManyRequests || ierr.status >= 500
+}
+
+// compress returns the gzipped content of r.
+func compress(r io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := io.Copy(gz, r); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// agentlessTraceWriter encodes traces in the protobuf format of the intake and sends them
+// straight to the Datadog intake, without going through an agent. It is used in agentless mode.
+type agentlessTraceWriter struct {
+	// config holds the tracer configuration
+	config *config
+
+	// transport sends the payloads to the intake
+	transport *agentlessTransport
+
+	// payload encodes and buffers traces in protobuf format
+	payload *intakePayload
+
+	// climit limits the number of concurrent outgoing connections
+	climit chan struct{}
+
+	// wg waits for all uploads to finish
+	wg sync.WaitGroup
+
+	// statsd is used to send metrics
+	statsd statsdClient
+}
+
+func newAgentlessTraceWriter(c *config, statsdClient statsdClient) *agentlessTraceWriter {
+	t, ok := c.transport.(*agentlessTransport)
+	if !ok {
+		// the transport was replaced, such as in tests
+		t = agentlessTransportForConfig(c)
+	}
+	return &agentlessTraceWriter{
+		config:    c,
+		transport: t,
+		payload:   new(intakePayload),
+		climit:    make(chan struct{}, concurrentConnectionLimit),
+		statsd:    statsdClient,
+	}
+}
+
+func (h *agentlessTraceWriter) add(trace []*span) {
+	h.payload.push(trace)
+	if h.payload.size() > payloadSizeLimit {
+		h.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:size"}, 1)
+		h.flush()
+	}
+}
+
+func (h *agentlessTraceWriter) stop() {
+	h.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:shutdown"}, 1)
+	h.flush()
+	h.wg.Wait()
+}
+
+// flush will push any currently buffered traces to the intake.
+func (h *agentlessTraceWriter) flush() {
+	if h.payload.itemCount() == 0 {
+		return
+	}
+	h.wg.Add(1)
+	h.climit <- struct{}{}
+	oldp := h.payload
+	h.payload = new(intakePayload)
+	go func(p *intakePayload) {
+		defer func(start time.Time) {
+			<-h.climit
+			h.wg.Done()
+			h.statsd.Timing("datadog.tracer.flush_duration", time.Since(start), nil, 1)
+		}(time.Now())
+
+		size, count := p.size(), p.itemCount()
+		log.Debug("Sending payload to the intake: size: %d traces: %d\n", size, count)
+		// the transport takes care of retrying transient failures
+		rc, err := h.transport.sendTraces(p)
+		if err != nil {
+			h.statsd.Count("datadog.tracer.traces_dropped", int64(count), []string{"reason:send_failed"}, 1)
+			log.Error("lost %d traces: %v", count, err)
+			return
+		}
+		rc.Close()
+		h.statsd.Count("datadog.tracer.flush_bytes", int64(size), nil, 1)
+		h.statsd.Count("datadog.tracer.flush_traces", int64(count), nil, 1)
+	}(oldp)
+}
@@ -0,0 +1,371 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"compress/gzip"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// withIntakeURL replaces the URL of the intake receiving traces in agentless mode.
+func withIntakeURL(u string) StartOption {
+	return func(c *config) {
+		c.intakeURL = u
+	}
+}
+
+// fakeIntake is a local stand-in for the Datadog trace intake.
+type fakeIntake struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int // number of requests to fail with status before succeeding
+	status   int
+	requests int
+	traces   spanLists
+	stats    []statsPayload
+	headers  []http.Header
+}
+
+func newFakeIntake(t *testing.T) *fakeIntake {
+	in := &fakeIntake{}
+	in.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in.mu.Lock()
+		defer in.mu.Unlock()
+		in.requests++
+		if in.failures > 0 {
+			in.failures--
+			w.WriteHeader(in.status)
+			return
+		}
+		in.headers = append(in.headers, r.Header)
+		gz, err := gzip.NewReader(r.Body)
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v0.2/traces":
+			in.traces = append(in.traces, decodeIntakeTraces(t, readAll(t, gz))...)
+		case "/api/v0.2/stats":
+			in.stats = append(in.stats, decodeIntakeStats(t, readAll(t, gz)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(in.Close)
+	return in
+}
+
+func readAll(t *testing.T, r io.Reader) []byte {
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return b
+}
+
+// forEachField calls fn with the number and the value of each field of the protobuf
+// message b. The value is the content of the length-delimited fields, or the encoding
+// of the other ones.
+func forEachField(t *testing.T, b []byte, fn func(num protowire.Number, v []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		require.GreaterOrEqual(t, m, 0)
+		v := b[:m]
+		if typ == protowire.BytesType {
+			v, _ = protowire.ConsumeBytes(v)
+		}
+		fn(num, v)
+		b = b[m:]
+	}
+}
+
+func varint(v []byte) uint64 {
+	n, _ := protowire.ConsumeVarint(v)
+	return n
+}
+
+// decodeIntakeTraces returns the traces of the AgentPayload b, with the fields of their
+// spans checked by the tests.
+func decodeIntakeTraces(t *testing.T, b []byte) spanLists {
+	var traces spanLists
+	forEachField(t, b, func(num protowire.Number, v []byte) {
+		if num != intakeAgentPayloadTracerPayloads {
+			return
+		}
+		forEachField(t, v, func(num protowire.Number, v []byte) {
+			if num != intakeTracerPayloadChunks {
+				return
+			}
+			var trace spanList
+			forEachField(t, v, func(num protowire.Number, v []byte) {
+				if num == intakeChunkSpans {
+					trace = append(trace, decodeIntakeSpan(t, v))
+				}
+			})
+			traces = append(traces, trace)
+		})
+	})
+	return traces
+}
+
+func decodeIntakeSpan(t *testing.T, b []byte) *span {
+	s := &span{Meta: map[string]string{}, Metrics: map[string]float64{}}
+	forEachField(t, b, func(num protowire.Number, v []byte) {
+		switch num {
+		case intakeSpanService:
+			s.Service = string(v)
+		case intakeSpanName:
+			s.Name = string(v)
+		case intakeSpanResource:
+			s.Resource = string(v)
+		case intakeSpanTraceID:
+			s.TraceID = varint(v)
+		case intakeSpanSpanID:
+			s.SpanID = varint(v)
+		case intakeSpanParentID:
+			s.ParentID = varint(v)
+		case intakeSpanMeta:
+			var key, val string
+			forEachField(t, v, func(num protowire.Number, v []byte) {
+				if num == intakeMapKey {
+					key = string(v)
+				} else {
+					val = string(v)
+				}
+			})
+			s.Meta[key] = val
+		case intakeSpanMetrics:
+			var key string
+			var val float64
+			forEachField(t, v, func(num protowire.Number, v []byte) {
+				if num == intakeMapKey {
+					key = string(v)
+				} else {
+					bits, _ := protowire.ConsumeFixed64(v)
+					val = math.Float64frombits(bits)
+				}
+			})
+			s.Metrics[key] = val
+		case intakeSpanSpanEvents:
+			var e spanEvent
+			forEachField(t, v, func(num protowire.Number, v []byte) {
+				if num == intakeEventName {
+					e.Name = string(v)
+				}
+			})
+			s.SpanEvents = append(s.SpanEvents, e)
+		}
+	})
+	return s
+}
+
+// decodeIntakeStats returns the stats of the StatsPayload b, with the fields checked by
+// the tests.
+func decodeIntakeStats(t *testing.T, b []byte) statsPayload {
+	var sp statsPayload
+	forEachField(t, b, func(num protowire.Number, v []byte) {
+		if num != intakeStatsPayloadStats {
+			return
+		}
+		forEachField(t, v, func(num protowire.Number, v []byte) {
+			switch num {
+			case intakeClientStatsEnv:
+				sp.Env = string(v)
+			case intakeClientStatsStats:
+				var bucket statsBucket
+				forEachField(t, v, func(num protowire.Number, v []byte) {
+					switch num {
+					case intakeBucketStart:
+						bucket.Start = varint(v)
+					case intakeBucketStats:
+						var gs groupedStats
+						forEachField(t, v, func(num protowire.Number, v []byte) {
+							switch num {
+							case intakeGroupedName:
+								gs.Name = string(v)
+							case intakeGroupedHits:
+								gs.Hits = varint(v)
+							}
+						})
+						bucket.Stats = append(bucket.Stats, gs)
+					}
+				})
+				sp.Stats = append(sp.Stats, bucket)
+			}
+		})
+	})
+	return sp
+}
+
+// failNext makes the next n requests fail with the given status.
+func (in *fakeIntake) failNext(n, status int) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.failures = n
+	in.status = status
+}
+
+func (in *fakeIntake) Requests() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.requests
+}
+
+func TestAgentlessConfig(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		c := newConfig(WithLogger(log.DiscardLogger{}))
+		assert.False(t, c.agentless)
+		assert.IsType(t, &httpTransport{}, c.transport)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_TRACE_AGENTLESS", "true")
+		t.Setenv("DD_API_KEY", "abc")
+		t.Setenv("DD_SITE", "datadoghq.eu")
+		c := newConfig()
+		assert.True(t, c.agentless)
+		assert.True(t, c.canComputeStats())
+		assert.True(t, c.canDropP0s())
+		assert.Equal(t, agentlessClient, c.httpClient)
+		tr, ok := c.transport.(*agentlessTransport)
+		require.True(t, ok)
+		assert.Equal(t, "https://trace.agent.datadoghq.eu/api/v0.2/traces", tr.endpoint())
+		assert.Equal(t, "https://trace.agent.datadoghq.eu/api/v0.2/stats", tr.statsURL)
+		assert.Equal(t, "abc", tr.headers["DD-API-KEY"])
+		assert.Equal(t, agentlessSendRetries, tr.retries)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Setenv("DD_API_KEY", "abc")
+		t.Setenv("DD_SITE", "datadoghq.eu")
+		client := &http.Client{}
+		c := newConfig(WithAgentlessMode(true), WithSite("us3.datadoghq.com"), WithHTTPClient(client), WithSendRetries(5))
+		assert.True(t, c.agentless)
+		assert.Equal(t, client, c.httpClient)
+		assert.Equal(t, "https://trace.agent.us3.datadoghq.com/api/v0.2/traces", c.transport.endpoint())
+		assert.Equal(t, 5, c.transport.(*agentlessTransport).retries)
+	})
+
+	t.Run("no-api-key", func(t *testing.T) {
+		t.Setenv("DD_API_KEY", "")
+		c := newConfig(WithAgentlessMode(true))
+		assert.False(t, c.agentless)
+		assert.IsType(t, &httpTransport{}, c.transport)
+	})
+}
+
+func TestAgentlessTransport(t *testing.T) {
+	defer func(old time.Duration) { agentlessRetryInterval = old }(agentlessRetryInterval)
+	agentlessRetryInterval = time.Millisecond
+
+	t.Run("send", func(t *testing.T) {
+		in := newFakeIntake(t)
+		tr := newAgentlessTransport(in.URL, "abc", defaultClient, 0, intakeMetadata{})
+		p, err := encode(getTestTrace(2, 3))
+		require.NoError(t, err)
+		rc, err := tr.send(p)
+		require.NoError(t, err)
+		rc.Close()
+		assert.Len(t, in.traces, 2)
+		h := in.headers[0]
+		assert.Equal(t, "abc", h.Get("DD-API-KEY"))
+		assert.Equal(t, "gzip", h.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", h.Get("Content-Type"))
+		assert.Equal(t, "2", h.Get(traceCountHeader))
+		assert.Equal(t, "yes", h.Get("Datadog-Client-Computed-Stats"))
+		assert.Equal(t, "go", h.Get("Datadog-Meta-Lang"))
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		in := newFakeIntake(t)
+		tr := newAgentlessTransport(in.URL, "abc", defaultClient, 0, intakeMetadata{})
+		require.NoError(t, tr.sendStats(&statsPayload{Env: "env", Stats: []statsBucket{{Start: 1}}}))
+		require.Len(t, in.stats, 1)
+		assert.Equal(t, "env", in.stats[0].Env)
+		assert.Len(t, in.stats[0].Stats, 1)
+		assert.Equal(t, "abc", in.headers[0].Get("DD-API-KEY"))
+	})
+
+	for name, tc := range map[string]struct {
+		status   int
+		failures int
+		retries  int
+		requests int
+		ok       bool
+	}{
+		"server-error":  {status: http.StatusInternalServerError, failures: 2, retries: 3, requests: 3, ok: true},
+		"throttled":     {status: http.StatusTooManyRequests, failures: 1, retries: 1, requests: 2, ok: true},
+		"out-of-tries":  {status: http.StatusServiceUnavailable, failures: 5, retries: 2, requests: 3, ok: false},
+		"invalid-key":   {status: http.StatusForbidden, failures: 1, retries: 3, requests: 1, ok: false},
+		"bad-request":   {status: http.StatusBadRequest, failures: 1, retries: 3, requests: 1, ok: false},
+		"timeout-retry": {status: http.StatusRequestTimeout, failures: 1, retries: 1, requests: 2, ok: true},
+	} {
+		t.Run("retries/"+name, func(t *testing.T) {
+			in := newFakeIntake(t)
+			in.failNext(tc.failures, tc.status)
+			tr := newAgentlessTransport(in.URL, "abc", defaultClient, tc.retries, intakeMetadata{})
+			p, err := encode(getTestTrace(1, 1))
+			require.NoError(t, err)
+			rc, err := tr.send(p)
+			assert.Equal(t, tc.requests, in.Requests())
+			if !tc.ok {
+				assert.Error(t, err)
+				assert.Empty(t, in.traces)
+				return
+			}
+			require.NoError(t, err)
+			rc.Close()
+			assert.Len(t, in.traces, 1)
+		})
+	}
+}
+
+func TestAgentlessTracer(t *testing.T) {
+	t.Setenv("DD_API_KEY", "abc")
+	in := newFakeIntake(t)
+	tracer := newTracer(
+		WithAgentlessMode(true),
+		withIntakeURL(in.URL),
+		WithLogger(log.DiscardLogger{}),
+	)
+	assert.IsType(t, &agentlessTraceWriter{}, tracer.traceWriter)
+	internal.SetGlobalTracer(tracer)
+	defer internal.SetGlobalTracer(&internal.NoopTracer{})
+
+	tracer.StartSpan("web.request").Finish()
+	// stopping the tracer flushes both the traces and the client-computed stats
+	tracer.Stop()
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	require.Len(t, in.traces, 1)
+	assert.Equal(t, "web.request", in.traces[0][0].Name)
+	require.Len(t, in.stats, 1)
+	require.Len(t, in.stats[0].Stats, 1)
+	var hits uint64
+	for _, gs := range in.stats[0].Stats[0].Stats {
+		if gs.Name == "web.request" {
+			hits += gs.Hits
+		}
+	}
+	assert.EqualValues(t, 1, hits)
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"context"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
+)
+
+// dataStreamsContainer is implemented by the tracers holding a Data Streams Monitoring processor.
+type dataStreamsContainer interface {
+	GetDataStreamsProcessor() *datastreams.Processor
+}
+
+// GetDataStreamsProcessor returns the processor tracking data streams stats, or nil
+// if Data Streams Monitoring is disabled.
+func (t *tracer) GetDataStreamsProcessor() *datastreams.Processor {
+	return t.dataStreams
+}
+
+// dataStreamsProcessor returns the Data Streams Monitoring processor of the global tracer,
+// or nil if there is none.
+func dataStreamsProcessor() *datastreams.Processor {
+	if t, ok := internal.GetGlobalTracer().(dataStreamsContainer); ok {
+		return t.GetDataStreamsProcessor()
+	}
+	return nil
+}
+
+// SetDataStreamsCheckpoint sets a consume or produce checkpoint in a Data Streams pathway.
+// This enables tracking data flow & end to end latency.
+// To learn more about the data streams product, see: https://docs.datadoghq.com/data_streams/go/
+// The edge tags identify the checkpoint, for example "direction:out", "topic:orders", "type:kafka".
+// The returned context holds the resulting pathway, which should be propagated to the
+// downstream services. It returns false if Data Streams Monitoring is disabled.
+func SetDataStreamsCheckpoint(ctx context.Context, edgeTags ...string) (outCtx context.Context, ok bool) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if p := dataStreamsProcessor(); p != nil {
+		_, ctx := p.SetCheckpoint(ctx, edgeTags...)
+		return ctx, true
+	}
+	return ctx, false
+}
+
+// TrackKafkaCommitOffset should be used in the consumer, to track when it acks offset.
+// If used together with TrackKafkaProduceOffset it can generate a Kafka lag in seconds metric.
+func TrackKafkaCommitOffset(group, topic string, partition int32, offset int64) {
+	if p := dataStreamsProcessor(); p != nil {
+		p.TrackKafkaCommitOffset(group, topic, partition, offset)
+	}
+}
+
+// TrackKafkaProduceOffset should be used in the producer, to track when it produces a message.
+// If used together with TrackKafkaCommitOffset it can generate a Kafka lag in seconds metric.
+func TrackKafkaProduceOffset(topic string, partition int32, offset int64) {
+	if p := dataStreamsProcessor(); p != nil {
+		p.TrackKafkaProduceOffset(topic, partition, offset)
+	}
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// newDataStreamsAgent returns a test server standing in for an agent supporting data streams,
+// and a function returning the pipeline stats payloads it received.
+func newDataStreamsAgent(t *testing.T) (*httptest.Server, func() []datastreams.StatsPayload) {
+	var (
+		mu       sync.Mutex
+		payloads []datastreams.StatsPayload
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"endpoints":["/v0.4/traces","/v0.1/pipeline_stats"]}`))
+		case "/v0.1/pipeline_stats":
+			gz, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var p datastreams.StatsPayload
+			if !assert.NoError(t, msgp.Decode(gz, &p)) {
+				return
+			}
+			mu.Lock()
+			payloads = append(payloads, p)
+			mu.Unlock()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []datastreams.StatsPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		return payloads
+	}
+}
+
+func TestDataStreams(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		Start(WithLogger(log.DiscardLogger{}))
+		defer Stop()
+
+		ctx, ok := SetDataStreamsCheckpoint(context.Background(), "direction:out", "type:kafka")
+		assert.False(t, ok)
+		_, ok = datastreams.PathwayFromContext(ctx)
+		assert.False(t, ok)
+		// no-ops
+		TrackKafkaCommitOffset("group", "topic", 0, 1)
+		TrackKafkaProduceOffset("topic", 0, 1)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"endpoints":["/v0.4/traces"]}`))
+		}))
+		defer srv.Close()
+		tr := newTracer(WithAgentAddr(strings.TrimPrefix(srv.URL, "http://")), WithDataStreamsMonitoring(true))
+		defer tr.Stop()
+		assert.Nil(t, tr.GetDataStreamsProcessor())
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_DATA_STREAMS_ENABLED", "true")
+		srv, _ := newDataStreamsAgent(t)
+		tr := newTracer(WithAgentAddr(strings.TrimPrefix(srv.URL, "http://")))
+		defer tr.Stop()
+		assert.NotNil(t, tr.GetDataStreamsProcessor())
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		srv, payloads := newDataStreamsAgent(t)
+		Start(
+			WithAgentAddr(strings.TrimPrefix(srv.URL, "http://")),
+			WithDataStreamsMonitoring(true),
+			WithEnv("dsm-env"),
+			WithLogger(log.DiscardLogger{}),
+		)
+		ctx, ok := SetDataStreamsCheckpoint(context.Background(), "direction:out", "topic:topic1", "type:kafka")
+		assert.True(t, ok)
+		_, ok = datastreams.PathwayFromContext(ctx)
+		assert.True(t, ok)
+		_, ok = SetDataStreamsCheckpoint(ctx, "direction:in", "group:group1", "topic:topic1", "type:kafka")
+		assert.True(t, ok)
+		TrackKafkaProduceOffset("topic1", 0, 10)
+		TrackKafkaCommitOffset("group1", "topic1", 0, 8)
+		Stop()
+
+		got := payloads()
+		require.Len(t, got, 1)
+		assert.NotEmpty(t, got[0].Service)
+		assert.Equal(t, "dsm-env", got[0].Env)
+		var points, backlogs int
+		for _, b := range got[0].Stats {
+			points += len(b.Stats)
+			backlogs += len(b.Backlogs)
+		}
+		// each checkpoint is reported in a current and an origin bucket
+		assert.Equal(t, 4, points)
+		assert.Equal(t, 2, backlogs)
+	})
+}
@@ -38,6 +38,9 @@
 //	      tracer.NameServiceRule("db.query", "postgres.db", 0.3),
 //	      // sample 100% of traces when service and name match these regular expressions
 //	      {Service: regexp.MustCompile("^test-"), Name: regexp.MustCompile("http\\..*"), Rate: 1.0},
+//	      // sample 100% of traces when the resource is "POST /checkout" and the
+//	      // "customer.tier" tag matches the glob pattern "enterprise*"
+//	      tracer.TagsResourceRule(map[string]string{"customer.tier": "enterprise*"}, "POST /checkout", "", "", 1.0),
 //	      // sample 50% of spans when service and name match these glob patterns with no limit on the number of spans
 //	      tracer.SpanNameServiceRule("^test-", "http\\..*", 0.5),
 //	      // sample 50% of spans when service and name match these glob patterns up to 100 spans per second
@@ -50,6 +53,10 @@
 // DD_SPAN_SAMPLING_RULES environment variables. When set, it overrides rules set by tracer.WithSamplingRules.
 // The value is a JSON array of objects.
 // For trace sampling rules, the "sample_rate" field is required, the "name" and "service" fields are optional.
+// The optional "resource" field and the values of the optional "tags" object are matched exactly, or as
+// glob patterns when they contain "*" or "?". Since resource names and tags are often set after a span
+// starts, these rules are applied again to the local root span when the trace context is propagated,
+// or else when the local root span finishes.
 // For span sampling rules, the "name" and "service", if specified, must be a valid glob pattern,
 // i.e. a string where "*" matches any contiguous substring, even an empty string,
 // and "?" character matches exactly one of any character.
@@ -57,6 +64,7 @@
 // The "max_per_second" field is optional, and if not specified, defaults to 0, keeping all the previously sampled spans.
 //
 //	export DD_TRACE_SAMPLING_RULES='[{"name": "web.request", "sample_rate": 1.0}]'
+//	export DD_TRACE_SAMPLING_RULES='[{"resource": "GET /health", "sample_rate": 0.01}, {"tags": {"customer.tier": "enterprise"}, "sample_rate": 1.0}]'
 //	export DD_SPAN_SAMPLING_RULES='[{"service":"test.?","name": "web.*", "sample_rate": 1.0, "max_per_second":100}]'
 //
 // To create spans, use the functions StartSpan and StartSpanFromContext. Both accept
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/dyninst"
+)
+
+// startDynamicInstrumentation starts running the dynamic instrumentation probes
+// received through remote configuration.
+func (t *tracer) startDynamicInstrumentation() {
+	t.stopDynamicInstrumentation = dyninst.Start(dyninst.Config{
+		AgentURL:   t.config.agentURL,
+		HTTPClient: t.config.httpClient,
+		Service:    t.config.serviceName,
+		Env:        t.config.env,
+		Version:    t.config.version,
+		SpanInfo:   dyninstSpanInfo,
+	})
+}
+
+// dyninstSpanInfo describes the span on which the dynamic instrumentation probes run.
+// Spans not created by this tracer are described by their context only.
+func dyninstSpanInfo(sp ddtrace.Span) dyninst.SpanInfo {
+	s, ok := sp.(*span)
+	if !ok {
+		if sp == nil {
+			return dyninst.SpanInfo{}
+		}
+		ctx := sp.Context()
+		return dyninst.SpanInfo{TraceID: ctx.TraceID(), SpanID: ctx.SpanID()}
+	}
+	s.RLock()
+	defer s.RUnlock()
+	info := dyninst.SpanInfo{
+		TraceID:   s.TraceID,
+		SpanID:    s.SpanID,
+		Operation: s.Name,
+		Service:   s.Service,
+		Resource:  s.Resource,
+		Tags:      make(map[string]interface{}, len(s.Meta)+len(s.Metrics)),
+	}
+	for k, v := range s.Meta {
+		info.Tags[k] = v
+	}
+	for k, v := range s.Metrics {
+		info.Tags[k] = v
+	}
+	return info
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/dyninst"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/remoteconfig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicInstrumentation(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		tr, _, _, stop := startTestTracer(t)
+		defer stop()
+		assert.False(t, tr.config.dynamicInstrumentation)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_DYNAMIC_INSTRUMENTATION_ENABLED", "true")
+		tr, _, _, stop := startTestTracer(t)
+		defer stop()
+		assert.True(t, tr.config.dynamicInstrumentation)
+	})
+
+	t.Run("probes", func(t *testing.T) {
+		tr, _, _, stop := startTestTracer(t, WithDynamicInstrumentation(true), WithService("web"))
+		defer stop()
+		defer globalconfig.SetServiceName("")
+		tr.startDynamicInstrumentation()
+		defer tr.stopDynamicInstrumentation()
+
+		statuses := dyninst.OnRemoteConfigUpdate(map[string]remoteconfig.ProductUpdate{
+			dyninst.ProductLiveDebugging: {
+				"start": []byte(`{
+					"id": "start",
+					"type": "SPAN_DECORATION_PROBE",
+					"where": {"point": "start_span", "operation": "http.request"},
+					"tags": [{"name": "probe.user", "value": "{user.id}"}]
+				}`),
+				"finish": []byte(`{
+					"id": "finish",
+					"type": "SPAN_DECORATION_PROBE",
+					"where": {"point": "finish_span"},
+					"tags": [{"name": "probe.duration", "value": "{duration}"}]
+				}`),
+			},
+		})
+		require.Len(t, statuses, 2)
+
+		sp := tr.StartSpan("http.request", ResourceName("GET /"), Tag("user.id", "u1")).(*span)
+		sp.Finish()
+		other := tr.StartSpan("db.query").(*span)
+		other.Finish()
+
+		assert.Equal(t, "u1", sp.Meta["probe.user"])
+		assert.NotEqual(t, "UNDEFINED", sp.Meta["probe.duration"])
+		assert.NotEmpty(t, sp.Meta["probe.duration"])
+		assert.NotContains(t, other.Meta, "probe.user")
+	})
+}
+
+func TestDyninstSpanInfo(t *testing.T) {
+	sp := newBasicSpan("op")
+	sp.Service = "svc"
+	sp.Resource = "res"
+	sp.SetTag("str", "v")
+	sp.SetTag("num", 1.5)
+
+	info := dyninstSpanInfo(sp)
+	assert.Equal(t, sp.TraceID, info.TraceID)
+	assert.Equal(t, sp.SpanID, info.SpanID)
+	assert.Equal(t, "op", info.Operation)
+	assert.Equal(t, "svc", info.Service)
+	assert.Equal(t, "res", info.Resource)
+	assert.Equal(t, "v", info.Tags["str"])
+	assert.Equal(t, 1.5, info.Tags["num"])
+}
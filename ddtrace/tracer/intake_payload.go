@@ -0,0 +1,393 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"math"
+	"runtime"
+	"sort"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/version"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the protobuf messages accepted by the Datadog intake, as defined in
+// https://github.com/DataDog/datadog-agent/tree/main/pkg/proto/datadog/trace
+const (
+	// AgentPayload
+	intakeAgentPayloadHostName       protowire.Number = 1
+	intakeAgentPayloadEnv            protowire.Number = 2
+	intakeAgentPayloadTracerPayloads protowire.Number = 5
+
+	// TracerPayload
+	intakeTracerPayloadContainerID     protowire.Number = 1
+	intakeTracerPayloadLanguageName    protowire.Number = 2
+	intakeTracerPayloadLanguageVersion protowire.Number = 3
+	intakeTracerPayloadTracerVersion   protowire.Number = 4
+	intakeTracerPayloadRuntimeID       protowire.Number = 5
+	intakeTracerPayloadChunks          protowire.Number = 6
+	intakeTracerPayloadEnv             protowire.Number = 8
+	intakeTracerPayloadHostname        protowire.Number = 9
+	intakeTracerPayloadAppVersion      protowire.Number = 10
+
+	// TraceChunk
+	intakeChunkPriority protowire.Number = 1
+	intakeChunkOrigin   protowire.Number = 2
+	intakeChunkSpans    protowire.Number = 3
+
+	// Span
+	intakeSpanService    protowire.Number = 1
+	intakeSpanName       protowire.Number = 2
+	intakeSpanResource   protowire.Number = 3
+	intakeSpanTraceID    protowire.Number = 4
+	intakeSpanSpanID     protowire.Number = 5
+	intakeSpanParentID   protowire.Number = 6
+	intakeSpanStart      protowire.Number = 7
+	intakeSpanDuration   protowire.Number = 8
+	intakeSpanError      protowire.Number = 9
+	intakeSpanMeta       protowire.Number = 10
+	intakeSpanMetrics    protowire.Number = 11
+	intakeSpanType       protowire.Number = 12
+	intakeSpanSpanLinks  protowire.Number = 14
+	intakeSpanSpanEvents protowire.Number = 15
+
+	// SpanLink
+	intakeLinkTraceID     protowire.Number = 1
+	intakeLinkTraceIDHigh protowire.Number = 2
+	intakeLinkSpanID      protowire.Number = 3
+	intakeLinkAttributes  protowire.Number = 4
+	intakeLinkTracestate  protowire.Number = 5
+	intakeLinkFlags       protowire.Number = 6
+
+	// SpanEvent
+	intakeEventTime       protowire.Number = 1
+	intakeEventName       protowire.Number = 2
+	intakeEventAttributes protowire.Number = 3
+
+	// AttributeAnyValue and AttributeArrayValue
+	intakeAttributeType   protowire.Number = 1
+	intakeAttributeString protowire.Number = 2
+	intakeAttributeBool   protowire.Number = 3
+	intakeAttributeInt    protowire.Number = 4
+	intakeAttributeDouble protowire.Number = 5
+	intakeAttributeArray  protowire.Number = 6
+
+	// AttributeArray
+	intakeAttributeArrayValues protowire.Number = 1
+
+	// map entries
+	intakeMapKey   protowire.Number = 1
+	intakeMapValue protowire.Number = 2
+
+	// StatsPayload
+	intakeStatsPayloadAgentHostname  protowire.Number = 1
+	intakeStatsPayloadAgentEnv       protowire.Number = 2
+	intakeStatsPayloadStats          protowire.Number = 3
+	intakeStatsPayloadClientComputed protowire.Number = 5
+
+	// ClientStatsPayload
+	intakeClientStatsHostname      protowire.Number = 1
+	intakeClientStatsEnv           protowire.Number = 2
+	intakeClientStatsVersion       protowire.Number = 3
+	intakeClientStatsStats         protowire.Number = 4
+	intakeClientStatsLang          protowire.Number = 5
+	intakeClientStatsTracerVersion protowire.Number = 6
+	intakeClientStatsRuntimeID     protowire.Number = 7
+
+	// ClientStatsBucket
+	intakeBucketStart    protowire.Number = 1
+	intakeBucketDuration protowire.Number = 2
+	intakeBucketStats    protowire.Number = 3
+
+	// ClientGroupedStats
+	intakeGroupedService        protowire.Number = 1
+	intakeGroupedName           protowire.Number = 2
+	intakeGroupedResource       protowire.Number = 3
+	intakeGroupedHTTPStatusCode protowire.Number = 4
+	intakeGroupedType           protowire.Number = 5
+	intakeGroupedDBType         protowire.Number = 6
+	intakeGroupedHits           protowire.Number = 7
+	intakeGroupedErrors         protowire.Number = 8
+	intakeGroupedDuration       protowire.Number = 9
+	intakeGroupedOkSummary      protowire.Number = 10
+	intakeGroupedErrorSummary   protowire.Number = 11
+	intakeGroupedSynthetics     protowire.Number = 12
+	intakeGroupedTopLevelHits   protowire.Number = 13
+)
+
+// intakeMetadata describes the application and the host in the payloads sent to the intake.
+type intakeMetadata struct {
+	hostname string
+	env      string
+	version  string // the version of the application
+}
+
+// intakePayload encodes and buffers traces in the protobuf format of the intake, as the
+// chunks of a TracerPayload. It is not safe for concurrent use.
+type intakePayload struct {
+	// chunks holds the encoded TraceChunk messages, as TracerPayload fields
+	chunks []byte
+
+	// count is the number of traces in chunks
+	count int
+}
+
+// push encodes trace as a chunk of the payload.
+func (p *intakePayload) push(trace []*span) {
+	if len(trace) == 0 {
+		return
+	}
+	var chunk []byte
+	chunk = protowire.AppendTag(chunk, intakeChunkPriority, protowire.VarintType)
+	chunk = protowire.AppendVarint(chunk, uint64(int64(chunkPriority(trace))))
+	if origin := trace[0].Meta[keyOrigin]; origin != "" {
+		chunk = protowire.AppendTag(chunk, intakeChunkOrigin, protowire.BytesType)
+		chunk = protowire.AppendString(chunk, origin)
+	}
+	for _, s := range trace {
+		chunk = protowire.AppendTag(chunk, intakeChunkSpans, protowire.BytesType)
+		chunk = protowire.AppendBytes(chunk, encodeIntakeSpan(s))
+	}
+	p.chunks = protowire.AppendTag(p.chunks, intakeTracerPayloadChunks, protowire.BytesType)
+	p.chunks = protowire.AppendBytes(p.chunks, chunk)
+	p.count++
+}
+
+// size returns the size in bytes of the encoded chunks.
+func (p *intakePayload) size() int {
+	return len(p.chunks)
+}
+
+// itemCount returns the number of traces in the payload.
+func (p *intakePayload) itemCount() int {
+	return p.count
+}
+
+// encode returns the AgentPayload message holding the buffered traces, described by meta.
+func (p *intakePayload) encode(meta intakeMetadata) []byte {
+	var tp []byte
+	tp = appendIntakeString(tp, intakeTracerPayloadContainerID, internal.ContainerID())
+	tp = appendIntakeString(tp, intakeTracerPayloadLanguageName, "go")
+	tp = appendIntakeString(tp, intakeTracerPayloadLanguageVersion, runtime.Version())
+	tp = appendIntakeString(tp, intakeTracerPayloadTracerVersion, version.Tag)
+	tp = appendIntakeString(tp, intakeTracerPayloadRuntimeID, globalconfig.RuntimeID())
+	tp = append(tp, p.chunks...)
+	tp = appendIntakeString(tp, intakeTracerPayloadEnv, meta.env)
+	tp = appendIntakeString(tp, intakeTracerPayloadHostname, meta.hostname)
+	tp = appendIntakeString(tp, intakeTracerPayloadAppVersion, meta.version)
+
+	var b []byte
+	b = appendIntakeString(b, intakeAgentPayloadHostName, meta.hostname)
+	b = appendIntakeString(b, intakeAgentPayloadEnv, meta.env)
+	b = protowire.AppendTag(b, intakeAgentPayloadTracerPayloads, protowire.BytesType)
+	return protowire.AppendBytes(b, tp)
+}
+
+// priorityNone is the priority of the chunks whose sampling decision isn't known, as
+// expected by the intake.
+const priorityNone = -128
+
+// chunkPriority returns the sampling priority of trace, or priorityNone when it has none.
+func chunkPriority(trace []*span) int {
+	if ctx := trace[0].context; ctx != nil {
+		if p, ok := ctx.samplingPriority(); ok {
+			return p
+		}
+	}
+	for _, s := range trace {
+		// the context is lost when the trace was decoded from msgpack
+		if p, ok := s.Metrics[keySamplingPriority]; ok {
+			return int(p)
+		}
+	}
+	return priorityNone
+}
+
+// encodeIntakeSpan returns the Span message describing s.
+func encodeIntakeSpan(s *span) []byte {
+	var b []byte
+	b = appendIntakeString(b, intakeSpanService, s.Service)
+	b = appendIntakeString(b, intakeSpanName, s.Name)
+	b = appendIntakeString(b, intakeSpanResource, s.Resource)
+	b = appendIntakeVarint(b, intakeSpanTraceID, s.TraceID)
+	b = appendIntakeVarint(b, intakeSpanSpanID, s.SpanID)
+	b = appendIntakeVarint(b, intakeSpanParentID, s.ParentID)
+	b = appendIntakeVarint(b, intakeSpanStart, uint64(s.Start))
+	b = appendIntakeVarint(b, intakeSpanDuration, uint64(s.Duration))
+	b = appendIntakeVarint(b, intakeSpanError, uint64(int64(s.Error)))
+	for _, k := range sortedKeys(s.Meta) {
+		var entry []byte
+		entry = appendIntakeString(entry, intakeMapKey, k)
+		entry = appendIntakeString(entry, intakeMapValue, s.Meta[k])
+		b = protowire.AppendTag(b, intakeSpanMeta, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	}
+	metrics := make([]string, 0, len(s.Metrics))
+	for k := range s.Metrics {
+		metrics = append(metrics, k)
+	}
+	sort.Strings(metrics)
+	for _, k := range metrics {
+		var entry []byte
+		entry = appendIntakeString(entry, intakeMapKey, k)
+		entry = protowire.AppendTag(entry, intakeMapValue, protowire.Fixed64Type)
+		entry = protowire.AppendFixed64(entry, math.Float64bits(s.Metrics[k]))
+		b = protowire.AppendTag(b, intakeSpanMetrics, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	}
+	b = appendIntakeString(b, intakeSpanType, s.Type)
+	for _, l := range s.SpanLinks {
+		var lb []byte
+		lb = appendIntakeVarint(lb, intakeLinkTraceID, l.TraceID)
+		lb = appendIntakeVarint(lb, intakeLinkTraceIDHigh, l.TraceIDHigh)
+		lb = appendIntakeVarint(lb, intakeLinkSpanID, l.SpanID)
+		for _, k := range sortedKeys(l.Attributes) {
+			var entry []byte
+			entry = appendIntakeString(entry, intakeMapKey, k)
+			entry = appendIntakeString(entry, intakeMapValue, l.Attributes[k])
+			lb = protowire.AppendTag(lb, intakeLinkAttributes, protowire.BytesType)
+			lb = protowire.AppendBytes(lb, entry)
+		}
+		lb = appendIntakeString(lb, intakeLinkTracestate, l.Tracestate)
+		lb = appendIntakeVarint(lb, intakeLinkFlags, uint64(l.Flags))
+		b = protowire.AppendTag(b, intakeSpanSpanLinks, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+	for _, e := range s.SpanEvents {
+		var eb []byte
+		eb = protowire.AppendTag(eb, intakeEventTime, protowire.Fixed64Type)
+		eb = protowire.AppendFixed64(eb, e.TimeUnixNano)
+		eb = appendIntakeString(eb, intakeEventName, e.Name)
+		keys := make([]string, 0, len(e.Attributes))
+		for k := range e.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var entry []byte
+			entry = appendIntakeString(entry, intakeMapKey, k)
+			entry = protowire.AppendTag(entry, intakeMapValue, protowire.BytesType)
+			entry = protowire.AppendBytes(entry, appendIntakeAttribute(nil, e.Attributes[k]))
+			eb = protowire.AppendTag(eb, intakeEventAttributes, protowire.BytesType)
+			eb = protowire.AppendBytes(eb, entry)
+		}
+		b = protowire.AppendTag(b, intakeSpanSpanEvents, protowire.BytesType)
+		b = protowire.AppendBytes(b, eb)
+	}
+	return b
+}
+
+// appendIntakeAttribute appends to b the AttributeAnyValue message holding a. The types of
+// the span event attributes match the values of the AttributeAnyValue type enum.
+func appendIntakeAttribute(b []byte, a *spanEventAttribute) []byte {
+	if a == nil {
+		return b
+	}
+	b = appendIntakeVarint(b, intakeAttributeType, uint64(a.Type))
+	switch a.Type {
+	case spanEventAttributeTypeBool:
+		b = protowire.AppendTag(b, intakeAttributeBool, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(a.BoolValue))
+	case spanEventAttributeTypeInt:
+		b = appendIntakeVarint(b, intakeAttributeInt, uint64(a.IntValue))
+	case spanEventAttributeTypeDouble:
+		b = protowire.AppendTag(b, intakeAttributeDouble, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(a.DoubleValue))
+	case spanEventAttributeTypeArray:
+		var arr []byte
+		if a.ArrayValue != nil {
+			for _, v := range a.ArrayValue.Values {
+				arr = protowire.AppendTag(arr, intakeAttributeArrayValues, protowire.BytesType)
+				arr = protowire.AppendBytes(arr, appendIntakeAttribute(nil, v))
+			}
+		}
+		b = protowire.AppendTag(b, intakeAttributeArray, protowire.BytesType)
+		b = protowire.AppendBytes(b, arr)
+	default:
+		b = appendIntakeString(b, intakeAttributeString, a.StringValue)
+	}
+	return b
+}
+
+// encodeIntakeStats returns the StatsPayload message holding the client-computed stats s.
+func encodeIntakeStats(s *statsPayload) []byte {
+	var cs []byte
+	cs = appendIntakeString(cs, intakeClientStatsHostname, s.Hostname)
+	cs = appendIntakeString(cs, intakeClientStatsEnv, s.Env)
+	cs = appendIntakeString(cs, intakeClientStatsVersion, s.Version)
+	for _, bucket := range s.Stats {
+		var bb []byte
+		bb = appendIntakeVarint(bb, intakeBucketStart, bucket.Start)
+		bb = appendIntakeVarint(bb, intakeBucketDuration, bucket.Duration)
+		for _, gs := range bucket.Stats {
+			bb = protowire.AppendTag(bb, intakeBucketStats, protowire.BytesType)
+			bb = protowire.AppendBytes(bb, encodeIntakeGroupedStats(&gs))
+		}
+		cs = protowire.AppendTag(cs, intakeClientStatsStats, protowire.BytesType)
+		cs = protowire.AppendBytes(cs, bb)
+	}
+	cs = appendIntakeString(cs, intakeClientStatsLang, "go")
+	cs = appendIntakeString(cs, intakeClientStatsTracerVersion, version.Tag)
+	cs = appendIntakeString(cs, intakeClientStatsRuntimeID, globalconfig.RuntimeID())
+
+	var b []byte
+	b = appendIntakeString(b, intakeStatsPayloadAgentHostname, s.Hostname)
+	b = appendIntakeString(b, intakeStatsPayloadAgentEnv, s.Env)
+	b = protowire.AppendTag(b, intakeStatsPayloadStats, protowire.BytesType)
+	b = protowire.AppendBytes(b, cs)
+	b = protowire.AppendTag(b, intakeStatsPayloadClientComputed, protowire.VarintType)
+	return protowire.AppendVarint(b, protowire.EncodeBool(true))
+}
+
+// encodeIntakeGroupedStats returns the ClientGroupedStats message describing gs.
+func encodeIntakeGroupedStats(gs *groupedStats) []byte {
+	var b []byte
+	b = appendIntakeString(b, intakeGroupedService, gs.Service)
+	b = appendIntakeString(b, intakeGroupedName, gs.Name)
+	b = appendIntakeString(b, intakeGroupedResource, gs.Resource)
+	b = appendIntakeVarint(b, intakeGroupedHTTPStatusCode, uint64(gs.HTTPStatusCode))
+	b = appendIntakeString(b, intakeGroupedType, gs.Type)
+	b = appendIntakeString(b, intakeGroupedDBType, gs.DBType)
+	b = appendIntakeVarint(b, intakeGroupedHits, gs.Hits)
+	b = appendIntakeVarint(b, intakeGroupedErrors, gs.Errors)
+	b = appendIntakeVarint(b, intakeGroupedDuration, gs.Duration)
+	if len(gs.OkSummary) > 0 {
+		b = protowire.AppendTag(b, intakeGroupedOkSummary, protowire.BytesType)
+		b = protowire.AppendBytes(b, gs.OkSummary)
+	}
+	if len(gs.ErrorSummary) > 0 {
+		b = protowire.AppendTag(b, intakeGroupedErrorSummary, protowire.BytesType)
+		b = protowire.AppendBytes(b, gs.ErrorSummary)
+	}
+	if gs.Synthetics {
+		b = protowire.AppendTag(b, intakeGroupedSynthetics, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(true))
+	}
+	return appendIntakeVarint(b, intakeGroupedTopLevelHits, gs.TopLevelHits)
+}
+
+// appendIntakeString appends the string field num holding v to b, unless v is empty, which
+// is the default value of protobuf strings.
+func appendIntakeString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+// appendIntakeVarint appends the varint field num holding v to b, unless v is zero, which
+// is the default value of protobuf integers.
+func appendIntakeVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
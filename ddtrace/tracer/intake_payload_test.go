@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/version"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestIntakePayload(t *testing.T) {
+	root := newSpan("web.request", "web", "GET /users", 10, 20, 0)
+	root.setMeta(keyOrigin, "synthetics")
+	root.setMetric("requests", 2)
+	root.context.trace.setSamplingPriority(ext.PriorityUserKeep, samplernames.Manual)
+	root.SpanEvents = []spanEvent{{
+		Name:         "exception",
+		TimeUnixNano: 42,
+		Attributes:   map[string]*spanEventAttribute{"count": newSpanEventAttribute(3)},
+	}}
+	child := newSpan("db.query", "db", "SELECT 1", 11, root.TraceID, root.SpanID)
+	child.context = newSpanContext(child, root.context)
+
+	var p intakePayload
+	p.push([]*span{root, child})
+	p.push([]*span{newBasicSpan("orphan")})
+	assert.Equal(t, 2, p.itemCount())
+	b := p.encode(intakeMetadata{hostname: "host", env: "prod", version: "1.2.3"})
+
+	var (
+		hostname, env string
+		tracer        = map[protowire.Number]string{}
+		priorities    []int64
+		origins       []string
+	)
+	forEachField(t, b, func(num protowire.Number, v []byte) {
+		switch num {
+		case intakeAgentPayloadHostName:
+			hostname = string(v)
+		case intakeAgentPayloadEnv:
+			env = string(v)
+		case intakeAgentPayloadTracerPayloads:
+			forEachField(t, v, func(num protowire.Number, v []byte) {
+				if num != intakeTracerPayloadChunks {
+					tracer[num] = string(v)
+					return
+				}
+				var origin string
+				forEachField(t, v, func(num protowire.Number, v []byte) {
+					switch num {
+					case intakeChunkPriority:
+						priorities = append(priorities, int64(varint(v)))
+					case intakeChunkOrigin:
+						origin = string(v)
+					}
+				})
+				origins = append(origins, origin)
+			})
+		}
+	})
+	assert.Equal(t, "host", hostname)
+	assert.Equal(t, "prod", env)
+	assert.Equal(t, "go", tracer[intakeTracerPayloadLanguageName])
+	assert.Equal(t, version.Tag, tracer[intakeTracerPayloadTracerVersion])
+	assert.Equal(t, "prod", tracer[intakeTracerPayloadEnv])
+	assert.Equal(t, "host", tracer[intakeTracerPayloadHostname])
+	assert.Equal(t, "1.2.3", tracer[intakeTracerPayloadAppVersion])
+	assert.Equal(t, []int64{ext.PriorityUserKeep, priorityNone}, priorities)
+	assert.Equal(t, []string{"synthetics", ""}, origins)
+
+	traces := decodeIntakeTraces(t, b)
+	require.Len(t, traces, 2)
+	require.Len(t, traces[0], 2)
+	s := traces[0][0]
+	assert.Equal(t, "web.request", s.Name)
+	assert.Equal(t, "web", s.Service)
+	assert.Equal(t, "GET /users", s.Resource)
+	assert.EqualValues(t, 10, s.SpanID)
+	assert.EqualValues(t, 20, s.TraceID)
+	assert.Equal(t, "synthetics", s.Meta[keyOrigin])
+	assert.Equal(t, 2.0, s.Metrics["requests"])
+	require.Len(t, s.SpanEvents, 1)
+	assert.Equal(t, "exception", s.SpanEvents[0].Name)
+	assert.EqualValues(t, root.SpanID, traces[0][1].ParentID)
+}
+
+func TestEncodeIntakeStats(t *testing.T) {
+	b := encodeIntakeStats(&statsPayload{
+		Hostname: "host",
+		Env:      "prod",
+		Stats: []statsBucket{{
+			Start: 1,
+			Stats: []groupedStats{{Name: "web.request", Hits: 3}},
+		}},
+	})
+	sp := decodeIntakeStats(t, b)
+	assert.Equal(t, "prod", sp.Env)
+	require.Len(t, sp.Stats, 1)
+	assert.EqualValues(t, 1, sp.Stats[0].Start)
+	require.Len(t, sp.Stats[0].Stats, 1)
+	assert.Equal(t, "web.request", sp.Stats[0].Stats[0].Name)
+	assert.EqualValues(t, 3, sp.Stats[0].Stats[0].Hits)
+
+	var clientComputed bool
+	forEachField(t, b, func(num protowire.Number, v []byte) {
+		if num == intakeStatsPayloadClientComputed {
+			clientComputed = protowire.DecodeBool(varint(v))
+		}
+	})
+	assert.True(t, clientComputed)
+}
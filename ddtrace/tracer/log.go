@@ -90,7 +90,7 @@ func logStartup(t *tracer) {
 		AgentURL:                    t.config.transport.endpoint(),
 		Debug:                       t.config.debug,
 		AnalyticsEnabled:            !math.IsNaN(globalconfig.AnalyticsRate()),
-		SampleRate:                  fmt.Sprintf("%f", t.rulesSampling.traces.globalRate),
+		SampleRate:                  fmt.Sprintf("%f", t.rulesSampling.traces.rate()),
 		SampleRateLimit:             "disabled",
 		SamplingRules:               append(t.config.traceRules, t.config.spanRules...),
 		ServiceMappings:             t.config.serviceMappings,
@@ -112,7 +112,7 @@ func logStartup(t *tracer) {
 	if limit, ok := t.rulesSampling.TraceRateLimit(); ok {
 		info.SampleRateLimit = fmt.Sprintf("%v", limit)
 	}
-	if !t.config.logToStdout {
+	if !t.config.logToStdout && !t.config.agentless && !t.config.otlpExport {
 		if err := checkEndpoint(t.config.httpClient, t.config.transport.endpoint()); err != nil {
 			info.AgentError = fmt.Sprintf("%s", err)
 			log.Warn("DIAGNOSTICS Unable to reach agent intake: %s", err)
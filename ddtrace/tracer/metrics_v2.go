@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"math"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+)
+
+const (
+	// runtimeMetricsV2Prefix prefixes the names of the metrics reported by the runtime metrics v2
+	// collector.
+	runtimeMetricsV2Prefix = "runtime.go.metrics."
+
+	// maxHistogramSamples limits the number of distribution samples reported per histogram and
+	// interval. Above it, the bucket counts are scaled down, which preserves the percentiles.
+	maxHistogramSamples = 1000
+)
+
+// runtimeMetricsV2 reports all the metrics supported by the runtime/metrics package, which,
+// unlike runtime.ReadMemStats, doesn't stop the world. Scalar metrics are reported as gauges
+// and histograms, such as the GC pauses and the scheduler latencies, as distributions of the
+// values observed during each interval.
+//
+// The metric names are derived from the runtime/metrics names: "/gc/heap/allocs:bytes" is
+// reported as "runtime.go.metrics.gc_heap_allocs.bytes".
+type runtimeMetricsV2 struct {
+	statsd  statsdClient
+	names   []string         // statsd names, by sample
+	samples []metrics.Sample // samples read at every interval
+	counts  [][]uint64       // histogram counts at the previous interval, by sample
+}
+
+func newRuntimeMetricsV2(statsd statsdClient) *runtimeMetricsV2 {
+	r := &runtimeMetricsV2{statsd: statsd}
+	for _, d := range metrics.All() {
+		switch d.Kind {
+		case metrics.KindUint64, metrics.KindFloat64, metrics.KindFloat64Histogram:
+		default:
+			// metrics of unknown kinds, added by newer Go versions, are skipped.
+			continue
+		}
+		r.names = append(r.names, runtimeMetricV2Name(d.Name))
+		r.samples = append(r.samples, metrics.Sample{Name: d.Name})
+	}
+	r.counts = make([][]uint64, len(r.samples))
+	// read the initial histograms, so that the first report only covers its interval.
+	metrics.Read(r.samples)
+	for i, s := range r.samples {
+		if s.Value.Kind() == metrics.KindFloat64Histogram {
+			r.counts[i] = append([]uint64(nil), s.Value.Float64Histogram().Counts...)
+		}
+	}
+	return r
+}
+
+// runtimeMetricV2Name returns the statsd name of the runtime/metrics metric name.
+func runtimeMetricV2Name(name string) string {
+	path, unit, _ := strings.Cut(strings.TrimPrefix(name, "/"), ":")
+	return runtimeMetricsV2Prefix + sanitizeMetricName(path) + "." + sanitizeMetricName(unit)
+}
+
+// sanitizeMetricName replaces all the characters of s other than lowercase letters, digits
+// and underscores with underscores.
+func sanitizeMetricName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(s))
+}
+
+// report reads and reports all the metrics.
+func (r *runtimeMetricsV2) report() {
+	metrics.Read(r.samples)
+	for i, s := range r.samples {
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			r.statsd.Gauge(r.names[i], float64(s.Value.Uint64()), nil, 1)
+		case metrics.KindFloat64:
+			r.statsd.Gauge(r.names[i], s.Value.Float64(), nil, 1)
+		case metrics.KindFloat64Histogram:
+			r.reportHistogram(i, s.Value.Float64Histogram())
+		}
+	}
+}
+
+// reportHistogram reports the values observed in the histogram h of sample i since the
+// previous interval, as distribution samples. The values of a bucket are reported as its
+// midpoint, or its finite bound for the unbounded buckets.
+func (r *runtimeMetricsV2) reportHistogram(i int, h *metrics.Float64Histogram) {
+	prev := r.counts[i]
+	if len(prev) != len(h.Counts) {
+		prev = make([]uint64, len(h.Counts))
+	}
+	var total uint64
+	for j, c := range h.Counts {
+		total += c - prev[j]
+	}
+	defer func() { r.counts[i] = append(prev[:0], h.Counts...) }()
+	if total == 0 {
+		return
+	}
+	scale := 1.0
+	if total > maxHistogramSamples {
+		scale = float64(maxHistogramSamples) / float64(total)
+	}
+	for j, c := range h.Counts {
+		delta := c - prev[j]
+		if delta == 0 {
+			continue
+		}
+		v := bucketValue(h.Buckets[j], h.Buckets[j+1])
+		// buckets with few values keep at least one sample.
+		n := int(math.Ceil(float64(delta) * scale))
+		for k := 0; k < n; k++ {
+			r.statsd.Distribution(r.names[i], v, nil, 1)
+		}
+	}
+}
+
+// bucketValue returns the value representing the histogram bucket [lo, hi).
+func bucketValue(lo, hi float64) float64 {
+	switch {
+	case math.IsInf(lo, -1):
+		return hi
+	case math.IsInf(hi, 1):
+		return lo
+	default:
+		return lo + (hi-lo)/2
+	}
+}
+
+// reportRuntimeMetricsV2 periodically reports the runtime/metrics metrics at the given interval.
+func (t *tracer) reportRuntimeMetricsV2(interval time.Duration) {
+	r := newRuntimeMetricsV2(t.statsd)
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			log.Debug("Reporting runtime metrics v2...")
+			r.report()
+		case <-t.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeMetricV2Name(t *testing.T) {
+	for in, out := range map[string]string{
+		"/gc/heap/allocs:bytes":                            "runtime.go.metrics.gc_heap_allocs.bytes",
+		"/gc/heap/allocs-by-size:bytes":                    "runtime.go.metrics.gc_heap_allocs_by_size.bytes",
+		"/sched/gomaxprocs:threads":                        "runtime.go.metrics.sched_gomaxprocs.threads",
+		"/cpu/classes/gc/total:cpu-seconds":                "runtime.go.metrics.cpu_classes_gc_total.cpu_seconds",
+		"/godebug/non-default-behavior/http2client:events": "runtime.go.metrics.godebug_non_default_behavior_http2client.events",
+	} {
+		assert.Equal(t, out, runtimeMetricV2Name(in))
+	}
+}
+
+func TestRuntimeMetricsV2Histogram(t *testing.T) {
+	var tg testStatsdClient
+	r := &runtimeMetricsV2{
+		statsd: &tg,
+		names:  []string{"runtime.go.metrics.test.seconds"},
+		counts: make([][]uint64, 1),
+	}
+	h := &metrics.Float64Histogram{
+		Buckets: []float64{math.Inf(-1), 1, 2, 4, math.Inf(1)},
+		Counts:  []uint64{1, 2, 0, 1},
+	}
+	r.reportHistogram(0, h)
+	var values []float64
+	for _, c := range tg.DistributionCalls() {
+		assert.Equal(t, "runtime.go.metrics.test.seconds", c.name)
+		values = append(values, c.floatVal)
+	}
+	assert.Equal(t, []float64{1, 1.5, 1.5, 4}, values)
+
+	// only the values observed since the previous report are reported.
+	tg.Reset()
+	h.Counts = []uint64{1, 2, 1, 1}
+	r.reportHistogram(0, h)
+	require.Len(t, tg.DistributionCalls(), 1)
+	assert.Equal(t, 3.0, tg.DistributionCalls()[0].floatVal)
+
+	tg.Reset()
+	r.reportHistogram(0, h)
+	assert.Empty(t, tg.DistributionCalls())
+
+	// the samples are limited, keeping at least one sample per bucket.
+	tg.Reset()
+	h.Counts = []uint64{1, 2 + 10*maxHistogramSamples, 2, 1}
+	r.reportHistogram(0, h)
+	calls := tg.CallsByName()["runtime.go.metrics.test.seconds"]
+	assert.InDelta(t, maxHistogramSamples, calls, 2)
+	assert.Equal(t, 3.0, tg.DistributionCalls()[calls-1].floatVal)
+}
+
+func TestReportRuntimeMetricsV2(t *testing.T) {
+	var tg testStatsdClient
+	trc := newUnstartedTracer(withStatsdClient(&tg))
+	defer trc.statsd.Close()
+
+	trc.wg.Add(1)
+	go func() {
+		defer trc.wg.Done()
+		trc.reportRuntimeMetricsV2(time.Millisecond)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for len(tg.DistributionCalls()) == 0 && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	close(trc.stop)
+	trc.wg.Wait()
+
+	calls := tg.CallNames()
+	assert.Contains(t, calls, "runtime.go.metrics.gc_heap_allocs.bytes")
+	assert.Contains(t, calls, "runtime.go.metrics.sched_goroutines.goroutines")
+	assert.NotEmpty(t, tg.DistributionCalls())
+	for _, c := range tg.DistributionCalls() {
+		assert.Regexp(t, `^runtime\.go\.metrics\.[a-z0-9_]+\.[a-z0-9_]+$`, c.name)
+	}
+}
+
+func TestRuntimeMetricsV2Config(t *testing.T) {
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_RUNTIME_METRICS_V2_ENABLED", "true")
+		c := newConfig()
+		assert.True(t, c.runtimeMetricsV2)
+		assert.False(t, c.runtimeMetrics)
+	})
+
+	t.Run("option", func(t *testing.T) {
+		c := newConfig(WithRuntimeMetricsV2())
+		assert.True(t, c.runtimeMetricsV2)
+		assert.True(t, c.runtimeMetrics)
+	})
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/namingschema"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/normalizer"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/traceprof"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/version"
 
@@ -91,6 +92,10 @@ type config struct {
 	// serviceMappings holds a set of service mappings to dynamically rename services
 	serviceMappings map[string]string
 
+	// logsInjection specifies whether the log correlation integrations add the trace and
+	// span details to the log records. Value from DD_LOGS_INJECTION, default true.
+	logsInjection bool
+
 	// globalTags holds a set of tags that will be automatically applied to
 	// all spans.
 	globalTags map[string]interface{}
@@ -115,6 +120,11 @@ type config struct {
 	// runtimeMetrics specifies whether collection of runtime metrics is enabled.
 	runtimeMetrics bool
 
+	// runtimeMetricsV2 specifies whether the runtime metrics are collected with the runtime/metrics
+	// package instead of runtime.ReadMemStats. Value from WithRuntimeMetricsV2 or
+	// DD_RUNTIME_METRICS_V2_ENABLED, default false.
+	runtimeMetricsV2 bool
+
 	// dogstatsdAddr specifies the address to connect for sending metrics to the
 	// Datadog Agent. If not set, it defaults to "localhost:8125" or to the
 	// combination of the environment variables DD_AGENT_HOST and DD_DOGSTATSD_PORT.
@@ -154,6 +164,82 @@ type config struct {
 
 	// spanAttributeSchemaVersion holds the selected DD_TRACE_SPAN_ATTRIBUTE_SCHEMA version.
 	spanAttributeSchemaVersion int
+
+	// partialFlushMinSpans is the number of finished spans in a single trace to trigger a
+	// partial flush. Value from DD_TRACE_PARTIAL_FLUSH_MIN_SPANS, default 1000.
+	partialFlushMinSpans int
+
+	// partialFlushEnabled specifies whether the tracer should enable partial flushing. Value
+	// from DD_TRACE_PARTIAL_FLUSH_ENABLED, default false.
+	partialFlushEnabled bool
+
+	// headerAsTags holds the "header[:tag]" mappings of the headers to be reported as span
+	// tags by HTTP and gRPC integrations. Value from DD_TRACE_HEADER_TAGS or WithHeaderTags.
+	headerAsTags []string
+
+	// agentless specifies whether traces and client-computed stats are sent straight to the
+	// Datadog intake instead of the agent. Value from DD_TRACE_AGENTLESS or WithAgentlessMode.
+	agentless bool
+
+	// site specifies the Datadog site (datadoghq.com, datadoghq.eu, etc.) of the intake used
+	// in agentless mode. Value from DD_SITE or WithSite, default datadoghq.com.
+	site string
+
+	// apiKey is the Datadog API key authenticating the payloads sent in agentless mode.
+	// Value from DD_API_KEY.
+	apiKey string
+
+	// intakeURL is the URL of the intake receiving traces in agentless mode. It is derived
+	// from site, unless replaced in tests.
+	intakeURL string
+
+	// spoolDir is the directory in which the trace payloads which could not be sent to the
+	// agent are stored, to be sent once it becomes reachable again. Spooling is disabled
+	// when empty. Value from DD_TRACE_SPOOL_DIR or WithTraceSpool.
+	spoolDir string
+
+	// spoolMaxSize is the maximum total size in bytes of the spooled payloads. Value from
+	// DD_TRACE_SPOOL_MAX_SIZE or WithTraceSpool, default 64MiB.
+	spoolMaxSize int64
+
+	// spoolMaxAge is the age after which spooled payloads are evicted. Value from
+	// DD_TRACE_SPOOL_MAX_AGE or WithTraceSpool, default 1h.
+	spoolMaxAge time.Duration
+
+	// otlpExport specifies whether traces are exported over OTLP/HTTP to an OpenTelemetry
+	// collector instead of the agent. Value from DD_TRACE_OTLP_EXPORT_ENABLED or WithOTLPExporter.
+	otlpExport bool
+
+	// otlpEndpoint is the URL of the OTLP/HTTP traces endpoint used when otlpExport is set.
+	// Value from WithOTLPExporter, OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT,
+	// default http://localhost:4318/v1/traces.
+	otlpEndpoint string
+
+	// otlpHeaders holds the headers sent with the OTLP export requests. Value from
+	// OTEL_EXPORTER_OTLP_TRACES_HEADERS or OTEL_EXPORTER_OTLP_HEADERS.
+	otlpHeaders map[string]string
+
+	// tailSampling holds the configuration of tail-based sampling, which is disabled when nil.
+	// Value from WithTailSampling.
+	tailSampling *TailSamplingConfig
+
+	// rootSampling configures the upgrade of sampling decisions to keep when root spans finish.
+	// Value from WithRootSampling, DD_TRACE_ROOT_SAMPLING_KEEP_ERRORS and
+	// DD_TRACE_ROOT_SAMPLING_LATENCY_THRESHOLD.
+	rootSampling RootSamplingConfig
+
+	// adaptiveSamplingTPS is the number of traces per second targeted by the adaptive sampler,
+	// which is disabled when zero. Value from WithAdaptiveSampling or DD_TRACE_SAMPLING_TARGET_TPS.
+	adaptiveSamplingTPS float64
+
+	// dynamicInstrumentation specifies whether probes received through remote configuration
+	// run in the tracer and the integrations. Value from WithDynamicInstrumentation or
+	// DD_DYNAMIC_INSTRUMENTATION_ENABLED, default false.
+	dynamicInstrumentation bool
+
+	// dataStreamsMonitoringEnabled specifies whether the tracer should enable Data Streams Monitoring,
+	// which computes end-to-end latencies of the pipelines the service is part of.
+	dataStreamsMonitoringEnabled bool
 }
 
 // HasFeature reports whether feature f is enabled.
@@ -165,6 +251,9 @@ func (c *config) HasFeature(f string) bool {
 // StartOption represents a function that can be provided as a parameter to Start.
 type StartOption func(*config)
 
+// partialFlushMinSpansDefault is the default number of spans for partial flushing, if enabled.
+const partialFlushMinSpansDefault = 1000
+
 // maxPropagatedTagsLength limits the size of DD_TRACE_X_DATADOG_TAGS_MAX_LENGTH to prevent HTTP 413 responses.
 const maxPropagatedTagsLength = 512
 
@@ -202,9 +291,13 @@ func newConfig(opts ...StartOption) *config {
 	if ver := os.Getenv("DD_VERSION"); ver != "" {
 		c.version = ver
 	}
+	c.logsInjection = internal.BoolEnv("DD_LOGS_INJECTION", true)
 	if v := os.Getenv("DD_SERVICE_MAPPING"); v != "" {
 		internal.ForEachStringTag(v, func(key, val string) { WithServiceMapping(key, val)(c) })
 	}
+	if v := os.Getenv("DD_TRACE_HEADER_TAGS"); v != "" {
+		c.headerAsTags = strings.Split(v, ",")
+	}
 	if v := os.Getenv("DD_TAGS"); v != "" {
 		tags := internal.ParseTagString(v)
 		internal.CleanGitMetadataTags(tags)
@@ -219,12 +312,47 @@ func newConfig(opts ...StartOption) *config {
 	}
 	c.logStartup = internal.BoolEnv("DD_TRACE_STARTUP_LOGS", true)
 	c.runtimeMetrics = internal.BoolEnv("DD_RUNTIME_METRICS_ENABLED", false)
+	c.runtimeMetricsV2 = internal.BoolEnv("DD_RUNTIME_METRICS_V2_ENABLED", false)
 	c.debug = internal.BoolEnv("DD_TRACE_DEBUG", false)
 	c.enabled = internal.BoolEnv("DD_TRACE_ENABLED", true)
 	c.profilerEndpoints = internal.BoolEnv(traceprof.EndpointEnvVar, true)
 	c.profilerHotspots = internal.BoolEnv(traceprof.CodeHotspotsEnvVar, true)
+	c.dynamicInstrumentation = internal.BoolEnv("DD_DYNAMIC_INSTRUMENTATION_ENABLED", false)
+	c.rootSampling.KeepErrors = internal.BoolEnv("DD_TRACE_ROOT_SAMPLING_KEEP_ERRORS", false)
+	c.rootSampling.LatencyThreshold = internal.DurationEnv("DD_TRACE_ROOT_SAMPLING_LATENCY_THRESHOLD", 0)
+	if v := os.Getenv("DD_TRACE_SAMPLING_TARGET_TPS"); v != "" {
+		if tps, err := strconv.ParseFloat(v, 64); err != nil || tps < 0 {
+			log.Warn("ignoring DD_TRACE_SAMPLING_TARGET_TPS: invalid value %q", v)
+		} else {
+			c.adaptiveSamplingTPS = tps
+		}
+	}
 	c.enableHostnameDetection = internal.BoolEnv("DD_CLIENT_HOSTNAME_ENABLED", true)
 
+	c.dataStreamsMonitoringEnabled = internal.BoolEnv("DD_DATA_STREAMS_ENABLED", false)
+	c.agentless = internal.BoolEnv("DD_TRACE_AGENTLESS", false)
+	c.otlpExport = internal.BoolEnv("DD_TRACE_OTLP_EXPORT_ENABLED", false)
+	c.otlpHeaders = otlpHeadersFromEnv()
+	c.spoolDir = os.Getenv("DD_TRACE_SPOOL_DIR")
+	c.spoolMaxSize = int64(internal.IntEnv("DD_TRACE_SPOOL_MAX_SIZE", defaultSpoolMaxSize))
+	c.spoolMaxAge = internal.DurationEnv("DD_TRACE_SPOOL_MAX_AGE", defaultSpoolMaxAge)
+	c.apiKey = os.Getenv("DD_API_KEY")
+	c.site = defaultSite
+	if v := os.Getenv("DD_SITE"); v != "" {
+		c.site = v
+	}
+	c.partialFlushEnabled = internal.BoolEnv("DD_TRACE_PARTIAL_FLUSH_ENABLED", false)
+	c.partialFlushMinSpans = internal.IntEnv("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS", partialFlushMinSpansDefault)
+	if c.partialFlushEnabled {
+		if c.partialFlushMinSpans <= 0 {
+			log.Warn("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS=%d is not a valid value, setting to default %d", c.partialFlushMinSpans, partialFlushMinSpansDefault)
+			c.partialFlushMinSpans = partialFlushMinSpansDefault
+		} else if c.partialFlushMinSpans >= traceMaxSize {
+			log.Warn("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS=%d is above the max number of spans that can be kept in memory for a single trace (%d spans), so partial flushing will never trigger, setting to default %d", c.partialFlushMinSpans, traceMaxSize, partialFlushMinSpansDefault)
+			c.partialFlushMinSpans = partialFlushMinSpansDefault
+		}
+	}
+
 	schemaVersionStr := os.Getenv("DD_TRACE_SPAN_ATTRIBUTE_SCHEMA")
 	if v, ok := namingschema.ParseVersion(schemaVersionStr); ok {
 		namingschema.SetVersion(v)
@@ -238,6 +366,16 @@ func newConfig(opts ...StartOption) *config {
 	for _, fn := range opts {
 		fn(c)
 	}
+	globalconfig.SetHeaderTags(normalizer.HeaderTags(c.headerAsTags))
+	globalconfig.SetLogsInjection(c.logsInjection)
+	if c.otlpExport && c.otlpEndpoint == "" {
+		c.otlpEndpoint = otlpEndpointFromEnv()
+	}
+	if c.agentless && c.apiKey == "" {
+		log.Warn("Agentless mode requires an API key to be set with DD_API_KEY; traces will be sent to the agent instead.")
+		c.agentless = false
+	}
+	customClient := c.httpClient != nil
 	if c.agentURL == nil {
 		c.agentURL = resolveAgentAddr()
 		if url := internal.AgentURLFromEnv(); url != nil {
@@ -256,6 +394,9 @@ func newConfig(opts ...StartOption) *config {
 	} else if c.httpClient == nil {
 		c.httpClient = defaultClient
 	}
+	if c.agentless && !customClient {
+		c.httpClient = agentlessClient
+	}
 	WithGlobalTag(ext.RuntimeID, globalconfig.RuntimeID())(c)
 	if c.env == "" {
 		if v, ok := c.globalTags["env"]; ok {
@@ -282,7 +423,11 @@ func newConfig(opts ...StartOption) *config {
 		}
 	}
 	if c.transport == nil {
-		c.transport = newHTTPTransport(c.agentURL.String(), c.httpClient)
+		if c.agentless {
+			c.transport = agentlessTransportForConfig(c)
+		} else {
+			c.transport = newHTTPTransport(c.agentURL.String(), c.httpClient)
+		}
 	}
 	if c.propagator == nil {
 		envKey := "DD_TRACE_X_DATADOG_TAGS_MAX_LENGTH"
@@ -402,6 +547,10 @@ type agentFeatures struct {
 	// the /v0.6/stats endpoint.
 	Stats bool
 
+	// DataStreams reports whether the agent can receive data streams stats on
+	// the /v0.1/pipeline_stats endpoint.
+	DataStreams bool
+
 	// StatsdPort specifies the Dogstatsd port as provided by the agent.
 	// If it's the default, it will be 0, which means 8125.
 	StatsdPort int
@@ -420,7 +569,7 @@ func (a *agentFeatures) HasFlag(feat string) bool {
 // the tracer's behaviour.
 func (c *config) loadAgentFeatures() {
 	c.agent = agentFeatures{}
-	if c.logToStdout {
+	if c.logToStdout || c.agentless {
 		// there is no agent; all features off
 		return
 	}
@@ -451,6 +600,8 @@ func (c *config) loadAgentFeatures() {
 		switch endpoint {
 		case "/v0.6/stats":
 			c.agent.Stats = true
+		case "/v0.1/pipeline_stats":
+			c.agent.DataStreams = true
 		}
 	}
 	c.agent.featureFlags = make(map[string]struct{}, len(info.FeatureFlags))
@@ -460,11 +611,20 @@ func (c *config) loadAgentFeatures() {
 }
 
 func (c *config) canComputeStats() bool {
+	if c.otlpExport {
+		// stats can't be exported over OTLP
+		return false
+	}
+	if c.agentless {
+		// there is no agent to compute stats, the intake relies on the ones computed
+		// by the tracer
+		return true
+	}
 	return c.agent.Stats && c.HasFeature("discovery")
 }
 
 func (c *config) canDropP0s() bool {
-	return c.canComputeStats() && c.agent.DropP0s
+	return c.canComputeStats() && (c.agent.DropP0s || c.agentless)
 }
 
 func statsTags(c *config) []string {
@@ -555,6 +715,40 @@ func WithLambdaMode(enabled bool) StartOption {
 	}
 }
 
+// WithAgentlessMode enables or disables agentless mode, in which traces and client-computed
+// stats are sent straight to the Datadog intake of the configured site instead of the agent.
+// It requires an API key to be set using the DD_API_KEY environment variable. Payloads are
+// encoded in the protobuf format of the intake and compressed, and transient failures are
+// retried with an exponential backoff. Agentless mode may also be enabled using the
+// DD_TRACE_AGENTLESS environment variable.
+func WithAgentlessMode(enabled bool) StartOption {
+	return func(c *config) {
+		c.agentless = enabled
+	}
+}
+
+// WithOTLPExporter enables exporting traces over OTLP/HTTP, in protobuf format, to the
+// OpenTelemetry collector listening at the given traces endpoint, instead of sending them to
+// the agent. When endpoint is empty, the OTEL_EXPORTER_OTLP_TRACES_ENDPOINT and
+// OTEL_EXPORTER_OTLP_ENDPOINT environment variables are used, defaulting to
+// http://localhost:4318/v1/traces. Only sampled traces are exported, and no trace stats
+// are computed. OTLP export may also be enabled using the DD_TRACE_OTLP_EXPORT_ENABLED
+// environment variable.
+func WithOTLPExporter(endpoint string) StartOption {
+	return func(c *config) {
+		c.otlpExport = true
+		c.otlpEndpoint = endpoint
+	}
+}
+
+// WithSite specifies the Datadog site (datadoghq.com, datadoghq.eu, etc.) to which traces
+// are sent in agentless mode. It takes precedence over the DD_SITE environment variable.
+func WithSite(site string) StartOption {
+	return func(c *config) {
+		c.site = site
+	}
+}
+
 // WithSendRetries enables re-sending payloads that are not successfully
 // submitted to the agent.  This will cause the tracer to retry the send at
 // most `retries` times.
@@ -564,6 +758,85 @@ func WithSendRetries(retries int) StartOption {
 	}
 }
 
+// WithTraceSpool enables storing on disk, in dir, the trace payloads which could not be sent
+// to the agent after all retries, such as during an agent restart. Spooled payloads are sent
+// oldest first once the agent becomes reachable again, including after a restart of the
+// application, alongside the newer payloads, which are not held back while they are replayed.
+// The spool holds at most maxSize bytes, evicting the oldest payloads first, and payloads older
+// than maxAge are evicted. Zero values use the defaults of 64MiB and one hour. The spool may
+// also be configured with the DD_TRACE_SPOOL_DIR, DD_TRACE_SPOOL_MAX_SIZE and
+// DD_TRACE_SPOOL_MAX_AGE environment variables.
+func WithTraceSpool(dir string, maxSize int64, maxAge time.Duration) StartOption {
+	return func(c *config) {
+		c.spoolDir = dir
+		if maxSize > 0 {
+			c.spoolMaxSize = maxSize
+		}
+		if maxAge > 0 {
+			c.spoolMaxAge = maxAge
+		}
+	}
+}
+
+// WithTailSampling enables tail-based sampling: once a trace completes, it is kept if any of
+// its spans matches any of the rules in cfg, even if it was dropped by the head-based samplers.
+// Since the decision is taken after the trace context has been propagated, it doesn't affect
+// the traces of downstream services. Kept traces are limited to cfg.MaxPerSecond per second.
+// The chunks of partially flushed traces are held in memory until their trace completes,
+// within the limits of cfg.MaxBufferedSpans and cfg.MaxBufferTime. Flushing the tracer doesn't
+// release them, and they are sent with their head-based decision, which drops them, when the
+// tracer stops before they complete.
+func WithTailSampling(cfg TailSamplingConfig) StartOption {
+	return func(c *config) {
+		c.tailSampling = &cfg
+	}
+}
+
+// RootSamplingConfig configures the re-evaluation of the sampling decision of a trace when its
+// local root span finishes. See WithRootSampling.
+type RootSamplingConfig struct {
+	// KeepErrors keeps the traces whose root span finishes with an error.
+	KeepErrors bool
+
+	// LatencyThreshold keeps the traces whose root span lasts at least LatencyThreshold.
+	// It is disabled when zero.
+	LatencyThreshold time.Duration
+}
+
+// WithRootSampling upgrades the sampling decision of traces to keep when their local root span
+// finishes with an error or exceeds a latency threshold, as configured by cfg. Only the traces
+// whose context wasn't propagated downstream, and which weren't partially flushed, are upgraded,
+// so that all the services of a trace agree on its decision. Traces dropped manually are kept
+// dropped. Upgraded traces get the user-keep sampling priority.
+func WithRootSampling(cfg RootSamplingConfig) StartOption {
+	return func(c *config) {
+		c.rootSampling = cfg
+	}
+}
+
+// WithAdaptiveSampling enables the adaptive sampler, which keeps about tracesPerSecond traces
+// per second in the process instead of applying the rates provided by the agent. The budget is
+// shared between the service, env and resource buckets of the traces based on their recent
+// throughput, so that quiet endpoints are fully kept while busy ones are sampled down, and every
+// bucket keeps at least one trace per minute. Sampling rules and manual decisions take precedence.
+// A zero value disables the adaptive sampler.
+func WithAdaptiveSampling(tracesPerSecond float64) StartOption {
+	return func(c *config) {
+		c.adaptiveSamplingTPS = tracesPerSecond
+	}
+}
+
+// WithDynamicInstrumentation enables or disables dynamic instrumentation. When enabled, the
+// probes received through remote configuration run when spans start and finish, and when the
+// HTTP and gRPC server integrations receive requests. Span decoration probes add tags to the
+// spans, and log probes upload rate-limited snapshots of the span tags and of the request
+// values to the agent's debugger endpoint. Remote configuration must be enabled in the agent.
+func WithDynamicInstrumentation(enabled bool) StartOption {
+	return func(c *config) {
+		c.dynamicInstrumentation = enabled
+	}
+}
+
 // WithPropagator sets an alternative propagator to be used by the tracer.
 func WithPropagator(p Propagator) StartOption {
 	return func(c *config) {
@@ -699,6 +972,18 @@ func WithRuntimeMetrics() StartOption {
 	}
 }
 
+// WithRuntimeMetricsV2 enables automatic collection of runtime metrics every 10 seconds, using
+// the runtime/metrics package, which doesn't stop the world to read them. All the metrics supported
+// by the Go version in use are reported under the "runtime.go.metrics." prefix, with histograms
+// such as the GC pauses and the scheduler latencies reported as distributions. It replaces the
+// metrics enabled by WithRuntimeMetrics.
+func WithRuntimeMetricsV2() StartOption {
+	return func(cfg *config) {
+		cfg.runtimeMetrics = true
+		cfg.runtimeMetricsV2 = true
+	}
+}
+
 // WithDogstatsdAddress specifies the address to connect to for sending metrics to the Datadog
 // Agent. It should be a "host:port" string, or the path to a unix domain socket.If not set, it
 // attempts to determine the address of the statsd service according to the following rules:
@@ -793,6 +1078,47 @@ func WithProfilerEndpoints(enabled bool) StartOption {
 	}
 }
 
+// WithPartialFlushing enables flushing of partially finished traces.
+// This is done after "numSpans" have finished in a single local trace at
+// which point all finished spans in that trace will be flushed, freeing up
+// any memory they were consuming. This can also be configured by setting
+// DD_TRACE_PARTIAL_FLUSH_ENABLED to true, which will default to 1000 spans
+// unless overridden with DD_TRACE_PARTIAL_FLUSH_MIN_SPANS. Partial flushing
+// is disabled by default.
+func WithPartialFlushing(numSpans int) StartOption {
+	return func(c *config) {
+		if numSpans >= 1 && numSpans < traceMaxSize {
+			c.partialFlushMinSpans = numSpans
+			c.partialFlushEnabled = true
+		} else {
+			log.Warn("WithPartialFlushing(%d) is not a valid value, partial flushing is disabled", numSpans)
+		}
+	}
+}
+
+// WithHeaderTags enables the HTTP and gRPC integrations to report the given request and
+// response headers (or gRPC metadata) as span tags. Each entry is of the form "header[:tag]":
+// when the tag is left out, the header is reported as http.request.headers.<header> and
+// http.response.headers.<header>, with its name lowercased and any character other than
+// letters, digits, hyphens and underscores replaced by an underscore. Header names are
+// case-insensitive. Integrations may override this setting with their own options.
+// This can also be configured with the comma-separated DD_TRACE_HEADER_TAGS environment variable.
+func WithHeaderTags(headerAsTags []string) StartOption {
+	return func(c *config) {
+		c.headerAsTags = headerAsTags
+	}
+}
+
+// WithDataStreamsMonitoring enables Data Streams Monitoring, which computes the end-to-end
+// latencies of the pipelines the service is part of, by setting checkpoints in the supported
+// messaging integrations. It requires an agent which supports data streams stats.
+// This can also be configured with the DD_DATA_STREAMS_ENABLED environment variable.
+func WithDataStreamsMonitoring(enabled bool) StartOption {
+	return func(c *config) {
+		c.dataStreamsMonitoringEnabled = enabled
+	}
+}
+
 // StartSpanOption is a configuration option for StartSpan. It is aliased in order
 // to help godoc group all the functions returning it together. It is considered
 // more correct to refer to it as the type as the origin, ddtrace.StartSpanOption.
@@ -865,6 +1191,15 @@ func StartTime(t time.Time) StartSpanOption {
 	}
 }
 
+// WithSpanLinks sets span links on the started span. Links are used to
+// reference spans which are not the parent of the started span, possibly
+// belonging to different traces.
+func WithSpanLinks(links []ddtrace.SpanLink) StartSpanOption {
+	return func(cfg *ddtrace.StartSpanConfig) {
+		cfg.SpanLinks = append(cfg.SpanLinks, links...)
+	}
+}
+
 // AnalyticsRate sets a custom analytics rate for a span. It decides the percentage
 // of events that will be picked up by the App Analytics product. It's represents a
 // float64 between 0 and 1 where 0.5 would represent 50% of events.
@@ -917,6 +1252,33 @@ func StackFrames(n, skip uint) FinishOption {
 	}
 }
 
+// SpanEventOption is a configuration option for AddEvent. It is aliased in order
+// to help godoc group all the functions returning it together. It is considered
+// more correct to refer to it as the type as the origin, ddtrace.SpanEventOption.
+type SpanEventOption = ddtrace.SpanEventOption
+
+// WithSpanEventTime sets the time at which the event happened. By default, the
+// current time is used.
+func WithSpanEventTime(t time.Time) SpanEventOption {
+	return func(cfg *ddtrace.SpanEventConfig) {
+		cfg.Time = t
+	}
+}
+
+// WithSpanEventAttributes sets the attributes describing the event. Values are
+// expected to be strings, booleans, numbers or slices of those; any other value
+// is recorded using its string representation.
+func WithSpanEventAttributes(attrs map[string]interface{}) SpanEventOption {
+	return func(cfg *ddtrace.SpanEventConfig) {
+		if cfg.Attributes == nil {
+			cfg.Attributes = make(map[string]interface{}, len(attrs))
+		}
+		for k, v := range attrs {
+			cfg.Attributes[k] = v
+		}
+	}
+}
+
 // UserMonitoringConfig is used to configure what is used to identify a user.
 // This configuration can be set by combining one or several UserMonitoringOption with a call to SetUser().
 type UserMonitoringConfig struct {
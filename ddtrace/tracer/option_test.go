@@ -200,7 +200,7 @@ func TestLoadAgentFeatures(t *testing.T) {
 
 	t.Run("OK", func(t *testing.T) {
 		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-			w.Write([]byte(`{"endpoints":["/v0.6/stats"],"feature_flags":["a","b"],"client_drop_p0s":true,"statsd_port":8999}`))
+			w.Write([]byte(`{"endpoints":["/v0.6/stats","/v0.1/pipeline_stats"],"feature_flags":["a","b"],"client_drop_p0s":true,"statsd_port":8999}`))
 		}))
 		defer srv.Close()
 		cfg := newConfig(WithAgentAddr(strings.TrimPrefix(srv.URL, "http://")))
@@ -211,6 +211,7 @@ func TestLoadAgentFeatures(t *testing.T) {
 			"b": {},
 		})
 		assert.True(t, cfg.agent.Stats)
+		assert.True(t, cfg.agent.DataStreams)
 		assert.True(t, cfg.agent.HasFlag("a"))
 		assert.True(t, cfg.agent.HasFlag("b"))
 	})
@@ -970,3 +971,76 @@ func TestHostnameDisabled(t *testing.T) {
 		assert.False(t, c.enableHostnameDetection)
 	})
 }
+
+func TestPartialFlushing(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		c := newConfig()
+		assert.False(t, c.partialFlushEnabled)
+		assert.Equal(t, partialFlushMinSpansDefault, c.partialFlushMinSpans)
+	})
+	t.Run("Disabled-DefaultMinSpans", func(t *testing.T) {
+		t.Setenv("DD_TRACE_PARTIAL_FLUSH_ENABLED", "false")
+		c := newConfig()
+		assert.False(t, c.partialFlushEnabled)
+		assert.Equal(t, partialFlushMinSpansDefault, c.partialFlushMinSpans)
+	})
+	t.Run("Default-SetMinSpans", func(t *testing.T) {
+		t.Setenv("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS", "10")
+		c := newConfig()
+		assert.False(t, c.partialFlushEnabled)
+		assert.Equal(t, 10, c.partialFlushMinSpans)
+	})
+	t.Run("Enabled-DefaultMinSpans", func(t *testing.T) {
+		t.Setenv("DD_TRACE_PARTIAL_FLUSH_ENABLED", "true")
+		c := newConfig()
+		assert.True(t, c.partialFlushEnabled)
+		assert.Equal(t, partialFlushMinSpansDefault, c.partialFlushMinSpans)
+	})
+	t.Run("Enabled-SetMinSpans", func(t *testing.T) {
+		t.Setenv("DD_TRACE_PARTIAL_FLUSH_ENABLED", "true")
+		t.Setenv("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS", "10")
+		c := newConfig()
+		assert.True(t, c.partialFlushEnabled)
+		assert.Equal(t, 10, c.partialFlushMinSpans)
+	})
+	t.Run("Enabled-SetMinSpansNegative", func(t *testing.T) {
+		t.Setenv("DD_TRACE_PARTIAL_FLUSH_ENABLED", "true")
+		t.Setenv("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS", "-1")
+		c := newConfig()
+		assert.True(t, c.partialFlushEnabled)
+		assert.Equal(t, partialFlushMinSpansDefault, c.partialFlushMinSpans)
+	})
+	t.Run("WithPartialFlushOption", func(t *testing.T) {
+		c := newConfig(WithPartialFlushing(20))
+		assert.True(t, c.partialFlushEnabled)
+		assert.Equal(t, 20, c.partialFlushMinSpans)
+	})
+	t.Run("WithPartialFlushOption-Invalid", func(t *testing.T) {
+		c := newConfig(WithPartialFlushing(0))
+		assert.False(t, c.partialFlushEnabled)
+		assert.Equal(t, partialFlushMinSpansDefault, c.partialFlushMinSpans)
+	})
+}
+
+func TestHeaderTags(t *testing.T) {
+	defer globalconfig.SetHeaderTags(nil)
+
+	t.Run("none", func(t *testing.T) {
+		c := newConfig()
+		assert.Empty(t, c.headerAsTags)
+		assert.Empty(t, globalconfig.HeaderTagMap())
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_TRACE_HEADER_TAGS", "X-Header, Content-Type:ctype,")
+		c := newConfig()
+		assert.Equal(t, []string{"X-Header", " Content-Type:ctype", ""}, c.headerAsTags)
+		assert.Equal(t, map[string]string{"x-header": "", "content-type": "ctype"}, globalconfig.HeaderTagMap())
+	})
+
+	t.Run("option", func(t *testing.T) {
+		t.Setenv("DD_TRACE_HEADER_TAGS", "X-Header")
+		newConfig(WithHeaderTags([]string{"X-Other:other"}))
+		assert.Equal(t, map[string]string{"x-other": "other"}, globalconfig.HeaderTagMap())
+	})
+}
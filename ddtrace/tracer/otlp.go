@@ -0,0 +1,559 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/version"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// defaultOTLPEndpoint is the OTLP/HTTP traces endpoint of a collector running locally.
+	defaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+	// otlpHTTPTimeout is the timeout of the requests to the OTLP endpoint.
+	otlpHTTPTimeout = 10 * time.Second
+)
+
+// otlpClient is the HTTP client used to export traces over OTLP. The client of the agent
+// transport isn't used, as it may be bound to the agent's unix domain socket.
+var otlpClient = &http.Client{
+	Transport: defaultClient.Transport,
+	Timeout:   otlpHTTPTimeout,
+}
+
+// otlpEndpointFromEnv returns the OTLP/HTTP traces endpoint configured through the standard
+// OpenTelemetry environment variables, or defaultOTLPEndpoint.
+func otlpEndpointFromEnv() string {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); v != "" {
+		return v
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		return strings.TrimSuffix(v, "/") + "/v1/traces"
+	}
+	return defaultOTLPEndpoint
+}
+
+// otlpHeadersFromEnv returns the headers to send with OTLP requests, configured through the
+// standard OpenTelemetry environment variables as a list of comma-separated key=value pairs.
+func otlpHeadersFromEnv() map[string]string {
+	v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")
+	if v == "" {
+		v = os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")
+	}
+	if v == "" {
+		return nil
+	}
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			log.Warn("Ignoring invalid OTLP header %q", pair)
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		val, err := url.QueryUnescape(strings.TrimSpace(kv[1]))
+		if key == "" || err != nil {
+			log.Warn("Ignoring invalid OTLP header %q", pair)
+			continue
+		}
+		headers[key] = val
+	}
+	return headers
+}
+
+// Field numbers of the OTLP trace protobuf messages, as defined in
+// https://github.com/open-telemetry/opentelemetry-proto/blob/v1.0.0/opentelemetry/proto/trace/v1/trace.proto
+const (
+	// ExportTraceServiceRequest
+	otlpRequestResourceSpans protowire.Number = 1
+
+	// ResourceSpans
+	otlpResourceSpansResource   protowire.Number = 1
+	otlpResourceSpansScopeSpans protowire.Number = 2
+
+	// Resource
+	otlpResourceAttributes protowire.Number = 1
+
+	// ScopeSpans
+	otlpScopeSpansScope protowire.Number = 1
+	otlpScopeSpansSpans protowire.Number = 2
+
+	// InstrumentationScope
+	otlpScopeName    protowire.Number = 1
+	otlpScopeVersion protowire.Number = 2
+
+	// Span
+	otlpSpanTraceID      protowire.Number = 1
+	otlpSpanSpanID       protowire.Number = 2
+	otlpSpanParentSpanID protowire.Number = 4
+	otlpSpanName         protowire.Number = 5
+	otlpSpanKind         protowire.Number = 6
+	otlpSpanStartTime    protowire.Number = 7
+	otlpSpanEndTime      protowire.Number = 8
+	otlpSpanAttributes   protowire.Number = 9
+	otlpSpanEvents       protowire.Number = 11
+	otlpSpanLinks        protowire.Number = 13
+	otlpSpanStatus       protowire.Number = 15
+
+	// Span.Event
+	otlpEventTime       protowire.Number = 1
+	otlpEventName       protowire.Number = 2
+	otlpEventAttributes protowire.Number = 3
+
+	// Span.Link
+	otlpLinkTraceID    protowire.Number = 1
+	otlpLinkSpanID     protowire.Number = 2
+	otlpLinkTraceState protowire.Number = 3
+	otlpLinkAttributes protowire.Number = 4
+	otlpLinkFlags      protowire.Number = 6
+
+	// Status
+	otlpStatusMessage protowire.Number = 2
+	otlpStatusCode    protowire.Number = 3
+
+	// KeyValue
+	otlpKeyValueKey   protowire.Number = 1
+	otlpKeyValueValue protowire.Number = 2
+
+	// AnyValue
+	otlpAnyValueString protowire.Number = 1
+	otlpAnyValueBool   protowire.Number = 2
+	otlpAnyValueInt    protowire.Number = 3
+	otlpAnyValueDouble protowire.Number = 4
+	otlpAnyValueArray  protowire.Number = 5
+
+	// ArrayValue
+	otlpArrayValueValues protowire.Number = 1
+)
+
+// otlpSpanKinds maps the values of the ext.SpanKind tag to the OTLP span kinds.
+var otlpSpanKinds = map[string]uint64{
+	ext.SpanKindInternal: 1,
+	ext.SpanKindServer:   2,
+	ext.SpanKindClient:   3,
+	ext.SpanKindProducer: 4,
+	ext.SpanKindConsumer: 5,
+}
+
+// otlpStatusCodeError is the OTLP status code of spans which finished with an error.
+const otlpStatusCodeError = 2
+
+// otlpTraceWriter converts traces to the OpenTelemetry protocol (OTLP) and exports them
+// over OTLP/HTTP, in protobuf format, to an OpenTelemetry collector. Spans are grouped by
+// service, each service being reported as a separate resource.
+type otlpTraceWriter struct {
+	// config holds the tracer configuration
+	config *config
+
+	// client is the HTTP client used to send traces
+	client *http.Client
+
+	// spans holds the encoded spans awaiting to be sent, by service
+	spans map[string][]byte
+
+	// services holds the keys of spans, in insertion order
+	services []string
+
+	// size is the total size of the encoded spans
+	size int
+
+	// count is the number of traces awaiting to be sent
+	count int
+
+	// climit limits the number of concurrent outgoing connections
+	climit chan struct{}
+
+	// wg waits for all uploads to finish
+	wg sync.WaitGroup
+
+	// statsd is used to send metrics
+	statsd statsdClient
+}
+
+func newOTLPTraceWriter(c *config, statsdClient statsdClient) *otlpTraceWriter {
+	return &otlpTraceWriter{
+		config: c,
+		client: otlpClient,
+		spans:  make(map[string][]byte),
+		climit: make(chan struct{}, concurrentConnectionLimit),
+		statsd: statsdClient,
+	}
+}
+
+func (h *otlpTraceWriter) add(trace []*span) {
+	if len(trace) == 0 {
+		return
+	}
+	var upper uint64
+	if ctx := trace[0].context; ctx != nil {
+		upper = ctx.traceID.Upper()
+	}
+	// OpenTelemetry collectors export every span they receive: unless the trace is kept,
+	// only the spans kept by single span sampling rules are sent.
+	keep := true
+	if ctx := trace[0].context; ctx != nil {
+		if p, ok := ctx.samplingPriority(); ok && p <= 0 {
+			keep = false
+		}
+	}
+	var kept bool
+	for _, s := range trace {
+		if !keep {
+			if _, ok := s.Metrics[keySpanSamplingMechanism]; !ok {
+				continue
+			}
+		}
+		b, ok := h.spans[s.Service]
+		if !ok {
+			h.services = append(h.services, s.Service)
+		}
+		n := len(b)
+		b = protowire.AppendTag(b, otlpScopeSpansSpans, protowire.BytesType)
+		b = protowire.AppendBytes(b, h.encodeSpan(s, upper))
+		h.spans[s.Service] = b
+		h.size += len(b) - n
+		kept = true
+	}
+	if kept {
+		h.count++
+	}
+	if h.size > payloadSizeLimit {
+		h.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:size"}, 1)
+		h.flush()
+	}
+}
+
+func (h *otlpTraceWriter) stop() {
+	h.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:shutdown"}, 1)
+	h.flush()
+	h.wg.Wait()
+}
+
+// flush will push any currently buffered traces to the OTLP endpoint.
+func (h *otlpTraceWriter) flush() {
+	if h.count == 0 {
+		return
+	}
+	req := h.encodeRequest()
+	count := h.count
+	h.spans = make(map[string][]byte)
+	h.services = nil
+	h.size = 0
+	h.count = 0
+
+	h.wg.Add(1)
+	h.climit <- struct{}{}
+	go func() {
+		defer func(start time.Time) {
+			<-h.climit
+			h.wg.Done()
+			h.statsd.Timing("datadog.tracer.flush_duration", time.Since(start), nil, 1)
+		}(time.Now())
+
+		var err error
+		for attempt := 0; attempt <= h.config.sendRetries; attempt++ {
+			log.Debug("Sending OTLP payload: size: %d traces: %d\n", len(req), count)
+			if err = h.send(req); err == nil {
+				log.Debug("sent traces after %d attempts", attempt+1)
+				h.statsd.Count("datadog.tracer.flush_bytes", int64(len(req)), nil, 1)
+				h.statsd.Count("datadog.tracer.flush_traces", int64(count), nil, 1)
+				return
+			}
+			log.Error("failure sending traces (attempt %d), will retry: %v", attempt+1, err)
+			time.Sleep(time.Millisecond)
+		}
+		h.statsd.Count("datadog.tracer.traces_dropped", int64(count), []string{"reason:send_failed"}, 1)
+		log.Error("lost %d traces: %v", count, err)
+	}()
+}
+
+// send posts the encoded ExportTraceServiceRequest to the OTLP endpoint.
+func (h *otlpTraceWriter) send(body []byte) error {
+	req, err := http.NewRequest("POST", h.config.otlpEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot create http request: %v", err)
+	}
+	for header, value := range h.config.otlpHeaders {
+		req.Header.Set(header, value)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	response, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if code := response.StatusCode; code >= 400 {
+		// error, check the body for context information and
+		// return a nice error.
+		msg := make([]byte, 1000)
+		n, _ := response.Body.Read(msg)
+		txt := http.StatusText(code)
+		if n > 0 {
+			return fmt.Errorf("%s (Status: %s)", msg[:n], txt)
+		}
+		return fmt.Errorf("%s", txt)
+	}
+	io.Copy(io.Discard, response.Body)
+	return nil
+}
+
+// encodeRequest returns the ExportTraceServiceRequest holding the buffered spans.
+func (h *otlpTraceWriter) encodeRequest() []byte {
+	var scope []byte
+	scope = protowire.AppendTag(scope, otlpScopeName, protowire.BytesType)
+	scope = protowire.AppendString(scope, "dd-trace-go")
+	scope = protowire.AppendTag(scope, otlpScopeVersion, protowire.BytesType)
+	scope = protowire.AppendString(scope, version.Tag)
+
+	var req []byte
+	for _, service := range h.services {
+		var resource []byte
+		for _, kv := range h.resourceAttributes(service) {
+			resource = appendOTLPKeyValue(resource, otlpResourceAttributes, kv.key, kv.value)
+		}
+		scopeSpans := protowire.AppendTag(nil, otlpScopeSpansScope, protowire.BytesType)
+		scopeSpans = protowire.AppendBytes(scopeSpans, scope)
+		scopeSpans = append(scopeSpans, h.spans[service]...)
+
+		var rs []byte
+		rs = protowire.AppendTag(rs, otlpResourceSpansResource, protowire.BytesType)
+		rs = protowire.AppendBytes(rs, resource)
+		rs = protowire.AppendTag(rs, otlpResourceSpansScopeSpans, protowire.BytesType)
+		rs = protowire.AppendBytes(rs, scopeSpans)
+
+		req = protowire.AppendTag(req, otlpRequestResourceSpans, protowire.BytesType)
+		req = protowire.AppendBytes(req, rs)
+	}
+	return req
+}
+
+// otlpAttribute is an attribute of an OTLP resource, span, event or link.
+type otlpAttribute struct {
+	key   string
+	value interface{}
+}
+
+// resourceAttributes returns the attributes of the resource holding the spans of the
+// given service: the service, environment, version and global tags.
+func (h *otlpTraceWriter) resourceAttributes(service string) []otlpAttribute {
+	attrs := []otlpAttribute{
+		{"service.name", service},
+		{"telemetry.sdk.name", "datadog"},
+		{"telemetry.sdk.language", "go"},
+		{"telemetry.sdk.version", version.Tag},
+	}
+	if h.config.env != "" {
+		attrs = append(attrs, otlpAttribute{"deployment.environment", h.config.env})
+	}
+	if h.config.version != "" {
+		attrs = append(attrs, otlpAttribute{"service.version", h.config.version})
+	}
+	keys := make([]string, 0, len(h.config.globalTags))
+	for k := range h.config.globalTags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch k {
+		case ext.Environment, "service", ext.Version:
+			// already reported using the OpenTelemetry semantic conventions
+			continue
+		}
+		attrs = append(attrs, otlpAttribute{k, fmt.Sprint(h.config.globalTags[k])})
+	}
+	return attrs
+}
+
+// encodeSpan returns the OTLP Span message describing s. upper holds the upper 64 bits
+// of the trace ID.
+func (h *otlpTraceWriter) encodeSpan(s *span, upper uint64) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, otlpSpanTraceID, protowire.BytesType)
+	b = protowire.AppendBytes(b, otlpTraceID(upper, s.TraceID))
+	b = protowire.AppendTag(b, otlpSpanSpanID, protowire.BytesType)
+	b = protowire.AppendBytes(b, otlpSpanID(s.SpanID))
+	if s.ParentID != 0 {
+		b = protowire.AppendTag(b, otlpSpanParentSpanID, protowire.BytesType)
+		b = protowire.AppendBytes(b, otlpSpanID(s.ParentID))
+	}
+	b = protowire.AppendTag(b, otlpSpanName, protowire.BytesType)
+	b = protowire.AppendString(b, s.Name)
+	if kind, ok := otlpSpanKinds[s.Meta[ext.SpanKind]]; ok {
+		b = protowire.AppendTag(b, otlpSpanKind, protowire.VarintType)
+		b = protowire.AppendVarint(b, kind)
+	}
+	b = protowire.AppendTag(b, otlpSpanStartTime, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(s.Start))
+	b = protowire.AppendTag(b, otlpSpanEndTime, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(s.Start+s.Duration))
+
+	b = appendOTLPKeyValue(b, otlpSpanAttributes, "resource.name", s.Resource)
+	if s.Type != "" {
+		b = appendOTLPKeyValue(b, otlpSpanAttributes, "span.type", s.Type)
+	}
+	for _, k := range sortedKeys(s.Meta) {
+		switch k {
+		case ext.SpanKind, keyTraceID128:
+			// reported as the span kind and trace ID
+			continue
+		}
+		if v, ok := h.config.globalTags[k]; ok && fmt.Sprint(v) == s.Meta[k] {
+			// reported as a resource attribute
+			continue
+		}
+		b = appendOTLPKeyValue(b, otlpSpanAttributes, k, s.Meta[k])
+	}
+	metrics := make([]string, 0, len(s.Metrics))
+	for k := range s.Metrics {
+		metrics = append(metrics, k)
+	}
+	sort.Strings(metrics)
+	for _, k := range metrics {
+		b = appendOTLPKeyValue(b, otlpSpanAttributes, k, s.Metrics[k])
+	}
+
+	for _, e := range s.SpanEvents {
+		var eb []byte
+		eb = protowire.AppendTag(eb, otlpEventTime, protowire.Fixed64Type)
+		eb = protowire.AppendFixed64(eb, e.TimeUnixNano)
+		eb = protowire.AppendTag(eb, otlpEventName, protowire.BytesType)
+		eb = protowire.AppendString(eb, e.Name)
+		keys := make([]string, 0, len(e.Attributes))
+		for k := range e.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			eb = appendOTLPKeyValue(eb, otlpEventAttributes, k, e.Attributes[k])
+		}
+		b = protowire.AppendTag(b, otlpSpanEvents, protowire.BytesType)
+		b = protowire.AppendBytes(b, eb)
+	}
+	for _, l := range s.SpanLinks {
+		var lb []byte
+		lb = protowire.AppendTag(lb, otlpLinkTraceID, protowire.BytesType)
+		lb = protowire.AppendBytes(lb, otlpTraceID(l.TraceIDHigh, l.TraceID))
+		lb = protowire.AppendTag(lb, otlpLinkSpanID, protowire.BytesType)
+		lb = protowire.AppendBytes(lb, otlpSpanID(l.SpanID))
+		if l.Tracestate != "" {
+			lb = protowire.AppendTag(lb, otlpLinkTraceState, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.Tracestate)
+		}
+		for _, k := range sortedKeys(l.Attributes) {
+			lb = appendOTLPKeyValue(lb, otlpLinkAttributes, k, l.Attributes[k])
+		}
+		if l.Flags != 0 {
+			// the high bit only distinguishes explicit zero flags from unset ones
+			lb = protowire.AppendTag(lb, otlpLinkFlags, protowire.Fixed32Type)
+			lb = protowire.AppendFixed32(lb, l.Flags&^(1<<31))
+		}
+		b = protowire.AppendTag(b, otlpSpanLinks, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+
+	if s.Error != 0 {
+		var sb []byte
+		if msg := s.Meta[ext.ErrorMsg]; msg != "" {
+			sb = protowire.AppendTag(sb, otlpStatusMessage, protowire.BytesType)
+			sb = protowire.AppendString(sb, msg)
+		}
+		sb = protowire.AppendTag(sb, otlpStatusCode, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, otlpStatusCodeError)
+		b = protowire.AppendTag(b, otlpSpanStatus, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+	return b
+}
+
+// otlpTraceID returns the 16 bytes OTLP trace ID made of the given upper and lower 64 bits.
+func otlpTraceID(upper, lower uint64) []byte {
+	id := make([]byte, 16)
+	binary.BigEndian.PutUint64(id[:8], upper)
+	binary.BigEndian.PutUint64(id[8:], lower)
+	return id
+}
+
+// otlpSpanID returns the 8 bytes OTLP span ID matching id.
+func otlpSpanID(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
+
+// appendOTLPKeyValue appends to b the KeyValue message holding key and value as the field num.
+func appendOTLPKeyValue(b []byte, num protowire.Number, key string, value interface{}) []byte {
+	var kv []byte
+	kv = protowire.AppendTag(kv, otlpKeyValueKey, protowire.BytesType)
+	kv = protowire.AppendString(kv, key)
+	kv = protowire.AppendTag(kv, otlpKeyValueValue, protowire.BytesType)
+	kv = protowire.AppendBytes(kv, appendOTLPAnyValue(nil, value))
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, kv)
+}
+
+// appendOTLPAnyValue appends to b the AnyValue message holding value.
+func appendOTLPAnyValue(b []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		b = protowire.AppendTag(b, otlpAnyValueString, protowire.BytesType)
+		return protowire.AppendString(b, v)
+	case float64:
+		b = protowire.AppendTag(b, otlpAnyValueDouble, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(v))
+	case *spanEventAttribute:
+		switch v.Type {
+		case spanEventAttributeTypeBool:
+			b = protowire.AppendTag(b, otlpAnyValueBool, protowire.VarintType)
+			return protowire.AppendVarint(b, protowire.EncodeBool(v.BoolValue))
+		case spanEventAttributeTypeInt:
+			b = protowire.AppendTag(b, otlpAnyValueInt, protowire.VarintType)
+			return protowire.AppendVarint(b, uint64(v.IntValue))
+		case spanEventAttributeTypeDouble:
+			return appendOTLPAnyValue(b, v.DoubleValue)
+		case spanEventAttributeTypeArray:
+			var arr []byte
+			if v.ArrayValue != nil {
+				for _, item := range v.ArrayValue.Values {
+					arr = protowire.AppendTag(arr, otlpArrayValueValues, protowire.BytesType)
+					arr = protowire.AppendBytes(arr, appendOTLPAnyValue(nil, item))
+				}
+			}
+			b = protowire.AppendTag(b, otlpAnyValueArray, protowire.BytesType)
+			return protowire.AppendBytes(b, arr)
+		default:
+			return appendOTLPAnyValue(b, v.StringValue)
+		}
+	default:
+		return appendOTLPAnyValue(b, fmt.Sprint(v))
+	}
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
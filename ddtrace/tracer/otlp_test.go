@@ -0,0 +1,490 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// otlpTestField is a decoded protobuf field.
+type otlpTestField struct {
+	num   protowire.Number
+	value uint64 // set for varint, fixed32 and fixed64 fields
+	bytes []byte // set for length-delimited fields
+}
+
+// decodeOTLPFields decodes the fields of the protobuf message b.
+func decodeOTLPFields(t *testing.T, b []byte) []otlpTestField {
+	var fields []otlpTestField
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0, "invalid tag")
+		b = b[n:]
+		f := otlpTestField{num: num}
+		switch typ {
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.value = uint64(v)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		require.GreaterOrEqual(t, n, 0, "invalid field %d", num)
+		b = b[n:]
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// otlpTestResource is a decoded ResourceSpans message.
+type otlpTestResource struct {
+	attributes map[string]interface{}
+	scopeName  string
+	spans      []otlpTestSpan
+}
+
+// otlpTestSpan is a decoded Span message.
+type otlpTestSpan struct {
+	traceID, spanID, parentID []byte
+	name                      string
+	kind                      uint64
+	start, end                uint64
+	attributes                map[string]interface{}
+	events                    []otlpTestEvent
+	links                     []otlpTestLink
+	statusCode                uint64
+	statusMessage             string
+}
+
+type otlpTestEvent struct {
+	time       uint64
+	name       string
+	attributes map[string]interface{}
+}
+
+type otlpTestLink struct {
+	traceID, spanID []byte
+	traceState      string
+	flags           uint64
+	attributes      map[string]interface{}
+}
+
+func decodeOTLPAnyValue(t *testing.T, b []byte) interface{} {
+	fields := decodeOTLPFields(t, b)
+	require.Len(t, fields, 1)
+	f := fields[0]
+	switch f.num {
+	case otlpAnyValueString:
+		return string(f.bytes)
+	case otlpAnyValueBool:
+		return f.value != 0
+	case otlpAnyValueInt:
+		return int64(f.value)
+	case otlpAnyValueDouble:
+		return math.Float64frombits(f.value)
+	case otlpAnyValueArray:
+		var arr []interface{}
+		for _, item := range decodeOTLPFields(t, f.bytes) {
+			arr = append(arr, decodeOTLPAnyValue(t, item.bytes))
+		}
+		return arr
+	}
+	t.Fatalf("unexpected AnyValue field %d", f.num)
+	return nil
+}
+
+func decodeOTLPKeyValue(t *testing.T, b []byte, into map[string]interface{}) {
+	var key string
+	var value interface{}
+	for _, f := range decodeOTLPFields(t, b) {
+		switch f.num {
+		case otlpKeyValueKey:
+			key = string(f.bytes)
+		case otlpKeyValueValue:
+			value = decodeOTLPAnyValue(t, f.bytes)
+		}
+	}
+	into[key] = value
+}
+
+func decodeOTLPSpan(t *testing.T, b []byte) otlpTestSpan {
+	s := otlpTestSpan{attributes: make(map[string]interface{})}
+	for _, f := range decodeOTLPFields(t, b) {
+		switch f.num {
+		case otlpSpanTraceID:
+			s.traceID = f.bytes
+		case otlpSpanSpanID:
+			s.spanID = f.bytes
+		case otlpSpanParentSpanID:
+			s.parentID = f.bytes
+		case otlpSpanName:
+			s.name = string(f.bytes)
+		case otlpSpanKind:
+			s.kind = f.value
+		case otlpSpanStartTime:
+			s.start = f.value
+		case otlpSpanEndTime:
+			s.end = f.value
+		case otlpSpanAttributes:
+			decodeOTLPKeyValue(t, f.bytes, s.attributes)
+		case otlpSpanEvents:
+			e := otlpTestEvent{attributes: make(map[string]interface{})}
+			for _, ef := range decodeOTLPFields(t, f.bytes) {
+				switch ef.num {
+				case otlpEventTime:
+					e.time = ef.value
+				case otlpEventName:
+					e.name = string(ef.bytes)
+				case otlpEventAttributes:
+					decodeOTLPKeyValue(t, ef.bytes, e.attributes)
+				}
+			}
+			s.events = append(s.events, e)
+		case otlpSpanLinks:
+			l := otlpTestLink{attributes: make(map[string]interface{})}
+			for _, lf := range decodeOTLPFields(t, f.bytes) {
+				switch lf.num {
+				case otlpLinkTraceID:
+					l.traceID = lf.bytes
+				case otlpLinkSpanID:
+					l.spanID = lf.bytes
+				case otlpLinkTraceState:
+					l.traceState = string(lf.bytes)
+				case otlpLinkAttributes:
+					decodeOTLPKeyValue(t, lf.bytes, l.attributes)
+				case otlpLinkFlags:
+					l.flags = lf.value
+				}
+			}
+			s.links = append(s.links, l)
+		case otlpSpanStatus:
+			for _, sf := range decodeOTLPFields(t, f.bytes) {
+				switch sf.num {
+				case otlpStatusCode:
+					s.statusCode = sf.value
+				case otlpStatusMessage:
+					s.statusMessage = string(sf.bytes)
+				}
+			}
+		}
+	}
+	return s
+}
+
+// decodeOTLPRequest decodes an ExportTraceServiceRequest message.
+func decodeOTLPRequest(t *testing.T, b []byte) []otlpTestResource {
+	var resources []otlpTestResource
+	for _, f := range decodeOTLPFields(t, b) {
+		require.Equal(t, otlpRequestResourceSpans, f.num)
+		r := otlpTestResource{attributes: make(map[string]interface{})}
+		for _, rf := range decodeOTLPFields(t, f.bytes) {
+			switch rf.num {
+			case otlpResourceSpansResource:
+				for _, af := range decodeOTLPFields(t, rf.bytes) {
+					decodeOTLPKeyValue(t, af.bytes, r.attributes)
+				}
+			case otlpResourceSpansScopeSpans:
+				for _, sf := range decodeOTLPFields(t, rf.bytes) {
+					switch sf.num {
+					case otlpScopeSpansScope:
+						for _, nf := range decodeOTLPFields(t, sf.bytes) {
+							if nf.num == otlpScopeName {
+								r.scopeName = string(nf.bytes)
+							}
+						}
+					case otlpScopeSpansSpans:
+						r.spans = append(r.spans, decodeOTLPSpan(t, sf.bytes))
+					}
+				}
+			}
+		}
+		resources = append(resources, r)
+	}
+	return resources
+}
+
+// otlpCollector is an in-process stand-in for an OpenTelemetry collector receiving
+// traces over OTLP/HTTP.
+type otlpCollector struct {
+	*httptest.Server
+
+	t        *testing.T
+	mu       sync.Mutex
+	failures int
+	requests int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func newOTLPCollector(t *testing.T) *otlpCollector {
+	c := &otlpCollector{t: t}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.requests++
+		if r.URL.Path != "/v1/traces" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if c.failures > 0 {
+			c.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		c.headers = append(c.headers, r.Header)
+		c.bodies = append(c.bodies, body)
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *otlpCollector) endpoint() string {
+	return c.URL + "/v1/traces"
+}
+
+// Resources decodes the resources received by the collector.
+func (c *otlpCollector) Resources() []otlpTestResource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var resources []otlpTestResource
+	for _, body := range c.bodies {
+		resources = append(resources, decodeOTLPRequest(c.t, body)...)
+	}
+	return resources
+}
+
+func TestOTLPConfig(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		c := newConfig()
+		assert.False(t, c.otlpExport)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_TRACE_OTLP_EXPORT_ENABLED", "true")
+		c := newConfig()
+		assert.True(t, c.otlpExport)
+		assert.Equal(t, defaultOTLPEndpoint, c.otlpEndpoint)
+		assert.False(t, c.canComputeStats())
+
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+		assert.Equal(t, "http://collector:4318/v1/traces", newConfig().otlpEndpoint)
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4318/custom")
+		assert.Equal(t, "http://traces:4318/custom", newConfig().otlpEndpoint)
+	})
+
+	t.Run("option", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4318/custom")
+		c := newConfig(WithOTLPExporter("http://option:4318/v1/traces"))
+		assert.True(t, c.otlpExport)
+		assert.Equal(t, "http://option:4318/v1/traces", c.otlpEndpoint)
+
+		c = newConfig(WithOTLPExporter(""))
+		assert.Equal(t, "http://traces:4318/custom", c.otlpEndpoint)
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=abc%20def, tenant = t1,invalid")
+		assert.Equal(t, map[string]string{"api-key": "abc def", "tenant": "t1"}, newConfig().otlpHeaders)
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "x=y")
+		assert.Equal(t, map[string]string{"x": "y"}, newConfig().otlpHeaders)
+	})
+}
+
+func TestOTLPTraceWriter(t *testing.T) {
+	t.Run("convert", func(t *testing.T) {
+		collector := newOTLPCollector(t)
+		t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=secret")
+		c := newConfig(
+			WithOTLPExporter(collector.endpoint()),
+			WithEnv("prod"),
+			WithServiceVersion("1.2.3"),
+			WithGlobalTag("team", "apm"),
+		)
+		h := newOTLPTraceWriter(c, &testStatsdClient{})
+
+		root := newSpan("web.request", "web", "GET /users", 10, 20, 0)
+		root.Type = "web"
+		root.setMeta(ext.SpanKind, ext.SpanKindServer)
+		root.setMeta("team", "apm")
+		root.setMetric("requests", 2)
+		root.context.traceID.SetUpper(0x0102030405060708)
+		root.setMeta(keyTraceID128, "0102030405060708")
+		root.SpanEvents = []spanEvent{{
+			Name:         "exception",
+			TimeUnixNano: 42,
+			Attributes: map[string]*spanEventAttribute{
+				"escaped": newSpanEventAttribute(true),
+				"count":   newSpanEventAttribute(3),
+				"lines":   newSpanEventAttribute([]string{"a", "b"}),
+			},
+		}}
+		root.SpanLinks = []ddtrace.SpanLink{{
+			TraceID:     2,
+			TraceIDHigh: 1,
+			SpanID:      3,
+			Attributes:  map[string]string{"link.kind": "follows"},
+			Tracestate:  "dd=s:1",
+			Flags:       1 | 1<<31,
+		}}
+		child := newSpan("db.query", "db", "SELECT 1", 11, root.TraceID, root.SpanID)
+		child.context = newSpanContext(child, root.context)
+		child.setMeta(ext.SpanKind, ext.SpanKindClient)
+		child.setMeta(ext.ErrorMsg, "timeout")
+		child.Error = 1
+
+		h.add([]*span{root, child})
+		h.stop()
+
+		assert.Equal(t, "secret", collector.headers[0].Get("api-key"))
+		assert.Equal(t, "application/x-protobuf", collector.headers[0].Get("Content-Type"))
+		resources := collector.Resources()
+		require.Len(t, resources, 2)
+
+		web := resources[0]
+		assert.Equal(t, "dd-trace-go", web.scopeName)
+		assert.Equal(t, "web", web.attributes["service.name"])
+		assert.Equal(t, "prod", web.attributes["deployment.environment"])
+		assert.Equal(t, "1.2.3", web.attributes["service.version"])
+		assert.Equal(t, "apm", web.attributes["team"])
+		assert.Equal(t, "go", web.attributes["telemetry.sdk.language"])
+		assert.NotContains(t, web.attributes, "env")
+		require.Len(t, web.spans, 1)
+		s := web.spans[0]
+		wantTraceID := []byte{1, 2, 3, 4, 5, 6, 7, 8, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint64(wantTraceID[8:], root.TraceID)
+		assert.Equal(t, wantTraceID, s.traceID)
+		assert.Equal(t, otlpSpanID(root.SpanID), s.spanID)
+		assert.Nil(t, s.parentID)
+		assert.Equal(t, "web.request", s.name)
+		assert.EqualValues(t, 2, s.kind)
+		assert.EqualValues(t, root.Start, s.start)
+		assert.EqualValues(t, root.Start+root.Duration, s.end)
+		assert.Equal(t, "GET /users", s.attributes["resource.name"])
+		assert.Equal(t, "web", s.attributes["span.type"])
+		assert.Equal(t, 2.0, s.attributes["requests"])
+		assert.NotContains(t, s.attributes, ext.SpanKind)
+		assert.NotContains(t, s.attributes, keyTraceID128)
+		assert.NotContains(t, s.attributes, "team")
+		assert.Zero(t, s.statusCode)
+		require.Len(t, s.events, 1)
+		assert.Equal(t, otlpTestEvent{
+			time: 42,
+			name: "exception",
+			attributes: map[string]interface{}{
+				"escaped": true,
+				"count":   int64(3),
+				"lines":   []interface{}{"a", "b"},
+			},
+		}, s.events[0])
+		require.Len(t, s.links, 1)
+		assert.Equal(t, otlpTestLink{
+			traceID:    otlpTraceID(1, 2),
+			spanID:     otlpSpanID(3),
+			traceState: "dd=s:1",
+			flags:      1,
+			attributes: map[string]interface{}{"link.kind": "follows"},
+		}, s.links[0])
+
+		db := resources[1]
+		assert.Equal(t, "db", db.attributes["service.name"])
+		require.Len(t, db.spans, 1)
+		s = db.spans[0]
+		assert.Equal(t, wantTraceID, s.traceID)
+		assert.Equal(t, otlpSpanID(root.SpanID), s.parentID)
+		assert.EqualValues(t, 3, s.kind)
+		assert.EqualValues(t, otlpStatusCodeError, s.statusCode)
+		assert.Equal(t, "timeout", s.statusMessage)
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		collector := newOTLPCollector(t)
+		c := newConfig(WithOTLPExporter(collector.endpoint()))
+		h := newOTLPTraceWriter(c, &testStatsdClient{})
+
+		dropped := newSpan("dropped", "svc", "", 1, 5, 0)
+		dropped.context.setSamplingPriority(ext.PriorityAutoReject, samplernames.AgentRate)
+		kept := newSpan("kept-by-span-rule", "svc", "", 2, dropped.TraceID, dropped.SpanID)
+		kept.context = newSpanContext(kept, dropped.context)
+		kept.setMetric(keySpanSamplingMechanism, 8)
+		h.add([]*span{dropped, kept})
+		other := newBasicSpan("rejected")
+		other.context.setSamplingPriority(ext.PriorityUserReject, samplernames.Manual)
+		h.add([]*span{other})
+		h.stop()
+
+		resources := collector.Resources()
+		require.Len(t, resources, 1)
+		require.Len(t, resources[0].spans, 1)
+		assert.Equal(t, "kept-by-span-rule", resources[0].spans[0].name)
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		collector := newOTLPCollector(t)
+		collector.failures = 1
+		c := newConfig(WithOTLPExporter(collector.endpoint()), WithSendRetries(1))
+		var statsd testStatsdClient
+		h := newOTLPTraceWriter(c, &statsd)
+		h.add([]*span{newBasicSpan("request")})
+		h.stop()
+		assert.Equal(t, 2, collector.requests)
+		assert.Len(t, collector.Resources(), 1)
+		assert.EqualValues(t, 1, statsd.counts["datadog.tracer.flush_traces"])
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		c := newConfig(WithOTLPExporter("http://127.0.0.1:1/v1/traces"))
+		var statsd testStatsdClient
+		h := newOTLPTraceWriter(c, &statsd)
+		h.add([]*span{newBasicSpan("request")})
+		h.stop()
+		assert.EqualValues(t, 1, statsd.counts["datadog.tracer.traces_dropped"])
+	})
+}
+
+func TestOTLPTracer(t *testing.T) {
+	collector := newOTLPCollector(t)
+	tracer, _, _, stop := startTestTracer(t, WithOTLPExporter(collector.endpoint()), WithLogger(log.DiscardLogger{}))
+	assert.IsType(t, &otlpTraceWriter{}, tracer.traceWriter)
+
+	sp := tracer.StartSpan("web.request", ServiceName("web"))
+	sp.SetTag(ext.Error, errors.New("boom"))
+	sp.Finish()
+	stop()
+
+	resources := collector.Resources()
+	require.Len(t, resources, 1)
+	require.Len(t, resources[0].spans, 1)
+	s := resources[0].spans[0]
+	assert.Equal(t, "web.request", s.name)
+	assert.EqualValues(t, otlpStatusCodeError, s.statusCode)
+	assert.Equal(t, "boom", s.statusMessage)
+}
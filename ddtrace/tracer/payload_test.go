@@ -13,6 +13,8 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/tinylib/msgp/msgp"
 )
@@ -25,6 +27,9 @@ func newSpanList(n int) spanList {
 	for i := 0; i < n; i++ {
 		list[i] = newBasicSpan("span.list." + itoa[i%5+1])
 		list[i].Start = fixedTime
+		if i%2 == 0 {
+			list[i].SpanLinks = []ddtrace.SpanLink{{TraceID: 1, SpanID: uint64(i)}}
+		}
 	}
 	return list
 }
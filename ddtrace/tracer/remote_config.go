@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/appsec"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/dyninst"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/normalizer"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/remoteconfig"
+
+	rc "github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
+)
+
+// configData holds the content of an APM_TRACING remote configuration file.
+type configData struct {
+	Action        string    `json:"action"`
+	ServiceTarget target    `json:"service_target"`
+	LibConfig     libConfig `json:"lib_config"`
+}
+
+// target specifies the service and environment a remote configuration applies to.
+type target struct {
+	Service string `json:"service"`
+	Env     string `json:"env"`
+}
+
+// libConfig holds the tracer settings which can be updated through remote configuration.
+// Settings left unset keep their locally configured value.
+type libConfig struct {
+	SamplingRate   *float64          `json:"tracing_sampling_rate,omitempty"`
+	SamplingRules  json.RawMessage   `json:"tracing_sampling_rules,omitempty"`
+	HeaderTags     *[]headerTag      `json:"tracing_header_tags,omitempty"`
+	ServiceMapping *[]serviceMapping `json:"tracing_service_mapping,omitempty"`
+	LogsInjection  *bool             `json:"log_injection_enabled,omitempty"`
+}
+
+// headerTag is a header to span tag mapping, as received through remote configuration.
+type headerTag struct {
+	Header  string `json:"header"`
+	TagName string `json:"tag_name"`
+}
+
+// serviceMapping is a service renaming, as received through remote configuration.
+type serviceMapping struct {
+	FromKey string `json:"from_key"`
+	ToName  string `json:"to_name"`
+}
+
+// remoteSettings holds the validated settings of an APM_TRACING configuration file. Nil
+// fields are left unset by the file.
+type remoteSettings struct {
+	rate            *float64
+	rules           []SamplingRule
+	headerTags      map[string]string
+	serviceMappings map[string]string
+	logsInjection   *bool
+}
+
+// remoteConfigClientConfig returns the remote configuration client configuration
+// matching the tracer's configuration.
+func (t *tracer) remoteConfigClientConfig() remoteconfig.ClientConfig {
+	cfg := remoteconfig.DefaultClientConfig()
+	cfg.AgentURL = t.config.agentURL.String()
+	cfg.AppVersion = t.config.version
+	cfg.Env = t.config.env
+	cfg.HTTP = t.config.httpClient
+	cfg.ServiceName = t.config.serviceName
+	return cfg
+}
+
+// newRemoteConfig creates the remote configuration client of the tracer and registers the
+// APM_TRACING product on it, allowing the tracer settings to be updated at runtime. The
+// client is shared with AppSec, and must be started with startRemoteConfig once all the
+// products are registered.
+func (t *tracer) newRemoteConfig(cfg remoteconfig.ClientConfig) error {
+	client, err := remoteconfig.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	client.RegisterProduct(rc.ProductAPMTracing)
+	client.RegisterCapability(remoteconfig.APMTracingSampleRate)
+	client.RegisterCapability(remoteconfig.APMTracingHTTPHeaderTags)
+	client.RegisterCapability(remoteconfig.APMTracingLogsInjection)
+	client.RegisterCallback(t.onRemoteConfigUpdate)
+	if t.config.dynamicInstrumentation {
+		client.RegisterProduct(dyninst.ProductLiveDebugging)
+		client.RegisterCallback(dyninst.OnRemoteConfigUpdate)
+		t.startDynamicInstrumentation()
+	}
+	t.rc = client
+	return nil
+}
+
+// needsRemoteConfig reports whether the remote configuration client is needed. Since it polls
+// the agent, it is only needed when traces are sent to the agent, or by AppSec or dynamic
+// instrumentation.
+func (t *tracer) needsRemoteConfig() bool {
+	return !t.config.agentless && !t.config.otlpExport || t.config.dynamicInstrumentation || appsec.UsesRemoteConfig()
+}
+
+// startRemoteConfig starts polling the remote configuration client, if any.
+func (t *tracer) startRemoteConfig() {
+	if t.rc != nil {
+		t.rc.Start()
+	}
+}
+
+// stopRemoteConfig stops the remote configuration client, if any.
+func (t *tracer) stopRemoteConfig() {
+	if t.rc != nil {
+		t.rc.Stop()
+	}
+	if t.stopDynamicInstrumentation != nil {
+		t.stopDynamicInstrumentation()
+	}
+}
+
+// onRemoteConfigUpdate is a remote configuration callback applying APM_TRACING updates.
+// The settings of all the configuration files currently received are applied together,
+// so that removing a file only restores the local value of the settings it held.
+func (t *tracer) onRemoteConfigUpdate(updates map[string]remoteconfig.ProductUpdate) map[string]rc.ApplyStatus {
+	statuses := map[string]rc.ApplyStatus{}
+	u, ok := updates[rc.ProductAPMTracing]
+	if !ok {
+		return statuses
+	}
+	t.rcMu.Lock()
+	defer t.rcMu.Unlock()
+	if t.rcFiles == nil {
+		t.rcFiles = make(map[string]remoteSettings)
+	}
+	for path, raw := range u {
+		if raw == nil {
+			log.Debug("Remote config: configuration %s removed, reverting its settings to the local configuration", path)
+			delete(t.rcFiles, path)
+			statuses[path] = rc.ApplyStatus{State: rc.ApplyStateAcknowledged}
+			continue
+		}
+		rs, err := t.parseRemoteConfig(raw)
+		if err != nil {
+			log.Error("Remote config: could not apply configuration %s: %v", path, err)
+			statuses[path] = rc.ApplyStatus{State: rc.ApplyStateError, Error: err.Error()}
+			continue
+		}
+		t.rcFiles[path] = rs
+		statuses[path] = rc.ApplyStatus{State: rc.ApplyStateAcknowledged}
+	}
+	t.applyRemoteConfigLocked()
+	return statuses
+}
+
+// parseRemoteConfig parses and validates the raw APM_TRACING configuration file. The
+// configuration is fully validated before being applied, so that the tracer is never left
+// with a partially applied configuration. Files targeting another service or environment
+// are rejected.
+func (t *tracer) parseRemoteConfig(raw []byte) (remoteSettings, error) {
+	var c configData
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return remoteSettings{}, fmt.Errorf("error unmarshalling JSON: %v", err)
+	}
+	if s := c.ServiceTarget.Service; s != "" && s != "*" && s != t.config.serviceName {
+		return remoteSettings{}, fmt.Errorf("service mismatch: targets %q instead of %q", s, t.config.serviceName)
+	}
+	if e := c.ServiceTarget.Env; e != "" && e != "*" && e != t.config.env {
+		return remoteSettings{}, fmt.Errorf("env mismatch: targets %q instead of %q", e, t.config.env)
+	}
+	rs := remoteSettings{
+		rate:          c.LibConfig.SamplingRate,
+		logsInjection: c.LibConfig.LogsInjection,
+	}
+	if rs.rate != nil && (*rs.rate < 0.0 || *rs.rate > 1.0) {
+		return remoteSettings{}, fmt.Errorf("tracing_sampling_rate is out of [0.0, 1.0] range: %f", *rs.rate)
+	}
+	if len(c.LibConfig.SamplingRules) > 0 && string(c.LibConfig.SamplingRules) != "null" {
+		r, err := unmarshalSamplingRules(c.LibConfig.SamplingRules, SamplingRuleTrace)
+		if err != nil {
+			return remoteSettings{}, fmt.Errorf("invalid tracing_sampling_rules: %v", err)
+		}
+		// non-nil rules override the local ones, even when empty
+		rs.rules = append([]SamplingRule{}, r...)
+	}
+	if c.LibConfig.HeaderTags != nil {
+		rs.headerTags = make(map[string]string, len(*c.LibConfig.HeaderTags))
+		for _, h := range *c.LibConfig.HeaderTags {
+			header := strings.ToLower(strings.TrimSpace(h.Header))
+			if header == "" {
+				return remoteSettings{}, fmt.Errorf("invalid tracing_header_tags: empty header name")
+			}
+			rs.headerTags[header] = strings.TrimSpace(h.TagName)
+		}
+	}
+	if c.LibConfig.ServiceMapping != nil {
+		rs.serviceMappings = make(map[string]string, len(*c.LibConfig.ServiceMapping))
+		for _, m := range *c.LibConfig.ServiceMapping {
+			if m.FromKey == "" || m.ToName == "" {
+				return remoteSettings{}, fmt.Errorf("invalid tracing_service_mapping: empty service name")
+			}
+			rs.serviceMappings[m.FromKey] = m.ToName
+		}
+	}
+	return rs, nil
+}
+
+// applyRemoteConfigLocked applies the settings of the configuration files in t.rcFiles.
+// When several files hold the same setting, the one whose path sorts last wins. The
+// settings held by none of them use their local value. t.rcMu must be held.
+func (t *tracer) applyRemoteConfigLocked() {
+	paths := make([]string, 0, len(t.rcFiles))
+	for path := range t.rcFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var merged remoteSettings
+	for _, path := range paths {
+		rs := t.rcFiles[path]
+		if rs.rate != nil {
+			merged.rate = rs.rate
+		}
+		if rs.rules != nil {
+			merged.rules = rs.rules
+		}
+		if rs.headerTags != nil {
+			merged.headerTags = rs.headerTags
+		}
+		if rs.serviceMappings != nil {
+			merged.serviceMappings = rs.serviceMappings
+		}
+		if rs.logsInjection != nil {
+			merged.logsInjection = rs.logsInjection
+		}
+	}
+	t.rulesSampling.traces.setRemoteConfig(merged.rate, merged.rules)
+	if merged.headerTags == nil {
+		merged.headerTags = normalizer.HeaderTags(t.config.headerAsTags)
+	}
+	globalconfig.SetHeaderTags(merged.headerTags)
+	t.rcServiceMappings = merged.serviceMappings
+	logsInjection := t.config.logsInjection
+	if merged.logsInjection != nil {
+		logsInjection = *merged.logsInjection
+	}
+	globalconfig.SetLogsInjection(logsInjection)
+}
+
+// mapService returns the name which the given service is renamed to, and whether it is
+// renamed. The mappings received through remote configuration replace the local ones.
+func (t *tracer) mapService(service string) (string, bool) {
+	t.rcMu.RLock()
+	mappings := t.rcServiceMappings
+	t.rcMu.RUnlock()
+	if mappings == nil {
+		mappings = t.config.serviceMappings
+	}
+	to, ok := mappings[service]
+	return to, ok
+}
@@ -0,0 +1,236 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/remoteconfig"
+
+	rc "github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnRemoteConfigUpdate(t *testing.T) {
+	const path = "datadog/2/APM_TRACING/config/config"
+	update := func(raw string) map[string]remoteconfig.ProductUpdate {
+		u := remoteconfig.ProductUpdate{path: nil}
+		if raw != "" {
+			u[path] = []byte(raw)
+		}
+		return map[string]remoteconfig.ProductUpdate{rc.ProductAPMTracing: u}
+	}
+
+	t.Run("sample-rate", func(t *testing.T) {
+		assert := assert.New(t)
+		t.Setenv("DD_TRACE_SAMPLE_RATE", "0.1")
+		tr := newTracer()
+		defer tr.Stop()
+
+		statuses := tr.onRemoteConfigUpdate(update(`{"lib_config": {"tracing_sampling_rate": 0.5}}`))
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[path].State)
+		assert.Equal(0.5, tr.rulesSampling.traces.rate())
+
+		s := tr.newRootSpan("web.request", "test-service", "/")
+		assert.True(tr.rulesSampling.SampleTrace(s))
+		assert.Equal(0.5, s.Metrics[keyRulesSamplerAppliedRate])
+
+		// removing the configuration restores the local rate
+		statuses = tr.onRemoteConfigUpdate(update(""))
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[path].State)
+		assert.Equal(0.1, tr.rulesSampling.traces.rate())
+	})
+
+	t.Run("sampling-rules", func(t *testing.T) {
+		assert := assert.New(t)
+		tr := newTracer(WithSamplingRules([]SamplingRule{ServiceRule("test-service", 0.2)}))
+		defer tr.Stop()
+
+		statuses := tr.onRemoteConfigUpdate(update(`{"lib_config": {"tracing_sampling_rules": [{"service": "test-service", "sample_rate": 1.0}]}}`))
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[path].State)
+		s := tr.newRootSpan("web.request", "test-service", "/")
+		assert.True(tr.rulesSampling.SampleTrace(s))
+		assert.Equal(1.0, s.Metrics[keyRulesSamplerAppliedRate])
+		assert.EqualValues(ext.PriorityUserKeep, s.Metrics[keySamplingPriority])
+
+		// empty rules remove the local ones
+		statuses = tr.onRemoteConfigUpdate(update(`{"lib_config": {"tracing_sampling_rules": []}}`))
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[path].State)
+		s = tr.newRootSpan("web.request", "test-service", "/")
+		assert.False(tr.rulesSampling.SampleTrace(s))
+
+		tr.onRemoteConfigUpdate(update(""))
+		s = tr.newRootSpan("web.request", "test-service", "/")
+		assert.True(tr.rulesSampling.SampleTrace(s))
+		assert.Equal(0.2, s.Metrics[keyRulesSamplerAppliedRate])
+	})
+
+	t.Run("header-tags", func(t *testing.T) {
+		assert := assert.New(t)
+		defer globalconfig.SetHeaderTags(nil)
+		tr := newTracer(WithHeaderTags([]string{"X-Local"}))
+		defer tr.Stop()
+
+		statuses := tr.onRemoteConfigUpdate(update(`{"lib_config": {"tracing_header_tags": [{"header": "X-Remote", "tag_name": "remote.tag"}, {"header": "X-Other"}]}}`))
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[path].State)
+		assert.Equal(map[string]string{"x-remote": "remote.tag", "x-other": ""}, globalconfig.HeaderTagMap())
+
+		tr.onRemoteConfigUpdate(update(""))
+		assert.Equal(map[string]string{"x-local": ""}, globalconfig.HeaderTagMap())
+	})
+
+	t.Run("service-mapping", func(t *testing.T) {
+		assert := assert.New(t)
+		tr := newTracer(WithServiceMapping("db", "local-db"))
+		defer tr.Stop()
+
+		statuses := tr.onRemoteConfigUpdate(update(`{"lib_config": {"tracing_service_mapping": [{"from_key": "db", "to_name": "remote-db"}]}}`))
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[path].State)
+		s := tr.newRootSpan("db.query", "db", "SELECT 1")
+		assert.Equal("remote-db", s.Service)
+
+		tr.onRemoteConfigUpdate(update(""))
+		s = tr.newRootSpan("db.query", "db", "SELECT 1")
+		assert.Equal("local-db", s.Service)
+	})
+
+	t.Run("logs-injection", func(t *testing.T) {
+		assert := assert.New(t)
+		defer globalconfig.SetLogsInjection(true)
+		tr := newTracer()
+		defer tr.Stop()
+		assert.True(globalconfig.LogsInjection())
+
+		statuses := tr.onRemoteConfigUpdate(update(`{"lib_config": {"log_injection_enabled": false}}`))
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[path].State)
+		assert.False(globalconfig.LogsInjection())
+
+		tr.onRemoteConfigUpdate(update(""))
+		assert.True(globalconfig.LogsInjection())
+	})
+
+	t.Run("target", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			target string
+			state  rc.ApplyState
+		}{
+			"match":              {`{"service": "test-service", "env": "prod"}`, rc.ApplyStateAcknowledged},
+			"wildcard":           {`{"service": "*", "env": "*"}`, rc.ApplyStateAcknowledged},
+			"other-service":      {`{"service": "other-service", "env": "prod"}`, rc.ApplyStateError},
+			"other-env":          {`{"service": "test-service", "env": "staging"}`, rc.ApplyStateError},
+			"service-only":       {`{"service": "test-service"}`, rc.ApplyStateAcknowledged},
+			"other-service-only": {`{"service": "other-service"}`, rc.ApplyStateError},
+		} {
+			t.Run(name, func(t *testing.T) {
+				tr := newTracer(WithService("test-service"), WithEnv("prod"))
+				defer tr.Stop()
+
+				statuses := tr.onRemoteConfigUpdate(update(`{"service_target": ` + tc.target + `, "lib_config": {"tracing_sampling_rate": 0.5}}`))
+				assert.Equal(t, tc.state, statuses[path].State)
+				if tc.state == rc.ApplyStateAcknowledged {
+					assert.Equal(t, 0.5, tr.rulesSampling.traces.rate())
+				} else {
+					assert.True(t, math.IsNaN(tr.rulesSampling.traces.rate()))
+				}
+			})
+		}
+	})
+
+	t.Run("several-files", func(t *testing.T) {
+		assert := assert.New(t)
+		defer globalconfig.SetHeaderTags(nil)
+		const other = "datadog/2/APM_TRACING/other/config"
+		tr := newTracer(WithHeaderTags([]string{"X-Local"}))
+		defer tr.Stop()
+
+		statuses := tr.onRemoteConfigUpdate(map[string]remoteconfig.ProductUpdate{rc.ProductAPMTracing: {
+			path:  []byte(`{"lib_config": {"tracing_sampling_rate": 0.5}}`),
+			other: []byte(`{"lib_config": {"tracing_header_tags": [{"header": "X-Remote"}]}}`),
+		}})
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[path].State)
+		assert.Equal(rc.ApplyStateAcknowledged, statuses[other].State)
+		assert.Equal(0.5, tr.rulesSampling.traces.rate())
+		assert.Equal(map[string]string{"x-remote": ""}, globalconfig.HeaderTagMap())
+
+		// removing a file only restores the local value of its own settings
+		tr.onRemoteConfigUpdate(map[string]remoteconfig.ProductUpdate{rc.ProductAPMTracing: {other: nil}})
+		assert.Equal(0.5, tr.rulesSampling.traces.rate())
+		assert.Equal(map[string]string{"x-local": ""}, globalconfig.HeaderTagMap())
+
+		tr.onRemoteConfigUpdate(update(""))
+		assert.True(math.IsNaN(tr.rulesSampling.traces.rate()))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, raw := range map[string]string{
+			"json":    `{"lib_config": `,
+			"rate":    `{"lib_config": {"tracing_sampling_rate": 1.5}}`,
+			"rules":   `{"lib_config": {"tracing_sampling_rate": 0.5, "tracing_sampling_rules": [{"service": "test-service"}]}}`,
+			"headers": `{"lib_config": {"tracing_sampling_rate": 0.5, "tracing_header_tags": [{"tag_name": "tag"}]}}`,
+			"mapping": `{"lib_config": {"tracing_sampling_rate": 0.5, "tracing_service_mapping": [{"from_key": "db"}]}}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				tr := newTracer()
+				defer tr.Stop()
+
+				statuses := tr.onRemoteConfigUpdate(update(raw))
+				require.Equal(t, rc.ApplyStateError, statuses[path].State)
+				assert.NotEmpty(t, statuses[path].Error)
+				// nothing was applied
+				assert.True(t, math.IsNaN(tr.rulesSampling.traces.rate()))
+			})
+		}
+	})
+
+	t.Run("other-product", func(t *testing.T) {
+		tr := newTracer()
+		defer tr.Stop()
+
+		statuses := tr.onRemoteConfigUpdate(map[string]remoteconfig.ProductUpdate{
+			rc.ProductASMFeatures: {path: []byte(`{"lib_config": {"tracing_sampling_rate": 0.5}}`)},
+		})
+		assert.Empty(t, statuses)
+		assert.True(t, math.IsNaN(tr.rulesSampling.traces.rate()))
+	})
+}
+
+func TestNeedsRemoteConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		opts  []StartOption
+		needs bool
+	}{
+		{name: "agent", needs: true},
+		{name: "agentless", opts: []StartOption{WithAgentlessMode(true)}},
+		{name: "otlp", opts: []StartOption{WithOTLPExporter("http://localhost:4318/v1/traces")}},
+		{name: "dynamic-instrumentation", opts: []StartOption{WithAgentlessMode(true), WithDynamicInstrumentation(true)}, needs: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DD_APPSEC_ENABLED", "false")
+			t.Setenv("DD_API_KEY", "key")
+			tr := newTracer(tt.opts...)
+			defer tr.Stop()
+			assert.Equal(t, tt.needs, tr.needsRemoteConfig())
+		})
+	}
+}
+
+func TestNewRemoteConfig(t *testing.T) {
+	tr := newTracer()
+	defer tr.Stop()
+
+	require.NoError(t, tr.newRemoteConfig(tr.remoteConfigClientConfig()))
+	require.NotNil(t, tr.rc)
+	tr.startRemoteConfig()
+	assert.Contains(t, tr.rc.Products, rc.ProductAPMTracing)
+	assert.Contains(t, tr.rc.Capabilities, remoteconfig.APMTracingSampleRate)
+	assert.Contains(t, tr.rc.Capabilities, remoteconfig.APMTracingHTTPHeaderTags)
+	assert.Contains(t, tr.rc.Capabilities, remoteconfig.APMTracingLogsInjection)
+}
@@ -53,7 +53,7 @@ func (r *rulesSampler) HasSpanRules() bool { return r.spans.enabled() }
 func (r *rulesSampler) TraceRateLimit() (float64, bool) { return r.traces.limit() }
 
 // SamplingRule is used for applying sampling rates to spans that match
-// the service name, operation name or both.
+// the service name, operation name, resource name and tags, or any of them.
 // For basic usage, consider using the helper functions ServiceRule, NameRule, etc.
 type SamplingRule struct {
 	// Service specifies the regex pattern that a span service name must match.
@@ -62,6 +62,13 @@ type SamplingRule struct {
 	// Name specifies the regex pattern that a span operation name must match.
 	Name *regexp.Regexp
 
+	// Resource specifies the regex pattern that a span resource name must match.
+	Resource *regexp.Regexp
+
+	// Tags specifies the regex patterns that the values of the span tags must match,
+	// by tag name. Numeric tags are matched in their decimal representation.
+	Tags map[string]*regexp.Regexp
+
 	// Rate specifies the sampling rate that should be applied to spans that match
 	// service and/or name of the rule.
 	Rate float64
@@ -70,10 +77,12 @@ type SamplingRule struct {
 	// If not specified, the default is no limit.
 	MaxPerSecond float64
 
-	ruleType     SamplingRuleType
-	exactService string
-	exactName    string
-	limiter      *rateLimiter
+	ruleType      SamplingRuleType
+	exactService  string
+	exactName     string
+	exactResource string
+	exactTags     map[string]string
+	limiter       *rateLimiter
 }
 
 // match returns true when the span's details match all the expected values in the rule.
@@ -88,9 +97,49 @@ func (sr *SamplingRule) match(s *span) bool {
 	} else if sr.exactName != "" && sr.exactName != s.Name {
 		return false
 	}
+	if !sr.matchesResourceOrTags() {
+		return true
+	}
+	// The resource and the tags can change until the span finishes.
+	s.RLock()
+	defer s.RUnlock()
+	if sr.Resource != nil && !sr.Resource.MatchString(s.Resource) {
+		return false
+	} else if sr.exactResource != "" && sr.exactResource != s.Resource {
+		return false
+	}
+	for k, re := range sr.Tags {
+		v, ok := spanTagValue(s, k)
+		if !ok || !re.MatchString(v) {
+			return false
+		}
+	}
+	for k, want := range sr.exactTags {
+		if v, ok := spanTagValue(s, k); !ok || v != want {
+			return false
+		}
+	}
 	return true
 }
 
+// matchesResourceOrTags reports whether the rule matches spans on their resource name or tags,
+// which are often only known once the span is about to finish.
+func (sr *SamplingRule) matchesResourceOrTags() bool {
+	return sr.Resource != nil || sr.exactResource != "" || len(sr.Tags) > 0 || len(sr.exactTags) > 0
+}
+
+// spanTagValue returns the value of the tag k of s, formatting numeric tags in decimal.
+// s must already be locked.
+func spanTagValue(s *span, k string) (string, bool) {
+	if v, ok := s.Meta[k]; ok {
+		return v, true
+	}
+	if v, ok := s.Metrics[k]; ok {
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
+
 // SamplingRuleType represents a type of sampling rule spans are matched against.
 type SamplingRuleType int
 
@@ -147,6 +196,45 @@ func NameServiceRule(name string, service string, rate float64) SamplingRule {
 	}
 }
 
+// TagsResourceRule returns a SamplingRule that applies the provided sampling rate to spans
+// matching all of the provided tags, resource, operation and service names. Each of them can be
+// a glob pattern, in which '*' matches any sequence of characters and '?' a single character,
+// or otherwise has to match exactly. Empty values and tags match all spans.
+//
+// As resource names and tags are often set after a span starts, rules matching them are applied
+// again to the local root span when the trace context is injected, or else when the local root
+// span finishes.
+func TagsResourceRule(tags map[string]string, resource, name, service string, rate float64) SamplingRule {
+	sr := SamplingRule{Rate: rate}
+	sr.Service, sr.exactService = newMatcher(service)
+	sr.Name, sr.exactName = newMatcher(name)
+	sr.Resource, sr.exactResource = newMatcher(resource)
+	for k, v := range tags {
+		re, exact := newMatcher(v)
+		if re != nil {
+			if sr.Tags == nil {
+				sr.Tags = make(map[string]*regexp.Regexp)
+			}
+			sr.Tags[k] = re
+			continue
+		}
+		if sr.exactTags == nil {
+			sr.exactTags = make(map[string]string)
+		}
+		sr.exactTags[k] = exact
+	}
+	return sr
+}
+
+// newMatcher returns the glob regex matching pattern if it has wildcards, or else pattern
+// itself as an exact value to match.
+func newMatcher(pattern string) (*regexp.Regexp, string) {
+	if strings.ContainsAny(pattern, "*?") {
+		return globMatch(pattern), ""
+	}
+	return nil, pattern
+}
+
 // RateRule returns a SamplingRule that applies the provided sampling rate to all spans.
 func RateRule(rate float64) SamplingRule {
 	return SamplingRule{
@@ -185,7 +273,7 @@ func SpanNameServiceMPSRule(name, service string, rate, limit float64) SamplingR
 }
 
 // traceRulesSampler allows a user-defined list of rules to apply to traces.
-// These rules can match based on the span's Service, Name or both.
+// These rules can match based on the span's Service, Name, Resource and tags.
 // When making a sampling decision, the rules are checked in order until
 // a match is found.
 // If a match is found, the rate from that rule is used.
@@ -198,19 +286,30 @@ func SpanNameServiceMPSRule(name, service string, rate, limit float64) SamplingR
 // limit can be defined using the DD_TRACE_RATE_LIMIT environment variable.
 // Its value is the number of spans to sample per second.
 // Spans that matched the rules but exceeded the rate limit are not sampled.
+//
+// The rules and the global rate can be replaced at runtime through remote
+// configuration, in which case the locally configured values are kept aside
+// so that they can be restored once the remote configuration is removed.
 type traceRulesSampler struct {
+	m          sync.RWMutex
 	rules      []SamplingRule // the rules to match spans with
 	globalRate float64        // a rate to apply when no rules match a span
 	limiter    *rateLimiter   // used to limit the volume of spans sampled
+
+	localRules []SamplingRule // the rules configured at startup
+	localRate  float64        // the global rate configured at startup
 }
 
 // newTraceRulesSampler configures a *traceRulesSampler instance using the given set of rules.
 // Invalid rules or environment variable values are tolerated, by logging warnings and then ignoring them.
 func newTraceRulesSampler(rules []SamplingRule) *traceRulesSampler {
+	rate := globalSampleRate()
 	return &traceRulesSampler{
 		rules:      rules,
-		globalRate: globalSampleRate(),
+		globalRate: rate,
 		limiter:    newRateLimiter(),
+		localRules: rules,
+		localRate:  rate,
 	}
 }
 
@@ -234,20 +333,55 @@ func globalSampleRate() float64 {
 	return defaultRate
 }
 
+// lazy reports whether any of the rules matches spans on their resource name or tags, in
+// which case the rules have to be applied again once these are known.
+func (rs *traceRulesSampler) lazy() bool {
+	rs.m.RLock()
+	defer rs.m.RUnlock()
+	for i := range rs.rules {
+		if rs.rules[i].matchesResourceOrTags() {
+			return true
+		}
+	}
+	return false
+}
+
 func (rs *traceRulesSampler) enabled() bool {
+	rs.m.RLock()
+	defer rs.m.RUnlock()
 	return len(rs.rules) > 0 || !math.IsNaN(rs.globalRate)
 }
 
+// setRemoteConfig atomically replaces the rules and the global rate of the sampler with
+// the ones received through remote configuration. A nil rate or nil rules mean that the
+// remote configuration does not override them, and the local values are used instead.
+// Empty, non-nil rules remove all the rules.
+func (rs *traceRulesSampler) setRemoteConfig(rate *float64, rules []SamplingRule) {
+	rs.m.Lock()
+	defer rs.m.Unlock()
+	rs.globalRate = rs.localRate
+	if rate != nil {
+		rs.globalRate = *rate
+	}
+	rs.rules = rs.localRules
+	if rules != nil {
+		rs.rules = rules
+	}
+}
+
+// rate returns the global sampling rate currently in use.
+func (rs *traceRulesSampler) rate() float64 {
+	rs.m.RLock()
+	defer rs.m.RUnlock()
+	return rs.globalRate
+}
+
 // apply uses the sampling rules to determine the sampling rate for the
 // provided span. If the rules don't match, and a default rate hasn't been
 // set using DD_TRACE_SAMPLE_RATE, then it returns false and the span is not
 // modified.
 func (rs *traceRulesSampler) apply(span *span) bool {
-	if !rs.enabled() {
-		// short path when disabled
-		return false
-	}
-
+	rs.m.RLock()
 	var matched bool
 	rate := rs.globalRate
 	for _, rule := range rs.rules {
@@ -257,6 +391,7 @@ func (rs *traceRulesSampler) apply(span *span) bool {
 			break
 		}
 	}
+	rs.m.RUnlock()
 	if !matched && math.IsNaN(rate) {
 		// no matching rule or global rate, so we want to fall back
 		// to priority sampling
@@ -495,10 +630,12 @@ func unmarshalSamplingRules(b []byte, spanType SamplingRuleType) ([]SamplingRule
 		return nil, nil
 	}
 	var jsonRules []struct {
-		Service      string      `json:"service"`
-		Name         string      `json:"name"`
-		Rate         json.Number `json:"sample_rate"`
-		MaxPerSecond float64     `json:"max_per_second"`
+		Service      string            `json:"service"`
+		Name         string            `json:"name"`
+		Resource     string            `json:"resource"`
+		Tags         map[string]string `json:"tags"`
+		Rate         json.Number       `json:"sample_rate"`
+		MaxPerSecond float64           `json:"max_per_second"`
 	}
 	err := json.Unmarshal(b, &jsonRules)
 	if err != nil {
@@ -526,14 +663,13 @@ func unmarshalSamplingRules(b []byte, spanType SamplingRuleType) ([]SamplingRule
 		}
 		switch spanType {
 		case SamplingRuleSpan:
-			rules = append(rules, SamplingRule{
-				Service:      globMatch(v.Service),
-				Name:         globMatch(v.Name),
-				Rate:         rate,
-				MaxPerSecond: v.MaxPerSecond,
-				limiter:      newSingleSpanRateLimiter(v.MaxPerSecond),
-				ruleType:     SamplingRuleSpan,
-			})
+			rule := TagsResourceRule(v.Tags, v.Resource, "", "", rate)
+			rule.Service = globMatch(v.Service)
+			rule.Name = globMatch(v.Name)
+			rule.MaxPerSecond = v.MaxPerSecond
+			rule.limiter = newSingleSpanRateLimiter(v.MaxPerSecond)
+			rule.ruleType = SamplingRuleSpan
+			rules = append(rules, rule)
 		case SamplingRuleTrace:
 			if v.Rate == "" {
 				errs = append(errs, fmt.Sprintf("at index %d: rate not provided", i))
@@ -549,14 +685,15 @@ func unmarshalSamplingRules(b []byte, spanType SamplingRuleType) ([]SamplingRule
 				continue
 			}
 
-			switch {
-			case v.Service != "" && v.Name != "":
-				rules = append(rules, NameServiceRule(v.Name, v.Service, rate))
-			case v.Service != "":
-				rules = append(rules, ServiceRule(v.Service, rate))
-			case v.Name != "":
-				rules = append(rules, NameRule(v.Name, rate))
+			if v.Service == "" && v.Name == "" && v.Resource == "" && len(v.Tags) == 0 {
+				continue
 			}
+			// The service and operation names are matched exactly, while the resource
+			// names and the tags can also be matched with glob patterns.
+			rule := TagsResourceRule(v.Tags, v.Resource, "", "", rate)
+			rule.exactService = v.Service
+			rule.exactName = v.Name
+			rules = append(rules, rule)
 		}
 	}
 	if len(errs) != 0 {
@@ -568,11 +705,13 @@ func unmarshalSamplingRules(b []byte, spanType SamplingRuleType) ([]SamplingRule
 // MarshalJSON implements the json.Marshaler interface.
 func (sr *SamplingRule) MarshalJSON() ([]byte, error) {
 	s := struct {
-		Service      string   `json:"service"`
-		Name         string   `json:"name"`
-		Rate         float64  `json:"sample_rate"`
-		Type         string   `json:"type"`
-		MaxPerSecond *float64 `json:"max_per_second,omitempty"`
+		Service      string            `json:"service"`
+		Name         string            `json:"name"`
+		Resource     string            `json:"resource,omitempty"`
+		Tags         map[string]string `json:"tags,omitempty"`
+		Rate         float64           `json:"sample_rate"`
+		Type         string            `json:"type"`
+		MaxPerSecond *float64          `json:"max_per_second,omitempty"`
 	}{}
 	if sr.exactService != "" {
 		s.Service = sr.exactService
@@ -584,6 +723,20 @@ func (sr *SamplingRule) MarshalJSON() ([]byte, error) {
 	} else if sr.Name != nil {
 		s.Name = fmt.Sprintf("%s", sr.Name)
 	}
+	if sr.exactResource != "" {
+		s.Resource = sr.exactResource
+	} else if sr.Resource != nil {
+		s.Resource = fmt.Sprintf("%s", sr.Resource)
+	}
+	if n := len(sr.Tags) + len(sr.exactTags); n > 0 {
+		s.Tags = make(map[string]string, n)
+		for k, v := range sr.exactTags {
+			s.Tags[k] = v
+		}
+		for k, v := range sr.Tags {
+			s.Tags[k] = fmt.Sprintf("%s", v)
+		}
+	}
 	s.Rate = sr.Rate
 	s.Type = fmt.Sprintf("%v(%d)", sr.ruleType.String(), sr.ruleType)
 	if sr.MaxPerSecond != 0 {
@@ -18,8 +18,10 @@ import (
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/time/rate"
 )
 
@@ -253,7 +255,7 @@ func TestRuleEnvVars(t *testing.T) {
 				// invalid rule ignored
 				value:  `[{"service": "abcd", "sample_rate": 42.0}, {"service": "abcd", "sample_rate": 0.2}]`,
 				ruleN:  1,
-				errStr: "\n\tat index 0: ignoring rule {Service:abcd Name: Rate:42.0 MaxPerSecond:0}: rate is out of [0.0, 1.0] range",
+				errStr: "\n\tat index 0: ignoring rule {Service:abcd Name: Resource: Tags:map[] Rate:42.0 MaxPerSecond:0}: rate is out of [0.0, 1.0] range",
 			}, {
 				value:  `not JSON at all`,
 				errStr: "\n\terror unmarshalling JSON: invalid character 'o' in literal null (expecting 'u')",
@@ -303,7 +305,7 @@ func TestRuleEnvVars(t *testing.T) {
 				// invalid rule ignored
 				value:  `[{"service": "abcd", "sample_rate": 42.0}, {"service": "abcd", "sample_rate": 0.2}]`,
 				ruleN:  1,
-				errStr: "\n\tat index 0: ignoring rule {Service:abcd Name: Rate:42.0 MaxPerSecond:0}: rate is out of [0.0, 1.0] range",
+				errStr: "\n\tat index 0: ignoring rule {Service:abcd Name: Resource: Tags:map[] Rate:42.0 MaxPerSecond:0}: rate is out of [0.0, 1.0] range",
 			}, {
 				value:  `not JSON at all`,
 				errStr: "\n\terror unmarshalling JSON: invalid character 'o' in literal null (expecting 'u')",
@@ -657,6 +659,132 @@ func TestRulesSamplerConcurrency(_ *testing.T) {
 	wg.Wait()
 }
 
+func TestSamplingRulesResourceTags(t *testing.T) {
+	makeSpan := func(resource string, tags map[string]interface{}) *span {
+		s := newSpan("http.request", "test-service", resource, random.Uint64(), random.Uint64(), 0)
+		for k, v := range tags {
+			s.SetTag(k, v)
+		}
+		return s
+	}
+
+	t.Run("match", func(t *testing.T) {
+		for _, tt := range []struct {
+			name  string
+			rule  SamplingRule
+			match bool
+		}{
+			{name: "exact-resource", rule: TagsResourceRule(nil, "POST /checkout", "", "", 1), match: true},
+			{name: "glob-resource", rule: TagsResourceRule(nil, "POST /check*", "", "", 1), match: true},
+			{name: "other-resource", rule: TagsResourceRule(nil, "GET /health", "", "", 1)},
+			{name: "exact-tag", rule: TagsResourceRule(map[string]string{"customer.tier": "enterprise"}, "", "", "", 1), match: true},
+			{name: "glob-tag", rule: TagsResourceRule(map[string]string{"customer.tier": "enter?rise"}, "", "", "", 1), match: true},
+			{name: "numeric-tag", rule: TagsResourceRule(map[string]string{"cart.items": "3"}, "", "", "", 1), match: true},
+			{name: "other-tag", rule: TagsResourceRule(map[string]string{"customer.tier": "free"}, "", "", "", 1)},
+			{name: "missing-tag", rule: TagsResourceRule(map[string]string{"customer.id": "*"}, "", "", "", 1)},
+			{name: "all", rule: TagsResourceRule(map[string]string{"customer.tier": "enterprise"}, "POST *", "http.*", "test-service", 1), match: true},
+			{name: "other-service", rule: TagsResourceRule(map[string]string{"customer.tier": "enterprise"}, "POST *", "http.*", "toast-service", 1)},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				span := makeSpan("POST /checkout", map[string]interface{}{"customer.tier": "enterprise", "cart.items": 3})
+				rs := newRulesSampler([]SamplingRule{tt.rule}, nil)
+				assert.Equal(t, tt.match, rs.SampleTrace(span))
+			})
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_TRACE_SAMPLING_RULES", `[
+			{"resource": "GET /health", "sample_rate": 0.01},
+			{"service": "test-service", "tags": {"customer.tier": "enterprise"}, "sample_rate": 1},
+			{"resource": "POST /checkout*", "sample_rate": 1}
+		]`)
+		rules, _, err := samplingRulesFromEnv()
+		require.NoError(t, err)
+		require.Len(t, rules, 3)
+		assert.Equal(t, "GET /health", rules[0].exactResource)
+		assert.Equal(t, "test-service", rules[1].exactService)
+		assert.Equal(t, map[string]string{"customer.tier": "enterprise"}, rules[1].exactTags)
+		assert.True(t, rules[2].Resource.MatchString("POST /checkout/confirm"))
+
+		rs := newRulesSampler(rules, nil)
+		span := makeSpan("POST /checkout", nil)
+		assert.True(t, rs.SampleTrace(span))
+		assert.Equal(t, 1.0, span.Metrics[keyRulesSamplerAppliedRate])
+		assert.False(t, rs.SampleTrace(makeSpan("GET /users", nil)))
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithSamplingRules([]SamplingRule{
+			TagsResourceRule(nil, "GET /health", "", "", 0),
+			TagsResourceRule(map[string]string{"customer.tier": "enterprise"}, "", "", "", 1),
+		}))
+		defer stop()
+
+		// the resource and the tags are set after the span started
+		root := tracer.StartSpan("http.request").(*span)
+		root.SetTag(ext.ResourceName, "GET /health")
+		root.Finish()
+		p, ok := root.context.samplingPriority()
+		assert.True(t, ok)
+		assert.Equal(t, ext.PriorityUserReject, p)
+		assert.Equal(t, 0.0, root.Metrics[keyRulesSamplerAppliedRate])
+
+		root = tracer.StartSpan("http.request").(*span)
+		root.SetTag("customer.tier", "enterprise")
+		root.Finish()
+		p, _ = root.context.samplingPriority()
+		assert.Equal(t, ext.PriorityUserKeep, p)
+		assert.Equal(t, "-3", root.context.trace.propagatingTags[keyDecisionMaker])
+	})
+
+	t.Run("propagated", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithSamplingRules([]SamplingRule{
+			TagsResourceRule(nil, "GET /health", "", "", 0),
+		}))
+		defer stop()
+
+		root := tracer.StartSpan("http.request").(*span)
+		require.NoError(t, tracer.Inject(root.Context(), TextMapCarrier(map[string]string{})))
+		before, _ := root.context.samplingPriority()
+		// changes made after the trace context was propagated don't affect the decision
+		root.SetTag(ext.ResourceName, "GET /health")
+		root.Finish()
+		p, _ := root.context.samplingPriority()
+		assert.Equal(t, before, p)
+		assert.NotEqual(t, ext.PriorityUserReject, p)
+	})
+
+	t.Run("manual", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithSamplingRules([]SamplingRule{
+			TagsResourceRule(nil, "GET /health", "", "", 0),
+		}))
+		defer stop()
+
+		root := tracer.StartSpan("http.request").(*span)
+		root.SetTag(ext.ManualKeep, true)
+		root.SetTag(ext.ResourceName, "GET /health")
+		root.Finish()
+		p, _ := root.context.samplingPriority()
+		assert.Equal(t, ext.PriorityUserKeep, p)
+	})
+
+	t.Run("appsec", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithSamplingRules([]SamplingRule{
+			TagsResourceRule(nil, "GET /health", "", "", 0),
+		}))
+		defer stop()
+
+		root := tracer.StartSpan("http.request").(*span)
+		root.SetTag(ext.ManualKeep, samplernames.AppSec)
+		root.SetTag(ext.ResourceName, "GET /health")
+		root.Finish()
+		p, _ := root.context.samplingPriority()
+		assert.Equal(t, ext.PriorityUserKeep, p)
+		assert.NotContains(t, root.Metrics, keyRulesSamplerAppliedRate)
+	})
+}
+
 func TestRulesSamplerInternals(t *testing.T) {
 	makeSpanAt := func(op string, svc string, ts time.Time) *span {
 		s := newSpan(op, svc, "", 0, 0, 0)
@@ -934,18 +1062,20 @@ func TestSamplingRuleMarshall(t *testing.T) {
 		in  SamplingRule
 		out string
 	}{
-		{SamplingRule{nil, nil, 0, 0, 0, "srv", "ops", nil},
+		{SamplingRule{exactService: "srv", exactName: "ops"},
 			`{"service":"srv","name":"ops","sample_rate":0,"type":"trace(0)"}`},
-		{SamplingRule{regexp.MustCompile("srv.[0-9]+]"), nil, 0, 0, 0, "srv", "ops", nil},
+		{SamplingRule{Service: regexp.MustCompile("srv.[0-9]+]"), exactService: "srv", exactName: "ops"},
 			`{"service":"srv","name":"ops","sample_rate":0,"type":"trace(0)"}`},
-		{SamplingRule{regexp.MustCompile("srv.*"), regexp.MustCompile("ops.[0-9]+]"), 0, 0, 0, "", "", nil},
+		{SamplingRule{Service: regexp.MustCompile("srv.*"), Name: regexp.MustCompile("ops.[0-9]+]")},
 			`{"service":"srv.*","name":"ops.[0-9]+]","sample_rate":0,"type":"trace(0)"}`},
-		{SamplingRule{regexp.MustCompile("srv.[0-9]+]"), regexp.MustCompile("ops.[0-9]+]"), 0.55, 0, 0, "", "", nil},
+		{SamplingRule{Service: regexp.MustCompile("srv.[0-9]+]"), Name: regexp.MustCompile("ops.[0-9]+]"), Rate: 0.55},
 			`{"service":"srv.[0-9]+]","name":"ops.[0-9]+]","sample_rate":0.55,"type":"trace(0)"}`},
-		{SamplingRule{regexp.MustCompile("srv.[0-9]+]"), regexp.MustCompile("ops.[0-9]+]"), 0.55, 0, 1, "", "", nil},
+		{SamplingRule{Service: regexp.MustCompile("srv.[0-9]+]"), Name: regexp.MustCompile("ops.[0-9]+]"), Rate: 0.55, ruleType: SamplingRuleSpan},
 			`{"service":"srv.[0-9]+]","name":"ops.[0-9]+]","sample_rate":0.55,"type":"span(1)"}`},
-		{SamplingRule{regexp.MustCompile("srv.[0-9]+]"), regexp.MustCompile("ops.[0-9]+]"), 0.55, 1000, 1, "", "", nil},
+		{SamplingRule{Service: regexp.MustCompile("srv.[0-9]+]"), Name: regexp.MustCompile("ops.[0-9]+]"), Rate: 0.55, MaxPerSecond: 1000, ruleType: SamplingRuleSpan},
 			`{"service":"srv.[0-9]+]","name":"ops.[0-9]+]","sample_rate":0.55,"type":"span(1)","max_per_second":1000}`},
+		{TagsResourceRule(map[string]string{"tier": "enterprise", "region": "eu-*"}, "POST /checkout", "", "srv", 1),
+			`{"service":"srv","name":"","resource":"POST /checkout","tags":{"region":"^eu-.*$","tier":"enterprise"},"sample_rate":1,"type":"trace(0)"}`},
 	} {
 		m, err := tt.in.MarshalJSON()
 		assert.Nil(t, err)
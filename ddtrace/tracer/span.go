@@ -26,6 +26,7 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/internal"
 	sharedinternal "github.com/lannguyen-c0x12c/dd-trace-go/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/dyninst"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
@@ -46,9 +47,11 @@ type (
 )
 
 var (
-	_ ddtrace.Span   = (*span)(nil)
-	_ msgp.Encodable = (*spanList)(nil)
-	_ msgp.Decodable = (*spanLists)(nil)
+	_ ddtrace.Span           = (*span)(nil)
+	_ ddtrace.SpanWithLinks  = (*span)(nil)
+	_ ddtrace.SpanWithEvents = (*span)(nil)
+	_ msgp.Encodable         = (*spanList)(nil)
+	_ msgp.Decodable         = (*spanLists)(nil)
 )
 
 // errorConfig holds customization options for setting error tags.
@@ -76,6 +79,9 @@ type span struct {
 	ParentID uint64             `msg:"parent_id"`         // identifier of the span's direct parent
 	Error    int32              `msg:"error"`             // error status of the span; 0 means no errors
 
+	SpanLinks  []ddtrace.SpanLink `msg:"span_links,omitempty"`  // links to other spans
+	SpanEvents []spanEvent        `msg:"span_events,omitempty"` // events which happened during the span's lifetime
+
 	noDebugStack bool         `msg:"-"` // disables debug stack traces
 	finished     bool         `msg:"-"` // true if the span has been submitted to a tracer.
 	context      *spanContext `msg:"-"` // span propagation context
@@ -163,6 +169,56 @@ func (s *span) SetTag(key string, value interface{}) {
 	s.setMeta(key, fmt.Sprint(value))
 }
 
+// AddLink links the span to the span described by link. Links are used to
+// express causal relationships which don't fit the parent-child model, e.g.
+// a batch consumer span linking to each of the producer spans it processes.
+func (s *span) AddLink(link ddtrace.SpanLink) {
+	s.Lock()
+	defer s.Unlock()
+	// We don't lock spans when flushing, so we could have a data race when
+	// modifying a span as it's being flushed. This protects us against that
+	// race, since spans are marked `finished` before we flush them.
+	if s.finished {
+		return
+	}
+	s.SpanLinks = append(s.SpanLinks, link)
+}
+
+// AddEvent records an event with the given name on the span. The time of the
+// event defaults to the current time. See WithSpanEventTime and
+// WithSpanEventAttributes.
+func (s *span) AddEvent(name string, opts ...ddtrace.SpanEventOption) {
+	var cfg ddtrace.SpanEventConfig
+	for _, fn := range opts {
+		fn(&cfg)
+	}
+	var t int64
+	if cfg.Time.IsZero() {
+		t = now()
+	} else {
+		t = cfg.Time.UnixNano()
+	}
+	event := spanEvent{
+		Name:         name,
+		TimeUnixNano: uint64(t),
+	}
+	if len(cfg.Attributes) > 0 {
+		event.Attributes = make(map[string]*spanEventAttribute, len(cfg.Attributes))
+		for k, v := range cfg.Attributes {
+			event.Attributes[k] = newSpanEventAttribute(v)
+		}
+	}
+	s.Lock()
+	defer s.Unlock()
+	// We don't lock spans when flushing, so we could have a data race when
+	// modifying a span as it's being flushed. This protects us against that
+	// race, since spans are marked `finished` before we flush them.
+	if s.finished {
+		return
+	}
+	s.SpanEvents = append(s.SpanEvents, event)
+}
+
 // setSamplingPriority locks then span, then updates the sampling priority.
 // It also updates the trace's sampling priority.
 func (s *span) setSamplingPriority(priority int, sampler samplernames.SamplerName) {
@@ -435,6 +491,15 @@ func (s *span) Finish(opts ...ddtrace.FinishOption) {
 	if s.taskEnd != nil {
 		s.taskEnd()
 	}
+	if tr, ok := internal.GetGlobalTracer().(*tracer); ok && s.context.trace.root == s {
+		tr.resample(s)
+		tr.keepRoot(s, t)
+	}
+	if dyninst.Enabled(dyninst.FinishSpan) {
+		dyninst.Run(dyninst.FinishSpan, s, map[string]interface{}{
+			"duration": time.Duration(t - s.Start),
+		})
+	}
 	s.finish(t)
 
 	if s.pprofCtxRestore != nil {
@@ -661,7 +726,9 @@ const (
 	keyHostname                = "_dd.hostname"
 	keyRulesSamplerAppliedRate = "_dd.rule_psr"
 	keyRulesSamplerLimiterRate = "_dd.limit_psr"
-	keyMeasured                = "_dd.measured"
+	// keyAdaptiveSamplerRate is the key of the rate applied by the adaptive sampler.
+	keyAdaptiveSamplerRate = "_dd.adaptive_psr"
+	keyMeasured            = "_dd.measured"
 	// keyTopLevel is the key of top level metric indicating if a span is top level.
 	// A top level span is a local root (parent span of the local trace) or the first span of each service.
 	keyTopLevel = "_dd.top_level"
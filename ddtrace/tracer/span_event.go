@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+//go:generate msgp -unexported -marshal=false -o=span_event_msgp.go -tests=false
+
+package tracer
+
+import (
+	"fmt"
+	"math"
+)
+
+// spanEvent represents an event which happened during the lifetime of a span,
+// e.g. an exception being recorded. It is encoded as part of the span.
+type spanEvent struct {
+	Name         string                         `msg:"name"`                 // name of the event
+	TimeUnixNano uint64                         `msg:"time_unix_nano"`       // time of the event expressed in nanoseconds since epoch
+	Attributes   map[string]*spanEventAttribute `msg:"attributes,omitempty"` // typed attributes of the event
+}
+
+// spanEventAttributeType specifies the type of the value held by a spanEventAttribute.
+// The values match the ones expected by the agent.
+type spanEventAttributeType int32
+
+const (
+	spanEventAttributeTypeString spanEventAttributeType = iota
+	spanEventAttributeTypeBool
+	spanEventAttributeTypeInt
+	spanEventAttributeTypeDouble
+	spanEventAttributeTypeArray
+)
+
+// spanEventAttribute holds a typed span event attribute value. Only the field
+// matching Type is set.
+type spanEventAttribute struct {
+	Type        spanEventAttributeType   `msg:"type"`
+	StringValue string                   `msg:"string_value,omitempty"`
+	BoolValue   bool                     `msg:"bool_value,omitempty"`
+	IntValue    int64                    `msg:"int_value,omitempty"`
+	DoubleValue float64                  `msg:"double_value,omitempty"`
+	ArrayValue  *spanEventArrayAttribute `msg:"array_value,omitempty"`
+}
+
+// spanEventArrayAttribute holds the values of an array span event attribute.
+// Arrays can't be nested.
+type spanEventArrayAttribute struct {
+	Values []*spanEventAttribute `msg:"values"`
+}
+
+// newSpanEventAttribute converts the given value into a typed span event attribute.
+// Values which aren't strings, booleans, numbers or slices of those are
+// converted to their string representation.
+func newSpanEventAttribute(value interface{}) *spanEventAttribute {
+	switch v := value.(type) {
+	case string:
+		return &spanEventAttribute{Type: spanEventAttributeTypeString, StringValue: v}
+	case bool:
+		return &spanEventAttribute{Type: spanEventAttributeTypeBool, BoolValue: v}
+	case int:
+		return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+	case int8:
+		return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+	case int16:
+		return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+	case int32:
+		return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+	case int64:
+		return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: v}
+	case uint8:
+		return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+	case uint16:
+		return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+	case uint32:
+		return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+		}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return &spanEventAttribute{Type: spanEventAttributeTypeInt, IntValue: int64(v)}
+		}
+	case float32:
+		return &spanEventAttribute{Type: spanEventAttributeTypeDouble, DoubleValue: float64(v)}
+	case float64:
+		return &spanEventAttribute{Type: spanEventAttributeTypeDouble, DoubleValue: v}
+	case []string:
+		return newSpanEventArrayAttribute(len(v), func(i int) interface{} { return v[i] })
+	case []bool:
+		return newSpanEventArrayAttribute(len(v), func(i int) interface{} { return v[i] })
+	case []int:
+		return newSpanEventArrayAttribute(len(v), func(i int) interface{} { return v[i] })
+	case []int64:
+		return newSpanEventArrayAttribute(len(v), func(i int) interface{} { return v[i] })
+	case []float64:
+		return newSpanEventArrayAttribute(len(v), func(i int) interface{} { return v[i] })
+	case fmt.Stringer:
+		return &spanEventAttribute{Type: spanEventAttributeTypeString, StringValue: v.String()}
+	}
+	return &spanEventAttribute{Type: spanEventAttributeTypeString, StringValue: fmt.Sprint(value)}
+}
+
+// newSpanEventArrayAttribute returns an array attribute made of the n values
+// returned by at.
+func newSpanEventArrayAttribute(n int, at func(i int) interface{}) *spanEventAttribute {
+	values := make([]*spanEventAttribute, n)
+	for i := range values {
+		values[i] = newSpanEventAttribute(at(i))
+	}
+	return &spanEventAttribute{
+		Type:       spanEventAttributeTypeArray,
+		ArrayValue: &spanEventArrayAttribute{Values: values},
+	}
+}
@@ -8,6 +8,7 @@ package tracer
 import (
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"runtime"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
@@ -583,6 +585,77 @@ func TestSpanModifyWhileFlushing(t *testing.T) {
 	}
 }
 
+func TestSpanLinks(t *testing.T) {
+	tracer, transport, flush, stop := startTestTracer(t)
+	defer stop()
+
+	links := []ddtrace.SpanLink{
+		{TraceID: 1, SpanID: 2},
+		{
+			TraceID:     3,
+			TraceIDHigh: 4,
+			SpanID:      5,
+			Attributes:  map[string]string{"link.kind": "producer"},
+			Tracestate:  "dd=s:1",
+			Flags:       1 | 1<<31,
+		},
+	}
+	sp := tracer.StartSpan("consumer", WithSpanLinks(links[:1])).(*span)
+	sp.AddLink(links[1])
+	sp.Finish()
+	// links can't be added after the span finished
+	sp.AddLink(ddtrace.SpanLink{TraceID: 6, SpanID: 7})
+	flush(1)
+
+	traces := transport.Traces()
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 1)
+	assert.Equal(t, links, traces[0][0].SpanLinks)
+}
+
+func TestSpanEvents(t *testing.T) {
+	tracer, transport, flush, stop := startTestTracer(t)
+	defer stop()
+
+	sp := tracer.StartSpan("op").(*span)
+	sp.AddEvent("first", WithSpanEventTime(time.Unix(0, 10)), WithSpanEventAttributes(map[string]interface{}{
+		"str":    "v",
+		"bool":   true,
+		"int":    1,
+		"uint64": uint64(math.MaxUint64),
+		"float":  1.5,
+		"ints":   []int64{1, 2},
+		"error":  errors.New("x"),
+	}))
+	sp.AddEvent("second")
+	sp.Finish()
+	sp.AddEvent("ignored")
+	flush(1)
+
+	traces := transport.Traces()
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 1)
+	events := traces[0][0].SpanEvents
+	require.Len(t, events, 2)
+	assert.Equal(t, "first", events[0].Name)
+	assert.Equal(t, uint64(10), events[0].TimeUnixNano)
+	assert.Equal(t, map[string]*spanEventAttribute{
+		"str":    {Type: spanEventAttributeTypeString, StringValue: "v"},
+		"bool":   {Type: spanEventAttributeTypeBool, BoolValue: true},
+		"int":    {Type: spanEventAttributeTypeInt, IntValue: 1},
+		"uint64": {Type: spanEventAttributeTypeString, StringValue: "18446744073709551615"},
+		"float":  {Type: spanEventAttributeTypeDouble, DoubleValue: 1.5},
+		"ints": {Type: spanEventAttributeTypeArray, ArrayValue: &spanEventArrayAttribute{Values: []*spanEventAttribute{
+			{Type: spanEventAttributeTypeInt, IntValue: 1},
+			{Type: spanEventAttributeTypeInt, IntValue: 2},
+		}}},
+		"error": {Type: spanEventAttributeTypeString, StringValue: "x"},
+	}, events[0].Attributes)
+	assert.Equal(t, "second", events[1].Name)
+	assert.NotZero(t, events[1].TimeUnixNano)
+	assert.Nil(t, events[1].Attributes)
+}
+
 func TestSpanSamplingPriority(t *testing.T) {
 	assert := assert.New(t)
 	tracer := newTracer(withTransport(newDefaultTransport()))
@@ -19,6 +19,7 @@ import (
 	sharedinternal "github.com/lannguyen-c0x12c/dd-trace-go/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
 )
 
 var _ ddtrace.SpanContext = (*spanContext)(nil)
@@ -96,6 +97,10 @@ type spanContext struct {
 	baggage    map[string]string
 	hasBaggage uint32 // atomic int for quick checking presence of baggage. 0 indicates no baggage, otherwise baggage exists.
 	origin     string // e.g. "synthetics"
+
+	// baggageProperties holds the W3C baggage metadata properties of the baggage
+	// items, e.g. ";ttl=60", keyed by item key.
+	baggageProperties map[string]string
 }
 
 // newSpanContext creates a new SpanContext to serve as context for the given
@@ -118,6 +123,7 @@ func newSpanContext(span *span, parent *spanContext) *spanContext {
 			context.setBaggageItem(k, v)
 			return true
 		})
+		context.inheritBaggageProperties(parent)
 	} else if sharedinternal.BoolEnv("DD_TRACE_128_BIT_TRACEID_GENERATION_ENABLED", false) {
 		// add 128 bit trace id, if enabled, formatted as big-endian:
 		// <32-bit unix seconds> <32 bits of zero> <64 random bits>
@@ -201,6 +207,34 @@ func (c *spanContext) setBaggageItem(key, val string) {
 	c.baggage[key] = val
 }
 
+// setBaggageItemProperties sets the W3C baggage metadata properties of the baggage item
+// with the given key.
+func (c *spanContext) setBaggageItemProperties(key, props string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.baggageProperties == nil {
+		c.baggageProperties = make(map[string]string, 1)
+	}
+	c.baggageProperties[key] = props
+}
+
+// baggageItemProperties returns the W3C baggage metadata properties of the baggage item
+// with the given key.
+func (c *spanContext) baggageItemProperties(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.baggageProperties[key]
+}
+
+// inheritBaggageProperties copies the W3C baggage metadata properties of parent.
+func (c *spanContext) inheritBaggageProperties(parent *spanContext) {
+	parent.mu.RLock()
+	defer parent.mu.RUnlock()
+	for k, v := range parent.baggageProperties {
+		c.setBaggageItemProperties(k, v)
+	}
+}
+
 func (c *spanContext) baggageItem(key string) string {
 	if atomic.LoadUint32(&c.hasBaggage) == 0 {
 		return ""
@@ -245,6 +279,10 @@ type trace struct {
 	full             bool              // signifies that the span buffer is full
 	priority         *float64          // sampling priority
 	locked           bool              // specifies if the sampling priority can be altered
+	resample         bool              // specifies if the trace sampling rules apply again to the root
+	propagated       bool              // specifies if the trace context was injected to a carrier
+	manual           bool              // specifies if the sampling priority was set by the user
+	inherited        bool              // specifies if the sampling priority was extracted from an upstream service
 	samplingDecision samplingDecision  // samplingDecision indicates whether to send the trace to the agent.
 
 	// root specifies the root of the trace, if known; it is nil when a span
@@ -342,6 +380,12 @@ func (t *trace) setSamplingPriorityLocked(p int, sampler samplernames.SamplerNam
 	if t.locked {
 		return
 	}
+	if sampler == samplernames.Manual || sampler == samplernames.AppSec {
+		// the decisions made through SetTag, by the user or by AppSec, prevail over
+		// the sampling rules.
+		t.resample = false
+		t.manual = true
+	}
 	if t.priority == nil {
 		t.priority = new(float64)
 	}
@@ -357,6 +401,72 @@ func (t *trace) setSamplingPriorityLocked(p int, sampler samplernames.SamplerNam
 	}
 }
 
+// setResample marks the sampling decision of the trace as to be made again by the trace
+// sampling rules, once the resource name and the tags of its root span are known.
+func (t *trace) setResample() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resample = true
+}
+
+// takeResample reports whether the sampling decision of the trace has to be made again,
+// which is only the case once: when the trace context is propagated, or else when the
+// root span finishes.
+func (t *trace) takeResample() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ok := t.resample && !t.locked
+	t.resample = false
+	return ok
+}
+
+// setInherited marks the sampling priority of the trace as decided by an upstream service.
+func (t *trace) setInherited() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inherited = true
+}
+
+// setPropagated marks the trace context as propagated: its sampling decision is now shared with
+// downstream services.
+func (t *trace) setPropagated() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.propagated = true
+}
+
+// keepRoot keeps the trace with the given priority and sampler as its root span finishes,
+// unless the trace is already kept, or its sampling decision was set by the user, inherited
+// from upstream, propagated downstream or locked by a partial flush. It reports whether the
+// decision was changed.
+func (t *trace) keepRoot(p int, sampler samplernames.SamplerName) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.locked || t.propagated || t.manual || t.inherited {
+		return false
+	}
+	if prio, ok := t.samplingPriorityLocked(); ok && prio > 0 {
+		return false
+	}
+	delete(t.propagatingTags, keyDecisionMaker)
+	t.setSamplingPriorityLocked(p, sampler)
+	atomic.StoreUint32((*uint32)(&t.samplingDecision), uint32(decisionKeep))
+	return true
+}
+
+// keepFinished keeps the trace with the given priority and sampler, overriding its locked
+// sampling priority. It is meant for samplers deciding on traces once all their chunks
+// have been flushed, which must update the first span of each chunk themselves.
+func (t *trace) keepFinished(p int, sampler samplernames.SamplerName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.locked = false
+	delete(t.propagatingTags, keyDecisionMaker)
+	t.setSamplingPriorityLocked(p, sampler)
+	t.locked = true
+	atomic.StoreUint32((*uint32)(&t.samplingDecision), uint32(decisionKeep))
+}
+
 // push pushes a new span into the trace. If the buffer is full, it returns
 // a errBufferFull error.
 func (t *trace) push(sp *span) {
@@ -387,7 +497,9 @@ func (t *trace) push(sp *span) {
 
 // finishedOne acknowledges that another span in the trace has finished, and checks
 // if the trace is complete, in which case it calls the onFinish function. It uses
-// the given priority, if non-nil, to mark the root span.
+// the given priority, if non-nil, to mark the root span. When partial flushing is
+// enabled and enough spans have finished, the finished spans are flushed as a
+// separate chunk while the rest of the trace is still in progress.
 func (t *trace) finishedOne(s *span) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -412,37 +524,80 @@ func (t *trace) finishedOne(s *span) {
 		// TODO(barbayar): make sure this doesn't happen in vain when switching to
 		// the new wire format. We won't need to set the tags on the first span
 		// in the chunk there.
-		for k, v := range t.tags {
-			s.setMeta(k, v)
-		}
-		for k, v := range t.propagatingTags {
-			s.setMeta(k, v)
-		}
-		for k, v := range ginternal.GetTracerGitMetadataTags() {
-			s.setMeta(k, v)
-		}
+		t.setTraceTags(s)
 	}
 	if s.context != nil && s.context.traceID.HasUpper() {
 		s.setMeta(keyTraceID128, s.context.traceID.UpperHex())
 	}
-	if len(t.spans) != t.finished {
+	tr, ok := internal.GetGlobalTracer().(*tracer)
+	if len(t.spans) == t.finished {
+		// the whole trace (or the remainder of it) is complete.
+		defer func() {
+			t.spans = nil
+			t.finished = 0 // important, because a buffer can be used for several flushes
+		}()
+		if !ok {
+			return
+		}
+		if hn := tr.hostname(); hn != "" {
+			s.setMeta(keyTracerHostname, hn)
+		}
+		// we have a tracer that can receive completed traces.
+		t.finishChunk(tr, t.spans, false)
 		return
 	}
-	defer func() {
-		t.spans = nil
-		t.finished = 0 // important, because a buffer can be used for several flushes
-	}()
-	tr, ok := internal.GetGlobalTracer().(*tracer)
-	if !ok {
+	if !ok || !tr.config.partialFlushEnabled || t.finished < tr.config.partialFlushMinSpans {
+		// the trace hasn't completed and partial flushing will not occur.
 		return
 	}
-	if hn := tr.hostname(); hn != "" {
-		s.setMeta(keyTracerHostname, hn)
+	log.Debug("Partial flush triggered with %d finished spans", t.finished)
+	telemetry.GlobalClient.Count(telemetry.NamespaceTracers, "trace_partial_flush.count", 1, []string{"reason:large_trace"}, true)
+	finishedSpans := make([]*span, 0, t.finished)
+	leftoverSpans := make([]*span, 0, len(t.spans)-t.finished)
+	for _, s2 := range t.spans {
+		if s2.finished {
+			finishedSpans = append(finishedSpans, s2)
+		} else {
+			leftoverSpans = append(leftoverSpans, s2)
+		}
+	}
+	if t.priority != nil {
+		// Once a chunk has been sent, the sampling priority can no longer
+		// change without making the chunks of this trace disagree with each other.
+		finishedSpans[0].setMetric(keySamplingPriority, *t.priority)
+		t.locked = true
+	}
+	if finishedSpans[0] != t.spans[0] {
+		// make sure the first span in the chunk has the trace-level tags.
+		t.setTraceTags(finishedSpans[0])
 	}
-	// we have a tracer that can receive completed traces.
-	atomic.AddUint32(&tr.spansFinished, uint32(len(t.spans)))
+	t.finishChunk(tr, finishedSpans, true)
+	t.spans = leftoverSpans
+	t.finished = 0
+}
+
+// finishChunk pushes the given spans as a chunk of this trace to the tracer.
+// partial reports whether more chunks of the trace will follow.
+// t must already be locked.
+func (t *trace) finishChunk(tr *tracer, spans []*span, partial bool) {
+	atomic.AddUint32(&tr.spansFinished, uint32(len(spans)))
 	tr.pushTrace(&finishedTrace{
-		spans:    t.spans,
+		spans:    spans,
 		willSend: decisionKeep == samplingDecision(atomic.LoadUint32((*uint32)(&t.samplingDecision))),
+		partial:  partial,
 	})
 }
+
+// setTraceTags sets all the trace-level tags on the given span, which should
+// be the first span of a chunk. t must already be locked.
+func (t *trace) setTraceTags(s *span) {
+	for k, v := range t.tags {
+		s.setMeta(k, v)
+	}
+	for k, v := range t.propagatingTags {
+		s.setMeta(k, v)
+	}
+	for k, v := range ginternal.GetTracerGitMetadataTags() {
+		s.setMeta(k, v)
+	}
+}
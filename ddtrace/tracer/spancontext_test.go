@@ -7,6 +7,7 @@ package tracer
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -15,8 +16,10 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry/telemetrytest"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupteardown(start, max int) func() {
@@ -245,6 +248,100 @@ func TestSpanFinishPriority(t *testing.T) {
 	assert.Fail("span not found")
 }
 
+func TestPartialFlush(t *testing.T) {
+	t.Setenv("DD_TRACE_PARTIAL_FLUSH_ENABLED", "true")
+	t.Setenv("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS", "2")
+	t.Run("WithFlush", func(t *testing.T) {
+		telemetryClient := new(telemetrytest.MockClient)
+		defer telemetry.MockGlobalClient(telemetryClient)()
+		tracer, transport, flush, stop := startTestTracer(t)
+		defer stop()
+
+		root := tracer.StartSpan("root")
+		root.(*span).context.trace.setTag("someTraceTag", "someValue")
+		var children []*span
+		for i := 0; i < 3; i++ { // create 3 child spans
+			child := tracer.StartSpan(fmt.Sprintf("child%d", i), ChildOf(root.Context()))
+			children = append(children, child.(*span))
+			child.Finish()
+		}
+		flush(1)
+
+		ts := transport.Traces()
+		require.Len(t, ts, 1)
+		require.Len(t, ts[0], 2)
+		assert.Equal(t, "someValue", ts[0][0].Meta["someTraceTag"])
+		assert.Equal(t, 1.0, ts[0][0].Metrics[keySamplingPriority])
+		assert.Empty(t, ts[0][1].Meta["someTraceTag"]) // the tag should only be on the first span in the chunk
+		comparePayloadSpans(t, children[0], ts[0][0])
+		comparePayloadSpans(t, children[1], ts[0][1])
+		telemetryClient.AssertCalled(t, "Count", telemetry.NamespaceTracers, "trace_partial_flush.count", 1.0, []string{"reason:large_trace"}, true)
+
+		root.Finish()
+		flush(1)
+		tsRoot := transport.Traces()
+		require.Len(t, tsRoot, 1)
+		require.Len(t, tsRoot[0], 2)
+		assert.Equal(t, "someValue", tsRoot[0][0].Meta["someTraceTag"])
+		assert.Equal(t, 1.0, tsRoot[0][0].Metrics[keySamplingPriority])
+		assert.Empty(t, tsRoot[0][1].Meta["someTraceTag"]) // the tag should only be on the first span in the chunk
+		comparePayloadSpans(t, root.(*span), tsRoot[0][0])
+		comparePayloadSpans(t, children[2], tsRoot[0][1])
+	})
+
+	// This test covers an issue where partial flushing + a rate sampler would panic
+	t.Run("WithRateSamplerNoPanic", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithSampler(NewRateSampler(0.000001)))
+		defer stop()
+
+		root := tracer.StartSpan("root")
+		root.(*span).context.trace.setTag("someTraceTag", "someValue")
+		var children []*span
+		for i := 0; i < 10; i++ { // create 10 child spans to ensure some aren't sampled
+			child := tracer.StartSpan(fmt.Sprintf("child%d", i), ChildOf(root.Context()))
+			children = append(children, child.(*span))
+			child.Finish()
+		}
+	})
+
+	t.Run("PriorityLocked", func(t *testing.T) {
+		tracer, transport, flush, stop := startTestTracer(t)
+		defer stop()
+
+		root := tracer.StartSpan("root")
+		for i := 0; i < 2; i++ {
+			tracer.StartSpan(fmt.Sprintf("child%d", i), ChildOf(root.Context())).Finish()
+		}
+		flush(1)
+		// the first chunk has been sent, so the priority can't change anymore
+		root.SetTag(ext.ManualDrop, true)
+		root.Finish()
+		flush(2)
+
+		ts := transport.Traces()
+		require.Len(t, ts, 2)
+		require.Len(t, ts[1], 1)
+		assert.Equal(t, 1.0, ts[1][0].Metrics[keySamplingPriority])
+	})
+}
+
+func TestPartialFlushDisabled(t *testing.T) {
+	t.Setenv("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS", "2")
+	tracer, transport, flush, stop := startTestTracer(t)
+	defer stop()
+
+	root := tracer.StartSpan("root")
+	for i := 0; i < 3; i++ {
+		tracer.StartSpan(fmt.Sprintf("child%d", i), ChildOf(root.Context())).Finish()
+	}
+	root.Finish()
+	flush(1)
+
+	ts := transport.Traces()
+	require.Len(t, ts, 1)
+	assert.Len(t, ts[0], 4)
+}
+
 func TestNewSpanContext(t *testing.T) {
 	t.Run("basic", func(t *testing.T) {
 		span := &span{
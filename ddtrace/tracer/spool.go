@@ -0,0 +1,268 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+)
+
+const (
+	// defaultSpoolMaxSize is the default limit of the total size of the spooled payloads.
+	defaultSpoolMaxSize = 64 << 20
+
+	// defaultSpoolMaxAge is the default age after which spooled payloads are evicted.
+	defaultSpoolMaxAge = time.Hour
+
+	// spoolFileExt is the extension of the files holding spooled payloads.
+	spoolFileExt = ".msgp"
+
+	// spoolTmpExt is appended to the name of the files holding payloads being spooled.
+	spoolTmpExt = ".tmp"
+)
+
+// spoolFile describes a payload stored on disk by the spool.
+type spoolFile struct {
+	path    string    // path of the file
+	size    int64     // size of the file, in bytes
+	count   int       // number of traces in the payload
+	created time.Time // time at which the payload was spooled
+}
+
+// spool stores on disk the trace payloads which could not be sent to the agent, so that
+// they can be sent once it becomes reachable again. Payloads are replayed in the order in
+// which they were spooled, but not in order with the payloads flushed in the meantime, which
+// are sent as they come. The spool is bounded: when it exceeds its maximum size, the
+// oldest payloads are evicted, and payloads older than its maximum age are never sent.
+// The payloads are kept across restarts of the application.
+type spool struct {
+	dir     string        // directory holding the spooled payloads
+	maxSize int64         // maximum total size of the spooled payloads
+	maxAge  time.Duration // maximum age of the spooled payloads
+
+	statsd statsdClient
+
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+
+	mu    sync.Mutex  // guards below fields
+	files []spoolFile // spooled payloads, oldest first
+	size  int64       // total size of the spooled payloads
+	seq   uint64      // sequence number distinguishing files spooled at the same time
+}
+
+// newSpool returns a spool storing payloads in dir, loading the payloads previously spooled
+// there and removing the ones which were left partially written. Zero maxSize and maxAge are
+// replaced by defaults.
+func newSpool(dir string, maxSize int64, maxAge time.Duration, statsdClient statsdClient) (*spool, error) {
+	if maxSize <= 0 {
+		maxSize = defaultSpoolMaxSize
+	}
+	if maxAge <= 0 {
+		maxAge = defaultSpoolMaxAge
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &spool{
+		dir:     dir,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+		statsd:  statsdClient,
+		now:     time.Now,
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), spoolFileExt+spoolTmpExt) {
+			// left behind by a crash while spooling a payload
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+				log.Error("Unable to remove partially spooled trace payload %s: %v", e.Name(), err)
+			}
+			continue
+		}
+		f, ok := parseSpoolFile(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		f.path = filepath.Join(dir, e.Name())
+		f.size = info.Size()
+		s.files = append(s.files, f)
+		s.size += f.size
+	}
+	// file names start with the fixed-width spooling time and sequence number
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].path < s.files[j].path })
+	if len(s.files) > 0 {
+		log.Debug("Loaded %d spooled trace payloads (%d bytes) from %s", len(s.files), s.size, dir)
+	}
+	return s, nil
+}
+
+// spoolFileName returns the name of the file holding a payload of count traces spooled at
+// the given time, with the given sequence number.
+func spoolFileName(created time.Time, seq uint64, count int) string {
+	return fmt.Sprintf("%020d-%010d-%d%s", created.UnixNano(), seq, count, spoolFileExt)
+}
+
+// parseSpoolFile parses a file name returned by spoolFileName. It reports false if name
+// does not hold a spooled payload.
+func parseSpoolFile(name string) (f spoolFile, ok bool) {
+	if !strings.HasSuffix(name, spoolFileExt) {
+		return f, false
+	}
+	parts := strings.Split(strings.TrimSuffix(name, spoolFileExt), "-")
+	if len(parts) != 3 {
+		return f, false
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return f, false
+	}
+	if _, err := strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return f, false
+	}
+	count, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return f, false
+	}
+	return spoolFile{count: count, created: time.Unix(0, ts)}, true
+}
+
+// len returns the number of spooled payloads.
+func (s *spool) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files)
+}
+
+// store writes the payload p to disk, evicting the oldest payloads if needed to respect
+// the maximum size of the spool.
+func (s *spool) store(p *payload) error {
+	data := p.buf.Bytes()
+	size := int64(len(data))
+	if size > s.maxSize {
+		s.statsd.Count("datadog.tracer.spool.bytes_evicted", size, []string{"reason:size"}, 1)
+		return fmt.Errorf("payload of %d bytes exceeds the spool size limit of %d bytes", size, s.maxSize)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictExpiredLocked()
+	for len(s.files) > 0 && s.size+size > s.maxSize {
+		s.evictLocked("size")
+	}
+	now := s.now()
+	s.seq++
+	f := spoolFile{
+		path:    filepath.Join(s.dir, spoolFileName(now, s.seq, p.itemCount())),
+		size:    size,
+		count:   p.itemCount(),
+		created: now,
+	}
+	// write to a temporary file first, so that a crash never leaves a partial payload behind
+	tmp := f.path + spoolTmpExt
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.files = append(s.files, f)
+	s.size += size
+	s.statsd.Count("datadog.tracer.spool.bytes_spooled", size, nil, 1)
+	return nil
+}
+
+// replay sends the spooled payloads using send, oldest first. It stops at the first payload
+// which fails to be sent, keeping it and the following ones for a later replay.
+func (s *spool) replay(send func(p *payload) error) {
+	for {
+		s.mu.Lock()
+		s.evictExpiredLocked()
+		if len(s.files) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		f := s.files[0]
+		s.mu.Unlock()
+
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			log.Error("Unable to read spooled trace payload %s: %v", f.path, err)
+			s.remove(f, "bytes_evicted", "reason:unreadable")
+			continue
+		}
+		if err := send(spooledPayload(data, f.count)); err != nil {
+			log.Debug("Unable to replay spooled trace payload, will retry later: %v", err)
+			return
+		}
+		s.remove(f, "bytes_replayed", "")
+	}
+}
+
+// remove deletes the spooled file f, which must be the oldest one, reporting its size
+// under the given metric.
+func (s *spool) remove(f spoolFile, metric, tag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.files) == 0 || s.files[0].path != f.path {
+		// already evicted in the meantime
+		return
+	}
+	s.files = s.files[1:]
+	s.size -= f.size
+	os.Remove(f.path)
+	var tags []string
+	if tag != "" {
+		tags = []string{tag}
+	}
+	s.statsd.Count("datadog.tracer.spool."+metric, f.size, tags, 1)
+}
+
+// evictExpiredLocked evicts the payloads which are older than the maximum age.
+// s.mu must be held.
+func (s *spool) evictExpiredLocked() {
+	deadline := s.now().Add(-s.maxAge)
+	for len(s.files) > 0 && s.files[0].created.Before(deadline) {
+		s.evictLocked("age")
+	}
+}
+
+// evictLocked evicts the oldest payload for the given reason. s.mu must be held.
+func (s *spool) evictLocked(reason string) {
+	f := s.files[0]
+	s.files = s.files[1:]
+	s.size -= f.size
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		log.Error("Unable to remove spooled trace payload %s: %v", f.path, err)
+	}
+	log.Debug("Evicted spooled trace payload of %d traces (reason: %s)", f.count, reason)
+	s.statsd.Count("datadog.tracer.spool.bytes_evicted", f.size, []string{"reason:" + reason}, 1)
+}
+
+// spooledPayload returns a payload holding the count msgpack-encoded traces in data.
+func spooledPayload(data []byte, count int) *payload {
+	p := newPayload()
+	p.buf.Write(data)
+	p.count = uint32(count)
+	p.updateHeader()
+	return p
+}
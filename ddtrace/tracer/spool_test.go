@@ -0,0 +1,260 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spoolTestPayload returns a payload holding a single trace of one span with the given name.
+func spoolTestPayload(t *testing.T, name string) *payload {
+	s := makeSpan(0)
+	s.Name = name
+	p, err := encode([][]*span{{s}})
+	require.NoError(t, err)
+	return p
+}
+
+// replayNames replays the spool, returning the names of the replayed spans in order.
+func replayNames(t *testing.T, s *spool) []string {
+	var names []string
+	s.replay(func(p *payload) error {
+		traces, err := decode(p)
+		require.NoError(t, err)
+		for _, trace := range traces {
+			names = append(names, trace[0].Name)
+		}
+		return nil
+	})
+	return names
+}
+
+func TestSpool(t *testing.T) {
+	t.Run("replay", func(t *testing.T) {
+		var statsd testStatsdClient
+		s, err := newSpool(t.TempDir(), 0, 0, &statsd)
+		require.NoError(t, err)
+		assert.EqualValues(t, defaultSpoolMaxSize, s.maxSize)
+		assert.Equal(t, defaultSpoolMaxAge, s.maxAge)
+
+		p1, p2 := spoolTestPayload(t, "first"), spoolTestPayload(t, "second")
+		size := int64(p1.buf.Len() + p2.buf.Len())
+		require.NoError(t, s.store(p1))
+		require.NoError(t, s.store(p2))
+		assert.Equal(t, 2, s.len())
+		assert.Equal(t, size, statsd.counts["datadog.tracer.spool.bytes_spooled"])
+
+		assert.Equal(t, []string{"first", "second"}, replayNames(t, s))
+		assert.Equal(t, 0, s.len())
+		assert.Zero(t, s.size)
+		assert.Equal(t, size, statsd.counts["datadog.tracer.spool.bytes_replayed"])
+		entries, err := os.ReadDir(s.dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("replay-failure", func(t *testing.T) {
+		s, err := newSpool(t.TempDir(), 0, 0, &testStatsdClient{})
+		require.NoError(t, err)
+		require.NoError(t, s.store(spoolTestPayload(t, "first")))
+		require.NoError(t, s.store(spoolTestPayload(t, "second")))
+
+		var attempts int
+		s.replay(func(p *payload) error {
+			attempts++
+			return errors.New("agent unreachable")
+		})
+		// replay stops at the first failure, keeping all payloads
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, 2, s.len())
+		assert.Equal(t, []string{"first", "second"}, replayNames(t, s))
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := newSpool(dir, 0, 0, &testStatsdClient{})
+		require.NoError(t, err)
+		for _, name := range []string{"first", "second", "third"} {
+			require.NoError(t, s.store(spoolTestPayload(t, name)))
+		}
+		// unrelated files are ignored
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("x"), 0600))
+		// partially spooled payloads are removed
+		tmp := filepath.Join(dir, spoolFileName(time.Now(), 4, 1)+spoolTmpExt)
+		require.NoError(t, os.WriteFile(tmp, []byte("partial"), 0600))
+
+		s, err = newSpool(dir, 0, 0, &testStatsdClient{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, s.len())
+		assert.NoFileExists(t, tmp)
+		assert.FileExists(t, filepath.Join(dir, "other.txt"))
+		assert.Equal(t, []string{"first", "second", "third"}, replayNames(t, s))
+	})
+
+	t.Run("evict-size", func(t *testing.T) {
+		var statsd testStatsdClient
+		p := spoolTestPayload(t, "p1")
+		size := int64(p.buf.Len())
+		s, err := newSpool(t.TempDir(), 2*size, 0, &statsd)
+		require.NoError(t, err)
+		require.NoError(t, s.store(p))
+		require.NoError(t, s.store(spoolTestPayload(t, "p2")))
+		require.NoError(t, s.store(spoolTestPayload(t, "p3")))
+		assert.Equal(t, 2, s.len())
+		assert.Equal(t, size, statsd.counts["datadog.tracer.spool.bytes_evicted"])
+		assert.Equal(t, []string{"p2", "p3"}, replayNames(t, s))
+
+		// payloads larger than the spool are never stored
+		s.maxSize = size - 1
+		assert.Error(t, s.store(spoolTestPayload(t, "p4")))
+		assert.Equal(t, 0, s.len())
+		assert.Equal(t, 2*size, statsd.counts["datadog.tracer.spool.bytes_evicted"])
+	})
+
+	t.Run("evict-age", func(t *testing.T) {
+		var statsd testStatsdClient
+		s, err := newSpool(t.TempDir(), 0, time.Minute, &statsd)
+		require.NoError(t, err)
+		now := time.Now()
+		s.now = func() time.Time { return now }
+		require.NoError(t, s.store(spoolTestPayload(t, "first")))
+		now = now.Add(30 * time.Second)
+		require.NoError(t, s.store(spoolTestPayload(t, "second")))
+		now = now.Add(45 * time.Second)
+
+		assert.Equal(t, []string{"second"}, replayNames(t, s))
+		assert.NotZero(t, statsd.counts["datadog.tracer.spool.bytes_evicted"])
+		statsd.mu.RLock()
+		defer statsd.mu.RUnlock()
+		var found bool
+		for _, c := range statsd.countCalls {
+			if c.name == "datadog.tracer.spool.bytes_evicted" {
+				found = true
+				assert.Equal(t, []string{"reason:age"}, c.tags)
+			}
+		}
+		assert.True(t, found)
+	})
+}
+
+// unreachableTransport is a dummyTransport which fails to send while down is set.
+type unreachableTransport struct {
+	*dummyTransport
+
+	mu   sync.Mutex
+	down bool
+}
+
+func (t *unreachableTransport) setDown(down bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.down = down
+}
+
+func (t *unreachableTransport) send(p *payload) (io.ReadCloser, error) {
+	t.mu.Lock()
+	down := t.down
+	t.mu.Unlock()
+	if down {
+		return nil, errors.New("connection refused")
+	}
+	return t.dummyTransport.send(p)
+}
+
+func TestTraceWriterSpool(t *testing.T) {
+	transport := &unreachableTransport{dummyTransport: newDummyTransport(), down: true}
+	c := newConfig(withTransport(transport), WithTraceSpool(t.TempDir(), 0, 0))
+	var statsd testStatsdClient
+	h := newAgentTraceWriter(c, newPrioritySampler(), &statsd)
+	require.NotNil(t, h.spool)
+
+	for _, name := range []string{"first", "second"} {
+		s := makeSpan(0)
+		s.Name = name
+		h.add([]*span{s})
+		h.flush()
+		h.wg.Wait()
+	}
+	assert.Equal(t, 2, h.spool.len())
+	assert.Zero(t, transport.Len())
+	assert.Zero(t, statsd.counts["datadog.tracer.traces_dropped"])
+	assert.NotZero(t, statsd.counts["datadog.tracer.spool.bytes_spooled"])
+
+	// while the agent is down, flushing without new traces keeps the spool
+	h.flush()
+	h.wg.Wait()
+	assert.Equal(t, 2, h.spool.len())
+
+	// once the agent is back, the next successful send replays the spool in order
+	transport.setDown(false)
+	s := makeSpan(0)
+	s.Name = "third"
+	h.add([]*span{s})
+	h.flush()
+	h.wg.Wait()
+	assert.Equal(t, 0, h.spool.len())
+	traces := transport.Traces()
+	require.Len(t, traces, 3)
+	assert.Equal(t, "third", traces[0][0].Name)
+	assert.Equal(t, "first", traces[1][0].Name)
+	assert.Equal(t, "second", traces[2][0].Name)
+	assert.Equal(t, statsd.counts["datadog.tracer.spool.bytes_spooled"], statsd.counts["datadog.tracer.spool.bytes_replayed"])
+}
+
+func TestTraceWriterSpoolIdleReplay(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpool(dir, 0, 0, &testStatsdClient{})
+	require.NoError(t, err)
+	require.NoError(t, s.store(spoolTestPayload(t, "spooled")))
+
+	// payloads spooled by a previous run are sent on the first flush, even without new traces
+	transport := newDummyTransport()
+	c := newConfig(withTransport(transport), WithTraceSpool(dir, 0, 0))
+	h := newAgentTraceWriter(c, newPrioritySampler(), &testStatsdClient{})
+	h.flush()
+	h.wg.Wait()
+	traces := transport.Traces()
+	require.Len(t, traces, 1)
+	assert.Equal(t, "spooled", traces[0][0].Name)
+	assert.Equal(t, 0, h.spool.len())
+}
+
+func TestSpoolConfig(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		c := newConfig()
+		assert.Empty(t, c.spoolDir)
+		h := newAgentTraceWriter(c, nil, &testStatsdClient{})
+		assert.Nil(t, h.spool)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("DD_TRACE_SPOOL_DIR", dir)
+		t.Setenv("DD_TRACE_SPOOL_MAX_SIZE", "1024")
+		t.Setenv("DD_TRACE_SPOOL_MAX_AGE", "5m")
+		c := newConfig()
+		assert.Equal(t, dir, c.spoolDir)
+		assert.EqualValues(t, 1024, c.spoolMaxSize)
+		assert.Equal(t, 5*time.Minute, c.spoolMaxAge)
+	})
+
+	t.Run("option", func(t *testing.T) {
+		t.Setenv("DD_TRACE_SPOOL_MAX_SIZE", "1024")
+		c := newConfig(WithTraceSpool("/tmp/spool", 0, time.Minute))
+		assert.Equal(t, "/tmp/spool", c.spoolDir)
+		assert.EqualValues(t, 1024, c.spoolMaxSize)
+		assert.Equal(t, time.Minute, c.spoolMaxAge)
+	})
+}
@@ -74,8 +74,19 @@ type SQLCommentCarrier struct {
 // Inject injects a span context in the carrier's Query field as a comment.
 func (c *SQLCommentCarrier) Inject(spanCtx ddtrace.SpanContext) error {
 	c.SpanID = generateSpanID(now())
+	tags := dbmTags(spanCtx, c.Mode, c.DBServiceName, c.SpanID)
+	if tags == nil {
+		return nil
+	}
+	c.Query = commentQuery(c.Query, tags)
+	return nil
+}
+
+// dbmTags returns the tags propagated to the database in the given mode, using spanID as the
+// parent ID of the database call. It returns nil when propagation is disabled.
+func dbmTags(spanCtx ddtrace.SpanContext, mode DBMPropagationMode, dbServiceName string, spanID uint64) map[string]string {
 	tags := make(map[string]string)
-	switch c.Mode {
+	switch mode {
 	case DBMPropagationModeUndefined:
 		fallthrough
 	case DBMPropagationModeDisabled:
@@ -92,9 +103,9 @@ func (c *SQLCommentCarrier) Inject(spanCtx ddtrace.SpanContext) error {
 			traceID = ctx.TraceID()
 		}
 		if traceID == 0 { // check if this is a root span
-			traceID = c.SpanID
+			traceID = spanID
 		}
-		tags[sqlCommentTraceParent] = encodeTraceParent(traceID, c.SpanID, sampled)
+		tags[sqlCommentTraceParent] = encodeTraceParent(traceID, spanID, sampled)
 		fallthrough
 	case DBMPropagationModeService:
 		if ctx, ok := spanCtx.(*spanContext); ok {
@@ -108,10 +119,9 @@ func (c *SQLCommentCarrier) Inject(spanCtx ddtrace.SpanContext) error {
 		if globalconfig.ServiceName() != "" {
 			tags[sqlCommentParentService] = globalconfig.ServiceName()
 		}
-		tags[sqlCommentDBService] = c.DBServiceName
+		tags[sqlCommentDBService] = dbServiceName
 	}
-	c.Query = commentQuery(c.Query, tags)
-	return nil
+	return tags
 }
 
 // encodeTraceParent encodes trace parent as per the w3c trace context spec (https://www.w3.org/TR/trace-context/#version).
@@ -151,20 +161,38 @@ func commentQuery(query string, tags map[string]string) string {
 	if len(tags) == 0 {
 		return ""
 	}
+	c := formatDBMTags(tags)
+	if c == "" {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(c) + len(query) + 5)
+	b.WriteString("/*")
+	b.WriteString(c)
+	b.WriteString("*/")
+	if query == "" {
+		return b.String()
+	}
+	log.Debug("Injected sql comment: %s", b.String())
+	b.WriteRune(' ')
+	b.WriteString(query)
+	return b.String()
+}
+
+// formatDBMTags returns tags as a comma-separated list of key='value' pairs, the content of
+// a sqlcommenter comment.
+func formatDBMTags(tags map[string]string) string {
 	var b strings.Builder
 	// the sqlcommenter specification dictates that tags should be sorted. Since we know all injected keys,
 	// we skip a sorting operation by specifying the order of keys statically
 	orderedKeys := []string{sqlCommentDBService, sqlCommentEnv, sqlCommentParentService, sqlCommentParentVersion, sqlCommentTraceParent}
-	first := true
 	for _, k := range orderedKeys {
 		if v, ok := tags[k]; ok {
 			// we need to URL-encode both keys and values and escape single quotes in values
 			// https://google.github.io/sqlcommenter/spec/
 			key := keyReplacer.Replace(k)
 			val := valueReplacer.Replace(v)
-			if first {
-				b.WriteString("/*")
-			} else {
+			if b.Len() > 0 {
 				b.WriteRune(',')
 			}
 			b.WriteString(key)
@@ -172,19 +200,8 @@ func commentQuery(query string, tags map[string]string) string {
 			b.WriteRune('\'')
 			b.WriteString(val)
 			b.WriteRune('\'')
-			first = false
 		}
 	}
-	if b.Len() == 0 {
-		return query
-	}
-	b.WriteString("*/")
-	if query == "" {
-		return b.String()
-	}
-	log.Debug("Injected sql comment: %s", b.String())
-	b.WriteRune(' ')
-	b.WriteString(query)
 	return b.String()
 }
 
@@ -192,3 +209,47 @@ func commentQuery(query string, tags map[string]string) string {
 func (c *SQLCommentCarrier) Extract() (ddtrace.SpanContext, error) {
 	return nil, nil
 }
+
+// DBMCommentCarrier is a carrier implementation for the database protocols which don't carry
+// comments in the query text. It injects the same tags as SQLCommentCarrier, both as key/value
+// pairs in Tags, such as for Cassandra custom payloads, and formatted as the content of a
+// sqlcommenter comment in Comment, such as for the MongoDB $comment field.
+type DBMCommentCarrier struct {
+	Comment       string
+	Tags          map[string]string
+	Mode          DBMPropagationMode
+	DBServiceName string
+	SpanID        uint64
+}
+
+// Inject injects a span context in the carrier's Tags and Comment fields. SpanID is set to
+// the span ID to use for the span of the database call.
+func (c *DBMCommentCarrier) Inject(spanCtx ddtrace.SpanContext) error {
+	c.SpanID = generateSpanID(now())
+	c.Tags = dbmTags(spanCtx, c.Mode, c.DBServiceName, c.SpanID)
+	c.Comment = formatDBMTags(c.Tags)
+	return nil
+}
+
+// Extract returns the span context propagated to the database in the carrier's Tags or,
+// when not set, in its Comment. Its span ID is the one of the database call. It returns
+// ErrSpanContextNotFound if the carrier wasn't injected in DBMPropagationModeFull.
+func (c *DBMCommentCarrier) Extract() (ddtrace.SpanContext, error) {
+	tp, ok := c.Tags[sqlCommentTraceParent]
+	if !ok {
+		const prefix = sqlCommentTraceParent + "='"
+		i := strings.Index(c.Comment, prefix)
+		if i < 0 {
+			return nil, ErrSpanContextNotFound
+		}
+		tp = c.Comment[i+len(prefix):]
+		if j := strings.IndexByte(tp, '\''); j >= 0 {
+			tp = tp[:j]
+		}
+	}
+	var ctx spanContext
+	if err := parseTraceparent(&ctx, tp); err != nil {
+		return nil, err
+	}
+	return &ctx, nil
+}
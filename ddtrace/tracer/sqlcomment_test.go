@@ -93,6 +93,50 @@ func TestSQLCommentCarrier(t *testing.T) {
 	}
 }
 
+func TestDBMCommentCarrier(t *testing.T) {
+	tracer := newTracer(WithService("whiskey-service"), WithEnv("test-env"), WithServiceVersion("1.0.0"))
+	defer tracer.Stop()
+	root := tracer.StartSpan("service.calling.db", WithSpanID(10)).(*span)
+	root.SetTag(ext.SamplingPriority, 1)
+
+	t.Run("full", func(t *testing.T) {
+		carrier := DBMCommentCarrier{Mode: DBMPropagationModeFull, DBServiceName: "whiskey-db"}
+		require.NoError(t, carrier.Inject(root.Context()))
+		tp := fmt.Sprintf("00-0000000000000000000000000000000a-%016x-01", carrier.SpanID)
+		assert.Equal(t, map[string]string{
+			"dddbs":       "whiskey-db",
+			"dde":         "test-env",
+			"ddps":        "whiskey-service",
+			"ddpv":        "1.0.0",
+			"traceparent": tp,
+		}, carrier.Tags)
+		assert.Equal(t, "dddbs='whiskey-db',dde='test-env',ddps='whiskey-service',ddpv='1.0.0',traceparent='"+tp+"'", carrier.Comment)
+
+		// the span context can be extracted from either field
+		for _, c := range []DBMCommentCarrier{{Tags: carrier.Tags}, {Comment: "/*" + carrier.Comment + "*/ SELECT 1"}} {
+			sctx, err := c.Extract()
+			require.NoError(t, err)
+			assert.Equal(t, carrier.SpanID, sctx.SpanID())
+			assert.EqualValues(t, 10, sctx.TraceID())
+		}
+	})
+
+	t.Run("service", func(t *testing.T) {
+		carrier := DBMCommentCarrier{Mode: DBMPropagationModeService, DBServiceName: "whiskey-db"}
+		require.NoError(t, carrier.Inject(root.Context()))
+		assert.Equal(t, "dddbs='whiskey-db',dde='test-env',ddps='whiskey-service',ddpv='1.0.0'", carrier.Comment)
+		_, err := carrier.Extract()
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		carrier := DBMCommentCarrier{Mode: DBMPropagationModeDisabled, DBServiceName: "whiskey-db"}
+		require.NoError(t, carrier.Inject(root.Context()))
+		assert.Empty(t, carrier.Tags)
+		assert.Empty(t, carrier.Comment)
+	})
+}
+
 func BenchmarkSQLCommentInjection(b *testing.B) {
 	tracer := newTracer(WithService("whiskey-service !#$%&'()*+,/:;=?@[]"), WithEnv("test-env"), WithServiceVersion("1.0.0"))
 	defer tracer.Stop()
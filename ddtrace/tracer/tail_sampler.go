@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"container/list"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultTailSamplingMaxPerSecond is the default limit of traces kept per second by tail sampling.
+	defaultTailSamplingMaxPerSecond = 100
+
+	// defaultTailSamplingMaxBufferedSpans is the default limit of spans buffered while waiting for
+	// their traces to complete.
+	defaultTailSamplingMaxBufferedSpans = 10000
+
+	// defaultTailSamplingMaxBufferTime is the default time after which an incomplete trace is
+	// released from the buffer without a tail sampling decision.
+	defaultTailSamplingMaxBufferTime = 30 * time.Second
+)
+
+// TailSamplingRule specifies the spans which cause a complete trace to be kept by tail sampling.
+// A trace matches the rule when any of its spans matches all the conditions set in the rule.
+// A rule without any condition matches all the traces.
+type TailSamplingRule struct {
+	// Error requires the span to have an error.
+	Error bool
+
+	// MinDuration requires the span to last at least MinDuration. Use it on the root span, by
+	// combining it with Service or Resource, to match traces exceeding a latency threshold.
+	MinDuration time.Duration
+
+	// Tags requires the span to have all of these tags. An empty value matches any value of the tag.
+	Tags map[string]string
+
+	// Service specifies the regex pattern that the span service name must match.
+	Service *regexp.Regexp
+
+	// Resource specifies the regex pattern that the span resource name must match.
+	Resource *regexp.Regexp
+}
+
+// match returns true when the span matches all the conditions of the rule.
+func (r *TailSamplingRule) match(s *span) bool {
+	s.RLock()
+	defer s.RUnlock()
+	if r.Error && s.Error == 0 {
+		return false
+	}
+	if r.MinDuration > 0 && time.Duration(s.Duration) < r.MinDuration {
+		return false
+	}
+	if r.Service != nil && !r.Service.MatchString(s.Service) {
+		return false
+	}
+	if r.Resource != nil && !r.Resource.MatchString(s.Resource) {
+		return false
+	}
+	for k, want := range r.Tags {
+		v, ok := s.Meta[k]
+		if !ok || (want != "" && v != want) {
+			return false
+		}
+	}
+	return true
+}
+
+// TailSamplingConfig configures tail-based sampling. See WithTailSampling.
+type TailSamplingConfig struct {
+	// Rules specifies the rules matching the traces to keep.
+	Rules []TailSamplingRule
+
+	// MaxPerSecond limits the number of traces kept per second by tail sampling.
+	// The default is 100.
+	MaxPerSecond float64
+
+	// MaxBufferedSpans limits the number of spans held while waiting for partially flushed
+	// traces to complete. The oldest traces are released without a decision when the limit
+	// is reached. The default is 10000.
+	MaxBufferedSpans int
+
+	// MaxBufferTime limits the time a partially flushed trace is held while waiting for it
+	// to complete, after which it is released without a decision. The default is 30 seconds.
+	MaxBufferTime time.Duration
+}
+
+// pendingTrace holds the chunks of a partially flushed trace waiting for its last chunk.
+type pendingTrace struct {
+	id      traceID
+	chunks  []*finishedTrace
+	spans   int       // number of spans in chunks
+	created time.Time // time at which the first chunk was received
+}
+
+// tailSampler decides whether to keep traces once they have completed, based on all of their
+// spans. Traces already kept by the head-based samplers are passed through untouched. The
+// chunks of partially flushed traces are buffered until the trace completes, so that the
+// decision applies to the whole trace. Kept traces get the user-keep sampling priority and
+// the TailSampling decision maker. Since the decision is taken after the trace context has
+// been propagated, downstream services keep their own decision.
+//
+// A tailSampler is not safe for concurrent use: it is only used by the tracer's worker.
+type tailSampler struct {
+	rules     []TailSamplingRule
+	limiter   *rate.Limiter
+	maxSpans  int
+	maxBuffer time.Duration
+	statsd    statsdClient
+
+	// out receives the traces once they have been evaluated or released.
+	out func(*finishedTrace)
+
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+
+	pending map[traceID]*list.Element // buffered traces, values are *pendingTrace
+	order   *list.List                // buffered traces, oldest first
+	spans   int                       // total number of buffered spans
+}
+
+// newTailSampler returns a tail sampler using the given configuration and sending the
+// traces to out. Zero values in cfg are replaced by defaults.
+func newTailSampler(cfg TailSamplingConfig, statsdClient statsdClient, out func(*finishedTrace)) *tailSampler {
+	if cfg.MaxPerSecond <= 0 {
+		cfg.MaxPerSecond = defaultTailSamplingMaxPerSecond
+	}
+	if cfg.MaxBufferedSpans <= 0 {
+		cfg.MaxBufferedSpans = defaultTailSamplingMaxBufferedSpans
+	}
+	if cfg.MaxBufferTime <= 0 {
+		cfg.MaxBufferTime = defaultTailSamplingMaxBufferTime
+	}
+	return &tailSampler{
+		rules:     cfg.Rules,
+		limiter:   rate.NewLimiter(rate.Limit(cfg.MaxPerSecond), int(math.Ceil(cfg.MaxPerSecond))),
+		maxSpans:  cfg.MaxBufferedSpans,
+		maxBuffer: cfg.MaxBufferTime,
+		statsd:    statsdClient,
+		out:       out,
+		now:       time.Now,
+		pending:   make(map[traceID]*list.Element),
+		order:     list.New(),
+	}
+}
+
+// push receives a chunk of a finished trace. Chunks of traces which are still in progress
+// are buffered, and the last chunk of a trace triggers the sampling decision.
+func (ts *tailSampler) push(trace *finishedTrace) {
+	if len(trace.spans) == 0 {
+		ts.out(trace)
+		return
+	}
+	first := trace.spans[0]
+	if p, ok := first.context.samplingPriority(); ok && p > 0 {
+		// kept by the head-based samplers
+		ts.out(trace)
+		return
+	}
+	id := first.context.traceID
+	if trace.partial {
+		ts.buffer(id, trace)
+		return
+	}
+	chunks := []*finishedTrace{trace}
+	if e, ok := ts.pending[id]; ok {
+		pt := ts.remove(e)
+		chunks = append(pt.chunks, trace)
+	}
+	if ts.match(chunks) {
+		if ts.limiter.AllowN(ts.now(), 1) {
+			ts.keep(chunks)
+			ts.statsd.Incr("datadog.tracer.tail_sampling.traces_kept", nil, 1)
+		} else {
+			ts.statsd.Incr("datadog.tracer.tail_sampling.traces_rate_limited", nil, 1)
+		}
+	}
+	for _, c := range chunks {
+		ts.out(c)
+	}
+}
+
+// buffer holds the partial chunk of the trace with the given id, releasing the oldest
+// traces if the buffer exceeds its maximum size.
+func (ts *tailSampler) buffer(id traceID, trace *finishedTrace) {
+	var pt *pendingTrace
+	if e, ok := ts.pending[id]; ok {
+		pt = e.Value.(*pendingTrace)
+	} else {
+		pt = &pendingTrace{id: id, created: ts.now()}
+		ts.pending[id] = ts.order.PushBack(pt)
+	}
+	pt.chunks = append(pt.chunks, trace)
+	pt.spans += len(trace.spans)
+	ts.spans += len(trace.spans)
+	for ts.spans > ts.maxSpans && ts.order.Len() > 0 {
+		ts.release(ts.order.Front(), "buffer_full")
+	}
+}
+
+// expire releases the buffered traces which have waited longer than the maximum buffer time.
+func (ts *tailSampler) expire() {
+	deadline := ts.now().Add(-ts.maxBuffer)
+	for e := ts.order.Front(); e != nil && e.Value.(*pendingTrace).created.Before(deadline); e = ts.order.Front() {
+		ts.release(e, "timeout")
+	}
+}
+
+// stop releases all the buffered traces when the tracer stops. As they can't complete anymore,
+// the tail sampling rules are not applied to them: they are sent with their head-based
+// decision, which drops them. Flushing the tracer doesn't release them, since they may still
+// complete.
+func (ts *tailSampler) stop() {
+	for ts.order.Len() > 0 {
+		ts.release(ts.order.Front(), "stop")
+	}
+}
+
+// release sends the chunks of the buffered trace e without a tail sampling decision.
+func (ts *tailSampler) release(e *list.Element, reason string) {
+	pt := ts.remove(e)
+	log.Debug("Releasing incomplete trace of %d spans from the tail sampling buffer (reason: %s)", pt.spans, reason)
+	ts.statsd.Incr("datadog.tracer.tail_sampling.traces_released", []string{"reason:" + reason}, 1)
+	for _, c := range pt.chunks {
+		ts.out(c)
+	}
+}
+
+// remove removes the buffered trace e and returns it.
+func (ts *tailSampler) remove(e *list.Element) *pendingTrace {
+	pt := ts.order.Remove(e).(*pendingTrace)
+	delete(ts.pending, pt.id)
+	ts.spans -= pt.spans
+	return pt
+}
+
+// match reports whether any span of the trace made of chunks matches any of the rules.
+func (ts *tailSampler) match(chunks []*finishedTrace) bool {
+	for i := range ts.rules {
+		r := &ts.rules[i]
+		for _, c := range chunks {
+			for _, s := range c.spans {
+				if r.match(s) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// keep marks the trace made of chunks as kept by tail sampling.
+func (ts *tailSampler) keep(chunks []*finishedTrace) {
+	chunks[0].spans[0].context.trace.keepFinished(ext.PriorityUserKeep, samplernames.TailSampling)
+	dm := "-" + strconv.Itoa(int(samplernames.TailSampling))
+	for _, c := range chunks {
+		c.willSend = true
+		// the trace-level tags were set on the first span of each chunk when it finished
+		s := c.spans[0]
+		s.Lock()
+		s.setMetric(keySamplingPriority, ext.PriorityUserKeep)
+		s.setMeta(keyDecisionMaker, dm)
+		s.Unlock()
+	}
+}
@@ -0,0 +1,261 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package tracer
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailSamplingRule(t *testing.T) {
+	s := newSpan("http.request", "web", "GET /users", 1, 1, 0)
+	s.Duration = int64(2 * time.Second)
+	s.Meta["http.status_code"] = "500"
+	errSpan := newSpan("db.query", "db", "SELECT", 2, 1, 1)
+	errSpan.Error = 1
+
+	for name, tc := range map[string]struct {
+		rule TailSamplingRule
+		span *span
+		want bool
+	}{
+		"empty":             {rule: TailSamplingRule{}, span: s, want: true},
+		"error":             {rule: TailSamplingRule{Error: true}, span: errSpan, want: true},
+		"no-error":          {rule: TailSamplingRule{Error: true}, span: s, want: false},
+		"duration":          {rule: TailSamplingRule{MinDuration: time.Second}, span: s, want: true},
+		"short":             {rule: TailSamplingRule{MinDuration: 3 * time.Second}, span: s, want: false},
+		"tag":               {rule: TailSamplingRule{Tags: map[string]string{"http.status_code": "500"}}, span: s, want: true},
+		"tag-any":           {rule: TailSamplingRule{Tags: map[string]string{"http.status_code": ""}}, span: s, want: true},
+		"tag-mismatch":      {rule: TailSamplingRule{Tags: map[string]string{"http.status_code": "200"}}, span: s, want: false},
+		"tag-missing":       {rule: TailSamplingRule{Tags: map[string]string{"http.status_code": ""}}, span: errSpan, want: false},
+		"service":           {rule: TailSamplingRule{Service: regexp.MustCompile("^web$")}, span: s, want: true},
+		"service-mismatch":  {rule: TailSamplingRule{Service: regexp.MustCompile("^web$")}, span: errSpan, want: false},
+		"resource":          {rule: TailSamplingRule{Resource: regexp.MustCompile("^GET ")}, span: s, want: true},
+		"resource-mismatch": {rule: TailSamplingRule{Resource: regexp.MustCompile("^POST ")}, span: s, want: false},
+		"all":               {rule: TailSamplingRule{Service: regexp.MustCompile("^web$"), MinDuration: time.Second, Tags: map[string]string{"http.status_code": "500"}}, span: s, want: true},
+		"all-but-one":       {rule: TailSamplingRule{Service: regexp.MustCompile("^web$"), Error: true}, span: s, want: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rule.match(tc.span))
+		})
+	}
+}
+
+// newTailSamplerTest returns a tail sampler using cfg and the list collecting the traces it outputs.
+func newTailSamplerTest(cfg TailSamplingConfig, statsd statsdClient) (*tailSampler, *[]*finishedTrace) {
+	var out []*finishedTrace
+	ts := newTailSampler(cfg, statsd, func(trace *finishedTrace) {
+		out = append(out, trace)
+	})
+	return ts, &out
+}
+
+// tailSampledChunk returns a chunk made of a root span and a child sharing its trace.
+func tailSampledChunk(traceID uint64, partial bool) (*finishedTrace, *span) {
+	root := newSpan("http.request", "web", "GET /", traceID, traceID, 0)
+	child := newSpan("db.query", "db", "SELECT", traceID+1, traceID, traceID)
+	child.context = newSpanContext(child, root.context)
+	return &finishedTrace{spans: []*span{root, child}, partial: partial}, child
+}
+
+// assertTailKept asserts whether the chunk was kept by tail sampling.
+func assertTailKept(t *testing.T, chunk *finishedTrace, kept bool) {
+	t.Helper()
+	s := chunk.spans[0]
+	assert.Equal(t, kept, chunk.willSend)
+	if !kept {
+		assert.NotContains(t, s.Metrics, keySamplingPriority)
+		assert.NotContains(t, s.Meta, keyDecisionMaker)
+		return
+	}
+	assert.EqualValues(t, ext.PriorityUserKeep, s.Metrics[keySamplingPriority])
+	assert.Equal(t, "-9", s.Meta[keyDecisionMaker])
+	p, ok := s.context.samplingPriority()
+	assert.True(t, ok)
+	assert.Equal(t, ext.PriorityUserKeep, p)
+}
+
+func TestTailSampler(t *testing.T) {
+	errorRule := TailSamplingConfig{Rules: []TailSamplingRule{{Error: true}}}
+
+	t.Run("defaults", func(t *testing.T) {
+		ts, _ := newTailSamplerTest(TailSamplingConfig{}, &testStatsdClient{})
+		assert.EqualValues(t, defaultTailSamplingMaxPerSecond, ts.limiter.Limit())
+		assert.Equal(t, defaultTailSamplingMaxBufferedSpans, ts.maxSpans)
+		assert.Equal(t, defaultTailSamplingMaxBufferTime, ts.maxBuffer)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		var statsd testStatsdClient
+		ts, out := newTailSamplerTest(errorRule, &statsd)
+		chunk, child := tailSampledChunk(10, false)
+		child.Error = 1
+		ts.push(chunk)
+		require.Len(t, *out, 1)
+		assertTailKept(t, (*out)[0], true)
+		assert.EqualValues(t, 1, statsd.counts["datadog.tracer.tail_sampling.traces_kept"])
+	})
+
+	t.Run("no-match", func(t *testing.T) {
+		ts, out := newTailSamplerTest(errorRule, &testStatsdClient{})
+		chunk, _ := tailSampledChunk(10, false)
+		ts.push(chunk)
+		require.Len(t, *out, 1)
+		assertTailKept(t, (*out)[0], false)
+	})
+
+	t.Run("head-kept", func(t *testing.T) {
+		ts, out := newTailSamplerTest(errorRule, &testStatsdClient{})
+		chunk, child := tailSampledChunk(10, false)
+		child.Error = 1
+		chunk.spans[0].context.setSamplingPriority(ext.PriorityAutoKeep, 0)
+		ts.push(chunk)
+		require.Len(t, *out, 1)
+		p, _ := chunk.spans[0].context.samplingPriority()
+		assert.Equal(t, ext.PriorityAutoKeep, p)
+		assert.NotContains(t, chunk.spans[0].Meta, keyDecisionMaker)
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		ts, out := newTailSamplerTest(errorRule, &testStatsdClient{})
+		first, _ := tailSampledChunk(10, true)
+		ts.push(first)
+		assert.Empty(t, *out)
+		assert.Equal(t, 2, ts.spans)
+
+		// the error in the last chunk keeps the whole trace
+		last := newSpan("http.request", "web", "GET /", 12, 10, 10)
+		last.context = newSpanContext(last, first.spans[0].context)
+		last.Error = 1
+		ts.push(&finishedTrace{spans: []*span{last}})
+		require.Len(t, *out, 2)
+		assert.Equal(t, first, (*out)[0])
+		assertTailKept(t, (*out)[0], true)
+		assertTailKept(t, (*out)[1], true)
+		assert.Zero(t, ts.spans)
+		assert.Empty(t, ts.pending)
+	})
+
+	t.Run("rate-limit", func(t *testing.T) {
+		var statsd testStatsdClient
+		ts, out := newTailSamplerTest(TailSamplingConfig{Rules: errorRule.Rules, MaxPerSecond: 1}, &statsd)
+		now := time.Now()
+		ts.now = func() time.Time { return now }
+		for i := uint64(0); i < 3; i++ {
+			chunk, child := tailSampledChunk(10*(i+1), false)
+			child.Error = 1
+			ts.push(chunk)
+		}
+		require.Len(t, *out, 3)
+		assertTailKept(t, (*out)[0], true)
+		assertTailKept(t, (*out)[1], false)
+		assertTailKept(t, (*out)[2], false)
+		assert.EqualValues(t, 2, statsd.counts["datadog.tracer.tail_sampling.traces_rate_limited"])
+
+		now = now.Add(time.Second)
+		chunk, child := tailSampledChunk(40, false)
+		child.Error = 1
+		ts.push(chunk)
+		assertTailKept(t, (*out)[3], true)
+	})
+
+	t.Run("buffer-full", func(t *testing.T) {
+		var statsd testStatsdClient
+		ts, out := newTailSamplerTest(TailSamplingConfig{Rules: errorRule.Rules, MaxBufferedSpans: 3}, &statsd)
+		first, _ := tailSampledChunk(10, true)
+		second, _ := tailSampledChunk(20, true)
+		ts.push(first)
+		ts.push(second)
+		// the oldest trace is released without a decision
+		require.Len(t, *out, 1)
+		assert.Equal(t, first, (*out)[0])
+		assertTailKept(t, first, false)
+		assert.Equal(t, 2, ts.spans)
+		assert.Len(t, ts.pending, 1)
+		assert.EqualValues(t, 1, statsd.counts["datadog.tracer.tail_sampling.traces_released"])
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		ts, out := newTailSamplerTest(TailSamplingConfig{Rules: errorRule.Rules, MaxBufferTime: time.Minute}, &testStatsdClient{})
+		now := time.Now()
+		ts.now = func() time.Time { return now }
+		first, _ := tailSampledChunk(10, true)
+		ts.push(first)
+		now = now.Add(45 * time.Second)
+		second, _ := tailSampledChunk(20, true)
+		ts.push(second)
+
+		now = now.Add(30 * time.Second)
+		ts.expire()
+		require.Len(t, *out, 1)
+		assert.Equal(t, first, (*out)[0])
+		assert.Len(t, ts.pending, 1)
+
+		ts.stop()
+		require.Len(t, *out, 2)
+		assert.Equal(t, second, (*out)[1])
+		assert.Empty(t, ts.pending)
+		assert.Zero(t, ts.spans)
+	})
+}
+
+func TestTailSamplingTracer(t *testing.T) {
+	tracer, transport, flush, stop := startTestTracer(t, WithTailSampling(TailSamplingConfig{
+		Rules: []TailSamplingRule{{Error: true}},
+	}))
+	defer stop()
+	require.NotNil(t, tracer.tailSampling)
+
+	dropped := tracer.StartSpan("web.request", Tag(ext.ManualDrop, true))
+	dropped.Finish()
+	errored := tracer.StartSpan("web.request", Tag(ext.ManualDrop, true))
+	errored.SetTag(ext.Error, true)
+	errored.Finish()
+	flush(2)
+
+	traces := transport.Traces()
+	require.Len(t, traces, 2)
+	assert.EqualValues(t, ext.PriorityUserReject, traces[0][0].Metrics[keySamplingPriority])
+	assert.EqualValues(t, ext.PriorityUserKeep, traces[1][0].Metrics[keySamplingPriority])
+	assert.Equal(t, "-9", traces[1][0].Meta[keyDecisionMaker])
+}
+
+func TestTailSamplingFlushAndStop(t *testing.T) {
+	tracer, transport, _, stop := startTestTracer(t, WithTailSampling(TailSamplingConfig{
+		Rules: []TailSamplingRule{{Error: true}},
+	}))
+	defer stop()
+
+	chunk, child := tailSampledChunk(10, true)
+	child.Error = 1
+	child.context.setSamplingPriority(ext.PriorityUserReject, samplernames.Manual)
+	tracer.pushTrace(chunk)
+	for len(tracer.out) > 0 {
+		// let the worker receive the chunk before flushing
+		time.Sleep(time.Millisecond)
+	}
+
+	// flushing the tracer keeps the incomplete trace buffered
+	tracer.flushSync()
+	assert.Zero(t, transport.Len())
+	assert.Len(t, tracer.tailSampling.pending, 1)
+
+	// stopping the tracer releases it with its head-based decision, which drops it
+	tracer.Stop()
+	assert.Zero(t, transport.Len())
+	assert.Empty(t, tracer.tailSampling.pending)
+	assert.False(t, chunk.willSend)
+	assert.Empty(t, chunk.spans)
+	p, _ := child.context.samplingPriority()
+	assert.Equal(t, ext.PriorityUserReject, p)
+}
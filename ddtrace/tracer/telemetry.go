@@ -7,6 +7,7 @@ package tracer
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
 )
@@ -46,11 +47,25 @@ func startTelemetry(c *config) {
 		{Name: "agent_url", Value: c.agentURL.String()},
 		{Name: "agent_hostname", Value: c.hostname},
 		{Name: "runtime_metrics_enabled", Value: c.runtimeMetrics},
+		{Name: "runtime_metrics_v2_enabled", Value: c.runtimeMetricsV2},
 		{Name: "dogstatsd_addr", Value: c.dogstatsdAddr},
 		{Name: "trace_debug_enabled", Value: !c.noDebugStack},
 		{Name: "profiling_hotspots_enabled", Value: c.profilerHotspots},
 		{Name: "profiling_endpoints_enabled", Value: c.profilerEndpoints},
 		{Name: "trace_enabled", Value: c.enabled},
+		{Name: "trace_partial_flush_enabled", Value: c.partialFlushEnabled},
+		{Name: "trace_partial_flush_min_spans", Value: c.partialFlushMinSpans},
+		{Name: "trace_header_tags", Value: strings.Join(c.headerAsTags, ",")},
+		{Name: "data_streams_enabled", Value: c.dataStreamsMonitoringEnabled},
+		{Name: "agentless", Value: c.agentless},
+		{Name: "site", Value: c.site},
+		{Name: "trace_spool_enabled", Value: c.spoolDir != ""},
+		{Name: "trace_otlp_export_enabled", Value: c.otlpExport},
+		{Name: "trace_tail_sampling_enabled", Value: c.tailSampling != nil},
+		{Name: "dynamic_instrumentation_enabled", Value: c.dynamicInstrumentation},
+		{Name: "trace_sampling_target_tps", Value: c.adaptiveSamplingTPS},
+		{Name: "trace_root_sampling_keep_errors", Value: c.rootSampling.KeepErrors},
+		{Name: "trace_root_sampling_latency_threshold", Value: c.rootSampling.LatencyThreshold.String()},
 	}
 	for k, v := range c.featureFlags {
 		telemetryConfigs = append(telemetryConfigs, telemetry.Configuration{Name: k, Value: v})
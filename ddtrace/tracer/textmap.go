@@ -8,13 +8,16 @@ package tracer
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
 )
@@ -127,6 +130,14 @@ type PropagatorConfig struct {
 	// B3 specifies if B3 headers should be added for trace propagation.
 	// See https://github.com/openzipkin/b3-propagation
 	B3 bool
+
+	// BaggageMaxItems specifies the maximum number of items propagated in the W3C baggage
+	// header. It defaults to DD_TRACE_BAGGAGE_MAX_ITEMS, or 64.
+	BaggageMaxItems int
+
+	// BaggageMaxBytes specifies the maximum size in bytes of the W3C baggage header.
+	// It defaults to DD_TRACE_BAGGAGE_MAX_BYTES, or 8192.
+	BaggageMaxBytes int
 }
 
 // NewPropagator returns a new propagator which uses TextMap to inject
@@ -139,6 +150,9 @@ type PropagatorConfig struct {
 //  2. DD_PROPAGATION_STYLE_INJECT (deprecated)
 //  3. DD_TRACE_PROPAGATION_STYLE (applies to both inject and extract)
 //  4. If none of the above, use default values
+//
+// Along with the trace propagation styles, the "baggage" style propagates the baggage
+// items using the W3C baggage header, within the limits set in the configuration.
 func NewPropagator(cfg *PropagatorConfig, propagators ...Propagator) Propagator {
 	if cfg == nil {
 		cfg = new(PropagatorConfig)
@@ -155,6 +169,12 @@ func NewPropagator(cfg *PropagatorConfig, propagators ...Propagator) Propagator
 	if cfg.PriorityHeader == "" {
 		cfg.PriorityHeader = DefaultPriorityHeader
 	}
+	if cfg.BaggageMaxItems <= 0 {
+		cfg.BaggageMaxItems = internal.IntEnv("DD_TRACE_BAGGAGE_MAX_ITEMS", defaultBaggageMaxItems)
+	}
+	if cfg.BaggageMaxBytes <= 0 {
+		cfg.BaggageMaxBytes = internal.IntEnv("DD_TRACE_BAGGAGE_MAX_BYTES", defaultBaggageMaxBytes)
+	}
 	if len(propagators) > 0 {
 		return &chainedPropagator{
 			injectors:  propagators,
@@ -225,6 +245,8 @@ func getPropagators(cfg *PropagatorConfig, ps string) []Propagator {
 			}
 		case "b3 single header":
 			list = append(list, &propagatorB3SingleHeader{})
+		case "baggage":
+			list = append(list, &propagatorBaggage{cfg})
 		case "none":
 			log.Warn("Propagator \"none\" has no effect when combined with other propagators. " +
 				"To disable the propagator, set to `none`")
@@ -251,21 +273,43 @@ func (p *chainedPropagator) Inject(spanCtx ddtrace.SpanContext, carrier interfac
 	return nil
 }
 
-// Extract implements Propagator.
+// Extract implements Propagator. The W3C baggage found by the baggage extractors
+// is added to the span context returned by the first successful extractor.
 func (p *chainedPropagator) Extract(carrier interface{}) (ddtrace.SpanContext, error) {
+	var ctx ddtrace.SpanContext
 	for _, v := range p.extractors {
-		ctx, err := v.Extract(carrier)
-		if ctx != nil {
+		if _, ok := v.(*propagatorBaggage); ok {
+			continue
+		}
+		c, err := v.Extract(carrier)
+		if c != nil {
 			// first extractor returns
-			log.Debug("Extracted span context: %#v", ctx)
-			return ctx, nil
+			ctx = c
+			break
 		}
 		if err == ErrSpanContextNotFound {
 			continue
 		}
 		return nil, err
 	}
-	return nil, ErrSpanContextNotFound
+	for _, v := range p.extractors {
+		if _, ok := v.(*propagatorBaggage); !ok {
+			continue
+		}
+		c, err := v.Extract(carrier)
+		if err == ErrSpanContextNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ctx = mergeBaggage(ctx, c.(*spanContext))
+	}
+	if ctx == nil {
+		return nil, ErrSpanContextNotFound
+	}
+	log.Debug("Extracted span context: %#v", ctx)
+	return ctx, nil
 }
 
 // propagator implements Propagator and injects/extracts span contexts
@@ -978,3 +1022,207 @@ func extractTraceID128(ctx *spanContext, v string) error {
 	}
 	return nil
 }
+
+const (
+	baggageHeader = "baggage"
+
+	// defaultBaggageMaxItems is the default maximum number of items in the W3C baggage header.
+	defaultBaggageMaxItems = 64
+
+	// defaultBaggageMaxBytes is the default maximum size of the W3C baggage header.
+	defaultBaggageMaxBytes = 8192
+)
+
+// propagatorBaggage implements Propagator and injects/extracts the span context baggage
+// using the W3C baggage header. Only TextMap carriers are supported. It doesn't propagate
+// the trace itself: when chained with other propagators, the extracted baggage is added to
+// the span context extracted by them. The metadata properties of the baggage items are
+// kept along with them, to be propagated further.
+// See https://www.w3.org/TR/baggage/
+type propagatorBaggage struct {
+	cfg *PropagatorConfig
+}
+
+func (p *propagatorBaggage) Inject(spanCtx ddtrace.SpanContext, carrier interface{}) error {
+	switch c := carrier.(type) {
+	case TextMapWriter:
+		return p.injectTextMap(spanCtx, c)
+	default:
+		return ErrInvalidCarrier
+	}
+}
+
+func (p *propagatorBaggage) injectTextMap(spanCtx ddtrace.SpanContext, writer TextMapWriter) error {
+	ctx, ok := spanCtx.(*spanContext)
+	if !ok {
+		return ErrInvalidSpanContext
+	}
+	if v := p.marshalBaggage(ctx); v != "" {
+		writer.Set(baggageHeader, v)
+	}
+	return nil
+}
+
+// marshalBaggage returns the value of the baggage header holding the baggage items of ctx,
+// sorted by key, within the configured limits.
+func (p *propagatorBaggage) marshalBaggage(ctx *spanContext) string {
+	var keys []string
+	ctx.ForeachBaggageItem(func(k, _ string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i == p.cfg.BaggageMaxItems {
+			log.Debug("Won't propagate %d baggage items: the limit of %d items is reached.", len(keys)-i, p.cfg.BaggageMaxItems)
+			break
+		}
+		member := encodeBaggage(k, isTokenChar) + "=" + encodeBaggage(ctx.baggageItem(k), isBaggageOctet) + ctx.baggageItemProperties(k)
+		if b.Len() > 0 {
+			member = "," + member
+		}
+		if b.Len()+len(member) > p.cfg.BaggageMaxBytes {
+			log.Debug("Won't propagate %d baggage items: the limit of %d bytes is reached.", len(keys)-i, p.cfg.BaggageMaxBytes)
+			break
+		}
+		b.WriteString(member)
+	}
+	return b.String()
+}
+
+func (p *propagatorBaggage) Extract(carrier interface{}) (ddtrace.SpanContext, error) {
+	switch c := carrier.(type) {
+	case TextMapReader:
+		return p.extractTextMap(c)
+	default:
+		return nil, ErrInvalidCarrier
+	}
+}
+
+// extractTextMap returns a span context holding only the baggage found in the reader.
+// It returns ErrSpanContextNotFound when the reader holds no valid baggage item.
+func (p *propagatorBaggage) extractTextMap(reader TextMapReader) (ddtrace.SpanContext, error) {
+	var header string
+	err := reader.ForeachKey(func(k, v string) error {
+		if strings.ToLower(k) == baggageHeader {
+			if header != "" {
+				header += ","
+			}
+			header += v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ctx spanContext
+	p.unmarshalBaggage(&ctx, header)
+	if len(ctx.baggage) == 0 {
+		return nil, ErrSpanContextNotFound
+	}
+	return &ctx, nil
+}
+
+// unmarshalBaggage sets the baggage items found in the baggage header value v on ctx,
+// within the configured limits. Malformed items are ignored.
+func (p *propagatorBaggage) unmarshalBaggage(ctx *spanContext, v string) {
+	var items, size int
+	for _, member := range strings.Split(v, ",") {
+		member = strings.Trim(member, " \t")
+		if member == "" {
+			continue
+		}
+		if items == p.cfg.BaggageMaxItems || size+len(member) > p.cfg.BaggageMaxBytes {
+			log.Debug("Dropping baggage items over the limits of %d items and %d bytes.", p.cfg.BaggageMaxItems, p.cfg.BaggageMaxBytes)
+			return
+		}
+		size += len(member) + 1
+		kv, props, _ := strings.Cut(member, ";")
+		k, val, ok := strings.Cut(kv, "=")
+		if !ok {
+			log.Debug("Ignoring malformed baggage item %q.", member)
+			continue
+		}
+		key, err := url.PathUnescape(strings.Trim(k, " \t"))
+		if err != nil || key == "" {
+			log.Debug("Ignoring baggage item with malformed key %q.", k)
+			continue
+		}
+		value, err := url.PathUnescape(strings.Trim(val, " \t"))
+		if err != nil {
+			log.Debug("Ignoring baggage item %q with malformed value.", key)
+			continue
+		}
+		ctx.setBaggageItem(key, value)
+		if props = normalizeBaggageProperties(props); props != "" {
+			ctx.setBaggageItemProperties(key, props)
+		}
+		items++
+	}
+}
+
+// normalizeBaggageProperties returns the ";"-separated metadata properties of a baggage
+// item without optional whitespaces, each property prefixed with ";".
+func normalizeBaggageProperties(props string) string {
+	var b strings.Builder
+	for _, prop := range strings.Split(props, ";") {
+		k, v, ok := strings.Cut(prop, "=")
+		k = strings.Trim(k, " \t")
+		if k == "" {
+			continue
+		}
+		b.WriteString(";" + k)
+		if ok {
+			b.WriteString("=" + strings.Trim(v, " \t"))
+		}
+	}
+	return b.String()
+}
+
+// mergeBaggage adds the baggage items of b, along with their properties, to ctx and returns
+// it. When no span context was extracted, b is returned. Span contexts which aren't
+// *spanContext can't hold the baggage, and are returned as is.
+func mergeBaggage(ctx ddtrace.SpanContext, b *spanContext) ddtrace.SpanContext {
+	if ctx == nil {
+		return b
+	}
+	sc, ok := ctx.(*spanContext)
+	if !ok {
+		return ctx
+	}
+	b.ForeachBaggageItem(func(k, v string) bool {
+		sc.setBaggageItem(k, v)
+		return true
+	})
+	sc.inheritBaggageProperties(b)
+	return sc
+}
+
+// encodeBaggage percent-encodes the bytes of s which aren't allowed by the given function.
+func encodeBaggage(s string, allowed func(c byte) bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; allowed(c) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// isTokenChar reports whether c may be used in a baggage key, which is an RFC 7230 token.
+// The percent sign is excluded as it introduces percent-encoded bytes.
+func isTokenChar(c byte) bool {
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$&'*+-.^_`|~", c) >= 0
+}
+
+// isBaggageOctet reports whether c may be used as is in a baggage value. The percent sign is
+// excluded as it introduces percent-encoded bytes.
+func isBaggageOctet(c byte) bool {
+	return c == 0x21 || c >= 0x23 && c <= 0x2B && c != '%' || c >= 0x2D && c <= 0x3A || c >= 0x3C && c <= 0x5B || c >= 0x5D && c <= 0x7E
+}
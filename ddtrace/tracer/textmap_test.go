@@ -1964,3 +1964,99 @@ func FuzzExtractTraceID128(f *testing.F) {
 		extractTraceID128(ctx, v) // make sure it doesn't panic
 	})
 }
+
+func TestBaggagePropagator(t *testing.T) {
+	t.Run("inject", func(t *testing.T) {
+		t.Setenv(headerPropagationStyleInject, "baggage")
+		tracer := newTracer()
+		defer tracer.Stop()
+		root := tracer.StartSpan("web.request").(*span)
+		root.SetBaggageItem("tenant", "acme corp")
+		root.SetBaggageItem("flag", "a,b;c=d%")
+		root.SetBaggageItem("user id", "ünïcode")
+		headers := TextMapCarrier(map[string]string{})
+		require.NoError(t, tracer.Inject(root.Context(), headers))
+		assert.Equal(t, "flag=a%2Cb%3Bc=d%25,tenant=acme%20corp,user%20id=%C3%BCn%C3%AFcode", headers[baggageHeader])
+		assert.Len(t, headers, 1)
+	})
+
+	t.Run("extract", func(t *testing.T) {
+		t.Setenv(headerPropagationStyle, "datadog,baggage")
+		tracer := newTracer()
+		defer tracer.Stop()
+		ctx, err := tracer.Extract(TextMapCarrier{
+			DefaultTraceIDHeader:  "1",
+			DefaultParentIDHeader: "2",
+			baggageHeader:         " tenant = acme%20corp ;ttl = 60; internal , user%20id=%C3%BCn%C3%AFcode,malformed,bad=%zz",
+		})
+		require.NoError(t, err)
+		sctx, ok := ctx.(*spanContext)
+		require.True(t, ok)
+		assert.Equal(t, traceIDFrom64Bits(1), sctx.traceID)
+		assert.Equal(t, "acme corp", sctx.baggageItem("tenant"))
+		assert.Equal(t, ";ttl=60;internal", sctx.baggageItemProperties("tenant"))
+		assert.Equal(t, "ünïcode", sctx.baggageItem("user id"))
+		assert.Empty(t, sctx.baggageItem("malformed"))
+		assert.Empty(t, sctx.baggageItem("bad"))
+
+		// the baggage and its properties are propagated further by child spans
+		child := tracer.StartSpan("child", ChildOf(ctx)).(*span)
+		child.SetBaggageItem("tenant", "other")
+		headers := TextMapCarrier(map[string]string{})
+		require.NoError(t, tracer.Inject(child.Context(), headers))
+		assert.Equal(t, "tenant=other;ttl=60;internal,user%20id=%C3%BCn%C3%AFcode", headers[baggageHeader])
+		assert.Equal(t, "1", headers[DefaultTraceIDHeader])
+	})
+
+	t.Run("extract/baggage-only", func(t *testing.T) {
+		t.Setenv(headerPropagationStyle, "tracecontext,baggage")
+		tracer := newTracer()
+		defer tracer.Stop()
+		ctx, err := tracer.Extract(TextMapCarrier{baggageHeader: "tenant=acme"})
+		require.NoError(t, err)
+		// a new trace is started, inheriting the baggage
+		root := tracer.StartSpan("web.request", ChildOf(ctx)).(*span)
+		assert.NotZero(t, root.TraceID)
+		assert.Zero(t, root.ParentID)
+		assert.Equal(t, root, root.context.trace.root)
+		assert.Equal(t, "acme", root.BaggageItem("tenant"))
+
+		_, err = tracer.Extract(TextMapCarrier{baggageHeader: "malformed"})
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		p := &propagatorBaggage{&PropagatorConfig{BaggageMaxItems: 2, BaggageMaxBytes: 12}}
+		var ctx spanContext
+		p.unmarshalBaggage(&ctx, "a=1,b=2,c=3")
+		assert.Equal(t, "1", ctx.baggageItem("a"))
+		assert.Equal(t, "2", ctx.baggageItem("b"))
+		assert.Empty(t, ctx.baggageItem("c"))
+
+		ctx = spanContext{}
+		p.unmarshalBaggage(&ctx, "a=12345,b=12345")
+		assert.Equal(t, "12345", ctx.baggageItem("a"))
+		assert.Empty(t, ctx.baggageItem("b"))
+
+		ctx = spanContext{}
+		for _, k := range []string{"a", "b", "c"} {
+			ctx.setBaggageItem(k, "1")
+		}
+		assert.Equal(t, "a=1,b=1", p.marshalBaggage(&ctx))
+		p.cfg.BaggageMaxBytes = 5
+		assert.Equal(t, "a=1", p.marshalBaggage(&ctx))
+	})
+
+	t.Run("config", func(t *testing.T) {
+		cfg := &PropagatorConfig{}
+		NewPropagator(cfg)
+		assert.Equal(t, defaultBaggageMaxItems, cfg.BaggageMaxItems)
+		assert.Equal(t, defaultBaggageMaxBytes, cfg.BaggageMaxBytes)
+
+		t.Setenv("DD_TRACE_BAGGAGE_MAX_ITEMS", "10")
+		cfg = &PropagatorConfig{BaggageMaxBytes: 100}
+		NewPropagator(cfg)
+		assert.Equal(t, 10, cfg.BaggageMaxItems)
+		assert.Equal(t, 100, cfg.BaggageMaxBytes)
+	})
+}
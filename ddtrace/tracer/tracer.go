@@ -19,9 +19,13 @@ import (
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/ext"
 	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace/internal"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/appsec"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/datastreams"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/dyninst"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/globalconfig"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/hostname"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/remoteconfig"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/samplernames"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/telemetry"
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/traceprof"
 
@@ -68,6 +72,10 @@ type tracer struct {
 	// prioritySampling holds an instance of the priority sampler.
 	prioritySampling *prioritySampler
 
+	// adaptiveSampling replaces the priority sampler to meet a traces-per-second budget.
+	// adaptiveSampling is nil when adaptive sampling is disabled.
+	adaptiveSampling *adaptiveSampler
+
 	// pid of the process
 	pid int
 
@@ -93,6 +101,33 @@ type tracer struct {
 
 	// statsd is used for tracking metrics associated with the runtime and the tracer.
 	statsd statsdClient
+
+	// rc is the remote configuration client used to update the tracer settings at runtime.
+	// It is shared with AppSec. rc is nil until the tracer is started.
+	rc *remoteconfig.Client
+
+	// rcMu guards rcFiles and rcServiceMappings.
+	rcMu sync.RWMutex
+
+	// rcFiles holds the settings of the APM_TRACING configuration files currently
+	// received through remote configuration, by path.
+	rcFiles map[string]remoteSettings
+
+	// rcServiceMappings holds the service mappings received through remote configuration.
+	// When not nil, they replace the ones configured with WithServiceMapping.
+	rcServiceMappings map[string]string
+
+	// stopDynamicInstrumentation stops the dynamic instrumentation started along with
+	// the remote configuration client. It is nil when dynamic instrumentation is disabled.
+	stopDynamicInstrumentation func()
+
+	// dataStreams processes the Data Streams Monitoring checkpoints and offsets.
+	// dataStreams is nil when Data Streams Monitoring is disabled.
+	dataStreams *datastreams.Processor
+
+	// tailSampling decides on finished traces before they are handed to the trace writer.
+	// tailSampling is nil when tail sampling is disabled.
+	tailSampling *tailSampler
 }
 
 const (
@@ -133,18 +168,21 @@ func Start(opts ...StartOption) {
 		// share control of the global telemetry client.
 		return
 	}
+	// The remote configuration client is shared with AppSec, and only starts polling once
+	// both registered their products. It is started before the tracer becomes the global
+	// tracer, so that it is always stopped along with it.
+	if t.needsRemoteConfig() {
+		if err := t.newRemoteConfig(t.remoteConfigClientConfig()); err != nil {
+			log.Warn("Remote config: disabled due to a client creation error: %v", err)
+		}
+	}
+	// Start AppSec with remote configuration
+	appsec.Start(appsec.WithRCClient(t.rc))
+	t.startRemoteConfig()
 	internal.SetGlobalTracer(t)
 	if t.config.logStartup {
 		logStartup(t)
 	}
-	// Start AppSec with remote configuration
-	cfg := remoteconfig.DefaultClientConfig()
-	cfg.AgentURL = t.config.agentURL.String()
-	cfg.AppVersion = t.config.version
-	cfg.Env = t.config.env
-	cfg.HTTP = t.config.httpClient
-	cfg.ServiceName = t.config.serviceName
-	appsec.Start(appsec.WithRCConfig(cfg))
 	// start instrumentation telemetry unless it is disabled through the
 	// DD_INSTRUMENTATION_TELEMETRY_ENABLED env var
 	startTelemetry(t.config)
@@ -211,9 +249,14 @@ func newUnstartedTracer(opts ...StartOption) *tracer {
 		log.Warn("Runtime and health metrics disabled: %v", err)
 	}
 	var writer traceWriter
-	if c.logToStdout {
+	switch {
+	case c.otlpExport:
+		writer = newOTLPTraceWriter(c, statsd)
+	case c.agentless:
+		writer = newAgentlessTraceWriter(c, statsd)
+	case c.logToStdout:
 		writer = newLogTraceWriter(c, statsd)
-	} else {
+	default:
 		writer = newAgentTraceWriter(c, sampler, statsd)
 	}
 	traces, spans, err := samplingRulesFromEnv()
@@ -247,6 +290,19 @@ func newUnstartedTracer(opts ...StartOption) *tracer {
 		}),
 		statsd: statsd,
 	}
+	if c.adaptiveSamplingTPS > 0 {
+		t.adaptiveSampling = newAdaptiveSampler(c.adaptiveSamplingTPS)
+	}
+	if c.tailSampling != nil {
+		t.tailSampling = newTailSampler(*c.tailSampling, statsd, t.submitTrace)
+	}
+	if c.dataStreamsMonitoringEnabled {
+		if c.agent.DataStreams {
+			t.dataStreams = datastreams.NewProcessor(statsd, c.env, c.serviceName, c.agentURL, c.httpClient)
+		} else {
+			log.Warn("Data Streams Monitoring was enabled but is not supported by the agent: upgrade the agent to use it.")
+		}
+	}
 	return t
 }
 
@@ -254,7 +310,16 @@ func newTracer(opts ...StartOption) *tracer {
 	t := newUnstartedTracer(opts...)
 	c := t.config
 	t.statsd.Incr("datadog.tracer.started", nil, 1)
-	if c.runtimeMetrics {
+	globalconfig.SetStatsd(t.statsd)
+	globalconfig.SetEnvAndVersion(c.env, c.version)
+	if c.runtimeMetricsV2 {
+		log.Debug("Runtime metrics v2 enabled.")
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.reportRuntimeMetricsV2(defaultMetricsReportInterval)
+		}()
+	} else if c.runtimeMetrics {
 		log.Debug("Runtime metrics enabled.")
 		t.wg.Add(1)
 		go func() {
@@ -279,6 +344,9 @@ func newTracer(opts ...StartOption) *tracer {
 		t.reportHealthMetrics(statsInterval)
 	}()
 	t.stats.Start()
+	if t.dataStreams != nil {
+		t.dataStreams.Start()
+	}
 	return t
 }
 
@@ -311,19 +379,24 @@ func (t *tracer) worker(tick <-chan time.Time) {
 	for {
 		select {
 		case trace := <-t.out:
-			t.sampleFinishedTrace(trace)
-			if len(trace.spans) != 0 {
-				t.traceWriter.add(trace.spans)
-			}
+			t.processTrace(trace)
+
 		case <-tick:
 			t.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:scheduled"}, 1)
+			if t.tailSampling != nil {
+				t.tailSampling.expire()
+			}
 			t.traceWriter.flush()
 
 		case done := <-t.flush:
 			t.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:invoked"}, 1)
+			// the traces buffered by tail sampling are kept until they complete.
 			t.traceWriter.flush()
 			t.statsd.Flush()
 			t.stats.flushAndSend(time.Now(), withCurrentBucket)
+			if t.dataStreams != nil {
+				t.dataStreams.Flush()
+			}
 			// TODO(x): In reality, the traceWriter.flush() call is not synchronous
 			// when using the agent traceWriter. However, this functionnality is used
 			// in Lambda so for that purpose this mechanism should suffice.
@@ -336,23 +409,42 @@ func (t *tracer) worker(tick <-chan time.Time) {
 			for {
 				select {
 				case trace := <-t.out:
-					t.sampleFinishedTrace(trace)
-					if len(trace.spans) != 0 {
-						t.traceWriter.add(trace.spans)
-					}
+					t.processTrace(trace)
 				default:
 					break loop
 				}
 			}
+			if t.tailSampling != nil {
+				t.tailSampling.stop()
+			}
 			return
 		}
 	}
 }
 
+// processTrace passes a finished trace through tail sampling, when enabled, on its way to
+// the trace writer.
+func (t *tracer) processTrace(trace *finishedTrace) {
+	if t.tailSampling != nil {
+		t.tailSampling.push(trace)
+		return
+	}
+	t.submitTrace(trace)
+}
+
+// submitTrace applies single span sampling to the finished trace and adds it to the trace writer.
+func (t *tracer) submitTrace(trace *finishedTrace) {
+	t.sampleFinishedTrace(trace)
+	if len(trace.spans) != 0 {
+		t.traceWriter.add(trace.spans)
+	}
+}
+
 // finishedTrace holds information about a trace that has finished, including its spans.
 type finishedTrace struct {
 	spans    []*span
 	willSend bool // willSend indicates whether the trace will be sent to the agent.
+	partial  bool // partial indicates whether more chunks of the trace will follow.
 }
 
 // sampleFinishedTrace applies single-span sampling to the provided trace, which is considered to be finished.
@@ -430,6 +522,12 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 			}
 		}
 	}
+	var baggageOnly *spanContext
+	if context != nil && context.traceID.Empty() && context.span == nil {
+		// the parent only holds baggage, such as when only the W3C baggage
+		// header was extracted: start a new trace which inherits it.
+		baggageOnly, context = context, nil
+	}
 	if pprofContext == nil {
 		// For root span's without context, there is no pprofContext, but we need
 		// one to avoid a panic() in pprof.WithLabels(). Using context.Background()
@@ -454,6 +552,9 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 		Start:        startTime,
 		noDebugStack: t.config.noDebugStack,
 	}
+	if len(opts.SpanLinks) > 0 {
+		span.SpanLinks = append(span.SpanLinks, opts.SpanLinks...)
+	}
 	if t.config.hostname != "" {
 		span.setMeta(keyHostname, t.config.hostname)
 	}
@@ -475,9 +576,19 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 				// mark origin
 				span.setMeta(keyOrigin, context.origin)
 			}
+			if _, ok := context.samplingPriority(); ok {
+				context.trace.setInherited()
+			}
 		}
 	}
 	span.context = newSpanContext(span, context)
+	if baggageOnly != nil {
+		baggageOnly.ForeachBaggageItem(func(k, v string) bool {
+			span.context.setBaggageItem(k, v)
+			return true
+		})
+		span.context.inheritBaggageProperties(baggageOnly)
+	}
 	span.setMetric(ext.Pid, float64(t.pid))
 	span.setMeta("language", "go")
 
@@ -489,10 +600,8 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 	for k, v := range t.config.globalTags {
 		span.SetTag(k, v)
 	}
-	if t.config.serviceMappings != nil {
-		if newSvc, ok := t.config.serviceMappings[span.Service]; ok {
-			span.Service = newSvc
-		}
+	if newSvc, ok := t.mapService(span.Service); ok {
+		span.Service = newSvc
 	}
 	isRootSpan := context == nil || context.span == nil
 	if isRootSpan {
@@ -519,10 +628,11 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 	if t.config.profilerHotspots || t.config.profilerEndpoints {
 		t.applyPPROFLabels(pprofContext, span)
 	}
-	if t.config.serviceMappings != nil {
-		if newSvc, ok := t.config.serviceMappings[span.Service]; ok {
-			span.Service = newSvc
-		}
+	if newSvc, ok := t.mapService(span.Service); ok {
+		span.Service = newSvc
+	}
+	if dyninst.Enabled(dyninst.StartSpan) {
+		dyninst.Run(dyninst.StartSpan, span, nil)
 	}
 	if log.DebugEnabled() {
 		// avoid allocating the ...interface{} argument if debug logging is disabled
@@ -584,16 +694,29 @@ func (t *tracer) Stop() {
 	t.stopOnce.Do(func() {
 		close(t.stop)
 		t.statsd.Incr("datadog.tracer.stopped", nil, 1)
+		t.stopRemoteConfig()
 	})
 	t.stats.Stop()
+	if t.dataStreams != nil {
+		t.dataStreams.Stop()
+	}
 	t.wg.Wait()
 	t.traceWriter.stop()
+	globalconfig.SetStatsd(nil)
+	globalconfig.SetEnvAndVersion("", "")
 	t.statsd.Close()
 	appsec.Stop()
 }
 
 // Inject uses the configured or default TextMap Propagator.
 func (t *tracer) Inject(ctx ddtrace.SpanContext, carrier interface{}) error {
+	if sctx, ok := ctx.(*spanContext); ok && sctx.trace != nil {
+		// the sampling decision can no longer change once propagated.
+		if sctx.trace.root != nil {
+			t.resample(sctx.trace.root)
+		}
+		sctx.trace.setPropagated()
+	}
 	return t.config.propagator.Inject(ctx, carrier)
 }
 
@@ -619,12 +742,64 @@ func (t *tracer) sample(span *span) {
 	if rs, ok := sampler.(RateSampler); ok && rs.Rate() < 1 {
 		span.setMetric(sampleRateMetricKey, rs.Rate())
 	}
+	if t.rulesSampling.traces.lazy() {
+		span.context.trace.setResample()
+	}
 	if t.rulesSampling.SampleTrace(span) {
 		return
 	}
+	if t.adaptiveSampling != nil {
+		t.adaptiveSampling.apply(span)
+		return
+	}
 	t.prioritySampling.apply(span)
 }
 
+// resample applies the trace sampling rules again to the root span of a trace sampled when
+// it started, now that its resource name and tags are known. It has an effect only once per
+// trace, and only if some rules match spans on their resource name or tags.
+func (t *tracer) resample(root *span) {
+	if !root.context.trace.takeResample() {
+		return
+	}
+	root.Lock()
+	delete(root.Metrics, keyRulesSamplerAppliedRate)
+	delete(root.Metrics, keyRulesSamplerLimiterRate)
+	delete(root.Metrics, keySamplingPriorityRate)
+	delete(root.Metrics, keyAdaptiveSamplerRate)
+	root.Unlock()
+	// the decision maker is set again along with the new sampling priority.
+	root.context.trace.unsetPropagatingTag(keyDecisionMaker)
+	if t.rulesSampling.SampleTrace(root) {
+		return
+	}
+	if t.adaptiveSampling != nil {
+		t.adaptiveSampling.reapply(root)
+		return
+	}
+	t.prioritySampling.apply(root)
+}
+
+// keepRoot upgrades the sampling decision of the trace of the given root span to keep when
+// the span finishes at finishTime with an error, or after the latency threshold configured
+// with WithRootSampling. It has no effect once the trace context was propagated.
+func (t *tracer) keepRoot(root *span, finishTime int64) {
+	cfg := t.config.rootSampling
+	if !cfg.KeepErrors && cfg.LatencyThreshold <= 0 {
+		return
+	}
+	root.RLock()
+	hasError := root.Error != 0
+	root.RUnlock()
+	slow := cfg.LatencyThreshold > 0 && time.Duration(finishTime-root.Start) >= cfg.LatencyThreshold
+	if !(cfg.KeepErrors && hasError) && !slow {
+		return
+	}
+	if root.context.trace.keepRoot(ext.PriorityUserKeep, samplernames.RootOutcome) {
+		log.Debug("Kept trace %s as its root span finished with an error or exceeded the latency threshold", root.context.TraceID128())
+	}
+}
+
 func startExecutionTracerTask(ctx gocontext.Context, span *span) (gocontext.Context, func()) {
 	if !rt.IsEnabled() {
 		return ctx, func() {}
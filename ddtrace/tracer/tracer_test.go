@@ -652,6 +652,123 @@ func TestSamplingDecision(t *testing.T) {
 	})
 }
 
+func TestRootSampling(t *testing.T) {
+	// startDropping returns a tracer dropping all the traces, with the decision left to
+	// the agent, as when client-side stats are enabled.
+	startDropping := func(t *testing.T, cfg RootSamplingConfig) (*tracer, func()) {
+		tracer, _, _, stop := startTestTracer(t, WithRootSampling(cfg))
+		tracer.config.featureFlags = map[string]struct{}{"discovery": {}}
+		tracer.config.agent.DropP0s = true
+		tracer.config.agent.Stats = true
+		tracer.prioritySampling.defaultRate = 0
+		return tracer, stop
+	}
+	assertKept := func(t *testing.T, root *span) {
+		assert.Equal(t, float64(ext.PriorityUserKeep), root.Metrics[keySamplingPriority])
+		assert.Equal(t, "-12", root.context.trace.propagatingTags[keyDecisionMaker])
+		assert.Equal(t, decisionKeep, root.context.trace.samplingDecision)
+	}
+	// Note that traces with errors can be sent while dropped, to compute stats.
+	assertDropped := func(t *testing.T, root *span) {
+		assert.LessOrEqual(t, root.Metrics[keySamplingPriority], float64(ext.PriorityAutoReject))
+		assert.Equal(t, "", root.context.trace.propagatingTags[keyDecisionMaker])
+	}
+
+	t.Run("error", func(t *testing.T) {
+		tracer, stop := startDropping(t, RootSamplingConfig{KeepErrors: true})
+		defer stop()
+		root := tracer.StartSpan("web.request").(*span)
+		tracer.StartSpan("db.query", ChildOf(root.context)).Finish()
+		root.Finish(WithError(errors.New("failed")))
+		assertKept(t, root)
+
+		root = tracer.StartSpan("web.request").(*span)
+		root.Finish()
+		assertDropped(t, root)
+		assert.Equal(t, decisionNone, root.context.trace.samplingDecision)
+	})
+
+	t.Run("child-error", func(t *testing.T) {
+		tracer, stop := startDropping(t, RootSamplingConfig{KeepErrors: true})
+		defer stop()
+		root := tracer.StartSpan("web.request").(*span)
+		tracer.StartSpan("db.query", ChildOf(root.context)).Finish(WithError(errors.New("failed")))
+		root.Finish()
+		assertDropped(t, root)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		tracer, stop := startDropping(t, RootSamplingConfig{LatencyThreshold: time.Second})
+		defer stop()
+		start := time.Now()
+		root := tracer.StartSpan("web.request", StartTime(start)).(*span)
+		root.Finish(FinishTime(start.Add(2 * time.Second)))
+		assertKept(t, root)
+
+		root = tracer.StartSpan("web.request", StartTime(start)).(*span)
+		root.Finish(FinishTime(start.Add(time.Millisecond)), WithError(errors.New("failed")))
+		assertDropped(t, root)
+	})
+
+	t.Run("propagated", func(t *testing.T) {
+		tracer, stop := startDropping(t, RootSamplingConfig{KeepErrors: true})
+		defer stop()
+		root := tracer.StartSpan("web.request").(*span)
+		err := tracer.Inject(root.Context(), TextMapCarrier(map[string]string{}))
+		assert.NoError(t, err)
+		root.Finish(WithError(errors.New("failed")))
+		assertDropped(t, root)
+	})
+
+	t.Run("inherited", func(t *testing.T) {
+		tracer, stop := startDropping(t, RootSamplingConfig{KeepErrors: true})
+		defer stop()
+		carrier := TextMapCarrier(map[string]string{
+			DefaultTraceIDHeader:  "1",
+			DefaultParentIDHeader: "2",
+			DefaultPriorityHeader: "0",
+		})
+		sctx, err := tracer.Extract(carrier)
+		assert.NoError(t, err)
+		root := tracer.StartSpan("web.request", ChildOf(sctx)).(*span)
+		root.Finish(WithError(errors.New("failed")))
+		assertDropped(t, root)
+
+		// without an upstream decision, the trace can be kept
+		delete(carrier, DefaultPriorityHeader)
+		sctx, err = tracer.Extract(carrier)
+		assert.NoError(t, err)
+		root = tracer.StartSpan("web.request", ChildOf(sctx)).(*span)
+		root.Finish(WithError(errors.New("failed")))
+		assertKept(t, root)
+	})
+
+	t.Run("manual", func(t *testing.T) {
+		tracer, stop := startDropping(t, RootSamplingConfig{KeepErrors: true})
+		defer stop()
+		root := tracer.StartSpan("web.request").(*span)
+		root.SetTag(ext.ManualDrop, true)
+		root.Finish(WithError(errors.New("failed")))
+		assert.Equal(t, float64(ext.PriorityUserReject), root.Metrics[keySamplingPriority])
+	})
+
+	t.Run("kept", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithRootSampling(RootSamplingConfig{KeepErrors: true}))
+		defer stop()
+		root := tracer.StartSpan("web.request").(*span)
+		root.Finish(WithError(errors.New("failed")))
+		assert.Equal(t, float64(ext.PriorityAutoKeep), root.Metrics[keySamplingPriority])
+		assert.Equal(t, "-1", root.context.trace.propagatingTags[keyDecisionMaker])
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("DD_TRACE_ROOT_SAMPLING_KEEP_ERRORS", "true")
+		t.Setenv("DD_TRACE_ROOT_SAMPLING_LATENCY_THRESHOLD", "500ms")
+		c := newConfig()
+		assert.Equal(t, RootSamplingConfig{KeepErrors: true, LatencyThreshold: 500 * time.Millisecond}, c.rootSampling)
+	})
+}
+
 func TestTracerRuntimeMetrics(t *testing.T) {
 	t.Run("on", func(t *testing.T) {
 		tp := new(log.RecordLogger)
@@ -1520,11 +1637,11 @@ func TestPushPayload(t *testing.T) {
 	s.Meta["key"] = strings.Repeat("X", payloadSizeLimit/2+10)
 
 	// half payload size reached
-	tracer.pushTrace(&finishedTrace{[]*span{s}, true})
+	tracer.pushTrace(&finishedTrace{spans: []*span{s}, willSend: true})
 	tracer.awaitPayload(t, 1)
 
 	// payload size exceeded
-	tracer.pushTrace(&finishedTrace{[]*span{s}, true})
+	tracer.pushTrace(&finishedTrace{spans: []*span{s}, willSend: true})
 	flush(2)
 }
 
@@ -88,8 +88,18 @@ type httpTransport struct {
 // otherwise needing to customize the transport layer, for instance when using
 // a unix domain socket.
 func newHTTPTransport(url string, client *http.Client) *httpTransport {
-	// initialize the default EncoderPool with Encoder headers
-	defaultHeaders := map[string]string{
+	return &httpTransport{
+		traceURL: fmt.Sprintf("%s/v0.4/traces", url),
+		statsURL: fmt.Sprintf("%s/v0.6/stats", url),
+		client:   client,
+		headers:  defaultHeaders(),
+	}
+}
+
+// defaultHeaders returns the headers describing the tracer, which are set on all the
+// payloads it sends.
+func defaultHeaders() map[string]string {
+	headers := map[string]string{
 		"Datadog-Meta-Lang":             "go",
 		"Datadog-Meta-Lang-Version":     strings.TrimPrefix(runtime.Version(), "go"),
 		"Datadog-Meta-Lang-Interpreter": runtime.Compiler + "-" + runtime.GOARCH + "-" + runtime.GOOS,
@@ -97,14 +107,9 @@ func newHTTPTransport(url string, client *http.Client) *httpTransport {
 		"Content-Type":                  "application/msgpack",
 	}
 	if cid := internal.ContainerID(); cid != "" {
-		defaultHeaders["Datadog-Container-ID"] = cid
-	}
-	return &httpTransport{
-		traceURL: fmt.Sprintf("%s/v0.4/traces", url),
-		statsURL: fmt.Sprintf("%s/v0.6/stats", url),
-		client:   client,
-		headers:  defaultHeaders,
+		headers["Datadog-Container-ID"] = cid
 	}
+	return headers
 }
 
 func (t *httpTransport) sendStats(p *statsPayload) error {
@@ -14,6 +14,7 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
@@ -49,16 +50,32 @@ type agentTraceWriter struct {
 
 	// statsd is used to send metrics
 	statsd statsdClient
+
+	// spool stores on disk the payloads which could not be sent to the agent,
+	// when enabled with WithTraceSpool
+	spool *spool
+
+	// replaying is 1 while the spooled payloads are being replayed
+	replaying uint32
 }
 
 func newAgentTraceWriter(c *config, s *prioritySampler, statsdClient statsdClient) *agentTraceWriter {
-	return &agentTraceWriter{
+	w := &agentTraceWriter{
 		config:           c,
 		payload:          newPayload(),
 		climit:           make(chan struct{}, concurrentConnectionLimit),
 		prioritySampling: s,
 		statsd:           statsdClient,
 	}
+	if c.spoolDir != "" {
+		sp, err := newSpool(c.spoolDir, c.spoolMaxSize, c.spoolMaxAge, statsdClient)
+		if err != nil {
+			log.Warn("Unable to set up the trace spool in %s, traces failing to be sent will be dropped: %v", c.spoolDir, err)
+		} else {
+			w.spool = sp
+		}
+	}
+	return w
 }
 
 func (h *agentTraceWriter) add(trace []*span) {
@@ -81,6 +98,9 @@ func (h *agentTraceWriter) stop() {
 // flush will push any currently buffered traces to the server.
 func (h *agentTraceWriter) flush() {
 	if h.payload.itemCount() == 0 {
+		// give the spooled payloads a chance to be sent, in case
+		// the agent became reachable again
+		h.replaySpool()
 		return
 	}
 	h.wg.Add(1)
@@ -113,17 +133,54 @@ func (h *agentTraceWriter) flush() {
 				if err := h.prioritySampling.readRatesJSON(rc); err != nil {
 					h.statsd.Incr("datadog.tracer.decode_error", nil, 1)
 				}
+				h.replaySpool()
 				return
 			}
 			log.Error("failure sending traces (attempt %d), will retry: %v", attempt+1, err)
 			p.reset()
 			time.Sleep(time.Millisecond)
 		}
+		if h.spool != nil {
+			err := h.spool.store(p)
+			if err == nil {
+				log.Debug("spooled %d traces, they will be sent once the agent is reachable", count)
+				return
+			}
+			log.Error("unable to spool %d traces: %v", count, err)
+		}
 		h.statsd.Count("datadog.tracer.traces_dropped", int64(count), []string{"reason:send_failed"}, 1)
 		log.Error("lost %d traces: %v", count, err)
 	}(oldp)
 }
 
+// replaySpool sends the spooled payloads in the background, oldest first, unless
+// they are already being replayed. Newer payloads are flushed concurrently, so they
+// may reach the agent before the spooled ones.
+func (h *agentTraceWriter) replaySpool() {
+	if h.spool == nil || h.spool.len() == 0 || !atomic.CompareAndSwapUint32(&h.replaying, 0, 1) {
+		return
+	}
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer atomic.StoreUint32(&h.replaying, 0)
+		h.spool.replay(func(p *payload) error {
+			size, count := p.size(), p.itemCount()
+			rc, err := h.config.transport.send(p)
+			if err != nil {
+				return err
+			}
+			log.Debug("replayed %d spooled traces", count)
+			h.statsd.Count("datadog.tracer.flush_bytes", int64(size), nil, 1)
+			h.statsd.Count("datadog.tracer.flush_traces", int64(count), nil, 1)
+			if err := h.prioritySampling.readRatesJSON(rc); err != nil {
+				h.statsd.Incr("datadog.tracer.decode_error", nil, 1)
+			}
+			return nil
+		})
+	}()
+}
+
 // logWriter specifies the output target of the logTraceWriter; replaced in tests.
 var logWriter io.Writer = os.Stdout
 
@@ -150,7 +150,7 @@ require (
 	github.com/labstack/gommon v0.3.1 // indirect
 	github.com/leodido/go-urn v1.2.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
-	github.com/mattn/go-colorable v0.1.11 // indirect
+	github.com/mattn/go-colorable v0.1.12 // indirect
 	github.com/mattn/go-isatty v0.0.14 // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
 	github.com/mitchellh/mapstructure v1.4.2 // indirect
@@ -210,12 +210,17 @@ require (
 require (
 	github.com/DataDog/appsec-internal-go v1.0.0
 	github.com/DataDog/go-libddwaf v1.1.0
+	github.com/aws/aws-sdk-go-v2/service/eventbridge v1.0.0
+	github.com/aws/aws-sdk-go-v2/service/kinesis v1.0.0
+	github.com/aws/aws-sdk-go-v2/service/sns v1.0.0
 	github.com/jackc/pgx/v5 v5.3.1
 	github.com/microsoft/go-mssqldb v0.21.0
+	github.com/rs/zerolog v1.30.0
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.40.0
 	go.opentelemetry.io/otel v1.14.0
 	go.opentelemetry.io/otel/trace v1.14.0
 	go.uber.org/atomic v1.10.0
+	go.uber.org/zap v1.21.0
 )
 
 require (
@@ -227,6 +232,7 @@ require (
 	github.com/outcaste-io/ristretto v0.2.1 // indirect
 	github.com/stretchr/objx v0.5.0 // indirect
 	go.opentelemetry.io/otel/metric v0.37.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
 	k8s.io/klog/v2 v2.30.0 // indirect
 	k8s.io/kube-openapi v0.0.0-20211115234752-e816edb12b65 // indirect
 	sigs.k8s.io/json v0.0.0-20211020170558-c049b76a60c6 // indirect
@@ -26,6 +26,13 @@ func Enabled() bool {
 	return activeAppSec != nil && activeAppSec.started
 }
 
+// UsesRemoteConfig reports whether AppSec needs remote configuration once started, which is the
+// case unless it is disabled by the configuration.
+func UsesRemoteConfig() bool {
+	enabled, set, err := isEnabled()
+	return err == nil && (enabled || !set)
+}
+
 // Start AppSec when enabled is enabled by both using the appsec build tag and
 // setting the environment variable DD_APPSEC_ENABLED to true.
 func Start(opts ...StartOption) {
@@ -97,11 +104,19 @@ type appsec struct {
 	cfg       *Config
 	limiter   *TokenTicker
 	rc        *remoteconfig.Client
+	sharedRC  bool // whether rc is owned by the tracer, which starts and stops it
 	wafHandle *waf.Handle
 	started   bool
 }
 
 func newAppSec(cfg *Config) *appsec {
+	if cfg.rcClient != nil {
+		return &appsec{
+			cfg:      cfg,
+			rc:       cfg.rcClient,
+			sharedRC: true,
+		}
+	}
 	var client *remoteconfig.Client
 	var err error
 	if cfg.rc != nil {
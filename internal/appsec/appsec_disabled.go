@@ -16,6 +16,12 @@ func Enabled() bool {
 	return false
 }
 
+// UsesRemoteConfig reports whether AppSec needs remote configuration once started, which is never
+// the case without the appsec build tag.
+func UsesRemoteConfig() bool {
+	return false
+}
+
 // Start AppSec when enabled by both using the appsec build tag and
 // setting the environment variable DD_APPSEC_ENABLED to true.
 func Start(...StartOption) {
@@ -50,6 +50,9 @@ type Config struct {
 	obfuscator ObfuscatorConfig
 	// rc is the remote configuration client used to receive product configuration updates. Nil if rc is disabled (default)
 	rc *remoteconfig.ClientConfig
+	// rcClient is the remote configuration client shared with the tracer, which owns it. When set, AppSec
+	// registers its products on it instead of creating its own client from rc.
+	rcClient *remoteconfig.Client
 }
 
 // WithRCConfig sets the AppSec remote config client configuration to the specified cfg
@@ -59,6 +62,19 @@ func WithRCConfig(cfg remoteconfig.ClientConfig) StartOption {
 	}
 }
 
+// WithRCClient makes AppSec register its products on the given remote config client, shared with the
+// tracer, instead of creating its own. The client is started and stopped by its owner. A nil client
+// disables remote config.
+func WithRCClient(client *remoteconfig.Client) StartOption {
+	return func(c *Config) {
+		if client == nil {
+			return
+		}
+		c.rcClient = client
+		c.rc = &client.ClientConfig
+	}
+}
+
 // ObfuscatorConfig wraps the key and value regexp to be passed to the WAF to perform obfuscation.
 type ObfuscatorConfig struct {
 	KeyRegex   string
@@ -288,16 +288,29 @@ func mergeRulesDataEntries(entries1, entries2 []rc.ASMDataRuleDataEntry) []rc.AS
 	return entries
 }
 
+// startRC starts the remote config client, unless it is shared with the tracer, which starts it.
 func (a *appsec) startRC() {
-	if a.rc != nil {
+	if a.rc != nil && !a.sharedRC {
 		a.rc.Start()
 	}
 }
 
+// stopRC stops the remote config client. A client shared with the tracer is left running, and
+// only the AppSec products and callbacks are removed from it.
 func (a *appsec) stopRC() {
-	if a.rc != nil {
+	if a.rc == nil {
+		return
+	}
+	if !a.sharedRC {
 		a.rc.Stop()
+		return
+	}
+	a.disableRCBlocking()
+	a.unregisterRCCapability(remoteconfig.ASMActivation)
+	for _, p := range []string{rc.ProductASMFeatures, rc.ProductASM, rc.ProductASMDD, rc.ProductASMData} {
+		a.unregisterRCProduct(p)
 	}
+	a.rc.UnregisterCallback(a.onRemoteActivation)
 }
 
 func (a *appsec) registerRCProduct(p string) error {
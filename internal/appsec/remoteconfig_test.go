@@ -360,6 +360,29 @@ func TestRemoteActivationScenarios(t *testing.T) {
 	})
 }
 
+func TestSharedRCClient(t *testing.T) {
+	if waf.Health() != nil {
+		t.Skip("WAF cannot be used")
+	}
+	t.Setenv(enabledEnvVar, "")
+	os.Unsetenv(enabledEnvVar)
+	client, err := remoteconfig.NewClient(remoteconfig.DefaultClientConfig())
+	require.NoError(t, err)
+	client.RegisterProduct(rc.ProductAPMTracing)
+
+	Start(WithRCClient(client))
+	require.NotNil(t, activeAppSec)
+	require.Equal(t, client, activeAppSec.rc)
+	require.Contains(t, client.Products, rc.ProductASMFeatures)
+	require.Contains(t, client.Capabilities, remoteconfig.ASMActivation)
+
+	// stopping AppSec only removes its own products from the shared client
+	Stop()
+	require.NotContains(t, client.Products, rc.ProductASMFeatures)
+	require.NotContains(t, client.Capabilities, remoteconfig.ASMActivation)
+	require.Contains(t, client.Products, rc.ProductAPMTracing)
+}
+
 func TestCapabilities(t *testing.T) {
 	for _, tc := range []struct {
 		name     string
@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package datastreams
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathway(t *testing.T) {
+	t.Run("test SetCheckpoint", func(t *testing.T) {
+		start := time.Now()
+		processor := Processor{
+			stopped:              1,
+			in:                   make(chan processorInput, 10),
+			service:              "service-1",
+			env:                  "env",
+			tsTypeCurrentBuckets: make(map[int64]bucket),
+			tsTypeOriginBuckets:  make(map[int64]bucket),
+			time:                 func() time.Time { return start },
+		}
+		_, ctx := processor.SetCheckpoint(context.Background())
+		middle := start.Add(time.Hour)
+		processor.time = func() time.Time { return middle }
+		_, ctx = processor.SetCheckpoint(ctx, "topic:topic1")
+		end := middle.Add(time.Hour)
+		processor.time = func() time.Time { return end }
+		p, _ := processor.SetCheckpoint(ctx, "topic:topic2")
+
+		hash1 := pathwayHash(nodeHash("service-1", "env", nil), 0)
+		hash2 := pathwayHash(nodeHash("service-1", "env", []string{"topic:topic1"}), hash1)
+		hash3 := pathwayHash(nodeHash("service-1", "env", []string{"topic:topic2"}), hash2)
+		assert.Equal(t, Pathway{
+			hash:         hash3,
+			pathwayStart: start,
+			edgeStart:    end,
+		}, p)
+		assert.Equal(t, processorInput{typ: pointTypeStats, point: statsPoint{
+			hash:           hash1,
+			timestamp:      start.UnixNano(),
+			pathwayLatency: 0,
+			edgeLatency:    0,
+		}}, <-processor.in)
+		assert.Equal(t, processorInput{typ: pointTypeStats, point: statsPoint{
+			edgeTags:       []string{"topic:topic1"},
+			hash:           hash2,
+			parentHash:     hash1,
+			timestamp:      middle.UnixNano(),
+			pathwayLatency: middle.Sub(start).Nanoseconds(),
+			edgeLatency:    middle.Sub(start).Nanoseconds(),
+		}}, <-processor.in)
+		assert.Equal(t, processorInput{typ: pointTypeStats, point: statsPoint{
+			edgeTags:       []string{"topic:topic2"},
+			hash:           hash3,
+			parentHash:     hash2,
+			timestamp:      end.UnixNano(),
+			pathwayLatency: end.Sub(start).Nanoseconds(),
+			edgeLatency:    end.Sub(middle).Nanoseconds(),
+		}}, <-processor.in)
+	})
+
+	t.Run("test new pathway creation", func(t *testing.T) {
+		processor := NewProcessor(nil, "env", "service-1", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+		pathwayWithNoEdgeTags, _ := processor.SetCheckpoint(context.Background())
+		pathwayWith1EdgeTag, _ := processor.SetCheckpoint(context.Background(), "type:internal")
+		pathwayWith2EdgeTags, _ := processor.SetCheckpoint(context.Background(), "type:internal", "some_other_key:some_other_val")
+
+		hash1 := pathwayHash(nodeHash("service-1", "env", nil), 0)
+		hash2 := pathwayHash(nodeHash("service-1", "env", []string{"type:internal"}), 0)
+		hash3 := pathwayHash(nodeHash("service-1", "env", []string{"type:internal", "some_other_key:some_other_val"}), 0)
+		assert.Equal(t, hash1, pathwayWithNoEdgeTags.hash)
+		assert.Equal(t, hash2, pathwayWith1EdgeTag.hash)
+		assert.Equal(t, hash3, pathwayWith2EdgeTags.hash)
+		assert.NotEqual(t, hash1, hash2)
+		// edge tags which are not hashable don't change the hash
+		assert.Equal(t, hash2, hash3)
+	})
+
+	t.Run("test nodeHash", func(t *testing.T) {
+		assert.NotEqual(t,
+			nodeHash("service-1", "env", []string{"type:internal"}),
+			nodeHash("service-1", "env", []string{"type:kafka"}),
+		)
+		assert.NotEqual(t,
+			nodeHash("service-1", "env", []string{"exchange:1"}),
+			nodeHash("service-1", "env", []string{"exchange:2"}),
+		)
+		// the order of the edge tags doesn't matter
+		assert.Equal(t,
+			nodeHash("service-1", "env", []string{"topic:a", "type:kafka"}),
+			nodeHash("service-1", "env", []string{"type:kafka", "topic:a"}),
+		)
+		assert.Equal(t,
+			nodeHash("service-1", "env", []string{"partition:0"}),
+			nodeHash("service-1", "env", []string{"partition:1"}),
+		)
+		// the edge tags of the caller are not reordered
+		edgeTags := []string{"type:kafka", "topic:a"}
+		nodeHash("service-1", "env", edgeTags)
+		assert.Equal(t, []string{"type:kafka", "topic:a"}, edgeTags)
+	})
+
+	t.Run("test isWellFormedEdgeTag", func(t *testing.T) {
+		for _, tc := range []string{"", "dog", "dog:", "dog:bark", "type", "type:kafka:topic"} {
+			assert.False(t, isWellFormedEdgeTag(tc), tc)
+		}
+		for _, tc := range []string{"type:kafka", "topic:orders", "group:consumers", "direction:in", "event_type:created", "exchange:direct"} {
+			assert.True(t, isWellFormedEdgeTag(tc), tc)
+		}
+	})
+
+	t.Run("test merge", func(t *testing.T) {
+		assert.Equal(t, Pathway{}, Merge(nil))
+		p := Pathway{hash: 1, pathwayStart: time.Now()}
+		assert.Equal(t, p, Merge([]Pathway{p}))
+	})
+}
+
+func TestEncode(t *testing.T) {
+	p := Pathway{
+		hash:         234,
+		pathwayStart: time.Now().Local().Truncate(time.Millisecond),
+		edgeStart:    time.Now().Local().Truncate(time.Millisecond),
+	}
+	encoded := p.Encode()
+	decoded, ctx, err := Decode(context.Background(), encoded)
+	require.NoError(t, err)
+	assert.Equal(t, p, decoded)
+	fromCtx, ok := PathwayFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, p, fromCtx)
+}
+
+func TestEncodeBase64(t *testing.T) {
+	p := Pathway{
+		hash:         234,
+		pathwayStart: time.Now().Local().Truncate(time.Millisecond),
+		edgeStart:    time.Now().Local().Truncate(time.Millisecond),
+	}
+	decoded, _, err := DecodeBase64(context.Background(), p.EncodeBase64())
+	require.NoError(t, err)
+	assert.Equal(t, p, decoded)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"short-hash":     {1, 2, 3},
+		"no-start":       {1, 2, 3, 4, 5, 6, 7, 8},
+		"no-edge-start":  {1, 2, 3, 4, 5, 6, 7, 8, 2},
+		"truncated-edge": {1, 2, 3, 4, 5, 6, 7, 8, 2, 0x80},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			_, outCtx, err := Decode(ctx, data)
+			assert.Error(t, err)
+			assert.Equal(t, ctx, outCtx)
+			_, ok := PathwayFromContext(outCtx)
+			assert.False(t, ok)
+		})
+	}
+	_, _, err := DecodeBase64(context.Background(), "not base64!")
+	assert.Error(t, err)
+}
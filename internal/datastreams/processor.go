@@ -0,0 +1,496 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package datastreams implements Data Streams Monitoring: it computes end-to-end latencies
+// of payloads flowing through pipelines of services, and the offsets of the queues between them.
+package datastreams
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/version"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// bucketDuration specifies the span of time covered by a stats bucket.
+	bucketDuration = time.Second * 10
+	// defaultServiceName is used when no service is configured.
+	defaultServiceName = "unnamed-go-service"
+	// inputChanSize is the size of the channel receiving checkpoints and offsets.
+	inputChanSize = 10000
+)
+
+// statsdClient is the subset of the statsd client used to report the processor health metrics.
+type statsdClient interface {
+	Gauge(name string, value float64, tags []string, rate float64) error
+	Count(name string, value int64, tags []string, rate float64) error
+}
+
+type statsPoint struct {
+	edgeTags       []string
+	hash           uint64
+	parentHash     uint64
+	timestamp      int64
+	pathwayLatency int64
+	edgeLatency    int64
+}
+
+type statsGroup struct {
+	edgeTags       []string
+	hash           uint64
+	parentHash     uint64
+	pathwayLatency *ddsketch.DDSketch
+	edgeLatency    *ddsketch.DDSketch
+}
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+type partitionConsumerKey struct {
+	partitionKey
+	group string
+}
+
+type bucket struct {
+	points               map[uint64]statsGroup
+	latestCommitOffsets  map[partitionConsumerKey]int64
+	latestProduceOffsets map[partitionKey]int64
+	start                uint64
+	duration             uint64
+}
+
+func newBucket(start, duration uint64) bucket {
+	return bucket{
+		points:               make(map[uint64]statsGroup),
+		latestCommitOffsets:  make(map[partitionConsumerKey]int64),
+		latestProduceOffsets: make(map[partitionKey]int64),
+		start:                start,
+		duration:             duration,
+	}
+}
+
+// export transforms the bucket into a StatsBucket, ready to be sent to the agent.
+func (b bucket) export(timestampType TimestampType) StatsBucket {
+	stats := make([]StatsPoint, 0, len(b.points))
+	for _, s := range b.points {
+		pathwayLatency, err := proto.Marshal(s.pathwayLatency.ToProto())
+		if err != nil {
+			log.Error("Failed to serialize pathway latency sketch: %v", err)
+			continue
+		}
+		edgeLatency, err := proto.Marshal(s.edgeLatency.ToProto())
+		if err != nil {
+			log.Error("Failed to serialize edge latency sketch: %v", err)
+			continue
+		}
+		stats = append(stats, StatsPoint{
+			PathwayLatency: pathwayLatency,
+			EdgeLatency:    edgeLatency,
+			EdgeTags:       s.edgeTags,
+			Hash:           s.hash,
+			ParentHash:     s.parentHash,
+			TimestampType:  timestampType,
+		})
+	}
+	exported := StatsBucket{
+		Start:    b.start,
+		Duration: b.duration,
+		Stats:    stats,
+		Backlogs: make([]Backlog, 0, len(b.latestCommitOffsets)+len(b.latestProduceOffsets)),
+	}
+	for key, offset := range b.latestProduceOffsets {
+		exported.Backlogs = append(exported.Backlogs, Backlog{
+			Tags:  []string{fmt.Sprintf("partition:%d", key.partition), "topic:" + key.topic, "type:kafka_produce"},
+			Value: offset,
+		})
+	}
+	for key, offset := range b.latestCommitOffsets {
+		exported.Backlogs = append(exported.Backlogs, Backlog{
+			Tags:  []string{"consumer_group:" + key.group, fmt.Sprintf("partition:%d", key.partition), "topic:" + key.topic, "type:kafka_commit"},
+			Value: offset,
+		})
+	}
+	return exported
+}
+
+type processorStats struct {
+	payloadsIn      int64
+	flushedPayloads int64
+	flushedBuckets  int64
+	flushErrors     int64
+	dropped         int64
+}
+
+type offsetType int
+
+const (
+	produceOffset offsetType = iota
+	commitOffset
+)
+
+type kafkaOffset struct {
+	offset     int64
+	topic      string
+	group      string
+	partition  int32
+	offsetType offsetType
+	timestamp  int64
+}
+
+type pointType int
+
+const (
+	pointTypeStats pointType = iota
+	pointTypeKafkaOffset
+)
+
+// processorInput is either a checkpoint or a kafka offset, as received on the processor input channel.
+type processorInput struct {
+	point       statsPoint
+	kafkaOffset kafkaOffset
+	typ         pointType
+}
+
+// Processor aggregates the data streams checkpoints and offsets into time buckets, and
+// periodically flushes them to the agent.
+type Processor struct {
+	in                   chan processorInput
+	tsTypeCurrentBuckets map[int64]bucket
+	tsTypeOriginBuckets  map[int64]bucket
+	wg                   sync.WaitGroup
+	stopped              uint64
+	stop                 chan struct{} // closing this channel triggers shutdown
+	flushRequest         chan chan<- struct{}
+	stats                processorStats
+	transport            *httpTransport
+	statsd               statsdClient
+	env                  string
+	service              string
+	// time returns the current time. It can be replaced in tests.
+	time func() time.Time
+}
+
+// NewProcessor returns a new processor sending the aggregated data to the agent located at agentURL.
+// The returned processor needs to be started using Start in order to process its input.
+func NewProcessor(statsd statsdClient, env, service string, agentURL *url.URL, httpClient *http.Client) *Processor {
+	if service == "" {
+		service = defaultServiceName
+	}
+	return &Processor{
+		tsTypeCurrentBuckets: make(map[int64]bucket),
+		tsTypeOriginBuckets:  make(map[int64]bucket),
+		in:                   make(chan processorInput, inputChanSize),
+		stopped:              1,
+		statsd:               statsd,
+		env:                  env,
+		service:              service,
+		transport:            newHTTPTransport(agentURL, httpClient),
+		time:                 time.Now,
+	}
+}
+
+// alignTs returns the provided timestamp truncated to the bucket size.
+// It gives us the start time of the time bucket in which such timestamp falls.
+func alignTs(ts, bucketSize int64) int64 { return ts - ts%bucketSize }
+
+// newSketch returns a sketch with a 1% relative accuracy.
+func newSketch() *ddsketch.DDSketch {
+	sketch, err := ddsketch.LogCollapsingLowestDenseDDSketch(0.01, 2048)
+	if err != nil {
+		// this only fails on invalid parameters
+		panic(err)
+	}
+	return sketch
+}
+
+func (p *Processor) getBucket(btime int64, buckets map[int64]bucket) bucket {
+	b, ok := buckets[btime]
+	if !ok {
+		b = newBucket(uint64(btime), uint64(bucketDuration.Nanoseconds()))
+		buckets[btime] = b
+	}
+	return b
+}
+
+func (p *Processor) addToBuckets(point statsPoint, btime int64, buckets map[int64]bucket) {
+	b := p.getBucket(btime, buckets)
+	group, ok := b.points[point.hash]
+	if !ok {
+		group = statsGroup{
+			edgeTags:       point.edgeTags,
+			parentHash:     point.parentHash,
+			hash:           point.hash,
+			pathwayLatency: newSketch(),
+			edgeLatency:    newSketch(),
+		}
+		b.points[point.hash] = group
+	}
+	if err := group.pathwayLatency.Add(math.Max(float64(point.pathwayLatency)/float64(time.Second), 0)); err != nil {
+		log.Error("Failed to add pathway latency to the data streams sketch: %v", err)
+	}
+	if err := group.edgeLatency.Add(math.Max(float64(point.edgeLatency)/float64(time.Second), 0)); err != nil {
+		log.Error("Failed to add edge latency to the data streams sketch: %v", err)
+	}
+}
+
+// add aggregates the point into both the bucket of its timestamp, and the bucket of the
+// start of its pathway.
+func (p *Processor) add(point statsPoint) {
+	currentBucketTime := alignTs(point.timestamp, bucketDuration.Nanoseconds())
+	p.addToBuckets(point, currentBucketTime, p.tsTypeCurrentBuckets)
+	originTimestamp := point.timestamp - point.pathwayLatency
+	originBucketTime := alignTs(originTimestamp, bucketDuration.Nanoseconds())
+	p.addToBuckets(point, originBucketTime, p.tsTypeOriginBuckets)
+}
+
+func (p *Processor) addKafkaOffset(o kafkaOffset) {
+	btime := alignTs(o.timestamp, bucketDuration.Nanoseconds())
+	b := p.getBucket(btime, p.tsTypeCurrentBuckets)
+	if o.offsetType == produceOffset {
+		b.latestProduceOffsets[partitionKey{partition: o.partition, topic: o.topic}] = o.offset
+		return
+	}
+	b.latestCommitOffsets[partitionConsumerKey{
+		partitionKey: partitionKey{partition: o.partition, topic: o.topic},
+		group:        o.group,
+	}] = o.offset
+}
+
+func (p *Processor) processInput(in processorInput) {
+	atomic.AddInt64(&p.stats.payloadsIn, 1)
+	switch in.typ {
+	case pointTypeStats:
+		p.add(in.point)
+	case pointTypeKafkaOffset:
+		p.addKafkaOffset(in.kafkaOffset)
+	}
+}
+
+func (p *Processor) run(tick <-chan time.Time) {
+	for {
+		select {
+		case s := <-p.in:
+			p.processInput(s)
+		case now := <-tick:
+			p.sendToAgent(p.flush(now))
+		case done := <-p.flushRequest:
+			p.flushInput()
+			p.sendToAgent(p.flush(p.time().Add(bucketDuration)))
+			close(done)
+		case <-p.stop:
+			// flush everything, including the current buckets
+			p.flushInput()
+			p.sendToAgent(p.flush(p.time().Add(bucketDuration * 10)))
+			return
+		}
+	}
+}
+
+// flushInput processes the points currently waiting on the input channel.
+func (p *Processor) flushInput() {
+	for {
+		select {
+		case s := <-p.in:
+			p.processInput(s)
+		default:
+			return
+		}
+	}
+}
+
+// Start starts the processor. A started processor needs to be stopped using Stop.
+func (p *Processor) Start() {
+	if atomic.SwapUint64(&p.stopped, 0) == 0 {
+		// already running
+		log.Warn("(*Processor).Start called more than once. This is likely a programming error.")
+		return
+	}
+	p.stop = make(chan struct{})
+	p.flushRequest = make(chan chan<- struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.reportStats()
+	}()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		tick := time.NewTicker(bucketDuration)
+		defer tick.Stop()
+		p.run(tick.C)
+	}()
+}
+
+// Flush triggers a flush of all the data currently held by the processor, and waits for
+// it to be sent to the agent. It is a no-op if the processor is not running.
+func (p *Processor) Flush() {
+	if atomic.LoadUint64(&p.stopped) > 0 {
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case p.flushRequest <- done:
+		<-done
+	case <-p.stop:
+	}
+}
+
+// Stop stops the processor, flushing the remaining data to the agent, and blocks until
+// the operation completes.
+func (p *Processor) Stop() {
+	if atomic.SwapUint64(&p.stopped, 1) > 0 {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+}
+
+func (p *Processor) reportStats() {
+	if p.statsd == nil {
+		return
+	}
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-tick.C:
+		}
+		p.statsd.Count("datadog.datastreams.processor.payloads_in", atomic.SwapInt64(&p.stats.payloadsIn, 0), nil, 1)
+		p.statsd.Count("datadog.datastreams.processor.flushed_payloads", atomic.SwapInt64(&p.stats.flushedPayloads, 0), nil, 1)
+		p.statsd.Count("datadog.datastreams.processor.flushed_buckets", atomic.SwapInt64(&p.stats.flushedBuckets, 0), nil, 1)
+		p.statsd.Count("datadog.datastreams.processor.flush_errors", atomic.SwapInt64(&p.stats.flushErrors, 0), nil, 1)
+		p.statsd.Count("datadog.datastreams.processor.dropped_payloads", atomic.SwapInt64(&p.stats.dropped, 0), nil, 1)
+	}
+}
+
+// flushBucket removes the bucket starting at bucketStart from the given buckets, and exports it.
+func (p *Processor) flushBucket(buckets map[int64]bucket, bucketStart int64, timestampType TimestampType) StatsBucket {
+	b := buckets[bucketStart]
+	delete(buckets, bucketStart)
+	return b.export(timestampType)
+}
+
+// flush exports all the buckets which ended before now.
+func (p *Processor) flush(now time.Time) StatsPayload {
+	nowNano := now.UnixNano()
+	sp := StatsPayload{
+		Service:       p.service,
+		Env:           p.env,
+		Lang:          "go",
+		TracerVersion: version.Tag,
+		Stats:         make([]StatsBucket, 0, len(p.tsTypeCurrentBuckets)+len(p.tsTypeOriginBuckets)),
+	}
+	for ts := range p.tsTypeCurrentBuckets {
+		if ts > nowNano-bucketDuration.Nanoseconds() {
+			// do not flush the bucket at the current time
+			continue
+		}
+		sp.Stats = append(sp.Stats, p.flushBucket(p.tsTypeCurrentBuckets, ts, TimestampTypeCurrent))
+	}
+	for ts := range p.tsTypeOriginBuckets {
+		if ts > nowNano-bucketDuration.Nanoseconds() {
+			// do not flush the bucket at the current time
+			continue
+		}
+		sp.Stats = append(sp.Stats, p.flushBucket(p.tsTypeOriginBuckets, ts, TimestampTypeOrigin))
+	}
+	return sp
+}
+
+func (p *Processor) sendToAgent(payload StatsPayload) {
+	if len(payload.Stats) == 0 {
+		// nothing to flush
+		return
+	}
+	atomic.AddInt64(&p.stats.flushedPayloads, 1)
+	atomic.AddInt64(&p.stats.flushedBuckets, int64(len(payload.Stats)))
+	if err := p.transport.sendPipelineStats(&payload); err != nil {
+		atomic.AddInt64(&p.stats.flushErrors, 1)
+		log.Error("Error sending data streams payload: %v", err)
+	}
+}
+
+// SetCheckpoint sets a checkpoint on the pathway found in ctx, or starts a new pathway if there
+// is none. The edge tags describe the edge going into the current node, for example
+// "direction:in", "topic:orders", "type:kafka". It returns the resulting pathway and a
+// context holding it.
+func (p *Processor) SetCheckpoint(ctx context.Context, edgeTags ...string) (Pathway, context.Context) {
+	parent, hasParent := PathwayFromContext(ctx)
+	parentHash := uint64(0)
+	now := p.time()
+	pathwayStart := now
+	edgeStart := now
+	if hasParent {
+		pathwayStart = parent.PathwayStart()
+		edgeStart = parent.EdgeStart()
+		parentHash = parent.GetHash()
+	}
+	child := Pathway{
+		hash:         pathwayHash(nodeHash(p.service, p.env, edgeTags), parentHash),
+		pathwayStart: pathwayStart,
+		edgeStart:    now,
+	}
+	select {
+	case p.in <- processorInput{
+		point: statsPoint{
+			edgeTags:       edgeTags,
+			parentHash:     parentHash,
+			hash:           child.hash,
+			timestamp:      now.UnixNano(),
+			pathwayLatency: now.Sub(pathwayStart).Nanoseconds(),
+			edgeLatency:    now.Sub(edgeStart).Nanoseconds(),
+		},
+		typ: pointTypeStats,
+	}:
+	default:
+		atomic.AddInt64(&p.stats.dropped, 1)
+	}
+	return child, ContextWithPathway(ctx, child)
+}
+
+// TrackKafkaCommitOffset records the latest offset committed by the consumer group on the given topic partition.
+func (p *Processor) TrackKafkaCommitOffset(group string, topic string, partition int32, offset int64) {
+	p.trackKafkaOffset(kafkaOffset{
+		offset:     offset,
+		group:      group,
+		topic:      topic,
+		partition:  partition,
+		offsetType: commitOffset,
+	})
+}
+
+// TrackKafkaProduceOffset records the latest offset produced to the given topic partition.
+func (p *Processor) TrackKafkaProduceOffset(topic string, partition int32, offset int64) {
+	p.trackKafkaOffset(kafkaOffset{
+		offset:     offset,
+		topic:      topic,
+		partition:  partition,
+		offsetType: produceOffset,
+	})
+}
+
+func (p *Processor) trackKafkaOffset(o kafkaOffset) {
+	o.timestamp = p.time().UnixNano()
+	select {
+	case p.in <- processorInput{typ: pointTypeKafkaOffset, kafkaOffset: o}:
+	default:
+		atomic.AddInt64(&p.stats.dropped, 1)
+	}
+}
@@ -0,0 +1,290 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package datastreams
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/version"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeAgent is a local stand-in for the agent's pipeline stats endpoint.
+type fakeAgent struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	payloads []StatsPayload
+	headers  []http.Header
+}
+
+func newFakeAgent(t *testing.T) *fakeAgent {
+	a := &fakeAgent{}
+	a.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0.1/pipeline_stats" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		gz, err := gzip.NewReader(r.Body)
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var p StatsPayload
+		if !assert.NoError(t, msgp.Decode(gz, &p)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		a.mu.Lock()
+		a.payloads = append(a.payloads, p)
+		a.headers = append(a.headers, r.Header)
+		a.mu.Unlock()
+	}))
+	t.Cleanup(a.Close)
+	return a
+}
+
+func (a *fakeAgent) url(t *testing.T) *url.URL {
+	u, err := url.Parse(a.URL)
+	require.NoError(t, err)
+	return u
+}
+
+func (a *fakeAgent) Payloads() []StatsPayload {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.payloads
+}
+
+func buildSketch(t *testing.T, values ...float64) []byte {
+	sketch := newSketch()
+	for _, v := range values {
+		require.NoError(t, sketch.Add(v))
+	}
+	b, err := proto.Marshal(sketch.ToProto())
+	require.NoError(t, err)
+	return b
+}
+
+func decodeSketch(t *testing.T, b []byte) *ddsketch.DDSketch {
+	var msg sketchpb.DDSketch
+	require.NoError(t, proto.Unmarshal(b, &msg))
+	sketch, err := ddsketch.FromProto(&msg)
+	require.NoError(t, err)
+	return sketch
+}
+
+func sortedBuckets(p StatsPayload) []StatsBucket {
+	sort.Slice(p.Stats, func(i, j int) bool {
+		if p.Stats[i].Start != p.Stats[j].Start {
+			return p.Stats[i].Start < p.Stats[j].Start
+		}
+		if len(p.Stats[i].Stats) == 0 || len(p.Stats[j].Stats) == 0 {
+			return len(p.Stats[i].Stats) < len(p.Stats[j].Stats)
+		}
+		return p.Stats[i].Stats[0].TimestampType < p.Stats[j].Stats[0].TimestampType
+	})
+	for _, b := range p.Stats {
+		sort.Slice(b.Stats, func(i, j int) bool { return b.Stats[i].Hash < b.Stats[j].Hash })
+	}
+	return p.Stats
+}
+
+func TestProcessorFlush(t *testing.T) {
+	p := NewProcessor(nil, "env", "service", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	tp1 := time.Now().Truncate(bucketDuration)
+	tp2 := tp1.Add(time.Minute)
+
+	p.add(statsPoint{
+		edgeTags:       []string{"type:edge-1"},
+		hash:           2,
+		parentHash:     1,
+		timestamp:      tp2.UnixNano(),
+		pathwayLatency: time.Second.Nanoseconds(),
+		edgeLatency:    time.Second.Nanoseconds(),
+	})
+	p.add(statsPoint{
+		edgeTags:       []string{"type:edge-1"},
+		hash:           2,
+		parentHash:     1,
+		timestamp:      tp2.UnixNano(),
+		pathwayLatency: (5 * time.Second).Nanoseconds(),
+		edgeLatency:    (2 * time.Second).Nanoseconds(),
+	})
+	p.add(statsPoint{
+		edgeTags:       []string{"type:edge-1"},
+		hash:           3,
+		parentHash:     1,
+		timestamp:      tp2.UnixNano(),
+		pathwayLatency: (5 * time.Second).Nanoseconds(),
+		edgeLatency:    (2 * time.Second).Nanoseconds(),
+	})
+	p.add(statsPoint{
+		edgeTags:       []string{"type:edge-1"},
+		hash:           2,
+		parentHash:     1,
+		timestamp:      tp1.UnixNano(),
+		pathwayLatency: (5 * time.Second).Nanoseconds(),
+		edgeLatency:    (2 * time.Second).Nanoseconds(),
+	})
+	// the current buckets are not flushed
+	got := p.flush(tp1.Add(bucketDuration - time.Nanosecond))
+	assert.Len(t, got.Stats, 1)
+	assert.Equal(t, uint64(tp1.Add(-5*time.Second).Truncate(bucketDuration).UnixNano()), got.Stats[0].Start)
+	assert.Equal(t, TimestampTypeOrigin, got.Stats[0].Stats[0].TimestampType)
+
+	got = p.flush(tp1.Add(bucketDuration))
+	assert.Equal(t, StatsPayload{
+		Env:           "env",
+		Service:       "service",
+		TracerVersion: version.Tag,
+		Lang:          "go",
+		Stats: []StatsBucket{{
+			Start:    uint64(tp1.UnixNano()),
+			Duration: uint64(bucketDuration.Nanoseconds()),
+			Stats: []StatsPoint{{
+				EdgeTags:       []string{"type:edge-1"},
+				Hash:           2,
+				ParentHash:     1,
+				PathwayLatency: buildSketch(t, 5),
+				EdgeLatency:    buildSketch(t, 2),
+				TimestampType:  TimestampTypeCurrent,
+			}},
+			Backlogs: []Backlog{},
+		}},
+	}, got)
+
+	got = p.flush(tp2.Add(bucketDuration))
+	buckets := sortedBuckets(got)
+	// the origin timestamps of all points fall in the bucket preceding tp2
+	require.Len(t, buckets, 2)
+	assert.Equal(t, uint64(tp2.Add(-bucketDuration).UnixNano()), buckets[0].Start)
+	assert.Equal(t, TimestampTypeOrigin, buckets[0].Stats[0].TimestampType)
+	current := buckets[1]
+	assert.Equal(t, uint64(tp2.UnixNano()), current.Start)
+	require.Len(t, current.Stats, 2)
+	assert.Equal(t, TimestampTypeCurrent, current.Stats[0].TimestampType)
+	assert.Equal(t, uint64(2), current.Stats[0].Hash)
+	assert.Equal(t, buildSketch(t, 1, 5), current.Stats[0].PathwayLatency)
+	assert.Equal(t, buildSketch(t, 1, 2), current.Stats[0].EdgeLatency)
+	assert.Equal(t, uint64(3), current.Stats[1].Hash)
+
+	// everything was flushed
+	assert.Empty(t, p.tsTypeCurrentBuckets)
+	assert.Empty(t, p.tsTypeOriginBuckets)
+}
+
+func TestKafkaOffsets(t *testing.T) {
+	p := NewProcessor(nil, "env", "service", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	now := time.Now().Truncate(bucketDuration)
+	p.time = func() time.Time { return now }
+	p.TrackKafkaProduceOffset("topic1", 1, 10)
+	p.TrackKafkaProduceOffset("topic1", 1, 12)
+	p.TrackKafkaCommitOffset("group1", "topic1", 1, 5)
+	p.TrackKafkaCommitOffset("group1", "topic1", 1, 7)
+	p.flushInput()
+
+	got := p.flush(now.Add(bucketDuration))
+	require.Len(t, got.Stats, 1)
+	backlogs := got.Stats[0].Backlogs
+	sort.Slice(backlogs, func(i, j int) bool { return len(backlogs[i].Tags) < len(backlogs[j].Tags) })
+	assert.Equal(t, []Backlog{
+		{Tags: []string{"partition:1", "topic:topic1", "type:kafka_produce"}, Value: 12},
+		{Tags: []string{"consumer_group:group1", "partition:1", "topic:topic1", "type:kafka_commit"}, Value: 7},
+	}, backlogs)
+}
+
+func TestProcessorSendToAgent(t *testing.T) {
+	agent := newFakeAgent(t)
+	p := NewProcessor(nil, "env", "service", agent.url(t), nil)
+	p.Start()
+	defer p.Stop()
+
+	ctx := context.Background()
+	_, ctx = p.SetCheckpoint(ctx, "direction:out", "topic:topic1", "type:kafka")
+	pathway, _ := p.SetCheckpoint(ctx, "direction:in", "group:group1", "topic:topic1", "type:kafka")
+	p.TrackKafkaProduceOffset("topic1", 0, 3)
+	p.Flush()
+
+	payloads := agent.Payloads()
+	require.Len(t, payloads, 1)
+	payload := payloads[0]
+	assert.Equal(t, "env", payload.Env)
+	assert.Equal(t, "service", payload.Service)
+	assert.Equal(t, "go", payload.Lang)
+	assert.Equal(t, "gzip", agent.headers[0].Get("Content-Encoding"))
+	assert.Equal(t, "application/msgpack", agent.headers[0].Get("Content-Type"))
+
+	var points []StatsPoint
+	var backlogs []Backlog
+	for _, b := range payload.Stats {
+		assert.Equal(t, uint64(bucketDuration.Nanoseconds()), b.Duration)
+		points = append(points, b.Stats...)
+		backlogs = append(backlogs, b.Backlogs...)
+	}
+	// each checkpoint is reported in both a current and an origin bucket
+	require.Len(t, points, 4)
+	var found bool
+	for _, point := range points {
+		if point.Hash != pathway.GetHash() {
+			continue
+		}
+		found = true
+		assert.Equal(t, []string{"direction:in", "group:group1", "topic:topic1", "type:kafka"}, point.EdgeTags)
+		assert.NotZero(t, point.ParentHash)
+		assert.Equal(t, 1.0, decodeSketch(t, point.PathwayLatency).GetCount())
+	}
+	assert.True(t, found)
+	assert.Equal(t, []Backlog{{Tags: []string{"partition:0", "topic:topic1", "type:kafka_produce"}, Value: 3}}, backlogs)
+}
+
+func TestProcessorStop(t *testing.T) {
+	agent := newFakeAgent(t)
+	p := NewProcessor(nil, "env", "service", agent.url(t), nil)
+	p.Start()
+	p.SetCheckpoint(context.Background(), "direction:out", "topic:topic1", "type:kafka")
+	p.Stop()
+	// stopping twice is a no-op
+	p.Stop()
+	// flushing a stopped processor is a no-op
+	p.Flush()
+
+	payloads := agent.Payloads()
+	require.Len(t, payloads, 1)
+	assert.Len(t, payloads[0].Stats, 2)
+}
+
+func TestProcessorAgentError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("oops"))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	p := NewProcessor(nil, "env", "service", u, nil)
+	err = p.transport.sendPipelineStats(&StatsPayload{})
+	assert.EqualError(t, err, "oops (Status: Internal Server Error)")
+
+	p.tsTypeCurrentBuckets[0] = newBucket(0, uint64(bucketDuration))
+	p.sendToAgent(p.flush(time.Now()))
+	assert.EqualValues(t, 1, p.stats.flushErrors)
+	assert.EqualValues(t, 1, p.stats.flushedPayloads)
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package datastreams
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+type contextKey struct{}
+
+var activePathwayKey = contextKey{}
+
+const (
+	// PropagationKeyBase64 is the key to use to propagate the pathway between services.
+	PropagationKeyBase64 = "dd-pathway-ctx-base64"
+)
+
+// Encode encodes the pathway into a binary representation: the pathway hash followed by
+// the pathway and edge start times, as varint encoded milliseconds.
+func (p Pathway) Encode() []byte {
+	data := make([]byte, 8+2*binary.MaxVarintLen64)
+	binary.LittleEndian.PutUint64(data, p.hash)
+	n := 8
+	n += binary.PutVarint(data[n:], p.pathwayStart.UnixNano()/int64(time.Millisecond))
+	n += binary.PutVarint(data[n:], p.edgeStart.UnixNano()/int64(time.Millisecond))
+	return data[:n]
+}
+
+// Decode decodes a pathway previously encoded with Encode.
+func Decode(ctx context.Context, data []byte) (p Pathway, outCtx context.Context, err error) {
+	if len(data) < 8 {
+		return p, ctx, errors.New("hash smaller than 8 bytes")
+	}
+	p.hash = binary.LittleEndian.Uint64(data)
+	data = data[8:]
+	pathwayStart, n := binary.Varint(data)
+	if n <= 0 {
+		return p, ctx, errors.New("failed to read pathway start")
+	}
+	data = data[n:]
+	edgeStart, n := binary.Varint(data)
+	if n <= 0 {
+		return p, ctx, errors.New("failed to read edge start")
+	}
+	p.pathwayStart = time.Unix(0, pathwayStart*int64(time.Millisecond))
+	p.edgeStart = time.Unix(0, edgeStart*int64(time.Millisecond))
+	return p, ContextWithPathway(ctx, p), nil
+}
+
+// EncodeBase64 encodes the pathway into a base64 string, suitable for message headers.
+func (p Pathway) EncodeBase64() string {
+	return base64.StdEncoding.EncodeToString(p.Encode())
+}
+
+// DecodeBase64 decodes a pathway previously encoded with EncodeBase64.
+func DecodeBase64(ctx context.Context, str string) (p Pathway, outCtx context.Context, err error) {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return p, ctx, err
+	}
+	return Decode(ctx, data)
+}
+
+// ContextWithPathway returns a copy of the given context which includes the pathway p.
+func ContextWithPathway(ctx context.Context, p Pathway) context.Context {
+	return context.WithValue(ctx, activePathwayKey, p)
+}
+
+// PathwayFromContext returns the pathway contained in a context, if present.
+func PathwayFromContext(ctx context.Context) (p Pathway, ok bool) {
+	if ctx == nil {
+		return p, false
+	}
+	v := ctx.Value(activePathwayKey)
+	if p, ok := v.(Pathway); ok {
+		return p, true
+	}
+	return p, false
+}
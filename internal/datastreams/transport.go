@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package datastreams
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/version"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// defaultClient is used when no HTTP client is provided. It doesn't use the default
+// transport, as it might be augmented with tracing.
+var defaultClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+	Timeout: 10 * time.Second,
+}
+
+type httpTransport struct {
+	url     string            // the delivery URL for pipeline stats
+	client  *http.Client      // the HTTP client used in the POST
+	headers map[string]string // the Transport headers
+}
+
+func newHTTPTransport(agentURL *url.URL, client *http.Client) *httpTransport {
+	defaultHeaders := map[string]string{
+		"Datadog-Meta-Lang":             "go",
+		"Datadog-Meta-Lang-Version":     strings.TrimPrefix(runtime.Version(), "go"),
+		"Datadog-Meta-Lang-Interpreter": runtime.Compiler + "-" + runtime.GOARCH + "-" + runtime.GOOS,
+		"Datadog-Meta-Tracer-Version":   version.Tag,
+		"Content-Type":                  "application/msgpack",
+		"Content-Encoding":              "gzip",
+	}
+	if cid := internal.ContainerID(); cid != "" {
+		defaultHeaders["Datadog-Container-ID"] = cid
+	}
+	if client == nil {
+		client = defaultClient
+	}
+	return &httpTransport{
+		url:     fmt.Sprintf("%s/v0.1/pipeline_stats", strings.TrimSuffix(agentURL.String(), "/")),
+		client:  client,
+		headers: defaultHeaders,
+	}
+}
+
+// sendPipelineStats sends the msgpack encoded and gzipped payload to the agent.
+func (t *httpTransport) sendPipelineStats(p *StatsPayload) error {
+	var buf bytes.Buffer
+	gzipWriter, err := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if err != nil {
+		return err
+	}
+	if err := msgp.Encode(gzipWriter, p); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", t.url, &buf)
+	if err != nil {
+		return err
+	}
+	for header, value := range t.headers {
+		req.Header.Set(header, value)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if code := resp.StatusCode; code >= 400 {
+		// error, check the body for context information and
+		// return a nice error.
+		msg := make([]byte, 1000)
+		n, _ := io.ReadFull(resp.Body, msg)
+		txt := http.StatusText(code)
+		if n > 0 {
+			return fmt.Errorf("%s (Status: %s)", msg[:n], txt)
+		}
+		return fmt.Errorf("%s", txt)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package dyninst implements dynamic instrumentation: probes received through remote
+// configuration run at predefined points of the traced code, such as when a span starts
+// or finishes and when a HTTP or gRPC server receives a request. Span decoration probes
+// add tags to the span at the point, and log probes upload rate-limited snapshots of the
+// span tags and of the values captured at the point to the agent's debugger endpoint.
+package dyninst
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+)
+
+// Point identifies a location of the instrumented code at which probes can run.
+type Point string
+
+const (
+	// StartSpan is the point at which the tracer started a span.
+	StartSpan Point = "start_span"
+	// FinishSpan is the point at which a span is about to finish.
+	FinishSpan Point = "finish_span"
+	// HTTPServer is the point at which a HTTP server started the span of a request.
+	HTTPServer Point = "http_server"
+	// GRPCServer is the point at which a gRPC server started the span of a call.
+	GRPCServer Point = "grpc_server"
+)
+
+// SpanInfo describes the span on which probes run.
+type SpanInfo struct {
+	TraceID   uint64
+	SpanID    uint64
+	Operation string
+	Service   string
+	Resource  string
+	// Tags holds the string and numeric tags of the span.
+	Tags map[string]interface{}
+}
+
+// Config configures dynamic instrumentation.
+type Config struct {
+	// AgentURL is the URL of the agent receiving the snapshots.
+	AgentURL *url.URL
+	// HTTPClient is the client used to upload the snapshots. A default client is used if nil.
+	HTTPClient *http.Client
+	// Service, Env and Version identify the application in the snapshots.
+	Service, Env, Version string
+	// SpanInfo returns the description of the spans on which probes run.
+	SpanInfo func(ddtrace.Span) SpanInfo
+}
+
+// instrumenter holds the installed probes and uploads the snapshots of the log probes.
+type instrumenter struct {
+	cfg      Config
+	uploader *uploader
+
+	mu     sync.Mutex        // guards byPath
+	byPath map[string]*probe // installed probes by remote configuration path
+
+	// probes holds the installed probes by point, as a map[Point][]*probe which is
+	// replaced on every update.
+	probes atomic.Value
+}
+
+// current holds the running *instrumenter, nil when dynamic instrumentation is stopped.
+var current atomic.Value
+
+func init() {
+	current.Store((*instrumenter)(nil))
+}
+
+// Start starts dynamic instrumentation, replacing any running instance. The probes are
+// installed through the remote configuration updates passed to OnRemoteConfigUpdate.
+// The returned function stops the started instance, and has no effect once another
+// instance has replaced it.
+func Start(cfg Config) (stop func()) {
+	i := &instrumenter{
+		cfg:      cfg,
+		uploader: newUploader(cfg),
+		byPath:   make(map[string]*probe),
+	}
+	i.probes.Store(map[Point][]*probe{})
+	if old := current.Swap(i).(*instrumenter); old != nil {
+		old.stop()
+	}
+	log.Debug("Dynamic instrumentation: started")
+	return func() {
+		if current.CompareAndSwap(i, (*instrumenter)(nil)) {
+			i.stop()
+		}
+	}
+}
+
+// Stop stops dynamic instrumentation, removing all the probes and uploading the
+// pending snapshots.
+func Stop() {
+	if i := current.Swap((*instrumenter)(nil)).(*instrumenter); i != nil {
+		i.stop()
+	}
+}
+
+func (i *instrumenter) stop() {
+	i.uploader.stop()
+	log.Debug("Dynamic instrumentation: stopped")
+}
+
+func load() *instrumenter {
+	return current.Load().(*instrumenter)
+}
+
+// Enabled reports whether probes are installed at point p. The instrumented code should
+// check it before gathering the values captured by Run.
+func Enabled(p Point) bool {
+	i := load()
+	return i != nil && len(i.probes.Load().(map[Point][]*probe)[p]) > 0
+}
+
+// Run runs the probes installed at point p on span, given the values captured at the
+// point by name. Span decoration probes set their tags on span, and log probes emit a
+// snapshot of the span tags and of args.
+func Run(p Point, span ddtrace.Span, args map[string]interface{}) {
+	i := load()
+	if i == nil {
+		return
+	}
+	probes := i.probes.Load().(map[Point][]*probe)[p]
+	if len(probes) == 0 {
+		return
+	}
+	var info SpanInfo
+	if i.cfg.SpanInfo != nil {
+		info = i.cfg.SpanInfo(span)
+	}
+	for _, pr := range probes {
+		if !pr.Where.match(&info) {
+			continue
+		}
+		switch pr.Type {
+		case SpanDecorationProbe:
+			for _, t := range pr.Tags {
+				span.SetTag(t.Name, pr.render(t.Value, &info, args))
+			}
+		case LogProbe:
+			if !pr.limiter.Allow() {
+				continue
+			}
+			i.uploader.add(i.newSnapshot(pr, p, &info, args))
+		}
+	}
+}
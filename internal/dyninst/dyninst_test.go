@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package dyninst
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/ddtrace"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/remoteconfig"
+
+	rc "github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSpan is a ddtrace.Span recording its tags.
+type testSpan struct {
+	ddtrace.Span
+	tags map[string]interface{}
+}
+
+func (s *testSpan) SetTag(k string, v interface{}) { s.tags[k] = v }
+
+func testSpanInfo(sp ddtrace.Span) SpanInfo {
+	return SpanInfo{
+		TraceID:   1,
+		SpanID:    2,
+		Operation: "http.request",
+		Service:   "web",
+		Resource:  "GET /users",
+		Tags:      sp.(*testSpan).tags,
+	}
+}
+
+func update(path, probe string) map[string]remoteconfig.ProductUpdate {
+	u := remoteconfig.ProductUpdate{path: nil}
+	if probe != "" {
+		u[path] = []byte(probe)
+	}
+	return map[string]remoteconfig.ProductUpdate{ProductLiveDebugging: u}
+}
+
+func TestParseProbe(t *testing.T) {
+	for _, tt := range []struct {
+		name, probe, err string
+	}{
+		{name: "invalid", probe: `{`, err: "error unmarshalling JSON"},
+		{name: "no-id", probe: `{"type":"LOG_PROBE","where":{"point":"start_span"}}`, err: "missing probe id"},
+		{name: "unknown-point", probe: `{"id":"p","type":"LOG_PROBE","where":{"point":"main"}}`, err: "unknown point"},
+		{name: "unknown-type", probe: `{"id":"p","type":"METRIC_PROBE","where":{"point":"start_span"}}`, err: "unsupported probe type"},
+		{name: "no-tags", probe: `{"id":"p","type":"SPAN_DECORATION_PROBE","where":{"point":"start_span"}}`, err: "no tags"},
+		{name: "empty-tag", probe: `{"id":"p","type":"SPAN_DECORATION_PROBE","where":{"point":"start_span"},"tags":[{"value":"v"}]}`, err: "empty tag name"},
+		{name: "log", probe: `{"id":"p","type":"LOG_PROBE","where":{"point":"finish_span"}}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseProbe([]byte(tt.probe))
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "p", p.ID)
+		})
+	}
+
+	t.Run("rate", func(t *testing.T) {
+		p, err := parseProbe([]byte(`{"id":"p","type":"LOG_PROBE","where":{"point":"start_span"}}`))
+		require.NoError(t, err)
+		assert.EqualValues(t, defaultLogsPerSecond, p.limiter.Limit())
+
+		p, err = parseProbe([]byte(`{"id":"p","type":"LOG_PROBE","captureSnapshot":true,"where":{"point":"start_span"}}`))
+		require.NoError(t, err)
+		assert.EqualValues(t, defaultSnapshotsPerSecond, p.limiter.Limit())
+
+		p, err = parseProbe([]byte(`{"id":"p","type":"LOG_PROBE","sampling":{"snapshotsPerSecond":10},"where":{"point":"start_span"}}`))
+		require.NoError(t, err)
+		assert.EqualValues(t, 10, p.limiter.Limit())
+	})
+}
+
+func TestRender(t *testing.T) {
+	var p probe
+	info := &SpanInfo{Tags: map[string]interface{}{"http.url": "/users", "method": "POST"}}
+	args := map[string]interface{}{"method": "GET", "duration": 2 * time.Second}
+
+	assert.Equal(t, "no reference", p.render("no reference", info, args))
+	assert.Equal(t, "GET /users in 2s", p.render("{method} {http.url} in { duration }", info, args))
+	assert.Equal(t, "missing UNDEFINED", p.render("missing {user}", info, args))
+	assert.Equal(t, "unclosed {method", p.render("unclosed {method", info, args))
+	assert.Len(t, p.render("{long}", info, map[string]interface{}{"long": strings.Repeat("a", 1000)}), maxValueLength)
+}
+
+func TestRemoteConfig(t *testing.T) {
+	stop := Start(Config{SpanInfo: testSpanInfo})
+	defer stop()
+
+	statuses := OnRemoteConfigUpdate(update("decoration", `{
+		"id": "decoration",
+		"type": "SPAN_DECORATION_PROBE",
+		"where": {"point": "start_span"},
+		"tags": [{"name": "probe.tag", "value": "{method}"}]
+	}`))
+	assert.Equal(t, rc.ApplyStateAcknowledged, statuses["decoration"].State)
+	assert.True(t, Enabled(StartSpan))
+	assert.False(t, Enabled(FinishSpan))
+
+	statuses = OnRemoteConfigUpdate(update("invalid", `{"id": "invalid"}`))
+	assert.Equal(t, rc.ApplyStateError, statuses["invalid"].State)
+	assert.NotEmpty(t, statuses["invalid"].Error)
+
+	statuses = OnRemoteConfigUpdate(update("decoration", ""))
+	assert.Equal(t, rc.ApplyStateAcknowledged, statuses["decoration"].State)
+	assert.False(t, Enabled(StartSpan))
+
+	statuses = OnRemoteConfigUpdate(map[string]remoteconfig.ProductUpdate{"APM_TRACING": {"path": []byte("{}")}})
+	assert.Empty(t, statuses)
+}
+
+func TestStop(t *testing.T) {
+	stop := Start(Config{})
+	OnRemoteConfigUpdate(update("log", `{"id": "log", "type": "LOG_PROBE", "where": {"point": "start_span"}}`))
+	require.True(t, Enabled(StartSpan))
+
+	// a newer instance isn't stopped by the previous one
+	stop2 := Start(Config{})
+	stop()
+	assert.NotNil(t, load())
+	assert.False(t, Enabled(StartSpan))
+	stop2()
+	assert.Nil(t, load())
+
+	// updates are ignored while stopped
+	OnRemoteConfigUpdate(update("log", `{"id": "log", "type": "LOG_PROBE", "where": {"point": "start_span"}}`))
+	assert.False(t, Enabled(StartSpan))
+	Run(StartSpan, &testSpan{tags: map[string]interface{}{}}, nil)
+}
+
+func TestRun(t *testing.T) {
+	defer func(old time.Duration) { uploadInterval = old }(uploadInterval)
+	uploadInterval = 10 * time.Millisecond
+
+	var (
+		mu     sync.Mutex
+		events []snapshotEvent
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/debugger/v1/input", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var batch []snapshotEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		mu.Lock()
+		events = append(events, batch...)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	stop := Start(Config{
+		AgentURL:   u,
+		HTTPClient: srv.Client(),
+		Service:    "web",
+		Env:        "prod",
+		Version:    "1.2",
+		SpanInfo:   testSpanInfo,
+	})
+	OnRemoteConfigUpdate(update("decoration", `{
+		"id": "decoration",
+		"type": "SPAN_DECORATION_PROBE",
+		"where": {"point": "http_server", "resource": "GET /users"},
+		"tags": [{"name": "probe.method", "value": "{method}"}]
+	}`))
+	OnRemoteConfigUpdate(update("other", `{
+		"id": "other",
+		"type": "SPAN_DECORATION_PROBE",
+		"where": {"point": "http_server", "service": "db"},
+		"tags": [{"name": "probe.other", "value": "x"}]
+	}`))
+	OnRemoteConfigUpdate(update("log", `{
+		"id": "log",
+		"version": 3,
+		"type": "LOG_PROBE",
+		"where": {"point": "http_server"},
+		"template": "{method} {span.kind}",
+		"captureSnapshot": true
+	}`))
+
+	span := &testSpan{tags: map[string]interface{}{"span.kind": "server"}}
+	args := map[string]interface{}{"method": "GET"}
+	Run(HTTPServer, span, args)
+	// the snapshots are rate limited to 1 per second by default
+	Run(HTTPServer, span, args)
+	stop()
+
+	assert.Equal(t, "GET", span.tags["probe.method"])
+	assert.NotContains(t, span.tags, "probe.other")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 1)
+	e := events[0]
+	assert.Equal(t, "web", e.Service)
+	assert.Equal(t, "dd_debugger", e.Source)
+	assert.Equal(t, "env:prod,version:1.2", e.Tags)
+	assert.Equal(t, "GET server", e.Message)
+	assert.Equal(t, "1", e.TraceID)
+	assert.Equal(t, "2", e.SpanID)
+	s := e.Debugger.Snapshot
+	assert.NotEmpty(t, s.ID)
+	assert.Equal(t, "go", s.Language)
+	assert.Equal(t, "log", s.Probe.ID)
+	assert.Equal(t, 3, s.Probe.Version)
+	assert.Equal(t, HTTPServer, s.Probe.Location.Type)
+	require.NotNil(t, s.Captures)
+	assert.Equal(t, capturedValue{Type: "string", Value: "GET"}, s.Captures.Entry.Arguments["method"])
+	assert.Equal(t, "server", s.Captures.Entry.Fields["span.kind"].Value)
+}
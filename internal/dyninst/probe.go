@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package dyninst
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// ProbeType is the type of a probe.
+type ProbeType string
+
+const (
+	// LogProbe emits snapshots of the values captured at its point.
+	LogProbe ProbeType = "LOG_PROBE"
+	// SpanDecorationProbe sets tags on the span of its point.
+	SpanDecorationProbe ProbeType = "SPAN_DECORATION_PROBE"
+)
+
+const (
+	// defaultSnapshotsPerSecond is the default rate limit of the log probes capturing snapshots.
+	defaultSnapshotsPerSecond = 1
+
+	// defaultLogsPerSecond is the default rate limit of the log probes emitting messages only.
+	defaultLogsPerSecond = 5000
+
+	// maxValueLength is the length after which the captured values are truncated.
+	maxValueLength = 255
+
+	// undefinedValue replaces the template references to missing values.
+	undefinedValue = "UNDEFINED"
+)
+
+// probe is a probe definition, as received through remote configuration.
+type probe struct {
+	ID      string    `json:"id"`
+	Version int       `json:"version"`
+	Type    ProbeType `json:"type"`
+	Where   where     `json:"where"`
+
+	// Template is the message of the snapshots of a log probe. It can reference the
+	// captured values and the span tags by name between braces, e.g. "GET {http.url}".
+	Template string `json:"template"`
+
+	// CaptureSnapshot makes a log probe capture the values and the span tags in its snapshots.
+	CaptureSnapshot bool `json:"captureSnapshot"`
+
+	Sampling struct {
+		SnapshotsPerSecond float64 `json:"snapshotsPerSecond"`
+	} `json:"sampling"`
+
+	// Tags are set by a span decoration probe. Their values are templates.
+	Tags []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"tags"`
+
+	limiter *rate.Limiter
+}
+
+// where specifies the point at which a probe runs, and the spans it runs on.
+// Empty span filters match all the spans.
+type where struct {
+	Point     Point  `json:"point"`
+	Operation string `json:"operation"`
+	Service   string `json:"service"`
+	Resource  string `json:"resource"`
+}
+
+// match reports whether the probe runs on the span described by info.
+func (w *where) match(info *SpanInfo) bool {
+	return (w.Operation == "" || w.Operation == info.Operation) &&
+		(w.Service == "" || w.Service == info.Service) &&
+		(w.Resource == "" || w.Resource == info.Resource)
+}
+
+// parseProbe parses and validates the probe definition raw.
+func parseProbe(raw []byte) (*probe, error) {
+	var p probe
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %v", err)
+	}
+	if p.ID == "" {
+		return nil, fmt.Errorf("missing probe id")
+	}
+	switch p.Where.Point {
+	case StartSpan, FinishSpan, HTTPServer, GRPCServer:
+	default:
+		return nil, fmt.Errorf("probe %s: unknown point %q", p.ID, p.Where.Point)
+	}
+	switch p.Type {
+	case LogProbe:
+		sps := p.Sampling.SnapshotsPerSecond
+		if sps <= 0 {
+			sps = defaultLogsPerSecond
+			if p.CaptureSnapshot {
+				sps = defaultSnapshotsPerSecond
+			}
+		}
+		p.limiter = rate.NewLimiter(rate.Limit(sps), 1)
+	case SpanDecorationProbe:
+		if len(p.Tags) == 0 {
+			return nil, fmt.Errorf("probe %s: no tags to decorate the span with", p.ID)
+		}
+		for _, t := range p.Tags {
+			if t.Name == "" {
+				return nil, fmt.Errorf("probe %s: empty tag name", p.ID)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("probe %s: unsupported probe type %q", p.ID, p.Type)
+	}
+	return &p, nil
+}
+
+// render returns template with its references replaced by the named values of args or,
+// if not found, of the span tags.
+func (p *probe) render(template string, info *SpanInfo, args map[string]interface{}) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(template[:start])
+		name := strings.TrimSpace(template[start+1 : start+end])
+		if v, ok := lookup(name, info, args); ok {
+			b.WriteString(formatValue(v))
+		} else {
+			b.WriteString(undefinedValue)
+		}
+		template = template[start+end+1:]
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+// lookup returns the value named name in args or, if not found, in the span tags.
+func lookup(name string, info *SpanInfo, args map[string]interface{}) (interface{}, bool) {
+	if v, ok := args[name]; ok {
+		return v, true
+	}
+	v, ok := info.Tags[name]
+	return v, ok
+}
+
+// formatValue returns the string representation of v, truncated to maxValueLength.
+func formatValue(v interface{}) string {
+	s := fmt.Sprintf("%v", v)
+	if len(s) > maxValueLength {
+		s = s[:maxValueLength]
+	}
+	return s
+}
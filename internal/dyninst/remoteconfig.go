@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package dyninst
+
+import (
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/remoteconfig"
+
+	rc "github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
+)
+
+// ProductLiveDebugging is the remote configuration product delivering the probes, one
+// probe per configuration file.
+const ProductLiveDebugging = "LIVE_DEBUGGING"
+
+// OnRemoteConfigUpdate is a remote configuration callback installing, updating and removing
+// the probes received through the LIVE_DEBUGGING product. The updates are ignored while
+// dynamic instrumentation is stopped.
+func OnRemoteConfigUpdate(updates map[string]remoteconfig.ProductUpdate) map[string]rc.ApplyStatus {
+	statuses := map[string]rc.ApplyStatus{}
+	u, ok := updates[ProductLiveDebugging]
+	if !ok {
+		return statuses
+	}
+	i := load()
+	if i == nil {
+		return statuses
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for path, raw := range u {
+		if raw == nil {
+			log.Debug("Dynamic instrumentation: probe %s removed", path)
+			delete(i.byPath, path)
+			statuses[path] = rc.ApplyStatus{State: rc.ApplyStateAcknowledged}
+			continue
+		}
+		p, err := parseProbe(raw)
+		if err != nil {
+			log.Error("Dynamic instrumentation: could not install probe %s: %v", path, err)
+			statuses[path] = rc.ApplyStatus{State: rc.ApplyStateError, Error: err.Error()}
+			continue
+		}
+		log.Debug("Dynamic instrumentation: installing %s %s (version %d) at %s", p.Type, p.ID, p.Version, p.Where.Point)
+		i.byPath[path] = p
+		statuses[path] = rc.ApplyStatus{State: rc.ApplyStateAcknowledged}
+	}
+	probes := make(map[Point][]*probe)
+	for _, p := range i.byPath {
+		probes[p.Where.Point] = append(probes[p.Where.Point], p)
+	}
+	i.probes.Store(probes)
+	return statuses
+}
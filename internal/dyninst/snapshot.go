@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package dyninst
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// snapshotEvent is a log event holding the snapshot of a log probe, in the format of the
+// debugger intake.
+type snapshotEvent struct {
+	Service  string `json:"service"`
+	Source   string `json:"ddsource"`
+	Tags     string `json:"ddtags,omitempty"`
+	Message  string `json:"message"`
+	TraceID  string `json:"dd.trace_id,omitempty"`
+	SpanID   string `json:"dd.span_id,omitempty"`
+	Debugger struct {
+		Snapshot snapshot `json:"snapshot"`
+	} `json:"debugger"`
+}
+
+type snapshot struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"` // in milliseconds
+	Language  string `json:"language"`
+	Probe     struct {
+		ID       string `json:"id"`
+		Version  int    `json:"version"`
+		Location struct {
+			Type Point `json:"type"`
+		} `json:"location"`
+	} `json:"probe"`
+	Captures *captures `json:"captures,omitempty"`
+}
+
+type captures struct {
+	Entry struct {
+		// Arguments holds the values captured at the point.
+		Arguments map[string]capturedValue `json:"arguments,omitempty"`
+		// Fields holds the span tags.
+		Fields map[string]capturedValue `json:"fields,omitempty"`
+	} `json:"entry"`
+}
+
+type capturedValue struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// newSnapshot returns the snapshot of the log probe pr, run at point p.
+func (i *instrumenter) newSnapshot(pr *probe, p Point, info *SpanInfo, args map[string]interface{}) *snapshotEvent {
+	e := &snapshotEvent{
+		Service: i.cfg.Service,
+		Source:  "dd_debugger",
+		Message: pr.render(pr.Template, info, args),
+	}
+	var tags []string
+	if i.cfg.Env != "" {
+		tags = append(tags, "env:"+i.cfg.Env)
+	}
+	if i.cfg.Version != "" {
+		tags = append(tags, "version:"+i.cfg.Version)
+	}
+	e.Tags = strings.Join(tags, ",")
+	if info.TraceID != 0 {
+		e.TraceID = strconv.FormatUint(info.TraceID, 10)
+		e.SpanID = strconv.FormatUint(info.SpanID, 10)
+	}
+	s := &e.Debugger.Snapshot
+	s.ID = uuid.New().String()
+	s.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+	s.Language = "go"
+	s.Probe.ID = pr.ID
+	s.Probe.Version = pr.Version
+	s.Probe.Location.Type = p
+	if pr.CaptureSnapshot {
+		s.Captures = &captures{}
+		s.Captures.Entry.Arguments = captureValues(args)
+		s.Captures.Entry.Fields = captureValues(info.Tags)
+	}
+	return e
+}
+
+// captureValues returns the captured representation of values.
+func captureValues(values map[string]interface{}) map[string]capturedValue {
+	if len(values) == 0 {
+		return nil
+	}
+	m := make(map[string]capturedValue, len(values))
+	for k, v := range values {
+		s := fmt.Sprintf("%v", v)
+		m[k] = capturedValue{
+			Type:      fmt.Sprintf("%T", v),
+			Value:     formatValue(s),
+			Truncated: len(s) > maxValueLength,
+		}
+	}
+	return m
+}
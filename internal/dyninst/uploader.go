@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package dyninst
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/version"
+)
+
+const (
+	// maxBatchSize is the number of snapshots triggering an upload.
+	maxBatchSize = 100
+
+	// maxQueueSize is the number of pending snapshots after which new ones are dropped.
+	maxQueueSize = 1000
+)
+
+// uploadInterval is the interval at which pending snapshots are uploaded; replaced in tests.
+var uploadInterval = time.Second
+
+// defaultClient is used when no HTTP client is provided. It doesn't use the default
+// transport, as it might be augmented with tracing.
+var defaultClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+	Timeout: 10 * time.Second,
+}
+
+// uploader uploads the snapshots to the agent's debugger endpoint in batches.
+type uploader struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex // guards pending
+	pending []*snapshotEvent
+
+	flush chan struct{} // signals a full batch
+	exit  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func newUploader(cfg Config) *uploader {
+	u := &uploader{
+		client: cfg.HTTPClient,
+		flush:  make(chan struct{}, 1),
+		exit:   make(chan struct{}),
+	}
+	if u.client == nil {
+		u.client = defaultClient
+	}
+	if cfg.AgentURL != nil {
+		u.url = strings.TrimSuffix(cfg.AgentURL.String(), "/") + "/debugger/v1/input"
+	}
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		u.run()
+	}()
+	return u
+}
+
+// add queues the snapshot e for upload.
+func (u *uploader) add(e *snapshotEvent) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.pending) >= maxQueueSize {
+		log.Debug("Dynamic instrumentation: dropping snapshot of probe %s: too many pending snapshots", e.Debugger.Snapshot.Probe.ID)
+		return
+	}
+	u.pending = append(u.pending, e)
+	if len(u.pending) == maxBatchSize {
+		select {
+		case u.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (u *uploader) run() {
+	tick := time.NewTicker(uploadInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			u.upload()
+		case <-u.flush:
+			u.upload()
+		case <-u.exit:
+			u.upload()
+			return
+		}
+	}
+}
+
+// stop uploads the pending snapshots and stops the uploader.
+func (u *uploader) stop() {
+	close(u.exit)
+	u.wg.Wait()
+}
+
+// upload uploads the pending snapshots in batches of at most maxBatchSize.
+func (u *uploader) upload() {
+	u.mu.Lock()
+	pending := u.pending
+	u.pending = nil
+	u.mu.Unlock()
+	for len(pending) > 0 {
+		n := len(pending)
+		if n > maxBatchSize {
+			n = maxBatchSize
+		}
+		if err := u.send(pending[:n]); err != nil {
+			log.Error("Dynamic instrumentation: failed to upload %d snapshots: %v", n, err)
+		}
+		pending = pending[n:]
+	}
+}
+
+// send sends the JSON encoded batch of snapshots to the agent.
+func (u *uploader) send(batch []*snapshotEvent) error {
+	if u.url == "" {
+		return fmt.Errorf("no agent URL")
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", u.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Datadog-Meta-Lang", "go")
+	req.Header.Set("Datadog-Meta-Tracer-Version", version.Tag)
+	if cid := internal.ContainerID(); cid != "" {
+		req.Header.Set("Datadog-Container-ID", cid)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if code := resp.StatusCode; code >= 400 {
+		msg := make([]byte, 1000)
+		n, _ := io.ReadFull(resp.Body, msg)
+		txt := http.StatusText(code)
+		if n > 0 {
+			return fmt.Errorf("%s (Status: %s)", msg[:n], txt)
+		}
+		return fmt.Errorf("%s", txt)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ package globalconfig
 import (
 	"math"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,13 +18,28 @@ import (
 var cfg = &config{
 	analyticsRate: math.NaN(),
 	runtimeID:     uuid.New().String(),
+	logsInjection: true,
 }
 
 type config struct {
 	mu            sync.RWMutex
 	analyticsRate float64
 	serviceName   string
+	env           string
+	version       string
 	runtimeID     string
+	headersAsTags map[string]string
+	logsInjection bool
+	statsd        StatsdClient
+}
+
+// StatsdClient is the subset of the tracer's statsd client which integrations may use to
+// report metrics.
+type StatsdClient interface {
+	Incr(name string, tags []string, rate float64) error
+	Count(name string, value int64, tags []string, rate float64) error
+	Gauge(name string, value float64, tags []string, rate float64) error
+	Timing(name string, value time.Duration, tags []string, rate float64) error
 }
 
 // AnalyticsRate returns the sampling rate at which events should be marked. It uses
@@ -56,9 +72,84 @@ func SetServiceName(name string) {
 	cfg.serviceName = name
 }
 
+// Env returns the environment of the running tracer, or an empty string if the tracer
+// isn't running.
+func Env() string {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return cfg.env
+}
+
+// Version returns the application version of the running tracer, or an empty string if
+// the tracer isn't running.
+func Version() string {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return cfg.version
+}
+
+// SetEnvAndVersion sets the environment and application version of the running tracer.
+// They are reset when the tracer stops.
+func SetEnvAndVersion(env, version string) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.env = env
+	cfg.version = version
+}
+
 // RuntimeID returns this process's unique runtime id.
 func RuntimeID() string {
 	cfg.mu.RLock()
 	defer cfg.mu.RUnlock()
 	return cfg.runtimeID
 }
+
+// HeaderTagMap returns the mapping of headers to span tags configured through DD_TRACE_HEADER_TAGS
+// or tracer.WithHeaderTags. Keys are lowercased header names and values are the tags they should be
+// reported as; an empty value means that the integration should use its default tag name for the
+// header. The returned map must not be modified.
+func HeaderTagMap() map[string]string {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return cfg.headersAsTags
+}
+
+// SetHeaderTags replaces the mapping of headers to span tags. The given map must not be modified
+// after being passed.
+func SetHeaderTags(headersAsTags map[string]string) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.headersAsTags = headersAsTags
+}
+
+// LogsInjection reports whether the log correlation integrations should add the trace
+// and span details to the log records. It is enabled by default.
+func LogsInjection() bool {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return cfg.logsInjection
+}
+
+// SetLogsInjection enables or disables the injection of the trace and span details in the
+// log records by the log correlation integrations.
+func SetLogsInjection(enabled bool) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.logsInjection = enabled
+}
+
+// Statsd returns the statsd client of the running tracer, or nil if the tracer
+// isn't running.
+func Statsd() StatsdClient {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return cfg.statsd
+}
+
+// SetStatsd sets the statsd client of the running tracer. It is reset to nil when
+// the tracer stops.
+func SetStatsd(c StatsdClient) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.statsd = c
+}
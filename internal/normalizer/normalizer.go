@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+// Package normalizer provides functions to normalize user-provided configuration values,
+// such as the headers to be reported as span tags.
+package normalizer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/lannguyen-c0x12c/dd-trace-go/internal/log"
+)
+
+// headerTagRegexp matches the characters of a header name which are not allowed in a span tag.
+var headerTagRegexp = regexp.MustCompile("[^a-z0-9_-]")
+
+// HeaderTag parses a header to tag mapping of the form "header[:tag]", as found in DD_TRACE_HEADER_TAGS.
+// It returns the lowercased header name and the tag it should be reported as. The tag is empty when
+// it isn't specified, in which case HeaderTagName should be used to compute it. When several colons
+// are present, the last one separates the header from the tag.
+func HeaderTag(headerAsTag string) (header string, tag string) {
+	headerAsTag = strings.TrimSpace(headerAsTag)
+	if i := strings.LastIndex(headerAsTag, ":"); i >= 0 {
+		headerAsTag, tag = headerAsTag[:i], strings.TrimSpace(headerAsTag[i+1:])
+	}
+	return strings.ToLower(strings.TrimSpace(headerAsTag)), tag
+}
+
+// HeaderTagName returns the span tag the header should be reported as. The tag is returned as-is if it is
+// not empty. Otherwise, it is made of the given prefix (e.g. ext.HTTPRequestHeaders) followed by the
+// normalized header name, in which characters other than letters, digits, hyphens and underscores are
+// replaced by underscores.
+func HeaderTagName(prefix, header, tag string) string {
+	if tag != "" {
+		return tag
+	}
+	return prefix + "." + headerTagRegexp.ReplaceAllString(strings.ToLower(header), "_")
+}
+
+// HeaderTags returns the header to tag mapping described by the given "header[:tag]" entries,
+// as parsed by HeaderTag. Empty and invalid entries are ignored. The returned map is never nil.
+func HeaderTags(headerAsTags []string) map[string]string {
+	m := make(map[string]string, len(headerAsTags))
+	for _, h := range headerAsTags {
+		if strings.TrimSpace(h) == "" {
+			continue
+		}
+		header, tag := HeaderTag(h)
+		if header == "" {
+			log.Warn("Ignoring header tag %q: the header name is empty", h)
+			continue
+		}
+		m[header] = tag
+	}
+	return m
+}
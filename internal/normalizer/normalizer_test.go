@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023 Datadog, Inc.
+
+package normalizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderTag(t *testing.T) {
+	for _, tt := range []struct {
+		in, header, tag string
+	}{
+		{in: "X-Header", header: "x-header"},
+		{in: "  x-header  ", header: "x-header"},
+		{in: "X-Header:my.tag", header: "x-header", tag: "my.tag"},
+		{in: " X-Header : My.Tag ", header: "x-header", tag: "My.Tag"},
+		{in: "X:Header:tag", header: "x:header", tag: "tag"},
+		{in: "X-Header:", header: "x-header"},
+		{in: ":tag", header: "", tag: "tag"},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			header, tag := HeaderTag(tt.in)
+			assert.Equal(t, tt.header, header)
+			assert.Equal(t, tt.tag, tag)
+		})
+	}
+}
+
+func TestHeaderTagName(t *testing.T) {
+	const prefix = "http.request.headers"
+	assert.Equal(t, "my.tag", HeaderTagName(prefix, "x-header", "my.tag"))
+	assert.Equal(t, "http.request.headers.x-header", HeaderTagName(prefix, "X-Header", ""))
+	assert.Equal(t, "http.request.headers.x_my_header_", HeaderTagName(prefix, "x.my header!", ""))
+	assert.Equal(t, "http.response.headers.content-type", HeaderTagName("http.response.headers", "Content-Type", ""))
+}
+
+func TestHeaderTags(t *testing.T) {
+	assert.Equal(t, map[string]string{}, HeaderTags(nil))
+	assert.Equal(t, map[string]string{
+		"x-header":     "",
+		"content-type": "ctype",
+	}, HeaderTags([]string{"X-Header", "", " ", ":invalid", "Content-Type:ctype"}))
+}
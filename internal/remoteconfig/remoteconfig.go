@@ -49,6 +49,20 @@ const (
 	ASMResponseBlocking
 	// ASMUserBlocking represents the capability for ASM to block requests based on user ID
 	ASMUserBlocking
+	// ASMCustomRules represents the capability for ASM to receive and use user-defined security rules
+	ASMCustomRules
+	// ASMCustomBlockingResponse represents the capability for ASM to receive and use user-defined blocking responses
+	ASMCustomBlockingResponse
+	// ASMTrustedIPs represents the capability for ASM to receive a list of IPs excluded from its protections
+	ASMTrustedIPs
+	// ASMApiSecuritySampleRate represents the capability for ASM to receive the API security sample rate
+	ASMApiSecuritySampleRate
+	// APMTracingSampleRate represents the capability to update the tracer's trace sampling rate and rules
+	APMTracingSampleRate
+	// APMTracingLogsInjection represents the capability to enable or disable the tracer's log correlation
+	APMTracingLogsInjection
+	// APMTracingHTTPHeaderTags represents the capability to update the headers reported as span tags by the tracer
+	APMTracingHTTPHeaderTags
 )
 
 // ProductUpdate represents an update for a specific product.
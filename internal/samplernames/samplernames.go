@@ -34,4 +34,13 @@ const (
 	// SingleSpan specifies that the span was sampled by single
 	// span sampling rules.
 	SingleSpan SamplerName = 8
+	// TailSampling specifies that the trace was sampled by tail sampling
+	// rules, once it completed.
+	TailSampling SamplerName = 9
+	// AdaptiveRate specifies that the span was sampled with a rate adjusted
+	// by the tracer to meet a traces-per-second budget.
+	AdaptiveRate SamplerName = 10
+	// RootOutcome specifies that the trace was kept when its root span
+	// finished, because of an error or of its duration.
+	RootOutcome SamplerName = 12
 )